@@ -3,8 +3,10 @@ package main
 import (
 	"context"
 	"hackathon-agent/internal/app"
+	"hackathon-agent/internal/cli"
 	"hackathon-agent/internal/config"
 	"hackathon-agent/pkg/logger"
+	"os"
 	"os/signal"
 	"syscall"
 
@@ -17,6 +19,13 @@ func main() {
 	ctx, stop := signal.NotifyContext(ctx, syscall.SIGINT, syscall.SIGTERM)
 	defer stop()
 
+	// agent run — разовый запуск задачи без Kafka и конфига
+	if len(os.Args) > 1 && os.Args[1] == cli.CommandRun {
+		code := cli.Run(ctx, os.Args[2:], os.Stdin, os.Stdout, os.Stderr)
+		stop()
+		os.Exit(code)
+	}
+
 	cfg := config.MustLoadConfig()
 	config.MustPrintConfig(cfg)
 
@@ -0,0 +1,74 @@
+// Package admin HTTP обработчики служебного листенера агента: liveness, readiness и метрики.
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+)
+
+const (
+	statusOK       = "ok"
+	statusNotReady = "not ready"
+)
+
+// ReadinessCheck возвращает nil, если зависимость готова.
+type ReadinessCheck func() error
+
+type readyResponse struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks"`
+}
+
+// NewHandler собирает обработчики /healthz, /readyz и /metrics.
+// /healthz отвечает 200, пока процесс жив; /readyz — 200, только если все checks вернули nil.
+func NewHandler(metrics http.Handler, checks map[string]ReadinessCheck) http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{"status": statusOK})
+	})
+
+	mux.HandleFunc("GET /readyz", func(w http.ResponseWriter, _ *http.Request) {
+		resp := readyResponse{
+			Status: statusOK,
+			Checks: make(map[string]string, len(checks)),
+		}
+
+		names := make([]string, 0, len(checks))
+		for name := range checks {
+			names = append(names, name)
+		}
+
+		sort.Strings(names)
+
+		for _, name := range names {
+			if err := checks[name](); err != nil {
+				resp.Status = statusNotReady
+				resp.Checks[name] = err.Error()
+
+				continue
+			}
+
+			resp.Checks[name] = statusOK
+		}
+
+		code := http.StatusOK
+		if resp.Status != statusOK {
+			code = http.StatusServiceUnavailable
+		}
+
+		writeJSON(w, code, resp)
+	})
+
+	mux.Handle("GET /metrics", metrics)
+
+	return mux
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -2,39 +2,85 @@ package app
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"hackathon-agent/internal/admin"
+	"hackathon-agent/internal/checks"
 	"hackathon-agent/internal/config"
+	"hackathon-agent/internal/enroll"
+	"hackathon-agent/internal/identity"
+	"hackathon-agent/internal/metrics"
 	"hackathon-agent/internal/service"
+	"hackathon-agent/pkg/gateway"
 	"hackathon-agent/pkg/kafka"
+	"hackathon-agent/pkg/server"
+	"hackathon-contract"
 
 	"go.uber.org/zap"
 )
 
 type App struct {
-	Cfg     *config.Config
-	Log     *zap.Logger
-	EBus    *EBus
-	Service *service.Service
+	Cfg         *config.Config
+	Log         *zap.Logger
+	EBus        *EBus
+	Registry    *checks.Registry
+	Identity    *identity.Identity
+	Metrics     *metrics.Metrics
+	Service     *service.Service
+	Heartbeat   *service.Heartbeater
+	AdminServer server.HTTPServer // AdminServer nil, если admin листенер выключен
 }
 
+var (
+	errConsumerNotJoined = errors.New("consumer has not joined the group")
+	errUnknownTransport  = errors.New("unknown transport")
+)
+
 type EBus struct {
 	Consumer kafka.ConsumerGroupRunner
 	Producer kafka.Producer
 }
 
 func New(cfg *config.Config, log *zap.Logger) (*App, error) {
+	registry, err := initRegistry(cfg, log)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := initEnrollment(cfg, log); err != nil {
+		return nil, err
+	}
+
+	id, err := initIdentity(cfg, log)
+	if err != nil {
+		return nil, err
+	}
+
 	eBus, err := initEBus(cfg, log)
 	if err != nil {
 		return nil, err
 	}
 
-	svc := initService(cfg, log, eBus)
+	mtr := metrics.New()
+
+	svc := initService(cfg, log, eBus, registry, mtr, id)
+
+	caps := initCapabilities(log, registry)
+
+	heartbeat := initHeartbeat(cfg, log, eBus, svc, mtr, caps, id)
+
+	adminServer := initAdminServer(cfg, log, eBus, svc, mtr)
 
 	return &App{
-		Cfg:     cfg,
-		Log:     log,
-		EBus:    eBus,
-		Service: svc,
+		Cfg:         cfg,
+		Log:         log,
+		EBus:        eBus,
+		Registry:    registry,
+		Identity:    id,
+		Metrics:     mtr,
+		Service:     svc,
+		Heartbeat:   heartbeat,
+		AdminServer: adminServer,
 	}, nil
 }
 
@@ -48,6 +94,16 @@ func MustNew(cfg *config.Config, log *zap.Logger) *App {
 }
 
 func (a *App) Run(ctx context.Context) error {
+	if a.AdminServer != nil {
+		go func() {
+			if err := a.AdminServer.Run(); err != nil {
+				a.Log.Error("Admin server stopped", zap.Error(err))
+			}
+		}()
+	}
+
+	go a.Heartbeat.Run(ctx)
+
 	if err := a.Service.Run(ctx); err != nil {
 		return fmt.Errorf("failed to run service: %w", err)
 	}
@@ -60,13 +116,36 @@ func (a *App) Shutdown() error {
 		return fmt.Errorf("failed to stop service: %w", err)
 	}
 
+	if a.AdminServer != nil {
+		if err := a.AdminServer.Shutdown(); err != nil {
+			return fmt.Errorf("failed to shutdown admin server: %w", err)
+		}
+	}
+
 	return nil
 }
 
 func initEBus(cfg *config.Config, log *zap.Logger) (*EBus, error) {
+	switch cfg.App.Transport {
+	case contract.TransportKafka:
+		return initKafkaEBus(cfg, log)
+	case contract.TransportHTTP:
+		return initGatewayEBus(cfg, log), nil
+	default:
+		return nil, fmt.Errorf("%w %q, expected %q or %q", errUnknownTransport, cfg.App.Transport, contract.TransportKafka, contract.TransportHTTP)
+	}
+}
+
+func initKafkaEBus(cfg *config.Config, log *zap.Logger) (*EBus, error) {
+	// задачи региона адресованы конкретным агентам, поэтому каждый агент читает топик своей consumer group
+	groupID := cfg.Subscriber.GroupID
+	if groupID == "" {
+		groupID = "agent-" + cfg.App.AgentID.String()
+	}
+
 	consumerGroup, err := kafka.NewConsumerGroupRunner(
 		cfg.Subscriber.Brokers,
-		cfg.Subscriber.GroupID,
+		groupID,
 		[]string{cfg.Subscriber.Topic},
 		cfg.Subscriber.BufferSize,
 		kafka.WithBalancerConsumer(kafka.RoundrobinBalanceStrategy),
@@ -96,7 +175,162 @@ func initEBus(cfg *config.Config, log *zap.Logger) (*EBus, error) {
 	}, nil
 }
 
-func initService(cfg *config.Config, log *zap.Logger, eBus *EBus) *service.Service {
-	svc := service.NewService(log, eBus.Consumer, eBus.Producer, cfg.Publisher.Topic)
+// initGatewayEBus HTTP транспорт: задачи агента long-poll'ом, результаты и heartbeat POST'ом в gateway бэкенда.
+func initGatewayEBus(cfg *config.Config, log *zap.Logger) *EBus {
+	gatewayCfg := gateway.Config{
+		URL:      cfg.Gateway.URL,
+		Token:    cfg.Gateway.Token,
+		AgentID:  cfg.App.AgentID,
+		PollWait: cfg.Gateway.PollWait,
+		Timeout:  cfg.Gateway.Timeout,
+	}
+
+	consumer := gateway.NewConsumer(gatewayCfg, cfg.Subscriber.BufferSize)
+
+	go func() {
+		startAndRunningStr := <-consumer.Info()
+
+		log.Info(startAndRunningStr)
+	}()
+
+	go func() {
+		for err := range consumer.Error() {
+			log.Warn("Gateway consumer error", zap.Error(err))
+		}
+	}()
+
+	log.Info("Gateway transport initialized", zap.String("url", cfg.Gateway.URL), zap.String("region", cfg.App.Region))
+
+	return &EBus{
+		Consumer: consumer,
+		Producer: gateway.NewProducer(gatewayCfg),
+	}
+}
+
+func initRegistry(cfg *config.Config, log *zap.Logger) (*checks.Registry, error) {
+	registry, err := checks.NewBuiltinRegistry(cfg.Checks.Enabled, cfg.Checks.Disabled)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init checks registry: %w", err)
+	}
+
+	log.Info("Checks registry initialized", zap.Strings("enabled", registry.Enabled()))
+
+	return registry, nil
+}
+
+// initCapabilities определяет возможности хоста один раз при старте, бэкенд получает их в каждом heartbeat.
+func initCapabilities(log *zap.Logger, registry *checks.Registry) contract.Capabilities {
+	caps, unavailable := checks.DetectCapabilities(registry)
+
+	for name, err := range unavailable {
+		log.Warn("Check is unavailable on this host", zap.String("check", name), zap.Error(err))
+	}
+
+	log.Info("Capabilities detected",
+		zap.Strings("checks", caps.Checks),
+		zap.Bool("ipv4", caps.IPv4),
+		zap.Bool("ipv6", caps.IPv6),
+		zap.Bool("icmp", caps.ICMP),
+	)
+
+	return caps
+}
+
+// initEnrollment до identity: регистрация задаёт agent_id и кладёт ключ бэкенда на диск.
+func initEnrollment(cfg *config.Config, log *zap.Logger) error {
+	resp, enrolled, err := enroll.Apply(context.Background(), cfg)
+	if err != nil {
+		return fmt.Errorf("failed to enroll agent: %w", err)
+	}
+
+	if resp == nil {
+		return nil
+	}
+
+	if enrolled {
+		log.Info("Agent enrolled", zap.String("agentID", resp.AgentID.String()), zap.String("state", cfg.Enrollment.State))
+	}
+
+	log.Info("Enrollment applied", zap.String("region", resp.Region), zap.String("transport", cfg.App.Transport))
+
+	return nil
+}
+
+// initIdentity ключ агента для подписи результатов и heartbeat и ключ бэкенда для проверки задач.
+func initIdentity(cfg *config.Config, log *zap.Logger) (*identity.Identity, error) {
+	id, generated, err := identity.Load(cfg.App.AgentID, cfg.Identity.PrivateKey, cfg.Identity.BackendPublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init identity: %w", err)
+	}
+
+	if generated {
+		log.Warn("Agent key generated, enroll the agent so the backend accepts it", zap.String("path", cfg.Identity.PrivateKey))
+	}
+
+	log.Info("Identity initialized", zap.String("agentID", cfg.App.AgentID.String()))
+
+	return id, nil
+}
+
+func initService(cfg *config.Config, log *zap.Logger, eBus *EBus, registry *checks.Registry, mtr *metrics.Metrics, id *identity.Identity) *service.Service {
+	svc := service.NewService(log, eBus.Consumer, eBus.Producer, cfg.Publisher.Topic, registry, mtr, cfg.App.AgentID, id)
+	mtr.RegisterWorkerPool(svc.Workers(), svc)
 	return svc
 }
+
+func initHeartbeat(cfg *config.Config, log *zap.Logger, eBus *EBus, svc *service.Service, mtr *metrics.Metrics, caps contract.Capabilities, id *identity.Identity) *service.Heartbeater {
+	var location *contract.Location
+	if cfg.App.Lat != 0 || cfg.App.Lon != 0 {
+		location = &contract.Location{Lat: cfg.App.Lat, Lon: cfg.App.Lon}
+	}
+
+	heartbeat := service.NewHeartbeater(
+		log,
+		eBus.Producer,
+		cfg.Heartbeat.Topic,
+		cfg.Heartbeat.Interval,
+		cfg.App.AgentID,
+		cfg.App.Region,
+		cfg.App.Country,
+		cfg.App.ASN,
+		location,
+		cfg.App.Version,
+		cfg.App.Transport,
+		svc,
+		mtr,
+		caps,
+		id,
+	)
+
+	log.Info("Heartbeat initialized", zap.String("topic", cfg.Heartbeat.Topic), zap.Duration("interval", cfg.Heartbeat.Interval))
+
+	return heartbeat
+}
+
+// initAdminServer служебный листенер для liveness/readiness проб и Prometheus.
+// Готовность: consumer в группе с активной сессией и producer видит брокеров, для http — последние poll и сообщение дошли до бэкенда.
+func initAdminServer(cfg *config.Config, log *zap.Logger, eBus *EBus, svc *service.Service, mtr *metrics.Metrics) server.HTTPServer {
+	if !cfg.Admin.Enabled {
+		return nil
+	}
+
+	readiness := map[string]admin.ReadinessCheck{
+		cfg.App.Transport + "_consumer": func() error {
+			if !eBus.Consumer.Joined() {
+				return errConsumerNotJoined
+			}
+
+			return nil
+		},
+		cfg.App.Transport + "_producer": eBus.Producer.Ready,
+	}
+
+	adminServer := server.NewHTTPServer(
+		server.WithAddr(cfg.Admin.Host, cfg.Admin.Port),
+		server.WithHandler(admin.NewHandler(mtr.Handler(), readiness)),
+	)
+
+	log.Info("Admin server initialized", zap.String("host", cfg.Admin.Host), zap.Uint16("port", cfg.Admin.Port))
+
+	return adminServer
+}
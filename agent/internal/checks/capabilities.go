@@ -0,0 +1,71 @@
+package checks
+
+import (
+	"hackathon-contract"
+	"net"
+	"time"
+)
+
+// адреса, до которых проверяется наличие маршрута. UDP dial пакетов не отправляет,
+// но падает, если у хоста нет адреса или маршрута нужного семейства.
+const (
+	probeIPv4Addr = "8.8.8.8:53"
+	probeIPv6Addr = "[2001:4860:4860::8888]:53"
+	probeTimeout  = time.Second
+)
+
+// DetectCapabilities определяет возможности хоста: какие из включённых проверок могут работать,
+// есть ли IPv4/IPv6 и права на raw ICMP сокет. Вторым значением возвращает причины, почему проверки недоступны.
+func DetectCapabilities(r *Registry) (contract.Capabilities, map[string]error) {
+	caps := contract.Capabilities{
+		Checks: make([]string, 0),
+		IPv4:   canRoute("udp4", probeIPv4Addr),
+		IPv6:   canRoute("udp6", probeIPv6Addr),
+		ICMP:   canRawICMP(),
+	}
+
+	unavailable := make(map[string]error)
+
+	for _, name := range r.Enabled() {
+		c, err := r.Lookup(name)
+		if err != nil {
+			unavailable[name] = err
+
+			continue
+		}
+
+		if p, ok := c.(Prober); ok {
+			if err := p.Probe(); err != nil {
+				unavailable[name] = err
+
+				continue
+			}
+		}
+
+		caps.Checks = append(caps.Checks, name)
+	}
+
+	return caps, unavailable
+}
+
+func canRoute(network, addr string) bool {
+	conn, err := net.DialTimeout(network, addr, probeTimeout)
+	if err != nil {
+		return false
+	}
+
+	_ = conn.Close()
+
+	return true
+}
+
+func canRawICMP() bool {
+	conn, err := net.ListenPacket("ip4:icmp", "0.0.0.0")
+	if err != nil {
+		return false
+	}
+
+	_ = conn.Close()
+
+	return true
+}
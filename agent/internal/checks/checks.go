@@ -0,0 +1,235 @@
+// Package checks содержит проверки, которые умеет выполнять агент, и реестр,
+// через который сервис их находит. Каждая проверка самодостаточна: чтобы добавить
+// новый тип, достаточно реализовать Checker и зарегистрировать его в Builtin.
+package checks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hackathon-contract"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	ErrUnsupportedCheck         = errors.New("unsupported check type")
+	ErrCheckDisabled            = errors.New("check type is disabled on this agent")
+	ErrCheckerAlreadyRegistered = errors.New("checker already registered")
+)
+
+// MakeResFunc собирает contract.CheckResult для текущей проверки: таск, индекс, время старта уже подставлены.
+type MakeResFunc func(ok bool, err error, payload any) contract.CheckResult
+
+// ProgressFunc публикует промежуточный результат проверки с частичным payload
+// (например, очередной хоп traceroute). Номер сообщения и флаг final проставляет сервис.
+type ProgressFunc func(payload any)
+
+// Checker описывает один тип проверки.
+type Checker interface {
+	// Name тип проверки, как он приходит в CheckRequest.Type.
+	Name() string
+	// Defaults параметры со значениями по умолчанию, по ним же видна схема параметров.
+	Defaults() any
+	// Normalize приводит сырые параметры из задачи к схеме и подставляет значения по умолчанию.
+	Normalize(params map[string]interface{}) (map[string]interface{}, error)
+	// Timeout индивидуальный таймаут проверки.
+	Timeout() time.Duration
+	// Run выполняет проверку с уже нормализованными параметрами. Долгие проверки
+	// могут сообщать о прогрессе через progress, итог всегда возвращается через makeRes.
+	Run(ctx context.Context, target string, params map[string]interface{}, makeRes MakeResFunc, progress ProgressFunc) contract.CheckResult
+}
+
+// Prober реализуют проверки, которым нужно что-то от хоста (системная утилита, права).
+// Probe возвращает ошибку, если проверка на этом хосте работать не будет.
+type Prober interface {
+	Probe() error
+}
+
+// Builtin возвращает все встроенные проверки агента.
+func Builtin() []Checker {
+	return []Checker{
+		NewHTTP(),
+		NewPing(),
+		NewTCP(),
+		NewTraceroute(),
+		NewDNS(),
+		NewWebSocket(),
+		NewGRPC(),
+		NewThroughput(),
+	}
+}
+
+type Registry struct {
+	mu       sync.RWMutex
+	checkers map[string]Checker
+	disabled map[string]struct{}
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		checkers: make(map[string]Checker),
+		disabled: make(map[string]struct{}),
+	}
+}
+
+// NewBuiltinRegistry регистрирует встроенные проверки с учётом конфига агента:
+// пустой enabled означает "все", disabled применяется поверх.
+func NewBuiltinRegistry(enabled, disabled []string) (*Registry, error) {
+	r := NewRegistry()
+
+	for _, c := range Builtin() {
+		if err := r.Register(c); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, name := range append(append([]string{}, enabled...), disabled...) {
+		if _, ok := r.checkers[strings.ToLower(name)]; !ok {
+			return nil, fmt.Errorf("%w: %q", ErrUnsupportedCheck, name)
+		}
+	}
+
+	if len(enabled) > 0 {
+		allow := make(map[string]struct{}, len(enabled))
+		for _, name := range enabled {
+			allow[strings.ToLower(name)] = struct{}{}
+		}
+
+		for name := range r.checkers {
+			if _, ok := allow[name]; !ok {
+				r.disabled[name] = struct{}{}
+			}
+		}
+	}
+
+	for _, name := range disabled {
+		r.disabled[strings.ToLower(name)] = struct{}{}
+	}
+
+	return r, nil
+}
+
+func (r *Registry) Register(c Checker) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	name := strings.ToLower(c.Name())
+	if _, ok := r.checkers[name]; ok {
+		return fmt.Errorf("%w: %q", ErrCheckerAlreadyRegistered, name)
+	}
+
+	r.checkers[name] = c
+
+	return nil
+}
+
+// Lookup ищет включённую проверку по типу без учёта регистра.
+func (r *Registry) Lookup(name string) (Checker, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	name = strings.ToLower(name)
+
+	c, ok := r.checkers[name]
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnsupportedCheck, name)
+	}
+
+	if _, off := r.disabled[name]; off {
+		return nil, fmt.Errorf("%w: %q", ErrCheckDisabled, name)
+	}
+
+	return c, nil
+}
+
+// Enabled возвращает отсортированные имена включённых проверок.
+func (r *Registry) Enabled() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.checkers))
+	for name := range r.checkers {
+		if _, off := r.disabled[name]; !off {
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// normalizeHeaders приводит headers к map: "" -> убрать; строка с JSON -> разобрать
+func normalizeHeaders(params map[string]interface{}) {
+	v, ok := params["headers"]
+	if !ok {
+		return
+	}
+	s, ok := v.(string)
+	if !ok {
+		return
+	}
+	str := strings.TrimSpace(s)
+	if !strings.HasPrefix(str, "{") {
+		delete(params, "headers")
+		return
+	}
+	var m map[string]string
+	if json.Unmarshal([]byte(str), &m) != nil {
+		delete(params, "headers")
+		return
+	}
+	params["headers"] = m
+}
+
+func parseRange2Int(s string) ([2]int, error) {
+	s = strings.TrimSpace(s)
+	s = strings.TrimPrefix(s, "[")
+	s = strings.TrimSuffix(s, "]")
+	parts := strings.Split(s, ",")
+	if len(parts) != 2 {
+		return [2]int{}, errors.New("range must be like [a,b]")
+	}
+	a, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+	if err != nil {
+		return [2]int{}, err
+	}
+	b, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+	if err != nil {
+		return [2]int{}, err
+	}
+	return [2]int{a, b}, nil
+}
+
+func toInt(v interface{}) int {
+	switch t := v.(type) {
+	case float64:
+		return int(t)
+	case int:
+		return t
+	case json.Number:
+		i, _ := t.Int64()
+		return int(i)
+	default:
+		return 0
+	}
+}
+
+func nonEmpty(s, def string) string {
+	if strings.TrimSpace(s) == "" {
+		return def
+	}
+	return s
+}
+
+func tail(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	return s[len(s)-max:]
+}
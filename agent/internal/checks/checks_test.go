@@ -0,0 +1,132 @@
+package checks
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"hackathon-contract"
+)
+
+type fakeChecker struct {
+	name string
+}
+
+func (c fakeChecker) Name() string {
+	return c.name
+}
+
+func (fakeChecker) Defaults() any {
+	return struct{}{}
+}
+
+func (fakeChecker) Normalize(params map[string]interface{}) (map[string]interface{}, error) {
+	return params, nil
+}
+
+func (fakeChecker) Timeout() time.Duration {
+	return time.Second
+}
+
+func (fakeChecker) Run(_ context.Context, _ string, _ map[string]interface{}, makeRes MakeResFunc, _ ProgressFunc) contract.CheckResult {
+	return makeRes(true, nil, nil)
+}
+
+func TestBuiltinRegistryLookup(t *testing.T) {
+	tests := []struct {
+		name     string
+		enabled  []string
+		disabled []string
+		lookup   string
+		wantErr  error
+	}{
+		{name: "all enabled", lookup: "dns"},
+		{name: "case insensitive", lookup: "HTTP"},
+		{name: "unknown check", lookup: "smtp", wantErr: ErrUnsupportedCheck},
+		{name: "enabled list", enabled: []string{"http", "DNS"}, lookup: "dns"},
+		{name: "not in enabled list", enabled: []string{"http"}, lookup: "dns", wantErr: ErrCheckDisabled},
+		{name: "disabled", disabled: []string{"Ping"}, lookup: "ping", wantErr: ErrCheckDisabled},
+		{name: "disabled wins over enabled", enabled: []string{"ping"}, disabled: []string{"ping"}, lookup: "ping", wantErr: ErrCheckDisabled},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := NewBuiltinRegistry(tt.enabled, tt.disabled)
+			if err != nil {
+				t.Fatalf("NewBuiltinRegistry: %v", err)
+			}
+
+			c, err := r.Lookup(tt.lookup)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Lookup(%q) = %v, want %v", tt.lookup, err, tt.wantErr)
+			}
+
+			if err == nil && c.Name() != strings.ToLower(tt.lookup) {
+				t.Fatalf("Lookup(%q) returned %q", tt.lookup, c.Name())
+			}
+		})
+	}
+}
+
+func TestBuiltinRegistryUnknownNames(t *testing.T) {
+	if _, err := NewBuiltinRegistry([]string{"http", "smtp"}, nil); !errors.Is(err, ErrUnsupportedCheck) {
+		t.Fatalf("got %v, want %v", err, ErrUnsupportedCheck)
+	}
+
+	if _, err := NewBuiltinRegistry(nil, []string{"smtp"}); !errors.Is(err, ErrUnsupportedCheck) {
+		t.Fatalf("got %v, want %v", err, ErrUnsupportedCheck)
+	}
+}
+
+func TestBuiltinRegistryEnabled(t *testing.T) {
+	r, err := NewBuiltinRegistry(nil, []string{"throughput", "grpc"})
+	if err != nil {
+		t.Fatalf("NewBuiltinRegistry: %v", err)
+	}
+
+	want := []string{"dns", "http", "ping", "tcp", "traceroute", "websocket"}
+	if got := r.Enabled(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("Enabled() = %v, want %v", got, want)
+	}
+}
+
+func TestBuiltinCoversContract(t *testing.T) {
+	var names []string
+	for _, c := range Builtin() {
+		names = append(names, c.Name())
+	}
+
+	for _, checkType := range contract.CheckTypes() {
+		if !slices.Contains(names, checkType) {
+			t.Errorf("no builtin checker for contract check type %q", checkType)
+		}
+	}
+}
+
+func TestRegistryRegister(t *testing.T) {
+	r := NewRegistry()
+
+	if err := r.Register(fakeChecker{name: "Echo"}); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+
+	if err := r.Register(fakeChecker{name: "echo"}); !errors.Is(err, ErrCheckerAlreadyRegistered) {
+		t.Fatalf("got %v, want %v", err, ErrCheckerAlreadyRegistered)
+	}
+
+	c, err := r.Lookup("echo")
+	if err != nil {
+		t.Fatalf("Lookup: %v", err)
+	}
+
+	res := c.Run(context.Background(), "example.com", nil, func(ok bool, _ error, _ any) contract.CheckResult {
+		return contract.CheckResult{OK: ok}
+	}, nil)
+	if !res.OK {
+		t.Fatalf("got result %+v from registered checker", res)
+	}
+}
@@ -0,0 +1,40 @@
+package checks
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"os/exec"
+)
+
+// runCommandLines запускает cmd и отдаёт в onLine каждую строку объединённого stdout/stderr,
+// как только утилита её напечатала. Возвращает весь вывод и ошибку завершения, как CombinedOutput.
+func runCommandLines(cmd *exec.Cmd, onLine func(line string)) ([]byte, error) {
+	pr, pw := io.Pipe()
+	cmd.Stdout = pw
+	cmd.Stderr = pw
+
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	waitErr := make(chan error, 1)
+	go func() {
+		err := cmd.Wait()
+		_ = pw.Close()
+		waitErr <- err
+	}()
+
+	var out bytes.Buffer
+	sc := bufio.NewScanner(pr)
+	for sc.Scan() {
+		line := sc.Bytes()
+		out.Write(line)
+		out.WriteByte('\n')
+		onLine(decodeConsole(line))
+	}
+	// дочитываем остаток, чтобы Wait не завис на записи в pipe
+	_, _ = io.Copy(&out, pr)
+
+	return out.Bytes(), <-waitErr
+}
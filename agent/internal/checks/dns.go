@@ -0,0 +1,132 @@
+package checks
+
+import (
+	"context"
+	"encoding/json"
+	"hackathon-contract"
+	"net"
+	"strings"
+	"time"
+)
+
+type dnsChecker struct{}
+
+func NewDNS() Checker {
+	return dnsChecker{}
+}
+
+func (dnsChecker) Name() string {
+	return "dns"
+}
+
+func (dnsChecker) Timeout() time.Duration {
+	return 4 * time.Second
+}
+
+func (dnsChecker) Defaults() any {
+	return contract.DNSParams{Records: []string{"A"}}
+}
+
+// Normalize records строкой: JSON-массив, одна запись или записи через запятую ("A", "A,MX").
+func (dnsChecker) Normalize(params map[string]interface{}) (map[string]interface{}, error) {
+	if v, ok := params["records"]; ok {
+		if s, ok := v.(string); ok {
+			var arr []string
+			if json.Unmarshal([]byte(s), &arr) != nil {
+				arr = nil
+				for _, rr := range strings.Split(s, ",") {
+					if rr = strings.TrimSpace(rr); rr != "" {
+						arr = append(arr, rr)
+					}
+				}
+			}
+			params["records"] = arr
+		}
+	}
+	var dp contract.DNSParams
+	if err := contract.DecodeLoose(params, &dp); err != nil {
+		return nil, err
+	}
+	if len(dp.Records) == 0 {
+		dp.Records = []string{"A"}
+	}
+	return contract.ToMap(dp), nil
+}
+
+func (dnsChecker) Run(ctx context.Context, target string, params map[string]interface{}, makeRes MakeResFunc, _ ProgressFunc) contract.CheckResult {
+	var p contract.DNSParams
+	_ = contract.DecodeLoose(params, &p)
+	return runDNS(ctx, target, p, makeRes)
+}
+
+func runDNS(ctx context.Context, target string, p contract.DNSParams,
+	makeRes MakeResFunc,
+) contract.CheckResult {
+	r := newResolver(p.Resolver, 2*time.Second)
+	results := contract.DNSPayload{Errors: map[string]string{}}
+	var haveError bool
+
+	for _, rr := range p.Records {
+		switch strings.ToUpper(rr) {
+		case "A":
+			ips, err := r.LookupHost(ctx, target)
+			if err != nil {
+				results.Errors["A"] = err.Error()
+				haveError = true
+			} else {
+				var a []string
+				for _, ip := range ips {
+					if parsed := net.ParseIP(ip); parsed != nil && parsed.To4() != nil {
+						a = append(a, ip)
+					}
+				}
+				results.A = a
+			}
+		case "AAAA":
+			ips, err := r.LookupHost(ctx, target)
+			if err != nil {
+				results.Errors["AAAA"] = err.Error()
+				haveError = true
+			} else {
+				var aaaa []string
+				for _, ip := range ips {
+					if parsed := net.ParseIP(ip); parsed != nil && parsed.To4() == nil {
+						aaaa = append(aaaa, ip)
+					}
+				}
+				results.AAAA = aaaa
+			}
+		case "MX":
+			mx, err := r.LookupMX(ctx, target)
+			if err != nil {
+				results.Errors["MX"] = err.Error()
+				haveError = true
+			} else {
+				out := make([]contract.MXRecord, 0, len(mx))
+				for _, rec := range mx {
+					out = append(out, contract.MXRecord{Host: rec.Host, Pref: rec.Pref})
+				}
+				results.MX = out
+			}
+		default:
+			results.Errors[strings.ToUpper(rr)] = "unsupported record type"
+			haveError = true
+		}
+	}
+
+	return makeRes(!haveError, nil, results)
+}
+
+func newResolver(addr string, timeout time.Duration) *net.Resolver {
+	if strings.TrimSpace(addr) == "" {
+		return &net.Resolver{}
+	}
+	a := net.JoinHostPort(addr, "53")
+	d := &net.Dialer{Timeout: timeout}
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			return d.DialContext(ctx, "udp", a)
+		},
+	}
+}
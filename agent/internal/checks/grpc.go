@@ -0,0 +1,131 @@
+package checks
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"hackathon-contract"
+	"net"
+	"strconv"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+type grpcChecker struct{}
+
+func NewGRPC() Checker {
+	return grpcChecker{}
+}
+
+func (grpcChecker) Name() string {
+	return "grpc"
+}
+
+func (grpcChecker) Timeout() time.Duration {
+	return 5 * time.Second
+}
+
+func (grpcChecker) Defaults() any {
+	return contract.GRPCParams{Port: 50051}
+}
+
+func (grpcChecker) Normalize(params map[string]interface{}) (map[string]interface{}, error) {
+	var gp contract.GRPCParams
+	if err := contract.DecodeLoose(params, &gp); err != nil {
+		return nil, err
+	}
+	if gp.Port <= 0 {
+		if gp.TLS {
+			gp.Port = 443
+		} else {
+			gp.Port = 50051
+		}
+	}
+	return contract.ToMap(gp), nil
+}
+
+func (grpcChecker) Run(ctx context.Context, target string, params map[string]interface{}, makeRes MakeResFunc, _ ProgressFunc) contract.CheckResult {
+	var p contract.GRPCParams
+	_ = contract.DecodeLoose(params, &p)
+	return runGRPC(ctx, target, p, makeRes)
+}
+
+func runGRPC(ctx context.Context, target string, p contract.GRPCParams,
+	makeRes MakeResFunc,
+) contract.CheckResult {
+	addr := net.JoinHostPort(target, strconv.Itoa(p.Port))
+
+	creds := insecure.NewCredentials()
+	if p.TLS {
+		creds = credentials.NewTLS(&tls.Config{
+			ServerName:         target,
+			InsecureSkipVerify: p.InsecureSkipVerify, //nolint:gosec // управляется параметром проверки
+			MinVersion:         tls.VersionTLS12,
+		})
+	}
+
+	opts := []grpc.DialOption{grpc.WithTransportCredentials(creds)}
+	if p.Authority != "" {
+		opts = append(opts, grpc.WithAuthority(p.Authority))
+	}
+
+	payload := contract.GRPCPayload{
+		Addr:    addr,
+		Service: p.Service,
+		TLS:     p.TLS,
+	}
+
+	conn, err := grpc.NewClient(addr, opts...)
+	if err != nil {
+		return makeRes(false, err, payload)
+	}
+	defer conn.Close()
+
+	// соединение ленивое: поднимаем руками, чтобы отдельно измерить connect + handshake
+	t0 := time.Now()
+	conn.Connect()
+	if err := waitReady(ctx, conn); err != nil {
+		payload.ConnectMs = time.Since(t0).Milliseconds()
+		return makeRes(false, err, payload)
+	}
+	payload.ConnectMs = time.Since(t0).Milliseconds()
+
+	t1 := time.Now()
+	resp, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{Service: p.Service})
+	payload.RPCMs = time.Since(t1).Milliseconds()
+	if err != nil {
+		if st, ok := status.FromError(err); ok {
+			payload.Code = st.Code().String()
+		}
+		return makeRes(false, err, payload)
+	}
+
+	payload.Status = resp.GetStatus().String()
+	if resp.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+		return makeRes(false, fmt.Errorf("service is %s", resp.GetStatus()), payload)
+	}
+
+	return makeRes(true, nil, payload)
+}
+
+func waitReady(ctx context.Context, conn *grpc.ClientConn) error {
+	for {
+		state := conn.GetState()
+		switch state {
+		case connectivity.Ready:
+			return nil
+		case connectivity.TransientFailure, connectivity.Shutdown:
+			return fmt.Errorf("connection %s", state)
+		}
+		if !conn.WaitForStateChange(ctx, state) {
+			return errors.Join(errors.New("connection not ready"), ctx.Err())
+		}
+	}
+}
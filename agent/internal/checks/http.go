@@ -0,0 +1,120 @@
+package checks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hackathon-contract"
+	"net"
+	"net/http"
+	"time"
+)
+
+type httpChecker struct{}
+
+func NewHTTP() Checker {
+	return httpChecker{}
+}
+
+func (httpChecker) Name() string {
+	return "http"
+}
+
+func (httpChecker) Timeout() time.Duration {
+	return 5 * time.Second
+}
+
+func (httpChecker) Defaults() any {
+	return contract.HTTPParams{
+		Scheme:              "https",
+		Path:                "/",
+		ExpectedStatusRange: [2]int{200, 299},
+	}
+}
+
+func (httpChecker) Normalize(params map[string]interface{}) (map[string]interface{}, error) {
+	// expectedStatusRange: строка "[200,299]" или массив
+	if v, ok := params["expectedStatusRange"]; ok {
+		switch vv := v.(type) {
+		case string:
+			r, err := parseRange2Int(vv)
+			if err != nil {
+				return nil, fmt.Errorf("expectedStatusRange: %w", err)
+			}
+			params["expectedStatusRange"] = [2]int{r[0], r[1]}
+		case []interface{}:
+			if len(vv) != 2 {
+				return nil, errors.New("expectedStatusRange must have 2 elements")
+			}
+			params["expectedStatusRange"] = [2]int{toInt(vv[0]), toInt(vv[1])}
+		}
+	} else {
+		params["expectedStatusRange"] = [2]int{200, 299}
+	}
+	normalizeHeaders(params)
+	var hp contract.HTTPParams
+	if err := contract.DecodeLoose(params, &hp); err != nil {
+		return nil, err
+	}
+	if hp.Scheme == "" {
+		hp.Scheme = "https"
+	}
+	if hp.Path == "" {
+		hp.Path = "/"
+	}
+	if hp.ExpectedStatusRange == ([2]int{}) {
+		hp.ExpectedStatusRange = [2]int{200, 299}
+	}
+	return contract.ToMap(hp), nil
+}
+
+func (httpChecker) Run(ctx context.Context, target string, params map[string]interface{}, makeRes MakeResFunc, _ ProgressFunc) contract.CheckResult {
+	var p contract.HTTPParams
+	_ = contract.DecodeLoose(params, &p)
+	return runHTTP(ctx, target, p, makeRes)
+}
+
+func runHTTP(ctx context.Context, target string, p contract.HTTPParams,
+	makeRes MakeResFunc,
+) contract.CheckResult {
+	url := fmt.Sprintf("%s://%s%s", nonEmpty(p.Scheme, "https"), target, nonEmpty(p.Path, "/"))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return makeRes(false, err, nil)
+	}
+	for k, v := range p.Headers {
+		req.Header.Set(k, v)
+	}
+
+	transport := &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           (&net.Dialer{Timeout: 3 * time.Second}).DialContext,
+		TLSHandshakeTimeout:   5 * time.Second,
+		ResponseHeaderTimeout: 5 * time.Second,
+		IdleConnTimeout:       10 * time.Second,
+	}
+	client := &http.Client{Transport: transport}
+	if !p.FollowRedirects {
+		client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		}
+	}
+
+	t0 := time.Now()
+	resp, err := client.Do(req)
+	latency := time.Since(t0)
+	if err != nil {
+		return makeRes(false, err, contract.HTTPPayload{URL: url})
+	}
+	defer resp.Body.Close()
+
+	ok := resp.StatusCode >= p.ExpectedStatusRange[0] && resp.StatusCode <= p.ExpectedStatusRange[1]
+	payload := contract.HTTPPayload{
+		URL:        url,
+		Status:     resp.StatusCode,
+		LatencyMs:  latency.Milliseconds(),
+		FinalURL:   resp.Request.URL.String(),
+		LimitBytes: p.MaxBodyBytes,
+	}
+	return makeRes(ok, nil, payload)
+}
@@ -0,0 +1,174 @@
+package checks
+
+import (
+	"reflect"
+	"testing"
+
+	"hackathon-contract"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name    string
+		checker Checker
+		params  map[string]interface{}
+		want    any
+	}{
+		{
+			name:    "http defaults",
+			checker: NewHTTP(),
+			params:  map[string]interface{}{},
+			want:    contract.HTTPParams{Scheme: "https", Path: "/", ExpectedStatusRange: [2]int{200, 299}},
+		},
+		{
+			name:    "http range and headers from strings",
+			checker: NewHTTP(),
+			params:  map[string]interface{}{"scheme": "http", "expectedStatusRange": "[200, 204]", "headers": `{"X-Probe":"1"}`},
+			want: contract.HTTPParams{
+				Scheme:              "http",
+				Path:                "/",
+				Headers:             map[string]string{"X-Probe": "1"},
+				ExpectedStatusRange: [2]int{200, 204},
+			},
+		},
+		{
+			name:    "http range array and empty headers",
+			checker: NewHTTP(),
+			params:  map[string]interface{}{"expectedStatusRange": []interface{}{float64(301), float64(302)}, "headers": ""},
+			want:    contract.HTTPParams{Scheme: "https", Path: "/", ExpectedStatusRange: [2]int{301, 302}},
+		},
+		{
+			name:    "ping defaults",
+			checker: NewPing(),
+			params:  map[string]interface{}{},
+			want:    contract.PingParams{Count: 4, IntervalMs: 1000},
+		},
+		{
+			name:    "ping keeps values",
+			checker: NewPing(),
+			params:  map[string]interface{}{"count": float64(10), "intervalMs": float64(500)},
+			want:    contract.PingParams{Count: 10, IntervalMs: 500},
+		},
+		{
+			name:    "tcp default timeout",
+			checker: NewTCP(),
+			params:  map[string]interface{}{"port": float64(443)},
+			want:    contract.TCPParams{Port: 443, ConnectTimeoutMs: 3000},
+		},
+		{
+			name:    "traceroute defaults",
+			checker: NewTraceroute(),
+			params:  map[string]interface{}{},
+			want:    contract.TracerouteParams{Mode: "udp", MaxHops: 30},
+		},
+		{
+			name:    "dns defaults",
+			checker: NewDNS(),
+			params:  map[string]interface{}{},
+			want:    contract.DNSParams{Records: []string{"A"}},
+		},
+		{
+			name:    "dns records from JSON string",
+			checker: NewDNS(),
+			params:  map[string]interface{}{"records": `["AAAA","MX"]`, "resolver": "1.1.1.1"},
+			want:    contract.DNSParams{Records: []string{"AAAA", "MX"}, Resolver: "1.1.1.1"},
+		},
+		{
+			name:    "dns single record",
+			checker: NewDNS(),
+			params:  map[string]interface{}{"records": "MX"},
+			want:    contract.DNSParams{Records: []string{"MX"}},
+		},
+		{
+			name:    "dns comma separated records",
+			checker: NewDNS(),
+			params:  map[string]interface{}{"records": "A, AAAA"},
+			want:    contract.DNSParams{Records: []string{"A", "AAAA"}},
+		},
+		{
+			name:    "websocket defaults",
+			checker: NewWebSocket(),
+			params:  map[string]interface{}{},
+			want:    contract.WebSocketParams{Scheme: "wss", Path: "/", ReadTimeoutMs: 3000},
+		},
+		{
+			name:    "grpc default port without tls",
+			checker: NewGRPC(),
+			params:  map[string]interface{}{},
+			want:    contract.GRPCParams{Port: 50051},
+		},
+		{
+			name:    "grpc default port with tls",
+			checker: NewGRPC(),
+			params:  map[string]interface{}{"tls": true},
+			want:    contract.GRPCParams{Port: 443, TLS: true},
+		},
+		{
+			name:    "throughput defaults",
+			checker: NewThroughput(),
+			params:  map[string]interface{}{},
+			want: contract.ThroughputParams{
+				Scheme:           "https",
+				Path:             "/",
+				MaxBytes:         10 << 20,
+				MaxDurationMs:    10000,
+				SampleIntervalMs: 250,
+				StallThresholdMs: 500,
+			},
+		},
+		{
+			name:    "throughput duration capped by timeout",
+			checker: NewThroughput(),
+			params:  map[string]interface{}{"maxDurationMs": float64(60000), "maxBytes": float64(1024)},
+			want: contract.ThroughputParams{
+				Scheme:           "https",
+				Path:             "/",
+				MaxBytes:         1024,
+				MaxDurationMs:    12000,
+				SampleIntervalMs: 250,
+				StallThresholdMs: 500,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params, err := tt.checker.Normalize(tt.params)
+			if err != nil {
+				t.Fatalf("Normalize: %v", err)
+			}
+
+			got := reflect.New(reflect.TypeOf(tt.want))
+			if err := contract.DecodeLoose(params, got.Interface()); err != nil {
+				t.Fatalf("decode params: %v", err)
+			}
+
+			if !reflect.DeepEqual(got.Elem().Interface(), tt.want) {
+				t.Fatalf("got %+v, want %+v", got.Elem().Interface(), tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		checker Checker
+		params  map[string]interface{}
+	}{
+		{name: "http range with one bound", checker: NewHTTP(), params: map[string]interface{}{"expectedStatusRange": "200"}},
+		{name: "http range array with one bound", checker: NewHTTP(), params: map[string]interface{}{"expectedStatusRange": []interface{}{float64(200)}}},
+		{name: "tcp port of wrong type", checker: NewTCP(), params: map[string]interface{}{"port": "https"}},
+		{name: "websocket http scheme", checker: NewWebSocket(), params: map[string]interface{}{"scheme": "http"}},
+		{name: "throughput inverted range", checker: NewThroughput(), params: map[string]interface{}{"rangeStart": float64(100), "rangeEnd": float64(10)}},
+		{name: "throughput negative start", checker: NewThroughput(), params: map[string]interface{}{"rangeStart": float64(-1)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.checker.Normalize(tt.params); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+}
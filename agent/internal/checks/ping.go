@@ -1 +1,176 @@
 package checks
+
+import (
+	"context"
+	"fmt"
+	"hackathon-contract"
+	"net"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// minUserPingIntervalMs меньший интервал ping (iputils) разрешает только пользователю с правами на raw сокет.
+const minUserPingIntervalMs = 200
+
+type pingChecker struct{}
+
+func NewPing() Checker {
+	return pingChecker{}
+}
+
+func (pingChecker) Name() string {
+	return "ping"
+}
+
+func (pingChecker) Timeout() time.Duration {
+	return 6 * time.Second
+}
+
+func (pingChecker) Defaults() any {
+	return contract.PingParams{Count: 4, IntervalMs: 1000}
+}
+
+func (pingChecker) Normalize(params map[string]interface{}) (map[string]interface{}, error) {
+	var pp contract.PingParams
+	if err := contract.DecodeLoose(params, &pp); err != nil {
+		return nil, err
+	}
+	if pp.Count <= 0 {
+		pp.Count = 4
+	}
+	if pp.IntervalMs <= 0 {
+		pp.IntervalMs = 1000
+	}
+	return contract.ToMap(pp), nil
+}
+
+func (pingChecker) Probe() error {
+	_, err := exec.LookPath("ping")
+	return err
+}
+
+func (pingChecker) Run(ctx context.Context, target string, params map[string]interface{}, makeRes MakeResFunc, progress ProgressFunc) contract.CheckResult {
+	var p contract.PingParams
+	_ = contract.DecodeLoose(params, &p)
+	return runPing(ctx, target, p, makeRes, progress)
+}
+
+func runPing(ctx context.Context, target string, p contract.PingParams,
+	makeRes MakeResFunc, progress ProgressFunc,
+) contract.CheckResult {
+	cmdName := "ping"
+	args := []string{}
+	if runtime.GOOS == "windows" {
+		args = []string{"-n", strconv.Itoa(p.Count), target}
+	} else {
+		// без прав ping откажется запускаться с интервалом меньше 200 мс, проверка выполняется с минимально допустимым
+		if p.IntervalMs < minUserPingIntervalMs && !canRawICMP() {
+			p.IntervalMs = minUserPingIntervalMs
+		}
+		iv := fmt.Sprintf("%.3f", float64(p.IntervalMs)/1000.0)
+		args = []string{"-c", strconv.Itoa(p.Count), "-i", iv, target}
+	}
+	cmd := exec.CommandContext(ctx, cmdName, args...)
+
+	// каждый ответ сразу уходит промежуточным результатом
+	var replies []contract.PingReply
+	ip := ""
+	if net.ParseIP(target) != nil {
+		ip = target
+	}
+	out, err := runCommandLines(cmd, func(line string) {
+		if ip == "" {
+			ip = parsePingAddr(line)
+		}
+		r, ok := parsePingReply(line, len(replies)+1)
+		if !ok {
+			return
+		}
+		replies = append(replies, r)
+		progress(contract.PingPayload{
+			CommandPayload: contract.CommandPayload{Command: cmd.String()},
+			IP:             ip,
+			Replies:        slices.Clone(replies),
+		})
+	})
+	output := decodeConsole(out)
+
+	if err != nil {
+		return makeRes(false, err, contract.PingPayload{CommandPayload: contract.CommandPayload{
+			Command:  cmd.String(),
+			Output:   tail(output, 4096),
+			ExitCode: exitCode(err),
+		}, IP: ip, Replies: replies})
+	}
+	return makeRes(true, nil, contract.PingPayload{CommandPayload: contract.CommandPayload{
+		Command:  cmd.String(),
+		Output:   tail(output, 4096),
+		ExitCode: 0,
+	}, IP: ip, Replies: replies})
+}
+
+var (
+	pingSeqRe  = regexp.MustCompile(`icmp_seq=(\d+)`)
+	pingTTLRe  = regexp.MustCompile(`(?i)ttl=(\d+)`)
+	pingTimeRe = regexp.MustCompile(`(?i)time[=<]([\d.]+)\s*ms`)
+	pingAddrRe = regexp.MustCompile(`^(?:PING|Pinging)\s+\S+\s+[(\[]([0-9A-Fa-f.:]+)[)\]]`)
+)
+
+// parsePingAddr достаёт адрес из заголовка ping: linux "PING example.com (93.184.216.34) 56(84) bytes of data.",
+// windows "Pinging example.com [93.184.216.34] with 32 bytes of data:".
+func parsePingAddr(line string) string {
+	m := pingAddrRe.FindStringSubmatch(strings.TrimSpace(line))
+	if m == nil || net.ParseIP(m[1]) == nil {
+		return ""
+	}
+
+	return m[1]
+}
+
+// parsePingReply разбирает строку ответа ping: linux "icmp_seq=1 ttl=57 time=12.3 ms",
+// windows "bytes=32 time=12ms TTL=57". Если номера в строке нет, берётся fallbackSeq.
+func parsePingReply(line string, fallbackSeq int) (contract.PingReply, bool) {
+	tm := pingTimeRe.FindStringSubmatch(line)
+	ttl := pingTTLRe.FindStringSubmatch(line)
+	if tm == nil || ttl == nil {
+		return contract.PingReply{}, false
+	}
+
+	r := contract.PingReply{Seq: fallbackSeq}
+	r.TimeMs, _ = strconv.ParseFloat(tm[1], 64)
+	r.TTL, _ = strconv.Atoi(ttl[1])
+	if seq := pingSeqRe.FindStringSubmatch(line); seq != nil {
+		r.Seq, _ = strconv.Atoi(seq[1])
+	}
+
+	return r, true
+}
+
+func decodeConsole(out []byte) string {
+	if runtime.GOOS == "windows" {
+		if s, err := charmap.CodePage866.NewDecoder().String(string(out)); err == nil {
+			return s
+		}
+		if s, err := charmap.Windows1251.NewDecoder().String(string(out)); err == nil {
+			return s
+		}
+	}
+	return string(out)
+}
+
+func exitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	if ee, ok := err.(*exec.ExitError); ok {
+		return ee.ExitCode()
+	}
+	return -1
+}
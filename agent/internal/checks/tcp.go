@@ -0,0 +1,62 @@
+package checks
+
+import (
+	"context"
+	"hackathon-contract"
+	"net"
+	"strconv"
+	"time"
+)
+
+type tcpChecker struct{}
+
+func NewTCP() Checker {
+	return tcpChecker{}
+}
+
+func (tcpChecker) Name() string {
+	return "tcp"
+}
+
+func (tcpChecker) Timeout() time.Duration {
+	return 3 * time.Second
+}
+
+func (tcpChecker) Defaults() any {
+	return contract.TCPParams{ConnectTimeoutMs: 3000}
+}
+
+func (tcpChecker) Normalize(params map[string]interface{}) (map[string]interface{}, error) {
+	var tp contract.TCPParams
+	if err := contract.DecodeLoose(params, &tp); err != nil {
+		return nil, err
+	}
+	if tp.ConnectTimeoutMs <= 0 {
+		tp.ConnectTimeoutMs = 3000
+	}
+	return contract.ToMap(tp), nil
+}
+
+func (tcpChecker) Run(ctx context.Context, target string, params map[string]interface{}, makeRes MakeResFunc, _ ProgressFunc) contract.CheckResult {
+	var p contract.TCPParams
+	_ = contract.DecodeLoose(params, &p)
+	return runTCP(ctx, target, p, makeRes)
+}
+
+func runTCP(ctx context.Context, target string, p contract.TCPParams,
+	makeRes MakeResFunc,
+) contract.CheckResult {
+	addr := net.JoinHostPort(target, strconv.Itoa(p.Port))
+	d := net.Dialer{Timeout: time.Duration(p.ConnectTimeoutMs) * time.Millisecond}
+	t0 := time.Now()
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	lat := time.Since(t0)
+	if err != nil {
+		return makeRes(false, err, contract.TCPPayload{Addr: addr})
+	}
+	_ = conn.Close()
+	return makeRes(true, nil, contract.TCPPayload{
+		Addr:      addr,
+		Handshake: lat.Milliseconds(),
+	})
+}
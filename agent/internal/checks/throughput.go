@@ -0,0 +1,216 @@
+package checks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hackathon-contract"
+	"io"
+	"net"
+	"net/http"
+	"time"
+)
+
+type throughputChecker struct{}
+
+func NewThroughput() Checker {
+	return throughputChecker{}
+}
+
+func (throughputChecker) Name() string {
+	return "throughput"
+}
+
+func (throughputChecker) Timeout() time.Duration {
+	return 15 * time.Second
+}
+
+func (throughputChecker) Defaults() any {
+	return contract.ThroughputParams{
+		Scheme:           "https",
+		Path:             "/",
+		MaxBytes:         10 << 20,
+		MaxDurationMs:    10000,
+		SampleIntervalMs: 250,
+		StallThresholdMs: 500,
+	}
+}
+
+func (throughputChecker) Normalize(params map[string]interface{}) (map[string]interface{}, error) {
+	normalizeHeaders(params)
+	var tp contract.ThroughputParams
+	if err := contract.DecodeLoose(params, &tp); err != nil {
+		return nil, err
+	}
+	if tp.Scheme == "" {
+		tp.Scheme = "https"
+	}
+	if tp.Path == "" {
+		tp.Path = "/"
+	}
+	if tp.RangeStart < 0 || (tp.RangeEnd > 0 && tp.RangeEnd < tp.RangeStart) {
+		return nil, errors.New("invalid byte range")
+	}
+	if tp.MaxBytes <= 0 {
+		tp.MaxBytes = 10 << 20
+	}
+	if tp.MaxDurationMs <= 0 {
+		tp.MaxDurationMs = 10000
+	}
+	// не дольше индивидуального таймаута проверки
+	if tp.MaxDurationMs > 12000 {
+		tp.MaxDurationMs = 12000
+	}
+	if tp.SampleIntervalMs <= 0 {
+		tp.SampleIntervalMs = 250
+	}
+	if tp.StallThresholdMs <= 0 {
+		tp.StallThresholdMs = 500
+	}
+	return contract.ToMap(tp), nil
+}
+
+func (throughputChecker) Run(ctx context.Context, target string, params map[string]interface{}, makeRes MakeResFunc, _ ProgressFunc) contract.CheckResult {
+	var p contract.ThroughputParams
+	_ = contract.DecodeLoose(params, &p)
+	return runThroughput(ctx, target, p, makeRes)
+}
+
+const throughputReadBuffer = 32 << 10
+
+func runThroughput(ctx context.Context, target string, p contract.ThroughputParams,
+	makeRes MakeResFunc,
+) contract.CheckResult {
+	url := fmt.Sprintf("%s://%s%s", nonEmpty(p.Scheme, "https"), target, nonEmpty(p.Path, "/"))
+
+	// лимит по времени — штатное завершение замера, а не ошибка
+	dlCtx, cancel := context.WithTimeout(ctx, time.Duration(p.MaxDurationMs)*time.Millisecond)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(dlCtx, http.MethodGet, url, nil)
+	if err != nil {
+		return makeRes(false, err, nil)
+	}
+	for k, v := range p.Headers {
+		req.Header.Set(k, v)
+	}
+	if p.RangeStart > 0 || p.RangeEnd > 0 {
+		rng := fmt.Sprintf("bytes=%d-", p.RangeStart)
+		if p.RangeEnd > 0 {
+			rng += fmt.Sprint(p.RangeEnd)
+		}
+		req.Header.Set("Range", rng)
+	}
+	// сжатие исказит объём переданных байт
+	req.Header.Set("Accept-Encoding", "identity")
+
+	transport := &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           (&net.Dialer{Timeout: 3 * time.Second}).DialContext,
+		TLSHandshakeTimeout:   5 * time.Second,
+		ResponseHeaderTimeout: 5 * time.Second,
+		DisableCompression:    true,
+	}
+	client := &http.Client{Transport: transport}
+	defer transport.CloseIdleConnections()
+
+	t0 := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		return makeRes(false, err, contract.ThroughputPayload{URL: url})
+	}
+	defer resp.Body.Close()
+
+	payload := contract.ThroughputPayload{
+		URL:    url,
+		Status: resp.StatusCode,
+	}
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusPartialContent {
+		return makeRes(false, fmt.Errorf("unexpected status %d", resp.StatusCode), payload)
+	}
+
+	var (
+		total       int64
+		ttfb        time.Duration
+		peakBps     float64
+		stalls      []contract.Stall
+		stalledMs   int64
+		stoppedBy   = "eof"
+		readErr     error
+		buf         = make([]byte, throughputReadBuffer)
+		interval    = time.Duration(p.SampleIntervalMs) * time.Millisecond
+		stallAfter  = time.Duration(p.StallThresholdMs) * time.Millisecond
+		windowStart time.Time
+		windowBytes int64
+		lastByte    time.Time
+	)
+
+	for {
+		n, err := resp.Body.Read(buf)
+		now := time.Now()
+
+		if n > 0 {
+			if total == 0 {
+				ttfb = now.Sub(t0)
+				windowStart = now
+			} else if gap := now.Sub(lastByte); gap >= stallAfter {
+				stalls = append(stalls, contract.Stall{AtMs: lastByte.Sub(t0).Milliseconds(), DurationMs: gap.Milliseconds()})
+				stalledMs += gap.Milliseconds()
+			}
+
+			lastByte = now
+			total += int64(n)
+			windowBytes += int64(n)
+
+			if elapsed := now.Sub(windowStart); elapsed >= interval {
+				peakBps = max(peakBps, float64(windowBytes)/elapsed.Seconds())
+				windowStart = now
+				windowBytes = 0
+			}
+
+			if total >= p.MaxBytes {
+				stoppedBy = "size"
+				break
+			}
+		}
+
+		if err != nil {
+			switch {
+			case errors.Is(err, io.EOF):
+			case dlCtx.Err() != nil && ctx.Err() == nil:
+				stoppedBy = "time"
+			default:
+				readErr = err
+			}
+			break
+		}
+	}
+
+	duration := time.Since(t0)
+	transfer := duration - ttfb
+
+	var avgBps float64
+	if total > 0 && transfer > 0 {
+		avgBps = float64(total) / transfer.Seconds()
+	}
+	// короткая загрузка могла не заполнить ни одного окна
+	peakBps = max(peakBps, avgBps)
+
+	payload.Bytes = total
+	payload.TTFBMs = ttfb.Milliseconds()
+	payload.DurationMs = duration.Milliseconds()
+	payload.AvgBps = int64(avgBps)
+	payload.PeakBps = int64(peakBps)
+	payload.AvgMbps = avgBps * 8 / 1e6
+	payload.PeakMbps = peakBps * 8 / 1e6
+	payload.Stalls = stalls
+	payload.StalledMs = stalledMs
+	payload.StoppedBy = stoppedBy
+	payload.ContentLength = resp.ContentLength
+
+	if readErr != nil {
+		return makeRes(false, readErr, payload)
+	}
+
+	return makeRes(true, nil, payload)
+}
@@ -0,0 +1,165 @@
+package checks
+
+import (
+	"context"
+	"hackathon-contract"
+	"net"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type tracerouteChecker struct{}
+
+func NewTraceroute() Checker {
+	return tracerouteChecker{}
+}
+
+func (tracerouteChecker) Name() string {
+	return "traceroute"
+}
+
+func (tracerouteChecker) Timeout() time.Duration {
+	return 16 * time.Second
+}
+
+func (tracerouteChecker) Defaults() any {
+	return contract.TracerouteParams{Mode: "udp", MaxHops: 30}
+}
+
+func (tracerouteChecker) Normalize(params map[string]interface{}) (map[string]interface{}, error) {
+	var tp contract.TracerouteParams
+	if err := contract.DecodeLoose(params, &tp); err != nil {
+		return nil, err
+	}
+	if tp.MaxHops <= 0 {
+		tp.MaxHops = 30
+	}
+	if tp.Mode == "" {
+		tp.Mode = "udp"
+	}
+	return contract.ToMap(tp), nil
+}
+
+func (tracerouteChecker) Probe() error {
+	cmdName, _ := buildTracerouteArgs("", contract.TracerouteParams{})
+	_, err := exec.LookPath(cmdName)
+	return err
+}
+
+func (tracerouteChecker) Run(ctx context.Context, target string, params map[string]interface{}, makeRes MakeResFunc, progress ProgressFunc) contract.CheckResult {
+	var p contract.TracerouteParams
+	_ = contract.DecodeLoose(params, &p)
+	return runTraceroute(ctx, target, p, makeRes, progress)
+}
+
+func runTraceroute(
+	ctx context.Context,
+	target string,
+	p contract.TracerouteParams,
+	makeRes MakeResFunc,
+	progress ProgressFunc,
+) contract.CheckResult {
+	start := time.Now()
+
+	cmdName, args := buildTracerouteArgs(target, p)
+	cmd := exec.CommandContext(ctx, cmdName, args...)
+
+	// хопы отдаём по мере появления строк, чтобы маршрут рисовался на карте сразу;
+	// координаты, ASN и PTR проставляет бэкенд
+	parser := newTraceIPParser()
+	var hops []contract.Hop
+	out, err := runCommandLines(cmd, func(line string) {
+		ips := parser.parseLine(line)
+		if len(ips) == 0 {
+			return
+		}
+		for _, ip := range ips {
+			hops = append(hops, contract.Hop{IP: ip})
+		}
+		progress(contract.TraceroutePayload{
+			CommandPayload: contract.CommandPayload{Command: cmd.String()},
+			Hops:           slices.Clone(hops),
+		})
+	})
+	output := decodeConsole(out)
+
+	if hops == nil {
+		hops = []contract.Hop{}
+	}
+
+	payload := contract.TraceroutePayload{
+		CommandPayload: contract.CommandPayload{
+			Command:  cmd.String(),
+			Output:   tail(output, 8192),
+			ExitCode: exitCode(err),
+		},
+		Hops: hops,
+	}
+	ok := err == nil
+	res := makeRes(ok, err, payload)
+	res.DurationMs = time.Since(start).Milliseconds()
+	return res
+}
+
+func buildTracerouteArgs(target string, p contract.TracerouteParams) (string, []string) {
+	if p.MaxHops <= 0 {
+		p.MaxHops = 30
+	}
+	if runtime.GOOS == "windows" {
+		// tracert / d, все флаги ДО цели, а не как у тебя
+		args := []string{"-d", "-h", strconv.Itoa(p.MaxHops), "-w", "1000", target}
+		return "tracert", args
+	}
+	args := []string{"-n", "-m", strconv.Itoa(p.MaxHops)}
+	switch strings.ToLower(p.Mode) {
+	case "tcp":
+		args = append(args, "-T")
+		if p.Port > 0 {
+			args = append(args, "-p", strconv.Itoa(p.Port))
+		}
+	case "icmp":
+		args = append(args, "-I")
+	}
+	args = append(args, target)
+	return "traceroute", args
+}
+
+var ipRe = regexp.MustCompile(`\b(\d{1,3}(?:\.\d{1,3}){3})\b`)
+
+// traceIPParser достаёт новые IPv4 из строк вывода traceroute, повторы отбрасываются.
+type traceIPParser struct {
+	seen map[string]struct{}
+}
+
+func newTraceIPParser() *traceIPParser {
+	return &traceIPParser{seen: make(map[string]struct{}, 64)}
+}
+
+func (p *traceIPParser) parseLine(ln string) []string {
+	// пропускаем строки где только звездочки
+	if strings.Count(ln, "*") >= 3 && !ipRe.MatchString(ln) {
+		return nil
+	}
+	var ips []string
+	for _, ip := range ipRe.FindAllString(ln, -1) {
+		if !validIPv4(ip) {
+			continue
+		}
+		if _, ok := p.seen[ip]; ok {
+			continue
+		}
+		p.seen[ip] = struct{}{}
+		ips = append(ips, ip)
+	}
+	return ips
+}
+
+func validIPv4(ip string) bool {
+	parsed := net.ParseIP(ip)
+	return parsed != nil && parsed.To4() != nil
+}
@@ -0,0 +1,143 @@
+package checks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hackathon-contract"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+type websocketChecker struct{}
+
+func NewWebSocket() Checker {
+	return websocketChecker{}
+}
+
+func (websocketChecker) Name() string {
+	return "websocket"
+}
+
+func (websocketChecker) Timeout() time.Duration {
+	return 6 * time.Second
+}
+
+func (websocketChecker) Defaults() any {
+	return contract.WebSocketParams{Scheme: "wss", Path: "/", ReadTimeoutMs: 3000}
+}
+
+func (websocketChecker) Normalize(params map[string]interface{}) (map[string]interface{}, error) {
+	normalizeHeaders(params)
+	var wp contract.WebSocketParams
+	if err := contract.DecodeLoose(params, &wp); err != nil {
+		return nil, err
+	}
+	if wp.Scheme == "" {
+		wp.Scheme = "wss"
+	}
+	if wp.Scheme != "ws" && wp.Scheme != "wss" {
+		return nil, fmt.Errorf("scheme must be ws or wss, got %q", wp.Scheme)
+	}
+	if wp.Path == "" {
+		wp.Path = "/"
+	}
+	if wp.ReadTimeoutMs <= 0 {
+		wp.ReadTimeoutMs = 3000
+	}
+	return contract.ToMap(wp), nil
+}
+
+func (websocketChecker) Run(ctx context.Context, target string, params map[string]interface{}, makeRes MakeResFunc, _ ProgressFunc) contract.CheckResult {
+	var p contract.WebSocketParams
+	_ = contract.DecodeLoose(params, &p)
+	return runWebSocket(ctx, target, p, makeRes)
+}
+
+func runWebSocket(ctx context.Context, target string, p contract.WebSocketParams,
+	makeRes MakeResFunc,
+) contract.CheckResult {
+	host := target
+	if p.Port > 0 {
+		host = net.JoinHostPort(target, strconv.Itoa(p.Port))
+	}
+	u := url.URL{Scheme: nonEmpty(p.Scheme, "wss"), Host: host, Path: nonEmpty(p.Path, "/")}
+
+	header := http.Header{}
+	for k, v := range p.Headers {
+		header.Set(k, v)
+	}
+
+	// отдельно меряем TCP connect, handshake включает TLS и HTTP Upgrade
+	var connectLat time.Duration
+	netDialer := &net.Dialer{Timeout: 3 * time.Second}
+	dialer := websocket.Dialer{
+		Proxy:            http.ProxyFromEnvironment,
+		HandshakeTimeout: 5 * time.Second,
+		Subprotocols:     p.Subprotocols,
+		NetDialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			t0 := time.Now()
+			conn, err := netDialer.DialContext(ctx, network, addr)
+			connectLat = time.Since(t0)
+			return conn, err
+		},
+	}
+
+	payload := contract.WebSocketPayload{URL: u.String()}
+
+	t0 := time.Now()
+	conn, resp, err := dialer.DialContext(ctx, u.String(), header)
+	handshakeLat := time.Since(t0)
+	if resp != nil {
+		payload.Status = resp.StatusCode
+		_ = resp.Body.Close()
+	}
+	payload.ConnectMs = connectLat.Milliseconds()
+	if err != nil {
+		return makeRes(false, err, payload)
+	}
+	defer conn.Close()
+
+	payload.HandshakeMs = handshakeLat.Milliseconds()
+	payload.Subprotocol = conn.Subprotocol()
+
+	if p.Message == "" {
+		_ = conn.WriteControl(websocket.CloseMessage,
+			websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(time.Second))
+		return makeRes(true, nil, payload)
+	}
+
+	deadline := time.Now().Add(time.Duration(p.ReadTimeoutMs) * time.Millisecond)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	_ = conn.SetWriteDeadline(deadline)
+	_ = conn.SetReadDeadline(deadline)
+
+	t1 := time.Now()
+	if err := conn.WriteMessage(websocket.TextMessage, []byte(p.Message)); err != nil {
+		return makeRes(false, fmt.Errorf("write message: %w", err), payload)
+	}
+
+	_, reply, err := conn.ReadMessage()
+	payload.RTTMs = time.Since(t1).Milliseconds()
+	if err != nil {
+		return makeRes(false, fmt.Errorf("read reply: %w", err), payload)
+	}
+	payload.Reply = tail(string(reply), 4096)
+
+	_ = conn.WriteControl(websocket.CloseMessage,
+		websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(time.Second))
+
+	if p.Expect != "" && !strings.Contains(string(reply), p.Expect) {
+		return makeRes(false, errors.New("reply does not contain expected text"), payload)
+	}
+
+	return makeRes(true, nil, payload)
+}
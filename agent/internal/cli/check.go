@@ -0,0 +1,78 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hackathon-contract"
+	"strings"
+)
+
+var ErrBadCheckSpec = errors.New("check must look like type[:key=value;key=value]")
+
+// checkSpecs значение повторяемого флага --check.
+// Формат: `dns:records=A,MX;resolver=1.1.1.1`. Значение сначала разбирается как JSON
+// (числа, bool, массивы, объекты), значение с запятыми — как список строк, иначе остаётся строкой.
+// Дальше параметры проходят обычный Normalize проверки.
+type checkSpecs []contract.CheckRequest
+
+func (c *checkSpecs) String() string {
+	if c == nil {
+		return ""
+	}
+
+	types := make([]string, 0, len(*c))
+	for _, chk := range *c {
+		types = append(types, chk.Type)
+	}
+
+	return strings.Join(types, ",")
+}
+
+func (c *checkSpecs) Set(spec string) error {
+	chk, err := parseCheckSpec(spec)
+	if err != nil {
+		return err
+	}
+
+	*c = append(*c, chk)
+
+	return nil
+}
+
+func parseCheckSpec(spec string) (contract.CheckRequest, error) {
+	typ, rest, _ := strings.Cut(strings.TrimSpace(spec), ":")
+	if typ = strings.TrimSpace(typ); typ == "" {
+		return contract.CheckRequest{}, fmt.Errorf("%w: %q", ErrBadCheckSpec, spec)
+	}
+
+	params := map[string]interface{}{}
+
+	for _, pair := range strings.Split(rest, ";") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+
+		key, value, ok := strings.Cut(pair, "=")
+		if key = strings.TrimSpace(key); !ok || key == "" {
+			return contract.CheckRequest{}, fmt.Errorf("%w: %q", ErrBadCheckSpec, spec)
+		}
+
+		params[key] = parseParamValue(strings.TrimSpace(value))
+	}
+
+	return contract.CheckRequest{Type: strings.ToLower(typ), Params: params}, nil
+}
+
+func parseParamValue(s string) interface{} {
+	var v interface{}
+	if json.Unmarshal([]byte(s), &v) == nil {
+		return v
+	}
+
+	if strings.Contains(s, ",") {
+		return splitList(s)
+	}
+
+	return s
+}
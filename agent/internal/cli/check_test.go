@@ -0,0 +1,121 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"hackathon-agent/internal/checks"
+	"hackathon-contract"
+)
+
+func TestParseCheckSpec(t *testing.T) {
+	tests := []struct {
+		spec string
+		want contract.CheckRequest
+	}{
+		{spec: "http", want: contract.CheckRequest{Type: "http", Params: map[string]interface{}{}}},
+		{spec: "TCP:port=443", want: contract.CheckRequest{Type: "tcp", Params: map[string]interface{}{"port": float64(443)}}},
+		{spec: "dns:records=A", want: contract.CheckRequest{Type: "dns", Params: map[string]interface{}{"records": "A"}}},
+		{spec: "dns:records=A,MX; resolver=1.1.1.1", want: contract.CheckRequest{Type: "dns", Params: map[string]interface{}{
+			"records":  []string{"A", "MX"},
+			"resolver": "1.1.1.1",
+		}}},
+	}
+
+	for _, tt := range tests {
+		got, err := parseCheckSpec(tt.spec)
+		if err != nil {
+			t.Fatalf("parseCheckSpec(%q): %v", tt.spec, err)
+		}
+
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Fatalf("parseCheckSpec(%q) = %#v, want %#v", tt.spec, got, tt.want)
+		}
+	}
+}
+
+func TestParseCheckSpecErrors(t *testing.T) {
+	for _, spec := range []string{"", ":records=A", "dns:records"} {
+		if _, err := parseCheckSpec(spec); !errors.Is(err, ErrBadCheckSpec) {
+			t.Errorf("parseCheckSpec(%q) = %v, want %v", spec, err, ErrBadCheckSpec)
+		}
+	}
+}
+
+func TestCheckSpecDNSRecords(t *testing.T) {
+	tests := []struct {
+		spec string
+		want []string
+	}{
+		{spec: "dns:records=A", want: []string{"A"}},
+		{spec: "dns:records=A,MX", want: []string{"A", "MX"}},
+		{spec: `dns:records=["AAAA"]`, want: []string{"AAAA"}},
+		{spec: "dns", want: []string{"A"}},
+	}
+
+	for _, tt := range tests {
+		chk, err := parseCheckSpec(tt.spec)
+		if err != nil {
+			t.Fatalf("parseCheckSpec(%q): %v", tt.spec, err)
+		}
+
+		params, err := checks.NewDNS().Normalize(chk.Params)
+		if err != nil {
+			t.Fatalf("Normalize(%q): %v", tt.spec, err)
+		}
+
+		var p contract.DNSParams
+		if err := contract.DecodeLoose(params, &p); err != nil {
+			t.Fatalf("decode params: %v", err)
+		}
+
+		if !reflect.DeepEqual(p.Records, tt.want) {
+			t.Fatalf("%q: got records %v, want %v", tt.spec, p.Records, tt.want)
+		}
+	}
+}
+
+func TestRunNDJSONPrintsOnlyResults(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+
+	code := Run(context.Background(), []string{"--target", "localhost", "--check", "dns:records=A", "--output", OutputNDJSON}, strings.NewReader(""), &stdout, &stderr)
+	if code != ExitOK {
+		t.Fatalf("exit code %d, stderr %q, stdout %q", code, stderr.String(), stdout.String())
+	}
+
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("got %d lines, want only the final result: %q", len(lines), stdout.String())
+	}
+
+	var res contract.CheckResult
+	if err := json.Unmarshal([]byte(lines[0]), &res); err != nil {
+		t.Fatalf("decode result: %v", err)
+	}
+
+	if !res.IsFinal() || !res.OK || res.Type != "dns" || len(res.Payload) == 0 {
+		t.Fatalf("got result %+v", res)
+	}
+}
+
+func TestRunUsageErrors(t *testing.T) {
+	tests := [][]string{
+		{"--target", "localhost"},
+		{"--check", "dns"},
+		{"--target", "localhost", "--check", "dns", "--output", "xml"},
+		{"--target", "localhost", "--check", "smtp"},
+	}
+
+	for _, args := range tests {
+		var stdout, stderr bytes.Buffer
+
+		if code := Run(context.Background(), args, strings.NewReader(""), &stdout, &stderr); code != ExitUsage {
+			t.Errorf("Run(%v) = %d, want %d, stderr %q", args, code, ExitUsage, stderr.String())
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"hackathon-contract"
+	"io"
+	"sync"
+)
+
+// resultWriter подменяет kafka.Producer для Service: вместо публикации в топик
+// печатает результаты в out. ndjson — каждое сообщение одной строкой, включая промежуточные
+// (кроме подтверждения старта без payload); text — только итоговые результаты в читаемом виде.
+type resultWriter struct {
+	mu     sync.Mutex
+	out    io.Writer
+	format string
+	failed bool
+}
+
+func newResultWriter(out io.Writer, format string) *resultWriter {
+	return &resultWriter{out: out, format: format}
+}
+
+func (w *resultWriter) PushMessage(_ context.Context, _, value []byte, _ string) (int32, int64, error) {
+	var res contract.CheckResult
+	if err := json.Unmarshal(value, &res); err != nil {
+		return 0, 0, fmt.Errorf("failed to decode check result: %w", err)
+	}
+
+	// подтверждение старта нужно только бэкенду
+	if !res.IsFinal() && len(res.Payload) == 0 {
+		return 0, 0, nil
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if res.IsFinal() && !res.OK {
+		w.failed = true
+	}
+
+	var err error
+	if w.format == OutputNDJSON {
+		_, err = fmt.Fprintf(w.out, "%s\n", value)
+	} else if res.IsFinal() {
+		err = writeText(w.out, res)
+	}
+
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to write check result: %w", err)
+	}
+
+	return 0, 0, nil
+}
+
+func (w *resultWriter) Ready() error {
+	return nil
+}
+
+func (w *resultWriter) Close() error {
+	return nil
+}
+
+// Failed true, если хотя бы одна проверка завершилась с ok=false.
+func (w *resultWriter) Failed() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.failed
+}
+
+func writeText(out io.Writer, res contract.CheckResult) error {
+	status := "OK"
+	if !res.OK {
+		status = "FAIL"
+	}
+
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, "[%d] %s %s: %s (%d ms)\n", res.CheckIndex, res.Type, res.Target, status, res.DurationMs)
+
+	if res.Error != "" {
+		fmt.Fprintf(&buf, "    error: %s\n", res.Error)
+	}
+
+	if len(res.Payload) > 0 {
+		var pretty bytes.Buffer
+		if json.Indent(&pretty, res.Payload, "    ", "  ") == nil {
+			fmt.Fprintf(&buf, "    %s\n", pretty.Bytes())
+		}
+	}
+
+	_, err := out.Write(buf.Bytes())
+
+	return err
+}
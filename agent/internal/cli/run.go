@@ -0,0 +1,230 @@
+// Package cli разовый запуск задачи на агенте без Kafka: `agent run`.
+// Задача выполняется тем же путём, что и из очереди (Service.ParseTask + Service.RunCheck),
+// только результаты вместо топика печатаются в stdout, а конверты с подписью не нужны.
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"hackathon-agent/internal/checks"
+	"hackathon-agent/internal/identity"
+	"hackathon-agent/internal/metrics"
+	"hackathon-agent/internal/service"
+	"hackathon-contract"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	CommandRun = "run"
+
+	OutputText   = "text"
+	OutputNDJSON = "ndjson"
+
+	ExitOK     = 0 // ExitOK все проверки прошли
+	ExitFailed = 1 // ExitFailed хотя бы одна проверка вернула ok=false
+	ExitUsage  = 2 // ExitUsage ошибка аргументов или задачи
+)
+
+var (
+	ErrNoTask        = errors.New("either --file or --target with --check is required")
+	ErrTaskAndTarget = errors.New("--file cannot be combined with --target or --check")
+	ErrNoChecks      = errors.New("at least one --check is required")
+	ErrBadOutput     = errors.New("output must be text or ndjson")
+)
+
+type runOptions struct {
+	file     string
+	target   string
+	checks   checkSpecs
+	timeout  int
+	output   string
+	enabled  string
+	disabled string
+	verbose  bool
+}
+
+// Run выполняет `agent run` с аргументами после подкоманды и возвращает код выхода.
+func Run(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	opts, err := parseRunFlags(args, stderr)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return ExitOK
+		}
+
+		fmt.Fprintf(stderr, "agent run: %v\n", err)
+
+		return ExitUsage
+	}
+
+	raw, err := opts.taskJSON(stdin)
+	if err != nil {
+		fmt.Fprintf(stderr, "agent run: %v\n", err)
+
+		return ExitUsage
+	}
+
+	registry, err := checks.NewBuiltinRegistry(splitList(opts.enabled), splitList(opts.disabled))
+	if err != nil {
+		fmt.Fprintf(stderr, "agent run: failed to init checks registry: %v\n", err)
+
+		return ExitUsage
+	}
+
+	log := zap.NewNop()
+	if opts.verbose {
+		if log, err = zap.NewDevelopment(); err != nil {
+			fmt.Fprintf(stderr, "agent run: failed to init logger: %v\n", err)
+
+			return ExitUsage
+		}
+	}
+
+	out := newResultWriter(stdout, opts.output)
+	svc := service.NewService(log, nil, out, "", registry, metrics.New(), uuid.Nil, identity.Unsigned{})
+
+	task, err := svc.ParseTask(raw)
+	if err != nil {
+		fmt.Fprintf(stderr, "agent run: %v\n", err)
+
+		return ExitUsage
+	}
+
+	if err := svc.RunCheck(ctx, task); err != nil {
+		fmt.Fprintf(stderr, "agent run: %v\n", err)
+
+		return ExitFailed
+	}
+
+	if out.Failed() {
+		return ExitFailed
+	}
+
+	return ExitOK
+}
+
+func parseRunFlags(args []string, stderr io.Writer) (*runOptions, error) {
+	opts := &runOptions{}
+
+	fs := flag.NewFlagSet("agent run", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage:")
+		fmt.Fprintln(fs.Output(), "  agent run --file task.json          TaskMessage from a file, '-' for stdin")
+		fmt.Fprintln(fs.Output(), "  agent run < task.json")
+		fmt.Fprintln(fs.Output(), "  agent run --target example.com --check dns:records=A,MX --check http")
+		fmt.Fprintln(fs.Output(), "\nFlags:")
+		fs.PrintDefaults()
+	}
+
+	fs.StringVar(&opts.file, "file", "", "path to TaskMessage JSON, '-' for stdin")
+	fs.StringVar(&opts.target, "target", "", "domain or IP to check")
+	fs.Var(&opts.checks, "check", "check as type[:key=value;key=value], repeatable")
+	fs.IntVar(&opts.timeout, "timeout", 20, "task timeout in seconds")
+	fs.StringVar(&opts.output, "output", OutputText, "output format: text or ndjson")
+	fs.StringVar(&opts.enabled, "enabled", "", "comma separated checks allowed on this run, empty means all")
+	fs.StringVar(&opts.disabled, "disabled", "", "comma separated checks to disable")
+	fs.BoolVar(&opts.verbose, "verbose", false, "write agent logs to stderr")
+
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	if opts.output != OutputText && opts.output != OutputNDJSON {
+		return nil, fmt.Errorf("%w: %q", ErrBadOutput, opts.output)
+	}
+
+	if fs.NArg() > 0 {
+		return nil, fmt.Errorf("unexpected arguments: %s", strings.Join(fs.Args(), " "))
+	}
+
+	return opts, nil
+}
+
+// taskJSON собирает задачу из файла, stdin или флагов. Пустые version и id
+// заполняются, чтобы в файле было достаточно target и checks.
+func (o *runOptions) taskJSON(stdin io.Reader) ([]byte, error) {
+	var task contract.TaskMessage
+
+	switch {
+	case o.file != "" && (o.target != "" || len(o.checks) > 0):
+		return nil, ErrTaskAndTarget
+
+	case o.target != "":
+		if len(o.checks) == 0 {
+			return nil, ErrNoChecks
+		}
+
+		task = contract.TaskMessage{
+			Target:         o.target,
+			TimeoutSeconds: o.timeout,
+			Checks:         o.checks,
+		}
+
+	case len(o.checks) > 0:
+		return nil, ErrNoTask
+
+	default:
+		data, err := o.readTask(stdin)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := json.Unmarshal(data, &task); err != nil {
+			return nil, fmt.Errorf("bad task json: %w", err)
+		}
+	}
+
+	if task.Version == "" {
+		task.Version = contract.SchemaVersion
+	}
+
+	if task.ID == uuid.Nil {
+		task.ID = uuid.New()
+	}
+
+	return json.Marshal(task)
+}
+
+func (o *runOptions) readTask(stdin io.Reader) ([]byte, error) {
+	if o.file == "" || o.file == "-" {
+		if f, ok := stdin.(*os.File); ok {
+			if info, err := f.Stat(); err == nil && info.Mode()&os.ModeCharDevice != 0 {
+				return nil, ErrNoTask
+			}
+		}
+
+		data, err := io.ReadAll(stdin)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read task from stdin: %w", err)
+		}
+
+		return data, nil
+	}
+
+	data, err := os.ReadFile(o.file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read task file: %w", err)
+	}
+
+	return data, nil
+}
+
+func splitList(s string) []string {
+	var out []string
+
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+
+	return out
+}
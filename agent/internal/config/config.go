@@ -4,6 +4,7 @@ import (
 	"errors"
 	"flag"
 	"os"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/ilyakaznacheev/cleanenv"
@@ -16,16 +17,28 @@ type Config struct {
 	App        `yaml:"app"`
 	Subscriber `yaml:"subscriber"`
 	Publisher  `yaml:"publisher"`
+	Checks     `yaml:"checks"`
+	Heartbeat  `yaml:"heartbeat"`
+	Admin      `yaml:"admin"`
+	Gateway    `yaml:"gateway"`
+	Identity   `yaml:"identity"`
+	Enrollment `yaml:"enrollment"`
 }
 
 type App struct {
-	AgentID uuid.UUID `yaml:"agent_id" env:"APP_AGENT_ID"`
-	Region  string    `yaml:"region" env:"APP_REGION"`
+	AgentID   uuid.UUID `yaml:"agent_id" env:"APP_AGENT_ID"`
+	Region    string    `yaml:"region" env:"APP_REGION"`
+	Country   string    `yaml:"country" env:"APP_COUNTRY"` // Country страна размещения агента (ISO 3166-1 alpha-2), при регистрации её задаёт реестр
+	ASN       int       `yaml:"asn" env:"APP_ASN"`         // ASN автономная система сети агента, при регистрации её задаёт реестр
+	Lat       float64   `yaml:"lat" env:"APP_LAT"`         // Lat, Lon координаты агента, при регистрации их задаёт реестр; 0, 0 — не заданы
+	Lon       float64   `yaml:"lon" env:"APP_LON"`
+	Version   string    `yaml:"version" env:"APP_VERSION" env-default:"dev"`
+	Transport string    `yaml:"transport" env:"APP_TRANSPORT" env-default:"kafka"` // Transport kafka — через брокеры (subscriber/publisher), http — через gateway бэкенда
 }
 
 type Subscriber struct {
 	Brokers    []string `yaml:"brokers" env:"SUBSCRIBER_BROKERS" env-separator:","`
-	GroupID    string   `yaml:"group_id" env:"SUBSCRIBER_GROUP_ID"`
+	GroupID    string   `yaml:"group_id" env:"SUBSCRIBER_GROUP_ID"` // GroupID своя у каждого агента, пусто — agent-<agent_id>
 	Topic      string   `yaml:"topic" env:"SUBSCRIBER_TOPIC"`
 	BufferSize int      `yaml:"buffer_size" env:"SUBSCRIBER_BUFFER_SIZE"`
 }
@@ -35,6 +48,50 @@ type Publisher struct {
 	Topic   string   `yaml:"topic" env:"PUBLISHER_TOPIC"`
 }
 
+// Checks включает/выключает проверки на агенте: пустой enabled — все встроенные, disabled применяется поверх.
+type Checks struct {
+	Enabled  []string `yaml:"enabled" env:"CHECKS_ENABLED" env-separator:","`
+	Disabled []string `yaml:"disabled" env:"CHECKS_DISABLED" env-separator:","`
+}
+
+// Heartbeat агент раз в interval сообщает бэкенду, что жив, бэкенд по пропущенным heartbeat помечает его offline.
+type Heartbeat struct {
+	Topic    string        `yaml:"topic" env:"HEARTBEAT_TOPIC" env-default:"agents-heartbeat"`
+	Interval time.Duration `yaml:"interval" env:"HEARTBEAT_INTERVAL" env-default:"10s"`
+}
+
+// Gateway HTTP транспорт для агентов без доступа к Kafka (app.transport: http).
+// Топики publisher.topic и heartbeat.topic передаются бэкенду как адрес сообщения, brokers не нужны.
+type Gateway struct {
+	URL      string        `yaml:"url" env:"GATEWAY_URL"`
+	Token    string        `yaml:"token" env:"GATEWAY_TOKEN"` // Token свой у каждого агента, приходит при регистрации
+	PollWait time.Duration `yaml:"poll_wait" env:"GATEWAY_POLL_WAIT" env-default:"25s"`
+	Timeout  time.Duration `yaml:"timeout" env:"GATEWAY_TIMEOUT" env-default:"10s"`
+}
+
+// Identity ключ Ed25519 агента (создаётся при первом запуске, если файла нет) и публичный ключ бэкенда,
+// которым подписаны задачи. Ключ агента должен переживать перезапуск: бэкенд привязывает его к agent_id.
+type Identity struct {
+	PrivateKey       string `yaml:"private_key" env:"IDENTITY_PRIVATE_KEY" env-default:"./agent_identity.pem"`
+	BackendPublicKey string `yaml:"backend_public_key" env:"IDENTITY_BACKEND_PUBLIC_KEY" env-default:"./backend_public_key.pem"`
+}
+
+// Enrollment регистрация по одноразовому токену администратора: при первом запуске агент получает agent_id,
+// регион, топики и ключ бэкенда и сохраняет их в state. Пока state есть, токен не нужен.
+// URL — базовый адрес API бэкенда, пусто — берётся gateway.url.
+type Enrollment struct {
+	URL   string `yaml:"url" env:"ENROLLMENT_URL"`
+	Token string `yaml:"token" env:"ENROLLMENT_TOKEN"`
+	State string `yaml:"state" env:"ENROLLMENT_STATE" env-default:"./enrollment.json"`
+}
+
+// Admin служебный HTTP листенер с /healthz, /readyz и /metrics, по умолчанию выключен.
+type Admin struct {
+	Enabled bool   `yaml:"enabled" env:"ADMIN_ENABLED" env-default:"false"`
+	Host    string `yaml:"host" env:"ADMIN_HOST" env-default:"0.0.0.0"`
+	Port    uint16 `yaml:"port" env:"ADMIN_PORT" env-default:"8080"`
+}
+
 func MustLoadConfig() *Config {
 	cfg, err := LoadConfig()
 	if err != nil {
@@ -0,0 +1,233 @@
+// Package enroll регистрация агента по одноразовому токену, выданному администратором.
+// При первом запуске агент отправляет бэкенду токен и свой публичный ключ и получает agent_id, регион,
+// топики и ключ бэкенда. Ответ сохраняется в state: токен одноразовый, повторно его не обменять.
+package enroll
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hackathon-agent/internal/config"
+	"hackathon-contract"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	enrollPath = "/agent/enroll"
+
+	requestTimeout = 30 * time.Second
+)
+
+var (
+	ErrTokenRejected = errors.New("enrollment token rejected")
+	ErrBadStatus     = errors.New("enrollment returned unexpected status")
+	ErrURLIsEmpty    = errors.New("enrollment url is empty")
+)
+
+type envelope struct {
+	Status  string          `json:"status"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// Apply настраивает агента по сохранённой регистрации или регистрирует его, если state ещё нет и задан токен.
+// enrolled сообщает, что агент зарегистрировался сейчас. Без токена и state конфиг не меняется.
+func Apply(ctx context.Context, cfg *config.Config) (resp *contract.EnrollResponse, enrolled bool, err error) {
+	resp, err = loadState(cfg.Enrollment.State)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if resp == nil {
+		if cfg.Enrollment.Token == "" {
+			return nil, false, nil
+		}
+
+		resp, err = enroll(ctx, cfg)
+		if err != nil {
+			return nil, false, err
+		}
+
+		if err := saveState(cfg.Enrollment.State, resp); err != nil {
+			return nil, false, err
+		}
+
+		enrolled = true
+	}
+
+	if err := writeBackendKey(cfg.Identity.BackendPublicKey, resp.BackendPublicKey); err != nil {
+		return nil, false, err
+	}
+
+	applyResponse(cfg, resp)
+
+	return resp, enrolled, nil
+}
+
+func enroll(ctx context.Context, cfg *config.Config) (*contract.EnrollResponse, error) {
+	base := cfg.Enrollment.URL
+	if base == "" {
+		base = cfg.Gateway.URL
+	}
+
+	if base == "" {
+		return nil, ErrURLIsEmpty
+	}
+
+	key, _, err := contract.LoadOrGenerateKey(cfg.Identity.PrivateKey, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load agent key: %w", err)
+	}
+
+	body, err := json.Marshal(contract.EnrollRequest{
+		Version:      contract.SchemaVersion,
+		Token:        cfg.Enrollment.Token,
+		PublicKey:    key.Public().(ed25519.PublicKey),
+		AgentVersion: cfg.App.Version,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal enrollment request: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(base, "/")+enrollPath, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create enrollment request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	httpResp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send enrollment request: %w", err)
+	}
+
+	defer httpResp.Body.Close()
+
+	data, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read enrollment response: %w", err)
+	}
+
+	var env envelope
+	_ = json.Unmarshal(data, &env)
+
+	switch {
+	case httpResp.StatusCode == http.StatusUnauthorized || httpResp.StatusCode == http.StatusForbidden:
+		return nil, fmt.Errorf("%w: %s", ErrTokenRejected, env.Message)
+	case httpResp.StatusCode < 200 || httpResp.StatusCode > 299:
+		return nil, fmt.Errorf("%w %d: %s", ErrBadStatus, httpResp.StatusCode, env.Message)
+	}
+
+	var resp contract.EnrollResponse
+	if err := json.Unmarshal(env.Data, &resp); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal enrollment response: %w", err)
+	}
+
+	return &resp, nil
+}
+
+// applyResponse значения из регистрации перекрывают конфиг. Брокеры бэкенда берутся, только если в конфиге их нет:
+// агент может ходить к Kafka по другому адресу, чем бэкенд.
+func applyResponse(cfg *config.Config, resp *contract.EnrollResponse) {
+	cfg.App.AgentID = resp.AgentID
+	cfg.App.Region = resp.Region
+	cfg.App.Country = resp.Country
+	cfg.App.ASN = resp.ASN
+
+	if resp.Location != nil {
+		cfg.App.Lat = resp.Location.Lat
+		cfg.App.Lon = resp.Location.Lon
+	}
+
+	if resp.Transport != "" {
+		cfg.App.Transport = resp.Transport
+	}
+
+	cfg.Subscriber.Topic = resp.Topics.Tasks
+	cfg.Publisher.Topic = resp.Topics.Results
+	cfg.Heartbeat.Topic = resp.Topics.Heartbeat
+
+	if resp.GatewayToken != "" {
+		cfg.Gateway.Token = resp.GatewayToken
+	}
+
+	if len(cfg.Subscriber.Brokers) == 0 {
+		cfg.Subscriber.Brokers = resp.Brokers
+	}
+
+	if len(cfg.Publisher.Brokers) == 0 {
+		cfg.Publisher.Brokers = resp.Brokers
+	}
+}
+
+func loadState(path string) (*contract.EnrollResponse, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read enrollment state: %w", err)
+	}
+
+	var resp contract.EnrollResponse
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal enrollment state: %w", err)
+	}
+
+	return &resp, nil
+}
+
+func saveState(path string, resp *contract.EnrollResponse) error {
+	data, err := json.MarshalIndent(resp, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal enrollment state: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("failed to create enrollment state dir: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write enrollment state: %w", err)
+	}
+
+	return nil
+}
+
+// writeBackendKey ключ бэкенда из регистрации кладётся туда, где его ждёт identity, если оператор не положил свой.
+func writeBackendKey(path string, key []byte) error {
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	}
+
+	if len(key) != ed25519.PublicKeySize {
+		return fmt.Errorf("failed to write backend public key: invalid key size %d", len(key))
+	}
+
+	data, err := contract.MarshalPublicKeyPEM(key)
+	if err != nil {
+		return fmt.Errorf("failed to marshal backend public key: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("failed to create backend public key dir: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write backend public key: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,79 @@
+// Package identity ключ Ed25519 агента и проверка подписи бэкенда.
+// Задачи приходят в contract.Envelope, подписанном бэкендом, результаты и heartbeat агент
+// отправляет в конверте, подписанном своим ключом от имени agent_id.
+package identity
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"fmt"
+	"hackathon-contract"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Identity ключ агента и публичный ключ бэкенда.
+type Identity struct {
+	agentID uuid.UUID
+	key     ed25519.PrivateKey
+	backend ed25519.PublicKey
+	now     func() time.Time
+}
+
+// Load читает ключ агента из keyPath (создаёт, если файла нет) и публичный ключ бэкенда из backendKeyPath.
+// generated сообщает, что ключ агента создан сейчас: бэкенд примет его только после регистрации по токену.
+func Load(agentID uuid.UUID, keyPath, backendKeyPath string) (id *Identity, generated bool, err error) {
+	key, generated, err := contract.LoadOrGenerateKey(keyPath, "")
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to load agent key: %w", err)
+	}
+
+	backend, err := contract.LoadPublicKey(backendKeyPath)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to load backend public key: %w", err)
+	}
+
+	return &Identity{agentID: agentID, key: key, backend: backend, now: time.Now}, generated, nil
+}
+
+// Seal подписывает сообщение агента. Срок действия не задаётся: результат может дойти
+// до бэкенда позже из-за ретраев, но подписант и содержимое не подделать.
+func (i *Identity) Seal(payload []byte) ([]byte, error) {
+	env, err := contract.Seal(i.key, i.agentID.String(), payload, i.now(), 0)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := json.Marshal(env)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal envelope: %w", err)
+	}
+
+	return b, nil
+}
+
+// Open проверяет, что задача подписана бэкендом и не истекла, и возвращает её содержимое.
+func (i *Identity) Open(data []byte) ([]byte, error) {
+	env, err := contract.OpenEnvelope(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := env.Verify(i.backend, contract.BackendSigner, i.now()); err != nil {
+		return nil, err
+	}
+
+	return env.Payload, nil
+}
+
+// Unsigned пропускает сообщения без конверта, для `agent run`, где задача приходит от оператора.
+type Unsigned struct{}
+
+func (Unsigned) Seal(payload []byte) ([]byte, error) {
+	return payload, nil
+}
+
+func (Unsigned) Open(data []byte) ([]byte, error) {
+	return data, nil
+}
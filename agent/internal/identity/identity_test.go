@@ -0,0 +1,179 @@
+package identity
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"hackathon-contract"
+)
+
+const testTask = `{"target":"example.com"}`
+
+// loadTestIdentity создаёт ключ агента во временной папке и возвращает ключ "бэкенда", которому агент доверяет.
+func loadTestIdentity(t *testing.T) (*Identity, ed25519.PrivateKey) {
+	t.Helper()
+
+	dir := t.TempDir()
+
+	backendPub, backendKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+
+	pem, err := contract.MarshalPublicKeyPEM(backendPub)
+	if err != nil {
+		t.Fatalf("marshal public key: %v", err)
+	}
+
+	backendKeyPath := filepath.Join(dir, "backend.pub")
+	if err := os.WriteFile(backendKeyPath, pem, 0o600); err != nil {
+		t.Fatalf("write backend key: %v", err)
+	}
+
+	id, generated, err := Load(uuid.New(), filepath.Join(dir, "agent.key"), backendKeyPath)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	if !generated {
+		t.Fatal("agent key was not generated")
+	}
+
+	return id, backendKey
+}
+
+func sealTask(t *testing.T, key ed25519.PrivateKey, signer string, issuedAt time.Time, ttl time.Duration) []byte {
+	t.Helper()
+
+	env, err := contract.Seal(key, signer, []byte(testTask), issuedAt, ttl)
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+
+	b, err := json.Marshal(env)
+	if err != nil {
+		t.Fatalf("marshal envelope: %v", err)
+	}
+
+	return b
+}
+
+func TestOpen(t *testing.T) {
+	id, backendKey := loadTestIdentity(t)
+
+	_, otherKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+
+	now := time.Now()
+
+	tampered := sealTask(t, backendKey, contract.BackendSigner, now, time.Minute)
+
+	var env contract.Envelope
+	if err := json.Unmarshal(tampered, &env); err != nil {
+		t.Fatalf("unmarshal envelope: %v", err)
+	}
+
+	env.Payload = json.RawMessage(`{"target":"evil.example"}`)
+
+	tampered, err = json.Marshal(env)
+	if err != nil {
+		t.Fatalf("marshal envelope: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr error
+	}{
+		{name: "task from backend", data: sealTask(t, backendKey, contract.BackendSigner, now, time.Minute)},
+		{name: "tampered task", data: tampered, wantErr: contract.ErrInvalidSignature},
+		{name: "expired task", data: sealTask(t, backendKey, contract.BackendSigner, now.Add(-time.Hour), time.Minute), wantErr: contract.ErrEnvelopeExpired},
+		{name: "signed by another key", data: sealTask(t, otherKey, contract.BackendSigner, now, time.Minute), wantErr: contract.ErrInvalidSignature},
+		{name: "signed as an agent", data: sealTask(t, backendKey, id.agentID.String(), now, time.Minute), wantErr: contract.ErrUnexpectedSigner},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payload, err := id.Open(tt.data)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Open = %v, want %v", err, tt.wantErr)
+			}
+
+			if err == nil && string(payload) != testTask {
+				t.Fatalf("got payload %s", payload)
+			}
+		})
+	}
+}
+
+func TestSealVerifiesWithAgentKey(t *testing.T) {
+	id, _ := loadTestIdentity(t)
+
+	data, err := id.Seal([]byte(testTask))
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+
+	env, err := contract.OpenEnvelope(data)
+	if err != nil {
+		t.Fatalf("OpenEnvelope: %v", err)
+	}
+
+	if env.ExpiresAt != nil {
+		t.Fatalf("agent envelope expires at %s, want no expiry", env.ExpiresAt)
+	}
+
+	pub := id.key.Public().(ed25519.PublicKey)
+
+	if err := env.Verify(pub, id.agentID.String(), time.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+
+	if err := env.Verify(pub, uuid.NewString(), time.Now()); !errors.Is(err, contract.ErrUnexpectedSigner) {
+		t.Fatalf("got %v, want %v", err, contract.ErrUnexpectedSigner)
+	}
+}
+
+func TestLoadKeepsAgentKey(t *testing.T) {
+	id, _ := loadTestIdentity(t)
+
+	dir := t.TempDir()
+	keyPath := filepath.Join(dir, "agent.key")
+
+	pem, err := contract.MarshalPrivateKeyPEM(id.key)
+	if err != nil {
+		t.Fatalf("marshal private key: %v", err)
+	}
+
+	if err := os.WriteFile(keyPath, pem, 0o600); err != nil {
+		t.Fatalf("write agent key: %v", err)
+	}
+
+	pubPEM, err := contract.MarshalPublicKeyPEM(id.backend)
+	if err != nil {
+		t.Fatalf("marshal public key: %v", err)
+	}
+
+	backendKeyPath := filepath.Join(dir, "backend.pub")
+	if err := os.WriteFile(backendKeyPath, pubPEM, 0o600); err != nil {
+		t.Fatalf("write backend key: %v", err)
+	}
+
+	loaded, generated, err := Load(id.agentID, keyPath, backendKeyPath)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	if generated || !loaded.key.Equal(id.key) {
+		t.Fatal("existing agent key was not reused")
+	}
+}
@@ -0,0 +1,129 @@
+// Package metrics собирает Prometheus метрики агента. Реестр свой, а не глобальный,
+// чтобы /metrics отдавал только метрики агента и стандартные метрики процесса.
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "agent"
+
+const (
+	OutcomeOK     = "ok"
+	OutcomeFailed = "failed"
+)
+
+// Виды сообщений, публикацию которых считает PublishFailed.
+const (
+	PublishResult    = "result"
+	PublishProgress  = "progress"
+	PublishHeartbeat = "heartbeat"
+)
+
+type StatsSource interface {
+	Stats() (inFlight, queueDepth int)
+}
+
+type Metrics struct {
+	registry *prometheus.Registry
+
+	tasksConsumed  prometheus.Counter
+	checksTotal    *prometheus.CounterVec
+	checkDuration  *prometheus.HistogramVec
+	publishFailure *prometheus.CounterVec
+}
+
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		tasksConsumed: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "tasks_consumed_total",
+			Help:      "Tasks read from the Kafka topic.",
+		}),
+		checksTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "checks_total",
+			Help:      "Checks run, by type and outcome.",
+		}, []string{"type", "outcome"}),
+		checkDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "check_duration_seconds",
+			Help:      "Check duration, by type.",
+			Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1, 2, 4, 8, 16, 32},
+		}, []string{"type"}),
+		publishFailure: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "publish_failures_total",
+			Help:      "Messages that failed to be published to Kafka, by kind.",
+		}, []string{"kind"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.tasksConsumed,
+		m.checksTotal,
+		m.checkDuration,
+		m.publishFailure,
+	)
+
+	return m
+}
+
+// RegisterWorkerPool добавляет метрики загрузки воркеров: они читаются из stats в момент сбора.
+func (m *Metrics) RegisterWorkerPool(workers int, stats StatsSource) {
+	m.registry.MustRegister(
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "workers",
+			Help:      "Size of the worker pool.",
+		}, func() float64 {
+			return float64(workers)
+		}),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "workers_busy",
+			Help:      "Workers currently running a task.",
+		}, func() float64 {
+			inFlight, _ := stats.Stats()
+			return float64(inFlight)
+		}),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "queue_depth",
+			Help:      "Tasks read from Kafka and waiting for a free worker.",
+		}, func() float64 {
+			_, queueDepth := stats.Stats()
+			return float64(queueDepth)
+		}),
+	)
+}
+
+func (m *Metrics) TaskConsumed() {
+	m.tasksConsumed.Inc()
+}
+
+func (m *Metrics) CheckFinished(checkType string, ok bool, duration time.Duration) {
+	outcome := OutcomeOK
+	if !ok {
+		outcome = OutcomeFailed
+	}
+
+	m.checksTotal.WithLabelValues(checkType, outcome).Inc()
+	m.checkDuration.WithLabelValues(checkType).Observe(duration.Seconds())
+}
+
+func (m *Metrics) PublishFailed(kind string) {
+	m.publishFailure.WithLabelValues(kind).Inc()
+}
+
+// Handler отдаёт метрики в формате Prometheus.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
@@ -0,0 +1,148 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hackathon-agent/internal/metrics"
+	"hackathon-agent/pkg/kafka"
+	"hackathon-contract"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type StatsSource interface {
+	Stats() (inFlight, queueDepth int)
+}
+
+// Signer ключ агента, им подписывается конверт heartbeat.
+type Signer interface {
+	Seal(payload []byte) ([]byte, error)
+}
+
+// Heartbeater раз в interval публикует contract.Heartbeat с загрузкой агента.
+type Heartbeater struct {
+	log       *zap.Logger
+	producer  kafka.Producer
+	topic     string
+	interval  time.Duration
+	agentID   uuid.UUID
+	region    string
+	country   string
+	asn       int
+	location  *contract.Location
+	version   string
+	transport string
+	stats     StatsSource
+	metrics   Metrics
+	caps      contract.Capabilities
+	signer    Signer
+	started   time.Time
+}
+
+func NewHeartbeater(
+	log *zap.Logger,
+	producer kafka.Producer,
+	topic string,
+	interval time.Duration,
+	agentID uuid.UUID,
+	region, country string,
+	asn int,
+	location *contract.Location,
+	version, transport string,
+	stats StatsSource,
+	metrics Metrics,
+	caps contract.Capabilities,
+	signer Signer,
+) *Heartbeater {
+	return &Heartbeater{
+		log:       log,
+		producer:  producer,
+		topic:     topic,
+		interval:  interval,
+		agentID:   agentID,
+		region:    region,
+		country:   country,
+		asn:       asn,
+		location:  location,
+		version:   version,
+		transport: transport,
+		stats:     stats,
+		metrics:   metrics,
+		caps:      caps,
+		signer:    signer,
+		started:   time.Now(),
+	}
+}
+
+func (h *Heartbeater) Run(ctx context.Context) {
+	ticker := time.NewTicker(h.interval)
+	defer ticker.Stop()
+
+	// первый heartbeat сразу, чтобы бэкенд увидел агента без ожидания интервала
+	h.beat(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			h.log.Info("context canceled, stopping heartbeat")
+
+			return
+		case <-ticker.C:
+			h.beat(ctx)
+		}
+	}
+}
+
+func (h *Heartbeater) beat(ctx context.Context) {
+	if err := h.publish(ctx); err != nil {
+		h.metrics.PublishFailed(metrics.PublishHeartbeat)
+		h.log.Warn("Failed to publish heartbeat", zap.Error(err))
+	}
+}
+
+func (h *Heartbeater) publish(ctx context.Context) error {
+	inFlight, queueDepth := h.stats.Stats()
+
+	hb := contract.Heartbeat{
+		Version:       contract.SchemaVersion,
+		AgentID:       h.agentID,
+		Region:        h.region,
+		Country:       h.country,
+		ASN:           h.asn,
+		Location:      h.location,
+		AgentVersion:  h.version,
+		SentAt:        time.Now().UTC(),
+		UptimeSeconds: int64(time.Since(h.started).Seconds()),
+		InFlight:      inFlight,
+		QueueDepth:    queueDepth,
+		Capabilities:  &h.caps,
+		Transport:     h.transport,
+	}
+
+	b, err := json.Marshal(hb)
+	if err != nil {
+		return fmt.Errorf("failed to marshal heartbeat: %w", err)
+	}
+
+	b, err = h.signer.Seal(b)
+	if err != nil {
+		return fmt.Errorf("failed to seal heartbeat: %w", err)
+	}
+
+	key, err := h.agentID.MarshalBinary()
+	if err != nil {
+		return fmt.Errorf("failed to marshal agentID: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, h.interval)
+	defer cancel()
+
+	if _, _, err := h.producer.PushMessage(ctx, key, b, h.topic); err != nil {
+		return fmt.Errorf("failed to push heartbeat: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"hackathon-agent/internal/metrics"
+	"hackathon-contract"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+type fakeProducer struct {
+	err    error
+	pushed [][]byte
+}
+
+func (p *fakeProducer) PushMessage(_ context.Context, _, value []byte, _ string) (int32, int64, error) {
+	if p.err != nil {
+		return 0, 0, p.err
+	}
+
+	p.pushed = append(p.pushed, value)
+
+	return 0, int64(len(p.pushed)), nil
+}
+
+func (*fakeProducer) Ready() error {
+	return nil
+}
+
+func (*fakeProducer) Close() error {
+	return nil
+}
+
+type fakeMetrics struct {
+	publishFailed []string
+}
+
+func (*fakeMetrics) TaskConsumed() {}
+
+func (*fakeMetrics) CheckFinished(string, bool, time.Duration) {}
+
+func (m *fakeMetrics) PublishFailed(kind string) {
+	m.publishFailed = append(m.publishFailed, kind)
+}
+
+// plainEnvelopes отдаёт сообщения без подписи.
+type plainEnvelopes struct{}
+
+func (plainEnvelopes) Seal(payload []byte) ([]byte, error) {
+	return payload, nil
+}
+
+func (plainEnvelopes) Open(data []byte) ([]byte, error) {
+	return data, nil
+}
+
+func TestPublish(t *testing.T) {
+	agentID := uuid.New()
+
+	tests := []struct {
+		name       string
+		res        contract.CheckResult
+		pushErr    error
+		wantPushed bool
+		wantFailed []string
+	}{
+		{
+			name:       "result",
+			res:        contract.CheckResult{TaskID: uuid.New(), Final: true, Seq: 2, OK: true},
+			wantPushed: true,
+		},
+		{
+			name:       "push error",
+			res:        contract.CheckResult{TaskID: uuid.New(), Final: true, Seq: 2},
+			pushErr:    errors.New("broker down"),
+			wantFailed: []string{metrics.PublishResult},
+		},
+		{
+			name:       "progress push error",
+			res:        contract.CheckResult{TaskID: uuid.New(), Seq: 1, Payload: json.RawMessage(`{"hops":[]}`)},
+			pushErr:    errors.New("broker down"),
+			wantFailed: []string{metrics.PublishProgress},
+		},
+		{
+			name: "payload does not marshal",
+			res:  contract.CheckResult{TaskID: uuid.New(), Final: true, Seq: 2, Payload: json.RawMessage(`{`)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core, logs := observer.New(zap.InfoLevel)
+			producer := &fakeProducer{err: tt.pushErr}
+			m := &fakeMetrics{}
+
+			s := NewService(zap.New(core), nil, producer, "results", nil, m, agentID, plainEnvelopes{})
+			s.publish(context.Background(), tt.res)
+
+			if got := len(producer.pushed) == 1; got != tt.wantPushed {
+				t.Fatalf("pushed %d messages, want pushed = %v", len(producer.pushed), tt.wantPushed)
+			}
+
+			if sent := logs.FilterMessage("Message sent").Len(); (sent == 1) != tt.wantPushed {
+				t.Fatalf("logged %d \"Message sent\", want pushed = %v", sent, tt.wantPushed)
+			}
+
+			if len(m.publishFailed) != len(tt.wantFailed) || (len(m.publishFailed) > 0 && m.publishFailed[0] != tt.wantFailed[0]) {
+				t.Fatalf("PublishFailed(%v), want %v", m.publishFailed, tt.wantFailed)
+			}
+
+			if !tt.wantPushed {
+				return
+			}
+
+			var res contract.CheckResult
+			if err := json.Unmarshal(producer.pushed[0], &res); err != nil {
+				t.Fatalf("unmarshal pushed result: %v", err)
+			}
+
+			if res.AgentID != agentID || res.TaskID != tt.res.TaskID {
+				t.Fatalf("pushed %+v", res)
+			}
+		})
+	}
+}
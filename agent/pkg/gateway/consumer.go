@@ -0,0 +1,131 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/IBM/sarama"
+
+	"hackathon-agent/pkg/kafka"
+)
+
+const (
+	minBackoff = time.Second
+	maxBackoff = 30 * time.Second
+)
+
+type consumer struct {
+	client   *client
+	ctx      context.Context
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+	messages chan *kafka.MessageWithMarkFunc
+	errChan  chan error
+	infoChan chan string
+	joined   atomic.Bool
+	once     sync.Once
+}
+
+// NewConsumer returns a kafka.ConsumerGroupRunner that long-polls the gateway for tasks assigned to cfg.AgentID.
+func NewConsumer(cfg Config, bufferSize int) kafka.ConsumerGroupRunner {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &consumer{
+		client:   newClient(cfg),
+		ctx:      ctx,
+		cancel:   cancel,
+		messages: make(chan *kafka.MessageWithMarkFunc, bufferSize),
+		errChan:  make(chan error, 1),
+		infoChan: make(chan string, 1),
+	}
+}
+
+// Run polls until Shutdown. Failed polls are retried with exponential backoff.
+func (c *consumer) Run() {
+	c.wg.Add(1)
+	defer c.wg.Done()
+
+	backoff := minBackoff
+
+	for c.ctx.Err() == nil {
+		messages, err := c.client.poll(c.ctx)
+		if err != nil {
+			if c.ctx.Err() != nil {
+				return
+			}
+
+			c.joined.Store(false)
+			c.reportError(fmt.Errorf("gateway poll error: %w", err))
+
+			select {
+			case <-c.ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+
+			backoff = min(backoff*2, maxBackoff)
+
+			continue
+		}
+
+		backoff = minBackoff
+
+		if !c.joined.Swap(true) {
+			c.once.Do(func() { c.infoChan <- UpAndRunning })
+		}
+
+		for _, msg := range messages {
+			key, _ := msg.Key.MarshalBinary()
+
+			select {
+			case <-c.ctx.Done():
+				return
+			case c.messages <- &kafka.MessageWithMarkFunc{
+				Message: &sarama.ConsumerMessage{Topic: msg.Topic, Key: key, Value: msg.Value},
+				// the backend marks a task as sent when it hands it out, there is nothing to commit
+				Mark: func() {},
+			}:
+			}
+		}
+	}
+}
+
+func (c *consumer) Messages() <-chan *kafka.MessageWithMarkFunc {
+	return c.messages
+}
+
+// Shutdown stops polling and closes the channels.
+func (c *consumer) Shutdown() error {
+	c.cancel()
+	c.wg.Wait()
+
+	close(c.messages)
+	close(c.errChan)
+	close(c.infoChan)
+
+	return nil
+}
+
+func (c *consumer) Error() <-chan error {
+	return c.errChan
+}
+
+func (c *consumer) Info() <-chan string {
+	return c.infoChan
+}
+
+// Joined reports whether the last poll reached the gateway.
+func (c *consumer) Joined() bool {
+	return c.joined.Load()
+}
+
+// reportError drops the error if nobody is reading the channel, polling must not block on it.
+func (c *consumer) reportError(err error) {
+	select {
+	case c.errChan <- err:
+	default:
+	}
+}
@@ -0,0 +1,152 @@
+// Package gateway provides an HTTP transport for agents that cannot reach the Kafka brokers.
+// The agent long-polls the backend for tasks assigned to it and posts results and heartbeats back.
+// Consumer and Producer implement kafka.ConsumerGroupRunner and kafka.Producer, so the service
+// does not care which transport it runs on.
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	UpAndRunning = "gateway consumer up and running"
+
+	AgentIDHeader = "X-Agent-ID"
+
+	tasksPath    = "/agent/tasks"
+	messagesPath = "/agent/messages"
+
+	// requestSlack is added to the poll wait so the client does not give up before the backend answers.
+	requestSlack = 10 * time.Second
+)
+
+var (
+	ErrUnauthorized   = fmt.Errorf("gateway rejected agent credentials")
+	ErrBadStatus      = fmt.Errorf("gateway returned unexpected status")
+	ErrProducerClosed = fmt.Errorf("gateway producer closed")
+	ErrNotReachable   = fmt.Errorf("gateway has not been reached yet")
+)
+
+// Config describes how the agent reaches the backend gateway.
+type Config struct {
+	URL      string        // URL backend base URL including the base path, e.g. https://api.example.com/api
+	Token    string        // Token bearer token issued to this agent at enrollment
+	AgentID  uuid.UUID     // AgentID sent in X-Agent-ID
+	PollWait time.Duration // PollWait how long the backend may hold a poll open
+	Timeout  time.Duration // Timeout for posting a single message
+}
+
+type client struct {
+	cfg  Config
+	http *http.Client
+}
+
+type envelope struct {
+	Status  string          `json:"status"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data"`
+}
+
+type gatewayMessage struct {
+	Topic string          `json:"topic"`
+	Key   uuid.UUID       `json:"key"`
+	Value json.RawMessage `json:"value"`
+}
+
+type pollResponse struct {
+	Messages []gatewayMessage `json:"messages"`
+}
+
+func newClient(cfg Config) *client {
+	return &client{
+		cfg:  cfg,
+		http: &http.Client{},
+	}
+}
+
+func (c *client) poll(ctx context.Context) ([]gatewayMessage, error) {
+	query := url.Values{}
+	query.Set("wait", strconv.Itoa(int(c.cfg.PollWait.Seconds())))
+
+	ctx, cancel := context.WithTimeout(ctx, c.cfg.PollWait+requestSlack)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.endpoint(tasksPath)+"?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp pollResponse
+	if err := c.do(req, &resp); err != nil {
+		return nil, err
+	}
+
+	return resp.Messages, nil
+}
+
+func (c *client) publish(ctx context.Context, msg gatewayMessage) error {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.cfg.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint(messagesPath), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	return c.do(req, nil)
+}
+
+func (c *client) do(req *http.Request, out any) error {
+	req.Header.Set("Authorization", "Bearer "+c.cfg.Token)
+	req.Header.Set(AgentIDHeader, c.cfg.AgentID.String())
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	var env envelope
+	_ = json.Unmarshal(body, &env)
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return fmt.Errorf("%w: %s", ErrUnauthorized, env.Message)
+	case resp.StatusCode < 200 || resp.StatusCode > 299:
+		return fmt.Errorf("%w %d: %s", ErrBadStatus, resp.StatusCode, env.Message)
+	}
+
+	if out == nil || len(env.Data) == 0 {
+		return nil
+	}
+
+	return json.Unmarshal(env.Data, out)
+}
+
+func (c *client) endpoint(path string) string {
+	return strings.TrimSuffix(c.cfg.URL, "/") + path
+}
@@ -0,0 +1,248 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const testToken = "agent-token"
+
+// fakeGateway hands out tasks once and records the messages posted by the agent.
+type fakeGateway struct {
+	t       *testing.T
+	agentID uuid.UUID
+
+	mu       sync.Mutex
+	tasks    []gatewayMessage
+	received []gatewayMessage
+}
+
+func newFakeGateway(t *testing.T, agentID uuid.UUID, tasks ...gatewayMessage) (*fakeGateway, *httptest.Server) {
+	t.Helper()
+
+	g := &fakeGateway{t: t, agentID: agentID, tasks: tasks}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api"+tasksPath, g.poll)
+	mux.HandleFunc("POST /api"+messagesPath, g.message)
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	return g, srv
+}
+
+func (g *fakeGateway) authorized(w http.ResponseWriter, r *http.Request) bool {
+	if r.Header.Get("Authorization") != "Bearer "+testToken || r.Header.Get(AgentIDHeader) != g.agentID.String() {
+		w.WriteHeader(http.StatusUnauthorized)
+		_ = json.NewEncoder(w).Encode(envelope{Status: "error", Message: "invalid agent token"})
+
+		return false
+	}
+
+	return true
+}
+
+func (g *fakeGateway) poll(w http.ResponseWriter, r *http.Request) {
+	if !g.authorized(w, r) {
+		return
+	}
+
+	wait, err := strconv.Atoi(r.URL.Query().Get("wait"))
+	if err != nil {
+		g.t.Errorf("poll wait %q: %v", r.URL.Query().Get("wait"), err)
+	}
+
+	g.mu.Lock()
+	tasks := g.tasks
+	g.tasks = nil
+	g.mu.Unlock()
+
+	if len(tasks) == 0 {
+		// long poll: hold the request for the requested wait and answer with no tasks
+		select {
+		case <-r.Context().Done():
+			return
+		case <-time.After(time.Duration(wait) * time.Second):
+		}
+	}
+
+	data, _ := json.Marshal(pollResponse{Messages: tasks})
+	_ = json.NewEncoder(w).Encode(envelope{Status: "ok", Data: data})
+}
+
+func (g *fakeGateway) message(w http.ResponseWriter, r *http.Request) {
+	if !g.authorized(w, r) {
+		return
+	}
+
+	var msg gatewayMessage
+	if err := json.NewDecoder(r.Body).Decode(&msg); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+
+		return
+	}
+
+	g.mu.Lock()
+	g.received = append(g.received, msg)
+	g.mu.Unlock()
+
+	_ = json.NewEncoder(w).Encode(envelope{Status: "ok"})
+}
+
+func (g *fakeGateway) messages() []gatewayMessage {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return append([]gatewayMessage(nil), g.received...)
+}
+
+func testConfig(url string, agentID uuid.UUID) Config {
+	return Config{
+		URL:      url + "/api/",
+		Token:    testToken,
+		AgentID:  agentID,
+		PollWait: time.Second,
+		Timeout:  time.Second,
+	}
+}
+
+func TestPollAckResultRoundTrip(t *testing.T) {
+	agentID := uuid.New()
+	taskID := uuid.New()
+
+	gw, srv := newFakeGateway(t, agentID, gatewayMessage{Topic: "tasks.eu", Key: taskID, Value: json.RawMessage(`{"id":"task"}`)})
+	cfg := testConfig(srv.URL, agentID)
+
+	consumer := NewConsumer(cfg, 1)
+	go consumer.Run()
+
+	select {
+	case info := <-consumer.Info():
+		if info != UpAndRunning {
+			t.Fatalf("got info %q", info)
+		}
+	case err := <-consumer.Error():
+		t.Fatalf("poll failed: %v", err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("consumer did not reach the gateway")
+	}
+
+	select {
+	case msg := <-consumer.Messages():
+		if msg.Message.Topic != "tasks.eu" || string(msg.Message.Value) != `{"id":"task"}` {
+			t.Fatalf("got message %+v", msg.Message)
+		}
+
+		if key, err := uuid.FromBytes(msg.Message.Key); err != nil || key != taskID {
+			t.Fatalf("got key %x, want %s", msg.Message.Key, taskID)
+		}
+
+		msg.Mark()
+	case <-time.After(5 * time.Second):
+		t.Fatal("task was not delivered")
+	}
+
+	producer := NewProducer(cfg)
+
+	if err := producer.Ready(); !errors.Is(err, ErrNotReachable) {
+		t.Fatalf("Ready before the first message = %v, want %v", err, ErrNotReachable)
+	}
+
+	key, _ := taskID.MarshalBinary()
+
+	for _, value := range []string{`{"seq":1}`, `{"seq":2,"final":true}`} {
+		if _, _, err := producer.PushMessage(context.Background(), key, []byte(value), "results"); err != nil {
+			t.Fatalf("PushMessage(%s): %v", value, err)
+		}
+	}
+
+	if err := producer.Ready(); err != nil {
+		t.Fatalf("Ready: %v", err)
+	}
+
+	received := gw.messages()
+	if len(received) != 2 {
+		t.Fatalf("gateway received %d messages, want ack and result", len(received))
+	}
+
+	for i, want := range []string{`{"seq":1}`, `{"seq":2,"final":true}`} {
+		if received[i].Topic != "results" || received[i].Key != taskID || string(received[i].Value) != want {
+			t.Fatalf("message %d = %+v, want %s", i, received[i], want)
+		}
+	}
+
+	if !consumer.Joined() {
+		t.Fatal("consumer is not joined after a successful poll")
+	}
+
+	if err := consumer.Shutdown(); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+
+	if err := producer.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	if _, _, err := producer.PushMessage(context.Background(), key, []byte(`{}`), "results"); !errors.Is(err, ErrProducerClosed) {
+		t.Fatalf("PushMessage after Close = %v, want %v", err, ErrProducerClosed)
+	}
+}
+
+func TestRejectedCredentials(t *testing.T) {
+	agentID := uuid.New()
+
+	gw, srv := newFakeGateway(t, agentID)
+
+	cfg := testConfig(srv.URL, agentID)
+	cfg.Token = "stolen-token"
+
+	consumer := NewConsumer(cfg, 1)
+	go consumer.Run()
+
+	select {
+	case err := <-consumer.Error():
+		if !errors.Is(err, ErrUnauthorized) {
+			t.Fatalf("got %v, want %v", err, ErrUnauthorized)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("poll error was not reported")
+	}
+
+	if err := consumer.Shutdown(); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+
+	producer := NewProducer(cfg)
+
+	key, _ := uuid.New().MarshalBinary()
+
+	if _, _, err := producer.PushMessage(context.Background(), key, []byte(`{}`), "results"); !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("PushMessage = %v, want %v", err, ErrUnauthorized)
+	}
+
+	if err := producer.Ready(); !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("Ready = %v, want %v", err, ErrUnauthorized)
+	}
+
+	if len(gw.messages()) != 0 {
+		t.Fatal("gateway accepted a message with a rejected token")
+	}
+}
+
+func TestPushMessageRequiresUUIDKey(t *testing.T) {
+	producer := NewProducer(testConfig("http://127.0.0.1:1", uuid.New()))
+
+	if _, _, err := producer.PushMessage(context.Background(), []byte("not-a-uuid"), []byte(`{}`), "results"); err == nil {
+		t.Fatal("expected error for non-uuid key")
+	}
+}
@@ -0,0 +1,70 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/google/uuid"
+
+	"hackathon-agent/pkg/kafka"
+)
+
+type producer struct {
+	client  *client
+	closed  atomic.Bool
+	reached atomic.Bool
+	lastErr atomic.Pointer[error]
+}
+
+// NewProducer returns a kafka.Producer that posts messages to the gateway instead of a topic.
+// The topic is passed through, the backend routes the message by it. Keys must be UUIDs.
+func NewProducer(cfg Config) kafka.Producer {
+	return &producer{client: newClient(cfg)}
+}
+
+func (p *producer) PushMessage(ctx context.Context, key, value []byte, topic string) (partition int32, offset int64, err error) {
+	if p.closed.Load() {
+		return 0, 0, ErrProducerClosed
+	}
+
+	id, err := uuid.FromBytes(key)
+	if err != nil {
+		return 0, 0, fmt.Errorf("gateway message key must be a uuid: %w", err)
+	}
+
+	err = p.client.publish(ctx, gatewayMessage{Topic: topic, Key: id, Value: value})
+	if err != nil {
+		p.lastErr.Store(&err)
+
+		return 0, 0, err
+	}
+
+	p.reached.Store(true)
+	p.lastErr.Store(nil)
+
+	return 0, 0, nil
+}
+
+// Ready returns nil once a message has been accepted and the last one did not fail.
+func (p *producer) Ready() error {
+	if p.closed.Load() {
+		return ErrProducerClosed
+	}
+
+	if errPtr := p.lastErr.Load(); errPtr != nil {
+		return *errPtr
+	}
+
+	if !p.reached.Load() {
+		return ErrNotReachable
+	}
+
+	return nil
+}
+
+func (p *producer) Close() error {
+	p.closed.Store(true)
+
+	return nil
+}
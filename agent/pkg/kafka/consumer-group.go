@@ -123,3 +123,8 @@ func (r *consumerGroupRunner) Error() <-chan error {
 func (r *consumerGroupRunner) Info() <-chan string {
 	return r.infoChan
 }
+
+// Joined reports whether the consumer is currently a member of the group with an active session.
+func (r *consumerGroupRunner) Joined() bool {
+	return r.consumer.joined.Load()
+}
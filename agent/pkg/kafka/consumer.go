@@ -1,11 +1,14 @@
 package kafka
 
 import (
+	"sync/atomic"
+
 	"github.com/IBM/sarama"
 )
 
 type Consumer struct {
 	ready    chan bool
+	joined   atomic.Bool
 	messages chan *MessageWithMarkFunc
 }
 
@@ -24,6 +27,7 @@ func NewConsumer(bufferSize int) *Consumer {
 // Setup is run at the beginning of a new session, before ConsumeClaim.
 func (c *Consumer) Setup(sarama.ConsumerGroupSession) error {
 	// Mark the consumer as ready
+	c.joined.Store(true)
 	close(c.ready)
 
 	return nil
@@ -31,6 +35,8 @@ func (c *Consumer) Setup(sarama.ConsumerGroupSession) error {
 
 // Cleanup is run at the end of a session, once all ConsumeClaim goroutines have exited.
 func (c *Consumer) Cleanup(sarama.ConsumerGroupSession) error {
+	c.joined.Store(false)
+
 	return nil
 }
 
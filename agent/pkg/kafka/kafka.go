@@ -13,7 +13,11 @@ const (
 	UpAndRunning = "kafka consumer up and running"
 )
 
-var ErrSendMessageTimeout = fmt.Errorf("kafka send message timeout")
+var (
+	ErrSendMessageTimeout = fmt.Errorf("kafka send message timeout")
+	ErrProducerClosed     = fmt.Errorf("kafka producer closed")
+	ErrNoBrokers          = fmt.Errorf("kafka has no available brokers")
+)
 
 // Option defines a configuration function for Kafka producers.
 type Option func(*sarama.Config)
@@ -22,6 +26,8 @@ type Option func(*sarama.Config)
 // Implementations should ensure messages are delivered according to configured options.
 type Producer interface {
 	PushMessage(ctx context.Context, key, value []byte, topic string) (partition int32, offset int64, err error)
+	// Ready returns nil if the producer knows live brokers and the last send did not fail.
+	Ready() error
 	Close() error
 }
 
@@ -32,4 +38,5 @@ type ConsumerGroupRunner interface {
 	Shutdown() error
 	Error() <-chan error
 	Info() <-chan string
+	Joined() bool
 }
@@ -2,6 +2,7 @@ package kafka
 
 import (
 	"context"
+	"sync/atomic"
 	"time"
 
 	"github.com/IBM/sarama"
@@ -90,7 +91,9 @@ func WithTimeuot(timeout time.Duration) Option {
 }
 
 type producer struct {
+	client       sarama.Client
 	syncProducer sarama.SyncProducer
+	lastErr      atomic.Pointer[error]
 }
 
 func NewProducer(brokers []string, opts ...Option) (Producer, error) {
@@ -102,12 +105,20 @@ func NewProducer(brokers []string, opts ...Option) (Producer, error) {
 		opt(cfg)
 	}
 
-	syncProducer, err := sarama.NewSyncProducer(brokers, cfg)
+	client, err := sarama.NewClient(brokers, cfg)
 	if err != nil {
 		return nil, err
 	}
 
+	syncProducer, err := sarama.NewSyncProducerFromClient(client)
+	if err != nil {
+		_ = client.Close()
+
+		return nil, err
+	}
+
 	return &producer{
+		client:       client,
 		syncProducer: syncProducer,
 	}, nil
 }
@@ -139,10 +150,32 @@ func (p *producer) PushMessage(ctx context.Context, key, value []byte, topic str
 	case <-ctx.Done():
 		return 0, 0, ctx.Err()
 	case res := <-result:
+		p.lastErr.Store(&res.err)
+
 		return res.partition, res.offset, res.err
 	}
 }
 
+func (p *producer) Ready() error {
+	if p.client.Closed() {
+		return ErrProducerClosed
+	}
+
+	if len(p.client.Brokers()) == 0 {
+		return ErrNoBrokers
+	}
+
+	if err := p.lastErr.Load(); err != nil && *err != nil {
+		return *err
+	}
+
+	return nil
+}
+
 func (p *producer) Close() error {
-	return p.syncProducer.Close()
+	if err := p.syncProducer.Close(); err != nil {
+		return err
+	}
+
+	return p.client.Close()
 }
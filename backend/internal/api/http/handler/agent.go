@@ -0,0 +1,464 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"hackathon-contract"
+
+	"hackathon-back/internal/apperrors"
+	"hackathon-back/internal/model"
+)
+
+type AgentService interface {
+	GetFleet(ctx context.Context) (*model.FleetResponse, error)
+	GetAgent(ctx context.Context, id uuid.UUID) (*model.Agent, error)
+}
+
+// AgentRegistry реестр агентов и их регистрация по одноразовому токену, см. service.AgentRegistryService.
+// access nil — администратор, иначе доступны только агенты пула пользователя.
+type AgentRegistry interface {
+	CreateAgent(ctx context.Context, req model.AgentCreateRequest, access *model.PoolAccess) (*model.AgentCreateResponse, error)
+	GetPoolAgents(ctx context.Context, access *model.PoolAccess) ([]*model.Agent, error)
+	UpdateAgent(ctx context.Context, id uuid.UUID, req model.AgentUpdateRequest, access *model.PoolAccess) (*model.Agent, error)
+	DeleteAgent(ctx context.Context, id uuid.UUID, access *model.PoolAccess) error
+	IssueEnrollmentToken(ctx context.Context, id uuid.UUID, access *model.PoolAccess) (*model.AgentEnrollmentToken, error)
+	GetEnrollments(ctx context.Context, id uuid.UUID, access *model.PoolAccess) ([]*model.AgentEnrollment, error)
+	Enroll(ctx context.Context, req contract.EnrollRequest, ip string) (*contract.EnrollResponse, error)
+}
+
+// PoolAccessResolver частные пулы, доступные пользователю, см. service.OrganizationService.
+type PoolAccessResolver interface {
+	PoolAccess(ctx context.Context, userID uuid.UUID) (*model.PoolAccess, error)
+}
+
+type AgentHandler struct {
+	BaseHandler
+	svc      AgentService
+	registry AgentRegistry
+	pools    PoolAccessResolver
+}
+
+func NewAgentHandler(svc AgentService, registry AgentRegistry, pools PoolAccessResolver) *AgentHandler {
+	return &AgentHandler{
+		svc:      svc,
+		registry: registry,
+		pools:    pools,
+	}
+}
+
+// GetFleet
+// @Summary Состояние агентов
+// @Description Возвращает всех агентов с online/last_seen и загрузкой из последнего heartbeat. Доступно для пользователей с ролью manager и выше.
+// @Tags Agent
+// @Security AccessToken
+// @Security RefreshToken
+// @Produce json
+// @Success 200 {object} ResponseWithData{data=model.FleetResponse} "Success"
+// @Failure 401 {object} ResponseWithMessage "Не авторизован"
+// @Failure 403 {object} ResponseWithMessage "Недостаточно прав"
+// @Failure 500 {object} ResponseWithMessage "Ошибка при получении агентов"
+// @Router /admin/agents [get]
+func (h *AgentHandler) GetFleet(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	fleet, err := h.svc.GetFleet(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ResponseWithMessage{
+			Status:  StatusInternalError,
+			Message: "Failed to get agents",
+		})
+
+		return
+	}
+
+	c.JSON(http.StatusOK, ResponseWithData{
+		Status: StatusSuccess,
+		Data:   fleet,
+	})
+}
+
+// GetAgent
+// @Summary Состояние агента по ID
+// @Description Возвращает агента с online/last_seen и загрузкой из последнего heartbeat. Доступно для пользователей с ролью manager и выше.
+// @Tags Agent
+// @Security AccessToken
+// @Security RefreshToken
+// @Produce json
+// @Param agent_id path string true "Agent UUID"
+// @Success 200 {object} ResponseWithData{data=model.Agent} "Success"
+// @Failure 400 {object} ResponseWithMessage "Неверный параметр пути"
+// @Failure 401 {object} ResponseWithMessage "Не авторизован"
+// @Failure 403 {object} ResponseWithMessage "Недостаточно прав"
+// @Failure 404 {object} ResponseWithMessage "Агент не найден"
+// @Failure 500 {object} ResponseWithMessage "Ошибка при получении агента"
+// @Router /admin/agents/{agent_id} [get]
+func (h *AgentHandler) GetAgent(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	agentUID, ok := bindAgentID(c)
+	if !ok {
+		return
+	}
+
+	agent, err := h.svc.GetAgent(ctx, agentUID)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrAgentDoesNotExist) {
+			c.JSON(http.StatusNotFound, ResponseWithMessage{
+				Status:  StatusErr,
+				Message: "Agent not found",
+			})
+
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, ResponseWithMessage{
+			Status:  StatusInternalError,
+			Message: "Failed to get agent",
+		})
+
+		return
+	}
+
+	c.JSON(http.StatusOK, ResponseWithData{
+		Status: StatusSuccess,
+		Data:   agent,
+	})
+}
+
+// CreateAgent
+// @Summary Завести агента
+// @Description Создаёт агента в реестре и выдаёт одноразовый токен регистрации. Токен показывается один раз, его нужно передать агенту
+// @Description (enrollment.token): при первом запуске агент обменяет его на свой id, регион, топики и ключи.
+// @Description С organizationId агент становится частным агентом организации. Доступно только admin.
+// @Tags Agent
+// @Security AccessToken
+// @Security RefreshToken
+// @Accept json
+// @Produce json
+// @Param payload body model.AgentCreateRequest true "Агент"
+// @Success 201 {object} ResponseWithData{data=model.AgentCreateResponse} "Success"
+// @Failure 400 {object} ResponseWithErrors "Неверные поля агента"
+// @Failure 401 {object} ResponseWithMessage "Не авторизован"
+// @Failure 403 {object} ResponseWithMessage "Недостаточно прав"
+// @Failure 404 {object} ResponseWithMessage "Организация не найдена"
+// @Failure 409 {object} ResponseWithMessage "У организации уже максимум частных агентов или регионов"
+// @Failure 500 {object} ResponseWithMessage "Ошибка при создании агента"
+// @Router /admin/agents [post]
+func (h *AgentHandler) CreateAgent(c *gin.Context) {
+	h.createAgent(c, nil)
+}
+
+func (h *AgentHandler) createAgent(c *gin.Context, access *model.PoolAccess) {
+	ctx := c.Request.Context()
+
+	var req model.AgentCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ResponseWithMessage{
+			Status:  StatusErr,
+			Message: err.Error(),
+		})
+
+		return
+	}
+
+	resp, err := h.registry.CreateAgent(ctx, req, access)
+	if err != nil {
+		respondRegistryError(c, err, "Failed to create agent")
+
+		return
+	}
+
+	c.JSON(http.StatusCreated, ResponseWithData{
+		Status: StatusSuccess,
+		Data:   resp,
+	})
+}
+
+// UpdateAgent
+// @Summary Изменить агента
+// @Description Меняет имя, страну, ASN, координаты агента или выключает его (disabled). Выключенный агент сразу уходит в offline,
+// @Description не получает задач и не выходит online по heartbeat. После правки heartbeat не перезаписывает страну, ASN и координаты. Доступно только admin.
+// @Tags Agent
+// @Security AccessToken
+// @Security RefreshToken
+// @Accept json
+// @Produce json
+// @Param agent_id path string true "Agent UUID"
+// @Param payload body model.AgentUpdateRequest true "Изменения"
+// @Success 200 {object} ResponseWithData{data=model.Agent} "Success"
+// @Failure 400 {object} ResponseWithErrors "Неверные поля агента"
+// @Failure 401 {object} ResponseWithMessage "Не авторизован"
+// @Failure 403 {object} ResponseWithMessage "Недостаточно прав"
+// @Failure 404 {object} ResponseWithMessage "Агент не найден"
+// @Failure 500 {object} ResponseWithMessage "Ошибка при изменении агента"
+// @Router /admin/agents/{agent_id} [patch]
+func (h *AgentHandler) UpdateAgent(c *gin.Context) {
+	h.updateAgent(c, nil)
+}
+
+func (h *AgentHandler) updateAgent(c *gin.Context, access *model.PoolAccess) {
+	ctx := c.Request.Context()
+
+	agentUID, ok := bindAgentID(c)
+	if !ok {
+		return
+	}
+
+	var req model.AgentUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ResponseWithMessage{
+			Status:  StatusErr,
+			Message: err.Error(),
+		})
+
+		return
+	}
+
+	agent, err := h.registry.UpdateAgent(ctx, agentUID, req, access)
+	if err != nil {
+		respondRegistryError(c, err, "Failed to update agent")
+
+		return
+	}
+
+	c.JSON(http.StatusOK, ResponseWithData{
+		Status: StatusSuccess,
+		Data:   agent,
+	})
+}
+
+// DeleteAgent
+// @Summary Удалить агента
+// @Description Удаляет агента и его токены регистрации, назначения и результаты остаются. Работающий агент сначала нужно выключить
+// @Description или остановить: по heartbeat он заведётся заново как агент без реестра. Доступно только admin.
+// @Tags Agent
+// @Security AccessToken
+// @Security RefreshToken
+// @Produce json
+// @Param agent_id path string true "Agent UUID"
+// @Success 200 {object} ResponseWithMessage "Агент удалён"
+// @Failure 400 {object} ResponseWithMessage "Неверный параметр пути"
+// @Failure 401 {object} ResponseWithMessage "Не авторизован"
+// @Failure 403 {object} ResponseWithMessage "Недостаточно прав"
+// @Failure 404 {object} ResponseWithMessage "Агент не найден"
+// @Failure 500 {object} ResponseWithMessage "Ошибка при удалении агента"
+// @Router /admin/agents/{agent_id} [delete]
+func (h *AgentHandler) DeleteAgent(c *gin.Context) {
+	h.deleteAgent(c, nil)
+}
+
+func (h *AgentHandler) deleteAgent(c *gin.Context, access *model.PoolAccess) {
+	ctx := c.Request.Context()
+
+	agentUID, ok := bindAgentID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.registry.DeleteAgent(ctx, agentUID, access); err != nil {
+		respondRegistryError(c, err, "Failed to delete agent")
+
+		return
+	}
+
+	c.JSON(http.StatusOK, ResponseWithMessage{
+		Status:  StatusSuccess,
+		Message: "Agent deleted successfully",
+	})
+}
+
+// IssueEnrollmentToken
+// @Summary Новый токен регистрации агента
+// @Description Выдаёт агенту новый одноразовый токен, например после переустановки; прежние неиспользованные токены отзываются.
+// @Description После регистрации по новому токену прежний ключ агента больше не принимается. Доступно только admin.
+// @Tags Agent
+// @Security AccessToken
+// @Security RefreshToken
+// @Produce json
+// @Param agent_id path string true "Agent UUID"
+// @Success 201 {object} ResponseWithData{data=model.AgentEnrollmentToken} "Success"
+// @Failure 400 {object} ResponseWithMessage "Неверный параметр пути"
+// @Failure 401 {object} ResponseWithMessage "Не авторизован"
+// @Failure 403 {object} ResponseWithMessage "Недостаточно прав"
+// @Failure 404 {object} ResponseWithMessage "Агент не найден"
+// @Failure 409 {object} ResponseWithMessage "Агент выключен"
+// @Failure 500 {object} ResponseWithMessage "Ошибка при выдаче токена"
+// @Router /admin/agents/{agent_id}/enrollments [post]
+func (h *AgentHandler) IssueEnrollmentToken(c *gin.Context) {
+	h.issueEnrollmentToken(c, nil)
+}
+
+func (h *AgentHandler) issueEnrollmentToken(c *gin.Context, access *model.PoolAccess) {
+	ctx := c.Request.Context()
+
+	agentUID, ok := bindAgentID(c)
+	if !ok {
+		return
+	}
+
+	token, err := h.registry.IssueEnrollmentToken(ctx, agentUID, access)
+	if err != nil {
+		respondRegistryError(c, err, "Failed to issue enrollment token")
+
+		return
+	}
+
+	c.JSON(http.StatusCreated, ResponseWithData{
+		Status: StatusSuccess,
+		Data:   token,
+	})
+}
+
+// GetEnrollments
+// @Summary История регистраций агента
+// @Description Выданные агенту токены: когда истекают, когда использованы, версия агента и IP, с которого он зарегистрировался.
+// @Description Доступно для пользователей с ролью manager и выше.
+// @Tags Agent
+// @Security AccessToken
+// @Security RefreshToken
+// @Produce json
+// @Param agent_id path string true "Agent UUID"
+// @Success 200 {object} ResponseWithData{data=[]model.AgentEnrollment} "Success"
+// @Failure 400 {object} ResponseWithMessage "Неверный параметр пути"
+// @Failure 401 {object} ResponseWithMessage "Не авторизован"
+// @Failure 403 {object} ResponseWithMessage "Недостаточно прав"
+// @Failure 404 {object} ResponseWithMessage "Агент не найден"
+// @Failure 500 {object} ResponseWithMessage "Ошибка при получении регистраций"
+// @Router /admin/agents/{agent_id}/enrollments [get]
+func (h *AgentHandler) GetEnrollments(c *gin.Context) {
+	h.getEnrollments(c, nil)
+}
+
+func (h *AgentHandler) getEnrollments(c *gin.Context, access *model.PoolAccess) {
+	ctx := c.Request.Context()
+
+	agentUID, ok := bindAgentID(c)
+	if !ok {
+		return
+	}
+
+	enrollments, err := h.registry.GetEnrollments(ctx, agentUID, access)
+	if err != nil {
+		respondRegistryError(c, err, "Failed to get enrollments")
+
+		return
+	}
+
+	c.JSON(http.StatusOK, ResponseWithData{
+		Status: StatusSuccess,
+		Data:   enrollments,
+	})
+}
+
+// Enroll
+// @Summary Регистрация агента по токену
+// @Description Агент при первом запуске обменивает одноразовый токен на свой id, регион, страну, ASN, координаты, топики,
+// @Description адреса брокеров, токен gateway и ключ, которым бэкенд подписывает задачи. publicKey агента привязывается к нему,
+// @Description регистрация записывается с версией агента и IP. Повторно токен не принимается.
+// @Tags Agent gateway
+// @Accept json
+// @Produce json
+// @Param payload body contract.EnrollRequest true "Токен и ключ агента"
+// @Success 200 {object} ResponseWithData{data=contract.EnrollResponse} "Success"
+// @Failure 400 {object} ResponseWithErrors "Неверный запрос"
+// @Failure 401 {object} ResponseWithMessage "Токен неизвестен, истёк или уже использован"
+// @Failure 403 {object} ResponseWithMessage "Агент выключен"
+// @Failure 500 {object} ResponseWithMessage "Ошибка при регистрации"
+// @Router /agent/enroll [post]
+func (h *AgentHandler) Enroll(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req contract.EnrollRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ResponseWithMessage{
+			Status:  StatusErr,
+			Message: err.Error(),
+		})
+
+		return
+	}
+
+	resp, err := h.registry.Enroll(ctx, req, c.ClientIP())
+	if err != nil {
+		switch {
+		case errors.Is(err, apperrors.ErrEnrollmentTokenInvalid):
+			c.JSON(http.StatusUnauthorized, ResponseWithMessage{
+				Status:  StatusNotPermitted,
+				Message: apperrors.ErrEnrollmentTokenInvalid.Error(),
+			})
+		case errors.Is(err, apperrors.ErrAgentDisabled):
+			c.JSON(http.StatusForbidden, ResponseWithMessage{
+				Status:  StatusForbidden,
+				Message: err.Error(),
+			})
+		default:
+			respondRegistryError(c, err, "Failed to enroll agent")
+		}
+
+		return
+	}
+
+	c.JSON(http.StatusOK, ResponseWithData{
+		Status: StatusSuccess,
+		Data:   resp,
+	})
+}
+
+func bindAgentID(c *gin.Context) (uuid.UUID, bool) {
+	var uri model.AgentIDPathParam
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, ResponseWithMessage{
+			Status:  StatusErr,
+			Message: err.Error(),
+		})
+
+		return uuid.Nil, false
+	}
+
+	agentUID, err := uuid.Parse(uri.ID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ResponseWithMessage{
+			Status:  StatusErr,
+			Message: err.Error(),
+		})
+
+		return uuid.Nil, false
+	}
+
+	return agentUID, true
+}
+
+func respondRegistryError(c *gin.Context, err error, message string) {
+	var vErr *contract.ValidationError
+
+	switch {
+	case errors.As(err, &vErr):
+		c.JSON(http.StatusBadRequest, newValidationResponse(vErr))
+	case errors.Is(err, apperrors.ErrAgentDoesNotExist):
+		c.JSON(http.StatusNotFound, ResponseWithMessage{
+			Status:  StatusErr,
+			Message: "Agent not found",
+		})
+	case errors.Is(err, apperrors.ErrOrganizationDoesNotExist):
+		c.JSON(http.StatusNotFound, ResponseWithMessage{
+			Status:  StatusErr,
+			Message: "Organization not found",
+		})
+	case errors.Is(err, apperrors.ErrAgentDisabled),
+		errors.Is(err, apperrors.ErrAgentLimitExceeded),
+		errors.Is(err, apperrors.ErrAgentPoolLimitExceeded):
+		c.JSON(http.StatusConflict, ResponseWithMessage{
+			Status:  StatusErr,
+			Message: err.Error(),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, ResponseWithMessage{
+			Status:  StatusInternalError,
+			Message: message,
+		})
+	}
+}
@@ -0,0 +1,157 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"hackathon-contract"
+
+	"hackathon-back/internal/apperrors"
+	"hackathon-back/internal/model"
+)
+
+type GatewayService interface {
+	Poll(ctx context.Context, agentID uuid.UUID, wait time.Duration) ([]contract.GatewayMessage, error)
+	Publish(ctx context.Context, agentID uuid.UUID, msg contract.GatewayMessage) error
+}
+
+type GatewayHandler struct {
+	log *zap.Logger
+	svc GatewayService
+}
+
+func NewGatewayHandler(log *zap.Logger, svc GatewayService) *GatewayHandler {
+	return &GatewayHandler{
+		log: log,
+		svc: svc,
+	}
+}
+
+// PollTasks
+// @Summary Long-poll задач для агента
+// @Description HTTP транспорт агентов без доступа к Kafka. Ждёт до wait секунд задачи, назначенные агенту, и отдаёт их пачкой,
+// @Description выданные задачи больше никому не отдаются. Пустой messages — за время ожидания задач не было.
+// @Tags Agent gateway
+// @Produce json
+// @Param Authorization header string true "Bearer токен gateway, выданный агенту при регистрации"
+// @Param X-Agent-ID header string true "Agent UUID"
+// @Param wait query int false "Сколько секунд ждать задач"
+// @Success 200 {object} ResponseWithData{data=contract.GatewayPoll} "Success"
+// @Failure 400 {object} ResponseWithMessage "Неверные параметры запроса"
+// @Failure 401 {object} ResponseWithMessage "Неверный токен или id агента"
+// @Failure 403 {object} ResponseWithMessage "Агент не прошёл регистрацию или выключен администратором"
+// @Failure 500 {object} ResponseWithMessage "Ошибка при получении задач"
+// @Router /agent/tasks [get]
+func (h *GatewayHandler) PollTasks(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var query model.GatewayPollQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, ResponseWithMessage{
+			Status:  StatusErr,
+			Message: err.Error(),
+		})
+
+		return
+	}
+
+	agentID := c.MustGet(model.AgentIDKey).(uuid.UUID)
+
+	messages, err := h.svc.Poll(ctx, agentID, time.Duration(query.Wait)*time.Second)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrAgentDisabled) || errors.Is(err, apperrors.ErrAgentNotRegistered) {
+			c.JSON(http.StatusForbidden, ResponseWithMessage{
+				Status:  StatusForbidden,
+				Message: err.Error(),
+			})
+
+			return
+		}
+
+		h.log.Error("Failed to poll tasks", zap.String("agent_id", agentID.String()), zap.Error(err))
+
+		c.JSON(http.StatusInternalServerError, ResponseWithMessage{
+			Status:  StatusInternalError,
+			Message: "Failed to poll tasks",
+		})
+
+		return
+	}
+
+	c.JSON(http.StatusOK, ResponseWithData{
+		Status: StatusSuccess,
+		Data:   contract.GatewayPoll{Messages: messages},
+	})
+}
+
+// PublishMessage
+// @Summary Сообщение от агента
+// @Description HTTP транспорт агентов без доступа к Kafka. Принимает результат проверки или heartbeat, адресованный
+// @Description топику результатов или heartbeat, и обрабатывает его так же, как сообщение из Kafka. value — конверт, подписанный ключом агента.
+// @Tags Agent gateway
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer токен gateway, выданный агенту при регистрации"
+// @Param X-Agent-ID header string true "Agent UUID"
+// @Param payload body contract.GatewayMessage true "Сообщение"
+// @Success 202 {object} ResponseWithMessage "Принято"
+// @Failure 400 {object} ResponseWithMessage "Неверное сообщение или неизвестный топик"
+// @Failure 401 {object} ResponseWithMessage "Неверный токен или id агента"
+// @Failure 403 {object} ResponseWithMessage "Сообщение другого агента, неверная подпись, неизвестный агент, задача не назначена агенту или агент выключен"
+// @Failure 500 {object} ResponseWithMessage "Ошибка при обработке сообщения"
+// @Router /agent/messages [post]
+func (h *GatewayHandler) PublishMessage(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var msg contract.GatewayMessage
+	if err := c.ShouldBindJSON(&msg); err != nil {
+		c.JSON(http.StatusBadRequest, ResponseWithMessage{
+			Status:  StatusErr,
+			Message: err.Error(),
+		})
+
+		return
+	}
+
+	agentID := c.MustGet(model.AgentIDKey).(uuid.UUID)
+
+	if err := h.svc.Publish(ctx, agentID, msg); err != nil {
+		switch {
+		case errors.Is(err, apperrors.ErrUnknownGatewayTopic), errors.Is(err, apperrors.ErrInvalidAgentMessage):
+			c.JSON(http.StatusBadRequest, ResponseWithMessage{
+				Status:  StatusErr,
+				Message: err.Error(),
+			})
+		case errors.Is(err, apperrors.ErrAgentIdentityMismatch),
+			errors.Is(err, apperrors.ErrInvalidAgentSignature),
+			errors.Is(err, apperrors.ErrAgentKeyNotRegistered),
+			errors.Is(err, apperrors.ErrAgentDoesNotExist),
+			errors.Is(err, apperrors.ErrAgentNotRegistered),
+			errors.Is(err, apperrors.ErrAgentNotAssigned),
+			errors.Is(err, apperrors.ErrAgentDisabled):
+			c.JSON(http.StatusForbidden, ResponseWithMessage{
+				Status:  StatusForbidden,
+				Message: err.Error(),
+			})
+		default:
+			h.log.Error("Failed to publish agent message", zap.String("agent_id", agentID.String()), zap.Error(err))
+
+			c.JSON(http.StatusInternalServerError, ResponseWithMessage{
+				Status:  StatusInternalError,
+				Message: "Failed to handle message",
+			})
+		}
+
+		return
+	}
+
+	c.JSON(http.StatusAccepted, ResponseWithMessage{
+		Status:  StatusSuccess,
+		Message: "Accepted",
+	})
+}
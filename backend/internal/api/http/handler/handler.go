@@ -68,6 +68,21 @@ type ResponseWithMessage struct {
 	Message string `son:"message"` // Человеко-читаемое сообщение
 } // @Name _ResponseWithMessage
 
+// ResponseWithErrors
+// @Description Ответ на невалидный запрос со списком ошибок по конкретным полям.
+type ResponseWithErrors struct {
+	Status  string       `json:"status"`  // Результат запроса
+	Message string       `json:"message"` // Человеко-читаемое сообщение
+	Errors  []FieldError `json:"errors"`  // Ошибки по полям
+} // @Name _ResponseWithErrors
+
+// FieldError
+// @Description Ошибка валидации одного поля запроса.
+type FieldError struct {
+	Field   string `example:"checks[0].params.count" json:"field"`   // Путь до поля в теле запроса
+	Message string `example:"must be <= 100"         json:"message"` // Что не так со значением
+} // @Name _FieldError
+
 // PaginationMetadata
 // @Description Пагинация в стиле Offset/limit.
 type PaginationMetadata struct {
@@ -0,0 +1,341 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"hackathon-contract"
+
+	"hackathon-back/internal/apperrors"
+	"hackathon-back/internal/model"
+)
+
+type MonitorService interface {
+	CreateMonitor(ctx context.Context, userID uuid.UUID, req model.MonitorCreateRequest) (*model.Monitor, error)
+	GetMonitors(ctx context.Context, userID uuid.UUID) ([]*model.Monitor, error)
+	GetMonitor(ctx context.Context, userID, id uuid.UUID) (*model.Monitor, error)
+	UpdateMonitor(ctx context.Context, userID, id uuid.UUID, req model.MonitorUpdateRequest) (*model.Monitor, error)
+	DeleteMonitor(ctx context.Context, userID, id uuid.UUID) error
+	GetRuns(ctx context.Context, userID, id uuid.UUID, limit int) ([]*model.MonitorRun, error)
+}
+
+type MonitorHandler struct {
+	BaseHandler
+	svc MonitorService
+}
+
+func NewMonitorHandler(svc MonitorService) *MonitorHandler {
+	return &MonitorHandler{
+		svc: svc,
+	}
+}
+
+// CreateMonitor
+// @Summary Создать монитор
+// @Description Заводит периодическую задачу: task в формате /check/task (где выполнять проверки — task.targets)
+// @Description и расписание — intervalSeconds или cron из 5 полей в UTC. Монитор с интервалом запускается сразу,
+// @Description по cron — в ближайшее время расписания. Каждый запуск создаёт обычный запрос, его результаты — в /check/{request_id}.
+// @Tags Monitor
+// @Security AccessToken
+// @Security RefreshToken
+// @Accept json
+// @Produce json
+// @Param payload body model.MonitorCreateRequest true "Монитор"
+// @Success 201 {object} ResponseWithData{data=model.Monitor} "Success"
+// @Failure 400 {object} ResponseWithErrors "Неверные поля монитора или задачи"
+// @Failure 401 {object} ResponseWithMessage "Не авторизован"
+// @Failure 403 {object} ResponseWithMessage "task.targets указывает на чужой частный пул"
+// @Failure 409 {object} ResponseWithMessage "Превышено число мониторов пользователя"
+// @Failure 500 {object} ResponseWithMessage "Ошибка при создании монитора"
+// @Router /monitors [post]
+func (h *MonitorHandler) CreateMonitor(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	userID, ok := h.userID(c)
+	if !ok {
+		return
+	}
+
+	var req model.MonitorCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ResponseWithMessage{
+			Status:  StatusErr,
+			Message: err.Error(),
+		})
+
+		return
+	}
+
+	monitor, err := h.svc.CreateMonitor(ctx, userID, req)
+	if err != nil {
+		respondMonitorError(c, err, "Failed to create monitor")
+
+		return
+	}
+
+	c.JSON(http.StatusCreated, ResponseWithData{
+		Status: StatusSuccess,
+		Data:   monitor,
+	})
+}
+
+// GetMonitors
+// @Summary Мои мониторы
+// @Description Возвращает мониторы пользователя.
+// @Tags Monitor
+// @Security AccessToken
+// @Security RefreshToken
+// @Produce json
+// @Success 200 {object} ResponseWithData{data=[]model.Monitor} "Success"
+// @Failure 401 {object} ResponseWithMessage "Не авторизован"
+// @Failure 500 {object} ResponseWithMessage "Ошибка при получении мониторов"
+// @Router /monitors [get]
+func (h *MonitorHandler) GetMonitors(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	userID, ok := h.userID(c)
+	if !ok {
+		return
+	}
+
+	monitors, err := h.svc.GetMonitors(ctx, userID)
+	if err != nil {
+		respondMonitorError(c, err, "Failed to get monitors")
+
+		return
+	}
+
+	c.JSON(http.StatusOK, ResponseWithData{
+		Status: StatusSuccess,
+		Data:   monitors,
+	})
+}
+
+// GetMonitor
+// @Summary Монитор
+// @Description Возвращает монитор пользователя.
+// @Tags Monitor
+// @Security AccessToken
+// @Security RefreshToken
+// @Produce json
+// @Param monitor_id path string true "Monitor UUID"
+// @Success 200 {object} ResponseWithData{data=model.Monitor} "Success"
+// @Failure 400 {object} ResponseWithMessage "Неверный параметр пути"
+// @Failure 401 {object} ResponseWithMessage "Не авторизован"
+// @Failure 404 {object} ResponseWithMessage "Монитор не найден"
+// @Failure 500 {object} ResponseWithMessage "Ошибка при получении монитора"
+// @Router /monitors/{monitor_id} [get]
+func (h *MonitorHandler) GetMonitor(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	userID, monitorID, ok := h.bindMonitor(c)
+	if !ok {
+		return
+	}
+
+	monitor, err := h.svc.GetMonitor(ctx, userID, monitorID)
+	if err != nil {
+		respondMonitorError(c, err, "Failed to get monitor")
+
+		return
+	}
+
+	c.JSON(http.StatusOK, ResponseWithData{
+		Status: StatusSuccess,
+		Data:   monitor,
+	})
+}
+
+// UpdateMonitor
+// @Summary Изменить монитор
+// @Description Меняет имя, задачу, расписание или enabled. Новое расписание или включение монитора
+// @Description заново отсчитывает следующий запуск от текущего времени.
+// @Tags Monitor
+// @Security AccessToken
+// @Security RefreshToken
+// @Accept json
+// @Produce json
+// @Param monitor_id path string true "Monitor UUID"
+// @Param payload body model.MonitorUpdateRequest true "Изменения"
+// @Success 200 {object} ResponseWithData{data=model.Monitor} "Success"
+// @Failure 400 {object} ResponseWithErrors "Неверные поля монитора или задачи"
+// @Failure 401 {object} ResponseWithMessage "Не авторизован"
+// @Failure 403 {object} ResponseWithMessage "task.targets указывает на чужой частный пул"
+// @Failure 404 {object} ResponseWithMessage "Монитор не найден"
+// @Failure 500 {object} ResponseWithMessage "Ошибка при изменении монитора"
+// @Router /monitors/{monitor_id} [patch]
+func (h *MonitorHandler) UpdateMonitor(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	userID, monitorID, ok := h.bindMonitor(c)
+	if !ok {
+		return
+	}
+
+	var req model.MonitorUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ResponseWithMessage{
+			Status:  StatusErr,
+			Message: err.Error(),
+		})
+
+		return
+	}
+
+	monitor, err := h.svc.UpdateMonitor(ctx, userID, monitorID, req)
+	if err != nil {
+		respondMonitorError(c, err, "Failed to update monitor")
+
+		return
+	}
+
+	c.JSON(http.StatusOK, ResponseWithData{
+		Status: StatusSuccess,
+		Data:   monitor,
+	})
+}
+
+// DeleteMonitor
+// @Summary Удалить монитор
+// @Description Удаляет монитор и историю его запусков, созданные запросы и их результаты остаются.
+// @Tags Monitor
+// @Security AccessToken
+// @Security RefreshToken
+// @Produce json
+// @Param monitor_id path string true "Monitor UUID"
+// @Success 200 {object} ResponseWithMessage "Монитор удалён"
+// @Failure 400 {object} ResponseWithMessage "Неверный параметр пути"
+// @Failure 401 {object} ResponseWithMessage "Не авторизован"
+// @Failure 404 {object} ResponseWithMessage "Монитор не найден"
+// @Failure 500 {object} ResponseWithMessage "Ошибка при удалении монитора"
+// @Router /monitors/{monitor_id} [delete]
+func (h *MonitorHandler) DeleteMonitor(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	userID, monitorID, ok := h.bindMonitor(c)
+	if !ok {
+		return
+	}
+
+	if err := h.svc.DeleteMonitor(ctx, userID, monitorID); err != nil {
+		respondMonitorError(c, err, "Failed to delete monitor")
+
+		return
+	}
+
+	c.JSON(http.StatusOK, ResponseWithMessage{
+		Status:  StatusSuccess,
+		Message: "Monitor deleted successfully",
+	})
+}
+
+// GetMonitorRuns
+// @Summary История запусков монитора
+// @Description Последние запуски монитора, новые первыми: созданный запрос и его статус или причина, по которой запуск не состоялся.
+// @Tags Monitor
+// @Security AccessToken
+// @Security RefreshToken
+// @Produce json
+// @Param monitor_id path string true "Monitor UUID"
+// @Param limit query int false "Сколько запусков вернуть, по умолчанию 50, не больше 500"
+// @Success 200 {object} ResponseWithData{data=[]model.MonitorRun} "Success"
+// @Failure 400 {object} ResponseWithMessage "Неверный параметр"
+// @Failure 401 {object} ResponseWithMessage "Не авторизован"
+// @Failure 404 {object} ResponseWithMessage "Монитор не найден"
+// @Failure 500 {object} ResponseWithMessage "Ошибка при получении запусков"
+// @Router /monitors/{monitor_id}/runs [get]
+func (h *MonitorHandler) GetMonitorRuns(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	userID, monitorID, ok := h.bindMonitor(c)
+	if !ok {
+		return
+	}
+
+	var query model.MonitorRunsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, ResponseWithMessage{
+			Status:  StatusErr,
+			Message: err.Error(),
+		})
+
+		return
+	}
+
+	runs, err := h.svc.GetRuns(ctx, userID, monitorID, query.Limit)
+	if err != nil {
+		respondMonitorError(c, err, "Failed to get monitor runs")
+
+		return
+	}
+
+	c.JSON(http.StatusOK, ResponseWithData{
+		Status: StatusSuccess,
+		Data:   runs,
+	})
+}
+
+func (h *MonitorHandler) userID(c *gin.Context) (uuid.UUID, bool) {
+	userID, err := h.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, ResponseWithMessage{
+			Status:  StatusNotPermitted,
+			Message: "User not authorized",
+		})
+
+		return uuid.Nil, false
+	}
+
+	return userID, true
+}
+
+func (h *MonitorHandler) bindMonitor(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	userID, ok := h.userID(c)
+	if !ok {
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	var uri model.MonitorIDPathParam
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, ResponseWithMessage{
+			Status:  StatusErr,
+			Message: err.Error(),
+		})
+
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return userID, uuid.MustParse(uri.ID), true
+}
+
+func respondMonitorError(c *gin.Context, err error, message string) {
+	var vErr *contract.ValidationError
+
+	switch {
+	case errors.As(err, &vErr):
+		c.JSON(http.StatusBadRequest, newValidationResponse(vErr))
+	case errors.Is(err, apperrors.ErrMonitorDoesNotExist):
+		c.JSON(http.StatusNotFound, ResponseWithMessage{
+			Status:  StatusErr,
+			Message: "Monitor not found",
+		})
+	case errors.Is(err, apperrors.ErrAgentPoolForbidden):
+		c.JSON(http.StatusForbidden, ResponseWithMessage{
+			Status:  StatusForbidden,
+			Message: err.Error(),
+		})
+	case errors.Is(err, apperrors.ErrMonitorLimitExceeded):
+		c.JSON(http.StatusConflict, ResponseWithMessage{
+			Status:  StatusErr,
+			Message: err.Error(),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, ResponseWithMessage{
+			Status:  StatusInternalError,
+			Message: message,
+		})
+	}
+}
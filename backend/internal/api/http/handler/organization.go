@@ -0,0 +1,304 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"hackathon-contract"
+
+	"hackathon-back/internal/apperrors"
+	"hackathon-back/internal/model"
+)
+
+type OrganizationService interface {
+	CreateOrganization(ctx context.Context, userID uuid.UUID, req model.OrganizationCreateRequest) (*model.Organization, error)
+	GetOrganizations(ctx context.Context, userID uuid.UUID) ([]*model.Organization, error)
+	GetMembers(ctx context.Context, userID, orgID uuid.UUID) ([]*model.OrganizationMember, error)
+	AddMember(ctx context.Context, userID, orgID, memberID uuid.UUID) error
+	RemoveMember(ctx context.Context, userID, orgID, memberID uuid.UUID) error
+}
+
+type OrganizationHandler struct {
+	BaseHandler
+	svc OrganizationService
+}
+
+func NewOrganizationHandler(svc OrganizationService) *OrganizationHandler {
+	return &OrganizationHandler{
+		svc: svc,
+	}
+}
+
+// CreateOrganization
+// @Summary Создать организацию
+// @Description Создаёт организацию, создатель становится её участником. Частных агентов организации видят и выбирают все участники.
+// @Tags Organization
+// @Security AccessToken
+// @Security RefreshToken
+// @Accept json
+// @Produce json
+// @Param payload body model.OrganizationCreateRequest true "Организация"
+// @Success 201 {object} ResponseWithData{data=model.Organization} "Success"
+// @Failure 400 {object} ResponseWithErrors "Неверное имя"
+// @Failure 401 {object} ResponseWithMessage "Не авторизован"
+// @Failure 500 {object} ResponseWithMessage "Ошибка при создании организации"
+// @Router /organizations [post]
+func (h *OrganizationHandler) CreateOrganization(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	userID, ok := h.userID(c)
+	if !ok {
+		return
+	}
+
+	var req model.OrganizationCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ResponseWithMessage{
+			Status:  StatusErr,
+			Message: err.Error(),
+		})
+
+		return
+	}
+
+	org, err := h.svc.CreateOrganization(ctx, userID, req)
+	if err != nil {
+		respondOrganizationError(c, err, "Failed to create organization")
+
+		return
+	}
+
+	c.JSON(http.StatusCreated, ResponseWithData{
+		Status: StatusSuccess,
+		Data:   org,
+	})
+}
+
+// GetOrganizations
+// @Summary Мои организации
+// @Description Возвращает организации, в которых состоит пользователь.
+// @Tags Organization
+// @Security AccessToken
+// @Security RefreshToken
+// @Produce json
+// @Success 200 {object} ResponseWithData{data=[]model.Organization} "Success"
+// @Failure 401 {object} ResponseWithMessage "Не авторизован"
+// @Failure 500 {object} ResponseWithMessage "Ошибка при получении организаций"
+// @Router /organizations [get]
+func (h *OrganizationHandler) GetOrganizations(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	userID, ok := h.userID(c)
+	if !ok {
+		return
+	}
+
+	orgs, err := h.svc.GetOrganizations(ctx, userID)
+	if err != nil {
+		respondOrganizationError(c, err, "Failed to get organizations")
+
+		return
+	}
+
+	if orgs == nil {
+		orgs = []*model.Organization{}
+	}
+
+	c.JSON(http.StatusOK, ResponseWithData{
+		Status: StatusSuccess,
+		Data:   orgs,
+	})
+}
+
+// GetMembers
+// @Summary Участники организации
+// @Description Возвращает участников организации. Доступно только участникам.
+// @Tags Organization
+// @Security AccessToken
+// @Security RefreshToken
+// @Produce json
+// @Param organization_id path string true "Organization UUID"
+// @Success 200 {object} ResponseWithData{data=[]model.OrganizationMember} "Success"
+// @Failure 400 {object} ResponseWithMessage "Неверный параметр пути"
+// @Failure 401 {object} ResponseWithMessage "Не авторизован"
+// @Failure 404 {object} ResponseWithMessage "Организация не найдена"
+// @Failure 500 {object} ResponseWithMessage "Ошибка при получении участников"
+// @Router /organizations/{organization_id}/members [get]
+func (h *OrganizationHandler) GetMembers(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	userID, ok := h.userID(c)
+	if !ok {
+		return
+	}
+
+	var uri model.OrganizationIDPathParam
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, ResponseWithMessage{
+			Status:  StatusErr,
+			Message: err.Error(),
+		})
+
+		return
+	}
+
+	members, err := h.svc.GetMembers(ctx, userID, uuid.MustParse(uri.ID))
+	if err != nil {
+		respondOrganizationError(c, err, "Failed to get organization members")
+
+		return
+	}
+
+	c.JSON(http.StatusOK, ResponseWithData{
+		Status: StatusSuccess,
+		Data:   members,
+	})
+}
+
+// AddMember
+// @Summary Добавить участника в организацию
+// @Description Добавляет пользователя в организацию. Доступно только участникам.
+// @Tags Organization
+// @Security AccessToken
+// @Security RefreshToken
+// @Accept json
+// @Produce json
+// @Param organization_id path string true "Organization UUID"
+// @Param payload body model.OrganizationMemberRequest true "Пользователь"
+// @Success 201 {object} ResponseWithMessage "Участник добавлен"
+// @Failure 400 {object} ResponseWithMessage "Неверный запрос"
+// @Failure 401 {object} ResponseWithMessage "Не авторизован"
+// @Failure 404 {object} ResponseWithMessage "Организация или пользователь не найдены"
+// @Failure 409 {object} ResponseWithMessage "Пользователь уже участник"
+// @Failure 500 {object} ResponseWithMessage "Ошибка при добавлении участника"
+// @Router /organizations/{organization_id}/members [post]
+func (h *OrganizationHandler) AddMember(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	userID, ok := h.userID(c)
+	if !ok {
+		return
+	}
+
+	var uri model.OrganizationIDPathParam
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, ResponseWithMessage{
+			Status:  StatusErr,
+			Message: err.Error(),
+		})
+
+		return
+	}
+
+	var req model.OrganizationMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ResponseWithMessage{
+			Status:  StatusErr,
+			Message: err.Error(),
+		})
+
+		return
+	}
+
+	if err := h.svc.AddMember(ctx, userID, uuid.MustParse(uri.ID), req.UserID); err != nil {
+		respondOrganizationError(c, err, "Failed to add organization member")
+
+		return
+	}
+
+	c.JSON(http.StatusCreated, ResponseWithMessage{
+		Status:  StatusSuccess,
+		Message: "Member added successfully",
+	})
+}
+
+// RemoveMember
+// @Summary Убрать участника из организации
+// @Description Убирает пользователя из организации, в том числе самого себя. Агенты организации остаются ей. Доступно только участникам.
+// @Tags Organization
+// @Security AccessToken
+// @Security RefreshToken
+// @Produce json
+// @Param organization_id path string true "Organization UUID"
+// @Param user_id path string true "User UUID"
+// @Success 200 {object} ResponseWithMessage "Участник удалён"
+// @Failure 400 {object} ResponseWithMessage "Неверный параметр пути"
+// @Failure 401 {object} ResponseWithMessage "Не авторизован"
+// @Failure 404 {object} ResponseWithMessage "Организация или участник не найдены"
+// @Failure 500 {object} ResponseWithMessage "Ошибка при удалении участника"
+// @Router /organizations/{organization_id}/members/{user_id} [delete]
+func (h *OrganizationHandler) RemoveMember(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	userID, ok := h.userID(c)
+	if !ok {
+		return
+	}
+
+	var uri model.OrganizationMemberPathParam
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, ResponseWithMessage{
+			Status:  StatusErr,
+			Message: err.Error(),
+		})
+
+		return
+	}
+
+	if err := h.svc.RemoveMember(ctx, userID, uuid.MustParse(uri.OrganizationID), uuid.MustParse(uri.UserID)); err != nil {
+		respondOrganizationError(c, err, "Failed to remove organization member")
+
+		return
+	}
+
+	c.JSON(http.StatusOK, ResponseWithMessage{
+		Status:  StatusSuccess,
+		Message: "Member removed successfully",
+	})
+}
+
+func (h *OrganizationHandler) userID(c *gin.Context) (uuid.UUID, bool) {
+	userID, err := h.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, ResponseWithMessage{
+			Status:  StatusNotPermitted,
+			Message: "User not authorized",
+		})
+
+		return uuid.Nil, false
+	}
+
+	return userID, true
+}
+
+func respondOrganizationError(c *gin.Context, err error, message string) {
+	var vErr *contract.ValidationError
+
+	switch {
+	case errors.As(err, &vErr):
+		c.JSON(http.StatusBadRequest, newValidationResponse(vErr))
+	case errors.Is(err, apperrors.ErrOrganizationDoesNotExist):
+		c.JSON(http.StatusNotFound, ResponseWithMessage{
+			Status:  StatusErr,
+			Message: "Organization not found",
+		})
+	case errors.Is(err, apperrors.ErrUserDoesNotExist), errors.Is(err, apperrors.ErrMemberDoesNotExist):
+		c.JSON(http.StatusNotFound, ResponseWithMessage{
+			Status:  StatusErr,
+			Message: err.Error(),
+		})
+	case errors.Is(err, apperrors.ErrMemberAlreadyExists):
+		c.JSON(http.StatusConflict, ResponseWithMessage{
+			Status:  StatusErr,
+			Message: err.Error(),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, ResponseWithMessage{
+			Status:  StatusInternalError,
+			Message: message,
+		})
+	}
+}
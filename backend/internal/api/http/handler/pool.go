@@ -0,0 +1,176 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"hackathon-back/internal/model"
+)
+
+// GetPoolAgents
+// @Summary Мои частные агенты
+// @Description Возвращает частных агентов пользователя и организаций, в которых он состоит.
+// @Tags Agent pool
+// @Security AccessToken
+// @Security RefreshToken
+// @Produce json
+// @Success 200 {object} ResponseWithData{data=[]model.Agent} "Success"
+// @Failure 401 {object} ResponseWithMessage "Не авторизован"
+// @Failure 500 {object} ResponseWithMessage "Ошибка при получении агентов"
+// @Router /agents [get]
+func (h *AgentHandler) GetPoolAgents(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	access, ok := h.poolAccess(c)
+	if !ok {
+		return
+	}
+
+	agents, err := h.registry.GetPoolAgents(ctx, access)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ResponseWithMessage{
+			Status:  StatusInternalError,
+			Message: "Failed to get agents",
+		})
+
+		return
+	}
+
+	c.JSON(http.StatusOK, ResponseWithData{
+		Status: StatusSuccess,
+		Data:   agents,
+	})
+}
+
+// CreatePoolAgent
+// @Summary Завести частного агента
+// @Description Создаёт агента пользователя или, с organizationId, его организации и выдаёт одноразовый токен регистрации.
+// @Description region — метка внутри пула (например OFFICE), агент получает регион вида USER-<id>-OFFICE или ORG-<id>-OFFICE
+// @Description и свой топик задач. Задачи частному агенту направляет только владелец, явно выбрав его регион или id в targets.
+// @Tags Agent pool
+// @Security AccessToken
+// @Security RefreshToken
+// @Accept json
+// @Produce json
+// @Param payload body model.AgentCreateRequest true "Агент"
+// @Success 201 {object} ResponseWithData{data=model.AgentCreateResponse} "Success"
+// @Failure 400 {object} ResponseWithErrors "Неверные поля агента"
+// @Failure 401 {object} ResponseWithMessage "Не авторизован"
+// @Failure 404 {object} ResponseWithMessage "Организация не найдена"
+// @Failure 409 {object} ResponseWithMessage "У владельца уже максимум частных агентов или регионов"
+// @Failure 500 {object} ResponseWithMessage "Ошибка при создании агента"
+// @Router /agents [post]
+func (h *AgentHandler) CreatePoolAgent(c *gin.Context) {
+	if access, ok := h.poolAccess(c); ok {
+		h.createAgent(c, access)
+	}
+}
+
+// UpdatePoolAgent
+// @Summary Изменить частного агента
+// @Description Меняет имя, страну, ASN, координаты агента пула или выключает его (disabled).
+// @Tags Agent pool
+// @Security AccessToken
+// @Security RefreshToken
+// @Accept json
+// @Produce json
+// @Param agent_id path string true "Agent UUID"
+// @Param payload body model.AgentUpdateRequest true "Изменения"
+// @Success 200 {object} ResponseWithData{data=model.Agent} "Success"
+// @Failure 400 {object} ResponseWithErrors "Неверные поля агента"
+// @Failure 401 {object} ResponseWithMessage "Не авторизован"
+// @Failure 404 {object} ResponseWithMessage "Агент не найден в пулах пользователя"
+// @Failure 500 {object} ResponseWithMessage "Ошибка при изменении агента"
+// @Router /agents/{agent_id} [patch]
+func (h *AgentHandler) UpdatePoolAgent(c *gin.Context) {
+	if access, ok := h.poolAccess(c); ok {
+		h.updateAgent(c, access)
+	}
+}
+
+// DeletePoolAgent
+// @Summary Удалить частного агента
+// @Description Удаляет агента пула и его токены регистрации.
+// @Tags Agent pool
+// @Security AccessToken
+// @Security RefreshToken
+// @Produce json
+// @Param agent_id path string true "Agent UUID"
+// @Success 200 {object} ResponseWithMessage "Агент удалён"
+// @Failure 400 {object} ResponseWithMessage "Неверный параметр пути"
+// @Failure 401 {object} ResponseWithMessage "Не авторизован"
+// @Failure 404 {object} ResponseWithMessage "Агент не найден в пулах пользователя"
+// @Failure 500 {object} ResponseWithMessage "Ошибка при удалении агента"
+// @Router /agents/{agent_id} [delete]
+func (h *AgentHandler) DeletePoolAgent(c *gin.Context) {
+	if access, ok := h.poolAccess(c); ok {
+		h.deleteAgent(c, access)
+	}
+}
+
+// IssuePoolEnrollmentToken
+// @Summary Новый токен регистрации частного агента
+// @Description Выдаёт агенту пула новый одноразовый токен, прежние неиспользованные токены отзываются.
+// @Tags Agent pool
+// @Security AccessToken
+// @Security RefreshToken
+// @Produce json
+// @Param agent_id path string true "Agent UUID"
+// @Success 201 {object} ResponseWithData{data=model.AgentEnrollmentToken} "Success"
+// @Failure 400 {object} ResponseWithMessage "Неверный параметр пути"
+// @Failure 401 {object} ResponseWithMessage "Не авторизован"
+// @Failure 404 {object} ResponseWithMessage "Агент не найден в пулах пользователя"
+// @Failure 409 {object} ResponseWithMessage "Агент выключен"
+// @Failure 500 {object} ResponseWithMessage "Ошибка при выдаче токена"
+// @Router /agents/{agent_id}/enrollments [post]
+func (h *AgentHandler) IssuePoolEnrollmentToken(c *gin.Context) {
+	if access, ok := h.poolAccess(c); ok {
+		h.issueEnrollmentToken(c, access)
+	}
+}
+
+// GetPoolEnrollments
+// @Summary История регистраций частного агента
+// @Description Выданные агенту пула токены: когда истекают, когда использованы, версия агента и IP.
+// @Tags Agent pool
+// @Security AccessToken
+// @Security RefreshToken
+// @Produce json
+// @Param agent_id path string true "Agent UUID"
+// @Success 200 {object} ResponseWithData{data=[]model.AgentEnrollment} "Success"
+// @Failure 400 {object} ResponseWithMessage "Неверный параметр пути"
+// @Failure 401 {object} ResponseWithMessage "Не авторизован"
+// @Failure 404 {object} ResponseWithMessage "Агент не найден в пулах пользователя"
+// @Failure 500 {object} ResponseWithMessage "Ошибка при получении регистраций"
+// @Router /agents/{agent_id}/enrollments [get]
+func (h *AgentHandler) GetPoolEnrollments(c *gin.Context) {
+	if access, ok := h.poolAccess(c); ok {
+		h.getEnrollments(c, access)
+	}
+}
+
+// poolAccess пулы пользователя из access токена; при ошибке ответ уже отправлен.
+func (h *AgentHandler) poolAccess(c *gin.Context) (*model.PoolAccess, bool) {
+	userID, err := h.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, ResponseWithMessage{
+			Status:  StatusNotPermitted,
+			Message: "User not authorized",
+		})
+
+		return nil, false
+	}
+
+	access, err := h.pools.PoolAccess(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ResponseWithMessage{
+			Status:  StatusInternalError,
+			Message: "Failed to resolve agent pools",
+		})
+
+		return nil, false
+	}
+
+	return access, true
+}
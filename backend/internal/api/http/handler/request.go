@@ -1,36 +1,56 @@
 package handler
 
 import (
+	"bytes"
 	"context"
-	"crypto/sha256"
-	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
 	"go.uber.org/zap"
+	"hackathon-contract"
 
+	"hackathon-back/internal/apperrors"
 	"hackathon-back/internal/model"
 )
 
 type RequestService interface {
-	CreateRequest(ctx context.Context, req model.TaskMessageRequest, ip net.IP, ua string) (*model.Request, error)
+	CreateRequest(ctx context.Context, req model.TaskMessageRequest, ip net.IP, ua string, userID uuid.UUID) (*model.Request, error)
+	GetLocations(ctx context.Context, userID uuid.UUID) ([]*model.Location, error)
 	GetResultsByRequestID(ctx context.Context, requestID uuid.UUID) ([]model.CheckResultResponse, error)
+	GetAgentResultsByRequestID(ctx context.Context, requestID uuid.UUID) ([]model.AgentResults, error)
+	GetProgressByRequestID(ctx context.Context, requestID uuid.UUID) ([]model.CheckProgress, error)
+	GetRequestStatus(ctx context.Context, requestID uuid.UUID) (string, error)
+}
+
+// RequestEvents подписка на изменения запроса с досылкой по номерам, см. stream.Hub.
+type RequestEvents interface {
+	Subscribe(topic string) (events <-chan model.StreamEvent, unsubscribe func())
+	Seq(ctx context.Context, topic string) (int64, error)
+	Replay(ctx context.Context, topic string, afterSeq int64) (events []model.StreamEvent, complete bool, err error)
 }
 
 type RequestHandler struct {
-	log *zap.Logger
-	svc RequestService
+	BaseHandler
+	log            *zap.Logger
+	svc            RequestService
+	events         RequestEvents
+	resyncInterval time.Duration
 }
 
-func NewRequestHandler(log *zap.Logger, svc RequestService) *RequestHandler {
+func NewRequestHandler(log *zap.Logger, svc RequestService, events RequestEvents, resyncInterval time.Duration) *RequestHandler {
 	return &RequestHandler{
-		log: log,
-		svc: svc,
+		log:            log,
+		svc:            svc,
+		events:         events,
+		resyncInterval: resyncInterval,
 	}
 }
 
@@ -48,7 +68,7 @@ var wsUpgrader = websocket.Upgrader{
 }
 
 type wsMessage struct {
-	Type string      `json:"type"           ` // "snapshot" | "update" | "done" | "error"
+	Type string      `json:"type"           ` // "snapshot" | "result" | "progress" | "status" | "done" | "error"
 	Data interface{} `json:"data,omitempty" ` // payload
 	Err  string      `json:"error,omitempty"`
 }
@@ -56,13 +76,23 @@ type wsMessage struct {
 // CreateRequest
 // @Summary Создать задачу сетевых проверок.
 // @Description Принимает проверки, достаёт IP клиента, определяет регион, пишет в checks=PENDING и в outbox кладёт.
+// @Description Проверки получают только online агенты, которые могут их выполнить (тип проверки, IPv4/IPv6, raw ICMP),
+// @Description при необходимости проверки делятся между агентами. Проверки, которые не может выполнить никто, перечислены в unassigned.
+// @Description targets задаёт, где выполнять проверки: регионы, страны, ASN, конкретные агенты или spread — N агентов из разных регионов.
+// @Description Если селектору не нашлось online агентов, ответ 400 с причиной по каждому значению (например targets.countries[1]).
+// @Description Токен необязателен. С токеном в targets можно указать регионы и агентов своих приватных пулов и пулов своих организаций,
+// @Description без явного указания приватные агенты проверки не получают.
 // @Tags Checks
 // @Accept json
 // @Produce json
 // @Param payload body model.TaskMessageRequest true "Task payload"
 // @Success 201 {object} ResponseWithData{data=model.Request} "Success"
-// @Failure 400 {object} ResponseWithMessage "Invalid JSON body"
+// @Failure 400 {object} ResponseWithErrors "Invalid JSON body, check params or targets, or targets can't be satisfied"
+// @Failure 401 {object} ResponseWithMessage "Invalid or expired token"
+// @Failure 403 {object} ResponseWithMessage "Targets name a private pool of another owner"
+// @Failure 422 {object} ResponseWithMessage "No online agent can run any of the checks, message explains each check"
 // @Failure 500 {object} ResponseWithMessage "Failed to create request"
+// @Failure 503 {object} ResponseWithMessage "No online agents to run checks"
 // @Router /check/task [post]
 func (h *RequestHandler) CreateRequest(c *gin.Context) {
 	ctx := c.Request.Context()
@@ -80,8 +110,41 @@ func (h *RequestHandler) CreateRequest(c *gin.Context) {
 	clientIP := net.ParseIP(clientIPStr)
 	userAgent := c.GetHeader(UserAgentHeader)
 
-	request, err := h.svc.CreateRequest(ctx, req, clientIP, userAgent)
+	// Анонимный запрос идёт с uuid.Nil и видит только публичные регионы.
+	userID, _ := h.GetUserID(c)
+
+	request, err := h.svc.CreateRequest(ctx, req, clientIP, userAgent, userID)
 	if err != nil {
+		var vErr *contract.ValidationError
+		if errors.As(err, &vErr) {
+			c.JSON(http.StatusBadRequest, newValidationResponse(vErr))
+			return
+		}
+
+		if errors.Is(err, apperrors.ErrAgentPoolForbidden) {
+			c.JSON(http.StatusForbidden, ResponseWithMessage{
+				Status:  StatusForbidden,
+				Message: err.Error(),
+			})
+			return
+		}
+
+		if errors.Is(err, apperrors.ErrNoCapableAgents) {
+			c.JSON(http.StatusUnprocessableEntity, ResponseWithMessage{
+				Status:  StatusNotAvailable,
+				Message: err.Error(),
+			})
+			return
+		}
+
+		if errors.Is(err, apperrors.ErrNoOnlineAgents) {
+			c.JSON(http.StatusServiceUnavailable, ResponseWithMessage{
+				Status:  StatusNotAvailable,
+				Message: err.Error(),
+			})
+			return
+		}
+
 		c.JSON(http.StatusInternalServerError, ResponseWithMessage{
 			Status:  StatusErr,
 			Message: err.Error(),
@@ -95,6 +158,34 @@ func (h *RequestHandler) CreateRequest(c *gin.Context) {
 	})
 }
 
+// GetLocations
+// @Summary Получить доступные локации.
+// @Description Возвращает регионы, в которых можно выполнять проверки: публичные регионы и, если передан токен,
+// @Description приватные пулы пользователя и его организаций. Значение region подставляется в targets.regions.
+// @Tags Checks
+// @Produce json
+// @Success 200 {object} ResponseWithData{data=[]model.Location} "Success"
+// @Failure 401 {object} ResponseWithMessage "Invalid or expired token"
+// @Failure 500 {object} ResponseWithMessage "Failed to get locations"
+// @Router /check/locations [get]
+func (h *RequestHandler) GetLocations(c *gin.Context) {
+	userID, _ := h.GetUserID(c)
+
+	locations, err := h.svc.GetLocations(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ResponseWithMessage{
+			Status:  StatusErr,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, ResponseWithData{
+		Status: StatusSuccess,
+		Data:   locations,
+	})
+}
+
 // GetResults
 // @Summary Получить результаты сетевых проверок.
 // @Description Возвращает текущее состояние проверок по request_id одним ответом.
@@ -141,9 +232,61 @@ func (h *RequestHandler) GetResults(c *gin.Context) {
 	})
 }
 
+// GetAgentResults
+// @Summary Получить результаты сетевых проверок по агентам.
+// @Description Результаты по request_id, сгруппированные по агенту и региону, который выполнил проверки.
+// @Description Для broadcast запроса каждый агент выполняет проверки отдельно и получает свою группу.
+// @Tags Checks
+// @Produce json
+// @Param request_id path string true "Request UUID"
+// @Success 200 {object} ResponseWithData{data=[]model.AgentResults} "Success"
+// @Failure 400 {object} ResponseWithMessage "Invalid path param"
+// @Failure 500 {object} ResponseWithMessage "Failed to get results"
+// @Router /check/{request_id}/agents [get]
+func (h *RequestHandler) GetAgentResults(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var uri model.RequestIDPathParam
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, ResponseWithMessage{
+			Status:  StatusErr,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	requestUID, err := uuid.Parse(uri.ID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ResponseWithMessage{
+			Status:  StatusErr,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	res, err := h.svc.GetAgentResultsByRequestID(ctx, requestUID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ResponseWithMessage{
+			Status:  StatusErr,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, ResponseWithData{
+		Status: StatusSuccess,
+		Data:   res,
+	})
+}
+
 // StreamResults
 // @Summary Стрим результатов сетевых проверок по WebSocket.
-// @Description Открывает WS и присылает актуальные результаты по request_id до завершения всех проверок.
+// @Description Открывает WS и сначала присылает текущее состояние: type=snapshot со всеми результатами, type=progress
+// @Description с промежуточными состояниями долгих проверок (traceroute, ping) и type=status со статусом запроса.
+// @Description Дальше приходят только изменения: result — новый итоговый результат одной проверки, progress — новое
+// @Description промежуточное состояние одной проверки, status — новый статус запроса (PENDING, DISPATCHED, RUNNING, DONE, FAILED, TIMEOUT).
+// @Description Когда запрос завершён, приходит done с итоговыми результатами, и соединение закрывается.
+// @Description Изменения могут повторять строки snapshot, ключ строки — assignmentId и checkIndex.
 // @Tags Checks
 // @Param request_id path string true "Request UUID"
 // @Produce application/json
@@ -182,10 +325,8 @@ func (h *RequestHandler) StreamResults(c *gin.Context) {
 	}()
 
 	ctx := c.Request.Context()
-	ticker := time.NewTicker(1 * time.Second) // период опроса svc
-	defer ticker.Stop()
 
-	var lastHash string
+	var lastStatus string
 
 	send := func(msg wsMessage) bool {
 		if err := conn.WriteJSON(msg); err != nil {
@@ -195,61 +336,402 @@ func (h *RequestHandler) StreamResults(c *gin.Context) {
 		return true
 	}
 
+	// sendStatus шлёт статус, если он сменился; finished — запрос завершён, и соединение пора закрывать
+	sendStatus := func(status string) (finished, ok bool) {
+		if status != lastStatus {
+			if !send(wsMessage{Type: "status", Data: status}) {
+				return false, false
+			}
+			lastStatus = status
+		}
+
+		if !model.IsTerminalStatus(status) {
+			return false, true
+		}
+
+		results, err := h.svc.GetResultsByRequestID(ctx, requestID)
+		if err != nil {
+			_ = conn.WriteJSON(wsMessage{Type: "error", Err: err.Error()})
+			return true, false
+		}
+
+		_ = conn.WriteJSON(wsMessage{Type: "done", Data: results})
+
+		return true, true
+	}
+
+	// sendSnapshot текущее состояние из БД; читается после подписки, чтобы не потерять изменения между ними
+	sendSnapshot := func() (finished, ok bool) {
+		status, err := h.svc.GetRequestStatus(ctx, requestID)
+		if err != nil {
+			_ = conn.WriteJSON(wsMessage{Type: "error", Err: err.Error()})
+			return false, false
+		}
+
+		results, err := h.svc.GetResultsByRequestID(ctx, requestID)
+		if err != nil {
+			_ = conn.WriteJSON(wsMessage{Type: "error", Err: err.Error()})
+			return false, false
+		}
+
+		if !send(wsMessage{Type: "snapshot", Data: results}) {
+			return false, false
+		}
+
+		// промежуточные результаты долгих проверок: хопы traceroute, ответы ping
+		progress, err := h.svc.GetProgressByRequestID(ctx, requestID)
+		if err != nil {
+			h.log.Warn("failed to get progress", zap.Error(err))
+		} else if len(progress) > 0 {
+			if !send(wsMessage{Type: "progress", Data: progress}) {
+				return false, false
+			}
+		}
+
+		lastStatus = ""
+
+		return sendStatus(status)
+	}
+
+	events, unsubscribe := h.events.Subscribe(model.RequestTopic(requestID))
+	defer func() {
+		unsubscribe()
+	}()
+
+	if finished, ok := sendSnapshot(); finished || !ok {
+		return
+	}
+
+	// сверка статуса с БД на случай потерянного события, она же держит соединение живым
+	resync := time.NewTicker(h.resyncInterval)
+	defer resync.Stop()
+
 	for {
 		select {
 		case <-ctx.Done():
 			_ = conn.WriteJSON(wsMessage{Type: "done"})
 			return
-		case <-ticker.C:
-			results, err := h.svc.GetResultsByRequestID(ctx, requestID)
-			if err != nil {
-				if !send(wsMessage{Type: "error", Err: err.Error()}) {
+		case event, open := <-events:
+			if !open {
+				// не успевали читать события: подписываемся заново и присылаем состояние целиком
+				events, unsubscribe = h.events.Subscribe(model.RequestTopic(requestID))
+
+				if finished, ok := sendSnapshot(); finished || !ok {
 					return
 				}
+
 				continue
 			}
 
-			// хэш снапшота, чтобы не слать дубликаты
-			raw, _ := json.Marshal(results)
-			sum := sha256.Sum256(raw)
-			newHash := hex.EncodeToString(sum[:])
-
-			if lastHash == "" {
-				if !send(wsMessage{Type: "snapshot", Data: results}) {
+			switch event.Type {
+			case model.StreamEventResult:
+				if !send(wsMessage{Type: "result", Data: event.Result}) {
+					return
+				}
+			case model.StreamEventProgress:
+				if !send(wsMessage{Type: "progress", Data: []*model.CheckProgress{event.Progress}}) {
 					return
 				}
-				lastHash = newHash
-			} else if newHash != lastHash {
-				if !send(wsMessage{Type: "update", Data: results}) {
+			case model.StreamEventStatus:
+				if finished, ok := sendStatus(event.Status); finished || !ok {
 					return
 				}
-				lastHash = newHash
 			}
-
-			// закрываем при терминальном состоянии всех проверок
-			if allTerminal(results) {
-				_ = conn.WriteJSON(wsMessage{Type: "done", Data: results})
+		case <-resync.C:
+			status, err := h.svc.GetRequestStatus(ctx, requestID)
+			if err != nil {
+				h.log.Warn("failed to get request status", zap.Error(err))
+			} else if finished, ok := sendStatus(status); finished || !ok {
 				return
 			}
 
-			// поддерживаем соединение живым
 			_ = conn.WriteControl(websocket.PingMessage, []byte("ping"), time.Now().Add(5*time.Second))
 		}
 	}
 }
 
-// Подстрой под свои статусы в model.CheckResultResponse.Status
-func allTerminal(list []model.CheckResultResponse) bool {
-	if len(list) == 0 {
-		return false
+// StreamResultEvents
+// @Summary Стрим результатов сетевых проверок через Server-Sent Events.
+// @Description Те же сообщения, что и у websocket /check/ws/check/{request_id}, для клиентов за прокси, которые не пропускают websocket:
+// @Description сначала snapshot, progress и status с текущим состоянием, дальше result, progress и status по мере изменений,
+// @Description done с итоговыми результатами, когда запрос завершён, после чего поток закрывается. Данные каждого события — JSON.
+// @Description id события — его номер в топике запроса: после переподключения клиент передаёт последний полученный id в Last-Event-ID
+// @Description и получает текущий status и только пропущенные события, а если они уже не хранятся — snapshot заново.
+// @Tags Checks
+// @Param request_id path string true "Request UUID"
+// @Param Last-Event-ID header string false "id последнего полученного события"
+// @Produce text/event-stream
+// @Failure 400 {object} ResponseWithMessage "Invalid path param"
+// @Failure 404 {object} ResponseWithMessage "Request not found"
+// @Failure 500 {object} ResponseWithMessage "Failed to get request"
+// @Router /check/{request_id}/events [get]
+func (h *RequestHandler) StreamResultEvents(c *gin.Context) {
+	var uri model.RequestIDPathParam
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, ResponseWithMessage{
+			Status:  StatusErr,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	requestID, err := uuid.Parse(uri.ID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ResponseWithMessage{
+			Status:  StatusErr,
+			Message: err.Error(),
+		})
+		return
 	}
-	for _, it := range list {
-		switch it.Status {
-		case "SUCCESS", "FAILED", "TIMEOUT", "CANCELLED":
-			// терминальные
-		default:
+
+	// таймаут http запроса на поток не распространяется: он закрывается после done или когда клиент отключился
+	reqCtx := c.Request.Context()
+	ctx, cancel := context.WithCancel(context.WithoutCancel(reqCtx))
+	defer cancel()
+
+	go func() {
+		<-reqCtx.Done()
+		if errors.Is(reqCtx.Err(), context.Canceled) {
+			cancel()
+		}
+	}()
+
+	status, err := h.svc.GetRequestStatus(ctx, requestID)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrRequestDoesNotExist) {
+			c.JSON(http.StatusNotFound, ResponseWithMessage{
+				Status:  StatusErr,
+				Message: "Request not found",
+			})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, ResponseWithMessage{
+			Status:  StatusErr,
+			Message: "Failed to get request",
+		})
+		return
+	}
+
+	// WriteTimeout сервера оборвал бы поток
+	if err := http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{}); err != nil {
+		h.log.Warn("failed to reset sse write deadline", zap.Error(err))
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	topic := model.RequestTopic(requestID)
+
+	var (
+		lastSeq    int64
+		lastStatus string
+	)
+
+	send := func(id int64, event string, data any) bool {
+		if err := writeSSE(c.Writer, id, event, data); err != nil {
+			h.log.Warn("sse write failed", zap.Error(err))
 			return false
 		}
+		return true
+	}
+
+	// sendStatus шлёт статус, если он сменился; finished — запрос завершён, и поток пора закрывать
+	sendStatus := func(id int64, status string) (finished, ok bool) {
+		if status != lastStatus {
+			if !send(id, "status", status) {
+				return false, false
+			}
+			lastStatus = status
+		}
+
+		if !model.IsTerminalStatus(status) {
+			return false, true
+		}
+
+		results, err := h.svc.GetResultsByRequestID(ctx, requestID)
+		if err != nil {
+			h.log.Warn("failed to get results", zap.Error(err))
+			return true, false
+		}
+
+		return true, send(0, "done", results)
+	}
+
+	// sendSnapshot текущее состояние из БД. Номер топика читается до БД: события не новее него уже в снимке
+	sendSnapshot := func() (finished, ok bool) {
+		seq, err := h.events.Seq(ctx, topic)
+		if err != nil {
+			h.log.Warn("failed to get stream seq", zap.Error(err))
+		}
+
+		status, err := h.svc.GetRequestStatus(ctx, requestID)
+		if err != nil {
+			h.log.Warn("failed to get request status", zap.Error(err))
+			return false, false
+		}
+
+		results, err := h.svc.GetResultsByRequestID(ctx, requestID)
+		if err != nil {
+			h.log.Warn("failed to get results", zap.Error(err))
+			return false, false
+		}
+
+		if !send(seq, "snapshot", results) {
+			return false, false
+		}
+
+		progress, err := h.svc.GetProgressByRequestID(ctx, requestID)
+		if err != nil {
+			h.log.Warn("failed to get progress", zap.Error(err))
+		} else if len(progress) > 0 {
+			if !send(seq, "progress", progress) {
+				return false, false
+			}
+		}
+
+		lastSeq = seq
+		lastStatus = ""
+
+		return sendStatus(seq, status)
+	}
+
+	sendEvent := func(event model.StreamEvent) (finished, ok bool) {
+		if event.Seq <= lastSeq {
+			return false, true
+		}
+
+		switch event.Type {
+		case model.StreamEventResult:
+			ok = send(event.Seq, "result", event.Result)
+		case model.StreamEventProgress:
+			ok = send(event.Seq, "progress", []*model.CheckProgress{event.Progress})
+		case model.StreamEventStatus:
+			finished, ok = sendStatus(event.Seq, event.Status)
+		default:
+			ok = true
+		}
+
+		lastSeq = event.Seq
+
+		return finished, ok
+	}
+
+	// catchUp досылает события после lastSeq из истории, а если её не хватает — снимок
+	catchUp := func() (finished, ok bool) {
+		events, complete, err := h.events.Replay(ctx, topic, lastSeq)
+		if err != nil {
+			h.log.Warn("failed to replay stream events", zap.Error(err))
+		}
+
+		if err != nil || !complete {
+			return sendSnapshot()
+		}
+
+		for _, event := range events {
+			if finished, ok = sendEvent(event); finished || !ok {
+				return finished, ok
+			}
+		}
+
+		return false, true
+	}
+
+	events, unsubscribe := h.events.Subscribe(topic)
+	defer func() {
+		unsubscribe()
+	}()
+
+	var finished, ok bool
+
+	if lastID, err := strconv.ParseInt(c.GetHeader("Last-Event-ID"), 10, 64); err == nil && lastID > 0 {
+		lastSeq = lastID
+
+		if finished, ok = sendStatus(0, status); !finished && ok {
+			finished, ok = catchUp()
+		}
+	} else {
+		finished, ok = sendSnapshot()
+	}
+
+	if finished || !ok {
+		return
+	}
+
+	// сверка статуса с БД на случай потерянного события, комментарий держит соединение через прокси
+	resync := time.NewTicker(h.resyncInterval)
+	defer resync.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, open := <-events:
+			switch {
+			case !open:
+				// не успевали читать события: подписываемся заново и досылаем пропущенное
+				events, unsubscribe = h.events.Subscribe(topic)
+				finished, ok = catchUp()
+			case event.Seq > lastSeq+1:
+				// событие потерялось в Redis pub/sub
+				finished, ok = catchUp()
+			default:
+				finished, ok = sendEvent(event)
+			}
+		case <-resync.C:
+			status, err := h.svc.GetRequestStatus(ctx, requestID)
+			if err != nil {
+				h.log.Warn("failed to get request status", zap.Error(err))
+			} else if finished, ok = sendStatus(0, status); finished || !ok {
+				return
+			}
+
+			if _, err := c.Writer.WriteString(": ping\n\n"); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		}
+
+		if finished || !ok {
+			return
+		}
+	}
+}
+
+// writeSSE пишет одно событие Server-Sent Events; id 0 не пишется, и клиент сохраняет прежний Last-Event-ID.
+func writeSSE(w gin.ResponseWriter, id int64, event string, data any) error {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to marshal sse data: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if id > 0 {
+		fmt.Fprintf(&buf, "id: %d\n", id)
+	}
+	fmt.Fprintf(&buf, "event: %s\ndata: %s\n\n", event, b)
+
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		return fmt.Errorf("failed to write sse event: %w", err)
+	}
+	w.Flush()
+
+	return nil
+}
+
+func newValidationResponse(vErr *contract.ValidationError) ResponseWithErrors {
+	fields := make([]FieldError, 0, len(vErr.Fields))
+	for _, f := range vErr.Fields {
+		fields = append(fields, FieldError{Field: f.Field, Message: f.Message})
+	}
+
+	return ResponseWithErrors{
+		Status:  StatusInvalidInput,
+		Message: contract.ErrInvalidParams.Error(),
+		Errors:  fields,
 	}
-	return true
 }
@@ -0,0 +1,522 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+
+	"hackathon-back/internal/apperrors"
+	"hackathon-back/internal/model"
+)
+
+const (
+	streamMaxTopics    = 100              // streamMaxTopics подписок на одно соединение
+	streamWriteTimeout = 10 * time.Second // streamWriteTimeout клиент, который не читает дольше, отключается
+)
+
+// Команды клиента мультиплексного websocket.
+const (
+	streamCommandSubscribe   = "subscribe"
+	streamCommandUnsubscribe = "unsubscribe"
+	streamCommandPing        = "ping"
+)
+
+// Сообщения сервера мультиплексного websocket.
+const (
+	streamMessageSubscribed   = "subscribed"
+	streamMessageSnapshot     = "snapshot"
+	streamMessageEvent        = "event"
+	streamMessageUnsubscribed = "unsubscribed"
+	streamMessageHeartbeat    = "heartbeat"
+	streamMessagePong         = "pong"
+	streamMessageError        = "error"
+)
+
+// StreamEvents подписка на топики событий с досылкой по номерам, см. stream.Hub.
+type StreamEvents interface {
+	Subscribe(topic string) (events <-chan model.StreamEvent, unsubscribe func())
+	Seq(ctx context.Context, topic string) (int64, error)
+	Replay(ctx context.Context, topic string, afterSeq int64) (events []model.StreamEvent, complete bool, err error)
+}
+
+type StreamHandler struct {
+	BaseHandler
+	log               *zap.Logger
+	requests          RequestService
+	agents            AgentService
+	monitors          MonitorService
+	events            StreamEvents
+	heartbeatInterval time.Duration
+}
+
+func NewStreamHandler(log *zap.Logger, requests RequestService, agents AgentService, monitors MonitorService, events StreamEvents, heartbeatInterval time.Duration) *StreamHandler {
+	return &StreamHandler{
+		log:               log,
+		requests:          requests,
+		agents:            agents,
+		monitors:          monitors,
+		events:            events,
+		heartbeatInterval: heartbeatInterval,
+	}
+}
+
+// streamCommand сообщение клиента.
+type streamCommand struct {
+	ID      string `json:"id,omitempty"`      // ID возвращается в ответе на команду
+	Type    string `json:"type"`              // "subscribe" | "unsubscribe" | "ping"
+	Topic   string `json:"topic,omitempty"`   // "request:<request_id>" | "monitor:<monitor_id>" | "fleet"
+	LastSeq *int64 `json:"lastSeq,omitempty"` // последний полученный номер события топика, для досылки после переподключения
+}
+
+// streamMessage сообщение сервера.
+type streamMessage struct {
+	ID      string `json:"id,omitempty"`
+	Type    string `json:"type"` // "subscribed" | "snapshot" | "event" | "unsubscribed" | "heartbeat" | "pong" | "error"
+	Topic   string `json:"topic,omitempty"`
+	Seq     int64  `json:"seq,omitempty"`
+	Event   string `json:"event,omitempty"`   // тип события для type=event, см. model.StreamEvent
+	Resumed bool   `json:"resumed,omitempty"` // для type=subscribed: пропущенное дошлётся событиями, снимка не будет
+	Data    any    `json:"data,omitempty"`
+	Err     string `json:"error,omitempty"`
+}
+
+// streamRequestSnapshot состояние запроса для топика request:<request_id>.
+type streamRequestSnapshot struct {
+	Status   string                      `json:"status"`
+	Results  []model.CheckResultResponse `json:"results"`
+	Progress []model.CheckProgress       `json:"progress"`
+}
+
+// streamMonitorSnapshot состояние монитора для топика monitor:<monitor_id>.
+type streamMonitorSnapshot struct {
+	Monitor *model.Monitor      `json:"monitor"`
+	Runs    []*model.MonitorRun `json:"runs"`
+}
+
+// streamSub подписка соединения на топик.
+type streamSub struct {
+	topic       string
+	target      model.StreamTopic // target id запроса или монитора из имени топика
+	seq         int64             // seq последний номер события, который получил клиент
+	done        chan struct{}
+	unsubscribe func()
+}
+
+func (sub *streamSub) stop() {
+	close(sub.done)
+	sub.unsubscribe()
+}
+
+// streamSession одно мультиплексное соединение. Писать в conn и менять subs может только Connect.
+type streamSession struct {
+	h      *StreamHandler
+	conn   *websocket.Conn
+	ctx    context.Context
+	cancel context.CancelFunc
+	role   string
+	userID uuid.UUID // userID uuid.Nil, если в токене нет пользователя: такому соединению мониторы недоступны
+
+	subs    map[string]*streamSub
+	events  chan model.StreamEvent // events из всех подписок
+	dropped chan *streamSub        // dropped подписки, которые stream.Hub закрыл из-за переполнения буфера
+}
+
+// Connect
+// @Summary Мультиплексный WebSocket: подписки на запросы и парк агентов.
+// @Description Одно соединение на клиента. Клиент шлёт JSON-команды: {"type":"subscribe","topic":"request:<request_id>","lastSeq":12},
+// @Description {"type":"unsubscribe","topic":"..."}, {"type":"ping"}; поле id команды возвращается в ответе. Топики:
+// @Description request:<request_id> — результаты, промежуточные состояния и статус запроса; monitor:<monitor_id> — запуски своего монитора;
+// @Description fleet — агенты online/offline, только для manager и admin.
+// @Description На subscribe приходит subscribed с текущим seq топика, за ним snapshot с состоянием целиком (для запроса — status, results, progress,
+// @Description для монитора — monitor и последние runs, для fleet — как GET /admin/agents). Дальше приходят event: event — тип события (result, progress,
+// @Description status, agent_online, agent_offline, monitor_run), seq — номер события в топике, data — его данные. Если в subscribe передан lastSeq и история топика
+// @Description ещё хранит всё после него, snapshot не приходит (subscribed с resumed=true), пропущенные события досылаются по порядку.
+// @Description Сервер раз в heartbeat_interval шлёт heartbeat, на ping отвечает pong, ошибки команд приходят как error с id и topic.
+// @Tags Stream
+// @Security AccessToken
+// @Security RefreshToken
+// @Produce application/json
+// @Failure 401 {object} ResponseWithMessage "Не авторизован"
+// @Router /ws [get]
+func (h *StreamHandler) Connect(c *gin.Context) {
+	role, _ := c.Get(model.UserRoleKey)
+	roleStr, _ := role.(string)
+
+	userID, _ := h.GetUserID(c)
+
+	conn, err := wsUpgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		h.log.Warn("ws upgrade failed", zap.Error(err))
+		return
+	}
+	defer conn.Close()
+
+	// соединение живёт дольше таймаута http запроса, закрывается по ошибке чтения или записи
+	ctx, cancel := context.WithCancel(context.WithoutCancel(c.Request.Context()))
+	defer cancel()
+
+	s := &streamSession{
+		h:       h,
+		conn:    conn,
+		ctx:     ctx,
+		cancel:  cancel,
+		role:    roleStr,
+		userID:  userID,
+		subs:    make(map[string]*streamSub),
+		events:  make(chan model.StreamEvent),
+		dropped: make(chan *streamSub),
+	}
+	defer func() {
+		for _, sub := range s.subs {
+			sub.stop()
+		}
+	}()
+
+	readTimeout := 3 * h.heartbeatInterval
+
+	_ = conn.SetReadDeadline(time.Now().Add(readTimeout))
+	conn.SetPongHandler(func(string) error {
+		_ = conn.SetReadDeadline(time.Now().Add(readTimeout))
+		return nil
+	})
+
+	commands := make(chan streamCommand)
+	go s.read(commands, readTimeout)
+
+	heartbeat := time.NewTicker(h.heartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		var ok bool
+
+		select {
+		case <-ctx.Done():
+			return
+		case cmd := <-commands:
+			ok = s.handle(cmd)
+		case event := <-s.events:
+			ok = s.deliver(event)
+		case sub := <-s.dropped:
+			ok = s.resync(sub)
+		case now := <-heartbeat.C:
+			ok = s.send(streamMessage{Type: streamMessageHeartbeat, Data: now.UTC()})
+			_ = conn.WriteControl(websocket.PingMessage, []byte("ping"), time.Now().Add(streamWriteTimeout))
+		}
+
+		if !ok {
+			return
+		}
+	}
+}
+
+// read читает команды клиента, пока соединение открыто.
+func (s *streamSession) read(commands chan<- streamCommand, readTimeout time.Duration) {
+	defer s.cancel()
+
+	for {
+		_, data, err := s.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		_ = s.conn.SetReadDeadline(time.Now().Add(readTimeout))
+
+		var cmd streamCommand
+		if err := json.Unmarshal(data, &cmd); err != nil {
+			cmd = streamCommand{}
+		}
+
+		select {
+		case commands <- cmd:
+		case <-s.ctx.Done():
+			return
+		}
+	}
+}
+
+// handle выполняет команду клиента; false — соединение пора закрывать.
+func (s *streamSession) handle(cmd streamCommand) bool {
+	switch cmd.Type {
+	case streamCommandSubscribe:
+		return s.subscribe(cmd)
+	case streamCommandUnsubscribe:
+		if sub, ok := s.subs[cmd.Topic]; ok {
+			sub.stop()
+			delete(s.subs, cmd.Topic)
+		}
+
+		return s.send(streamMessage{ID: cmd.ID, Type: streamMessageUnsubscribed, Topic: cmd.Topic})
+	case streamCommandPing:
+		return s.send(streamMessage{ID: cmd.ID, Type: streamMessagePong})
+	default:
+		return s.send(streamMessage{ID: cmd.ID, Type: streamMessageError, Err: fmt.Sprintf("unknown command type %q", cmd.Type)})
+	}
+}
+
+func (s *streamSession) subscribe(cmd streamCommand) bool {
+	target, err := model.ParseTopic(cmd.Topic)
+	if err != nil {
+		return s.sendError(cmd.ID, cmd.Topic, err)
+	}
+
+	if cmd.Topic == model.TopicFleet && s.role != model.RoleManager && s.role != model.RoleAdmin {
+		return s.sendError(cmd.ID, cmd.Topic, apperrors.ErrStreamTopicForbidden)
+	}
+
+	if _, ok := s.subs[cmd.Topic]; !ok && len(s.subs) >= streamMaxTopics {
+		return s.sendError(cmd.ID, cmd.Topic, fmt.Errorf("%w, limit is %d", apperrors.ErrTooManyStreamTopics, streamMaxTopics))
+	}
+
+	switch {
+	case target.RequestID != uuid.Nil:
+		if _, err := s.h.requests.GetRequestStatus(s.ctx, target.RequestID); err != nil {
+			return s.sendError(cmd.ID, cmd.Topic, err)
+		}
+	case target.MonitorID != uuid.Nil:
+		// чужой монитор для пользователя не существует
+		if _, err := s.h.monitors.GetMonitor(s.ctx, s.userID, target.MonitorID); err != nil {
+			return s.sendError(cmd.ID, cmd.Topic, err)
+		}
+	}
+
+	sub := s.attach(cmd.Topic, target)
+
+	if cmd.LastSeq != nil {
+		events, complete, err := s.h.events.Replay(s.ctx, cmd.Topic, *cmd.LastSeq)
+		if err != nil {
+			s.h.log.Warn("failed to replay stream events", zap.String("topic", cmd.Topic), zap.Error(err))
+		} else if complete {
+			sub.seq = *cmd.LastSeq
+
+			if !s.send(streamMessage{ID: cmd.ID, Type: streamMessageSubscribed, Topic: cmd.Topic, Seq: sub.seq, Resumed: true}) {
+				return false
+			}
+
+			return s.sendEvents(sub, events)
+		}
+	}
+
+	return s.sendSnapshot(cmd.ID, sub, true)
+}
+
+// attach подписывает на топик вместо прежней подписки, события пересылаются в s.events.
+func (s *streamSession) attach(topic string, target model.StreamTopic) *streamSub {
+	if old, ok := s.subs[topic]; ok {
+		old.stop()
+	}
+
+	events, unsubscribe := s.h.events.Subscribe(topic)
+
+	sub := &streamSub{
+		topic:       topic,
+		target:      target,
+		done:        make(chan struct{}),
+		unsubscribe: unsubscribe,
+	}
+	s.subs[topic] = sub
+
+	go func() {
+		for {
+			select {
+			case <-sub.done:
+				return
+			case event, open := <-events:
+				if !open {
+					select {
+					case s.dropped <- sub:
+					case <-sub.done:
+					}
+
+					return
+				}
+
+				select {
+				case s.events <- event:
+				case <-sub.done:
+					return
+				}
+			}
+		}
+	}()
+
+	return sub
+}
+
+// deliver пересылает событие клиенту. Пропуск в номерах (событие потерялось в Redis pub/sub)
+// закрывается из истории, а если её не хватает — снимком.
+func (s *streamSession) deliver(event model.StreamEvent) bool {
+	sub, ok := s.subs[event.Topic]
+	if !ok || event.Seq <= sub.seq {
+		return true
+	}
+
+	if event.Seq == sub.seq+1 {
+		return s.sendEvents(sub, []model.StreamEvent{event})
+	}
+
+	return s.catchUp(sub)
+}
+
+// resync stream.Hub закрыл подписку: клиент не успевал читать. Подписываемся заново и досылаем пропущенное.
+func (s *streamSession) resync(dropped *streamSub) bool {
+	if s.subs[dropped.topic] != dropped {
+		return true
+	}
+
+	sub := s.attach(dropped.topic, dropped.target)
+	sub.seq = dropped.seq
+
+	return s.catchUp(sub)
+}
+
+func (s *streamSession) catchUp(sub *streamSub) bool {
+	events, complete, err := s.h.events.Replay(s.ctx, sub.topic, sub.seq)
+	if err != nil {
+		s.h.log.Warn("failed to replay stream events", zap.String("topic", sub.topic), zap.Error(err))
+	} else if complete {
+		return s.sendEvents(sub, events)
+	}
+
+	return s.sendSnapshot("", sub, false)
+}
+
+func (s *streamSession) sendEvents(sub *streamSub, events []model.StreamEvent) bool {
+	for _, event := range events {
+		if event.Seq <= sub.seq {
+			continue
+		}
+
+		if !s.send(streamMessage{
+			Type:  streamMessageEvent,
+			Topic: sub.topic,
+			Seq:   event.Seq,
+			Event: event.Type,
+			Data:  event.Data(),
+		}) {
+			return false
+		}
+
+		sub.seq = event.Seq
+	}
+
+	return true
+}
+
+// sendSnapshot присылает состояние топика из БД. Номер топика читается до БД: события с номером
+// не больше него уже попали в снимок, более поздние придут событиями и могут его повторять.
+func (s *streamSession) sendSnapshot(id string, sub *streamSub, announce bool) bool {
+	seq, err := s.h.events.Seq(s.ctx, sub.topic)
+	if err != nil {
+		s.h.log.Warn("failed to get stream seq", zap.String("topic", sub.topic), zap.Error(err))
+	}
+
+	data, err := s.snapshot(sub)
+	if err != nil {
+		sub.stop()
+		delete(s.subs, sub.topic)
+
+		return s.sendError(id, sub.topic, err)
+	}
+
+	sub.seq = seq
+
+	if announce && !s.send(streamMessage{ID: id, Type: streamMessageSubscribed, Topic: sub.topic, Seq: seq}) {
+		return false
+	}
+
+	return s.send(streamMessage{ID: id, Type: streamMessageSnapshot, Topic: sub.topic, Seq: seq, Data: data})
+}
+
+func (s *streamSession) snapshot(sub *streamSub) (any, error) {
+	switch {
+	case sub.target.RequestID != uuid.Nil:
+		return s.requestSnapshot(sub.target.RequestID)
+	case sub.target.MonitorID != uuid.Nil:
+		return s.monitorSnapshot(sub.target.MonitorID)
+	default:
+		return s.h.agents.GetFleet(s.ctx)
+	}
+}
+
+func (s *streamSession) monitorSnapshot(monitorID uuid.UUID) (any, error) {
+	monitor, err := s.h.monitors.GetMonitor(s.ctx, s.userID, monitorID)
+	if err != nil {
+		return nil, err
+	}
+
+	runs, err := s.h.monitors.GetRuns(s.ctx, s.userID, monitorID, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return streamMonitorSnapshot{
+		Monitor: monitor,
+		Runs:    runs,
+	}, nil
+}
+
+func (s *streamSession) requestSnapshot(requestID uuid.UUID) (any, error) {
+	status, err := s.h.requests.GetRequestStatus(s.ctx, requestID)
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := s.h.requests.GetResultsByRequestID(s.ctx, requestID)
+	if err != nil {
+		return nil, err
+	}
+
+	progress, err := s.h.requests.GetProgressByRequestID(s.ctx, requestID)
+	if err != nil {
+		return nil, err
+	}
+
+	if results == nil {
+		results = []model.CheckResultResponse{}
+	}
+
+	if progress == nil {
+		progress = []model.CheckProgress{}
+	}
+
+	return streamRequestSnapshot{
+		Status:   status,
+		Results:  results,
+		Progress: progress,
+	}, nil
+}
+
+func (s *streamSession) sendError(id, topic string, err error) bool {
+	msg := err.Error()
+
+	// внутренние ошибки клиенту не показываются
+	if !errors.Is(err, apperrors.ErrUnknownStreamTopic) &&
+		!errors.Is(err, apperrors.ErrStreamTopicForbidden) &&
+		!errors.Is(err, apperrors.ErrTooManyStreamTopics) &&
+		!errors.Is(err, apperrors.ErrRequestDoesNotExist) &&
+		!errors.Is(err, apperrors.ErrMonitorDoesNotExist) {
+		s.h.log.Warn("stream subscription failed", zap.String("topic", topic), zap.Error(err))
+
+		msg = "failed to subscribe"
+	}
+
+	return s.send(streamMessage{ID: id, Type: streamMessageError, Topic: topic, Err: msg})
+}
+
+func (s *streamSession) send(msg streamMessage) bool {
+	_ = s.conn.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+
+	if err := s.conn.WriteJSON(msg); err != nil {
+		s.h.log.Warn("ws write failed", zap.Error(err))
+		return false
+	}
+
+	return true
+}
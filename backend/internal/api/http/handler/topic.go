@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"hackathon-back/internal/model"
+)
+
+type TopicService interface {
+	GetTopics(ctx context.Context) ([]*model.TopicHealth, error)
+}
+
+type TopicHandler struct {
+	svc TopicService
+}
+
+func NewTopicHandler(svc TopicService) *TopicHandler {
+	return &TopicHandler{
+		svc: svc,
+	}
+}
+
+// GetTopics
+// @Summary Состояние топиков Kafka
+// @Description Возвращает топики результатов, heartbeat и задач всех регионов агентов: партиции, лидеров, реплики и ISR. Доступно для пользователей с ролью manager и выше.
+// @Tags Agent
+// @Security AccessToken
+// @Security RefreshToken
+// @Produce json
+// @Success 200 {object} ResponseWithData{data=[]model.TopicHealth} "Success"
+// @Failure 401 {object} ResponseWithMessage "Не авторизован"
+// @Failure 403 {object} ResponseWithMessage "Недостаточно прав"
+// @Failure 503 {object} ResponseWithMessage "Kafka недоступна"
+// @Router /admin/topics [get]
+func (h *TopicHandler) GetTopics(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	topics, err := h.svc.GetTopics(ctx)
+	if err != nil {
+		c.JSON(http.StatusServiceUnavailable, ResponseWithMessage{
+			Status:  StatusNotAvailable,
+			Message: "Failed to get topics",
+		})
+
+		return
+	}
+
+	c.JSON(http.StatusOK, ResponseWithData{
+		Status: StatusSuccess,
+		Data:   topics,
+	})
+}
@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"hackathon-back/internal/api/http/handler"
+	"hackathon-back/internal/apperrors"
+	"hackathon-back/internal/model"
+)
+
+// AgentGatewayAuthenticator проверяет gateway токен агента, см. GatewayService.Authenticate.
+type AgentGatewayAuthenticator interface {
+	Authenticate(ctx context.Context, agentID uuid.UUID, token string) error
+}
+
+// AgentGatewayAuth пускает агентов HTTP транспорта: id агента в X-Agent-ID и Bearer токен gateway,
+// выданный именно этому агенту при регистрации.
+func AgentGatewayAuth(auth AgentGatewayAuthenticator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		agentID, err := uuid.Parse(c.GetHeader(model.AgentIDHeader))
+		if err != nil || agentID == uuid.Nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, handler.ResponseWithMessage{
+				Status:  handler.StatusNotPermitted,
+				Message: "missing or invalid " + model.AgentIDHeader,
+			})
+
+			return
+		}
+
+		authHeader := c.GetHeader("Authorization")
+		if !strings.HasPrefix(authHeader, "Bearer ") {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, handler.ResponseWithMessage{
+				Status:  handler.StatusNotPermitted,
+				Message: "invalid agent token",
+			})
+
+			return
+		}
+
+		if err := auth.Authenticate(c.Request.Context(), agentID, strings.TrimPrefix(authHeader, "Bearer ")); err != nil {
+			if errors.Is(err, apperrors.ErrInvalidAgentToken) {
+				c.AbortWithStatusJSON(http.StatusUnauthorized, handler.ResponseWithMessage{
+					Status:  handler.StatusNotPermitted,
+					Message: "invalid agent token",
+				})
+
+				return
+			}
+
+			c.AbortWithStatusJSON(http.StatusInternalServerError, handler.ResponseWithMessage{
+				Status:  handler.StatusInternalError,
+				Message: "Failed to authenticate agent",
+			})
+
+			return
+		}
+
+		c.Set(model.AgentIDKey, agentID)
+
+		c.Next()
+	}
+}
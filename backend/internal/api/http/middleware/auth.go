@@ -14,25 +14,40 @@ import (
 
 func JWTAuth(publicKey *ecdsa.PublicKey) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		var tokenStr string
-
-		if cookie, err := c.Cookie("access"); err == nil {
-			tokenStr = cookie
-		}
+		tokenStr := accessToken(c)
 
 		if tokenStr == "" {
-			authHeader := c.GetHeader("Authorization")
-			if strings.HasPrefix(authHeader, "Bearer ") {
-				tokenStr = strings.TrimPrefix(authHeader, "Bearer ")
-			}
+			c.AbortWithStatusJSON(http.StatusUnauthorized, handler.ResponseWithMessage{
+				Status:  handler.StatusNotPermitted,
+				Message: "Missing access token",
+			})
+
+			return
 		}
 
-		if tokenStr == "" {
+		claims, err := jwt.ValidateToken(tokenStr, publicKey)
+		if err != nil {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, handler.ResponseWithMessage{
 				Status:  handler.StatusNotPermitted,
-				Message: "Missing access token",
+				Message: "invalid or expired token",
 			})
+			return
+		}
+
+		setClaims(c, claims)
 
+		c.Next()
+	}
+}
+
+// OptionalJWTAuth как JWTAuth, но запрос без токена пропускает анонимно.
+// Невалидный токен по-прежнему отклоняется, чтобы не выполнить запрос молча от имени анонима.
+func OptionalJWTAuth(publicKey *ecdsa.PublicKey) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tokenStr := accessToken(c)
+
+		if tokenStr == "" {
+			c.Next()
 			return
 		}
 
@@ -45,12 +60,29 @@ func JWTAuth(publicKey *ecdsa.PublicKey) gin.HandlerFunc {
 			return
 		}
 
-		c.Set(model.UserUIDKey, claims[model.UserUIDKey])
-		c.Set(model.UserEmailKey, claims[model.UserEmailKey])
-		c.Set(model.UserNameKey, claims[model.UserNameKey])
-		c.Set(model.UserConfirmedKey, claims[model.UserConfirmedKey])
-		c.Set(model.UserRoleKey, claims[model.UserRoleKey])
+		setClaims(c, claims)
 
 		c.Next()
 	}
 }
+
+func accessToken(c *gin.Context) string {
+	if cookie, err := c.Cookie("access"); err == nil && cookie != "" {
+		return cookie
+	}
+
+	authHeader := c.GetHeader("Authorization")
+	if strings.HasPrefix(authHeader, "Bearer ") {
+		return strings.TrimPrefix(authHeader, "Bearer ")
+	}
+
+	return ""
+}
+
+func setClaims(c *gin.Context, claims map[string]any) {
+	c.Set(model.UserUIDKey, claims[model.UserUIDKey])
+	c.Set(model.UserEmailKey, claims[model.UserEmailKey])
+	c.Set(model.UserNameKey, claims[model.UserNameKey])
+	c.Set(model.UserConfirmedKey, claims[model.UserConfirmedKey])
+	c.Set(model.UserRoleKey, claims[model.UserRoleKey])
+}
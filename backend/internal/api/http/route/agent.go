@@ -0,0 +1,35 @@
+package route
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+type AgentHandler interface {
+	GetFleet(c *gin.Context)
+	GetAgent(c *gin.Context)
+	CreateAgent(c *gin.Context)
+	UpdateAgent(c *gin.Context)
+	DeleteAgent(c *gin.Context)
+	IssueEnrollmentToken(c *gin.Context)
+	GetEnrollments(c *gin.Context)
+	Enroll(c *gin.Context)
+	AgentPoolHandler
+}
+
+func RegisterAdminAgentRoutes(g *gin.RouterGroup, h AgentHandler, jwtAuthMiddleware, allowManagerAndAdminMiddleware, allowAdminMiddleware gin.HandlerFunc) {
+	protected := g.Group("", jwtAuthMiddleware, allowManagerAndAdminMiddleware)
+	protected.GET("", h.GetFleet)
+	protected.GET("/:agent_id", h.GetAgent)
+	protected.GET("/:agent_id/enrollments", h.GetEnrollments)
+
+	adminRequired := g.Group("", jwtAuthMiddleware, allowAdminMiddleware)
+	adminRequired.POST("", h.CreateAgent)
+	adminRequired.PATCH("/:agent_id", h.UpdateAgent)
+	adminRequired.DELETE("/:agent_id", h.DeleteAgent)
+	adminRequired.POST("/:agent_id/enrollments", h.IssueEnrollmentToken)
+}
+
+// RegisterAgentEnrollRoutes регистрация агента: доступ по одноразовому токену в теле запроса, без gateway токена.
+func RegisterAgentEnrollRoutes(g *gin.RouterGroup, h AgentHandler) {
+	g.POST("/enroll", h.Enroll)
+}
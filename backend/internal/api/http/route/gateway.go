@@ -0,0 +1,16 @@
+package route
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+type GatewayHandler interface {
+	PollTasks(c *gin.Context)
+	PublishMessage(c *gin.Context)
+}
+
+func RegisterAgentGatewayRoutes(g *gin.RouterGroup, h GatewayHandler, agentGatewayMiddleware gin.HandlerFunc) {
+	protected := g.Group("", agentGatewayMiddleware)
+	protected.GET("/tasks", h.PollTasks)
+	protected.POST("/messages", h.PublishMessage)
+}
@@ -0,0 +1,24 @@
+package route
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+type MonitorHandler interface {
+	CreateMonitor(c *gin.Context)
+	GetMonitors(c *gin.Context)
+	GetMonitor(c *gin.Context)
+	UpdateMonitor(c *gin.Context)
+	DeleteMonitor(c *gin.Context)
+	GetMonitorRuns(c *gin.Context)
+}
+
+func RegisterMonitorRoutes(g *gin.RouterGroup, h MonitorHandler, jwtAuthMiddleware gin.HandlerFunc) {
+	protected := g.Group("", jwtAuthMiddleware)
+	protected.POST("", h.CreateMonitor)
+	protected.GET("", h.GetMonitors)
+	protected.GET("/:monitor_id", h.GetMonitor)
+	protected.PATCH("/:monitor_id", h.UpdateMonitor)
+	protected.DELETE("/:monitor_id", h.DeleteMonitor)
+	protected.GET("/:monitor_id/runs", h.GetMonitorRuns)
+}
@@ -0,0 +1,22 @@
+package route
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+type OrganizationHandler interface {
+	CreateOrganization(c *gin.Context)
+	GetOrganizations(c *gin.Context)
+	GetMembers(c *gin.Context)
+	AddMember(c *gin.Context)
+	RemoveMember(c *gin.Context)
+}
+
+func RegisterOrganizationRoutes(g *gin.RouterGroup, h OrganizationHandler, jwtAuthMiddleware gin.HandlerFunc) {
+	protected := g.Group("", jwtAuthMiddleware)
+	protected.POST("", h.CreateOrganization)
+	protected.GET("", h.GetOrganizations)
+	protected.GET("/:organization_id/members", h.GetMembers)
+	protected.POST("/:organization_id/members", h.AddMember)
+	protected.DELETE("/:organization_id/members/:user_id", h.RemoveMember)
+}
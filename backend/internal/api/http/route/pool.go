@@ -0,0 +1,25 @@
+package route
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+type AgentPoolHandler interface {
+	GetPoolAgents(c *gin.Context)
+	CreatePoolAgent(c *gin.Context)
+	UpdatePoolAgent(c *gin.Context)
+	DeletePoolAgent(c *gin.Context)
+	IssuePoolEnrollmentToken(c *gin.Context)
+	GetPoolEnrollments(c *gin.Context)
+}
+
+// RegisterAgentPoolRoutes приватные пулы: владелец управляет своими агентами и агентами своих организаций.
+func RegisterAgentPoolRoutes(g *gin.RouterGroup, h AgentPoolHandler, jwtAuthMiddleware gin.HandlerFunc) {
+	protected := g.Group("", jwtAuthMiddleware)
+	protected.GET("", h.GetPoolAgents)
+	protected.POST("", h.CreatePoolAgent)
+	protected.PATCH("/:agent_id", h.UpdatePoolAgent)
+	protected.DELETE("/:agent_id", h.DeletePoolAgent)
+	protected.POST("/:agent_id/enrollments", h.IssuePoolEnrollmentToken)
+	protected.GET("/:agent_id/enrollments", h.GetPoolEnrollments)
+}
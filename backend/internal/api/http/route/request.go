@@ -6,12 +6,18 @@ import (
 
 type RequestHandler interface {
 	CreateRequest(c *gin.Context)
+	GetLocations(c *gin.Context)
 	GetResults(c *gin.Context)
+	GetAgentResults(c *gin.Context)
 	StreamResults(c *gin.Context)
+	StreamResultEvents(c *gin.Context)
 }
 
-func RegisterRequestRoutes(g *gin.RouterGroup, handler RequestHandler) {
-	g.POST("/task", handler.CreateRequest)
+func RegisterRequestRoutes(g *gin.RouterGroup, handler RequestHandler, optionalJWTAuthMiddleware gin.HandlerFunc) {
+	g.POST("/task", optionalJWTAuthMiddleware, handler.CreateRequest)
+	g.GET("/locations", optionalJWTAuthMiddleware, handler.GetLocations)
 	g.GET("/:request_id", handler.GetResults)
+	g.GET("/:request_id/agents", handler.GetAgentResults)
+	g.GET("/:request_id/events", handler.StreamResultEvents)
 	g.GET("/ws/check/:request_id", handler.StreamResults)
 }
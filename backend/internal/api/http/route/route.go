@@ -26,6 +26,13 @@ func SetupRouter(
 	apiKeyRepo middleware.APIKeyRepositoryInterface,
 	faqHdl FAQHandler,
 	reqHdl RequestHandler,
+	agentHdl AgentHandler,
+	organizationHdl OrganizationHandler,
+	monitorHdl MonitorHandler,
+	topicHdl TopicHandler,
+	gatewayHdl GatewayHandler,
+	gatewayAuth middleware.AgentGatewayAuthenticator,
+	streamHdl StreamHandler,
 ) *gin.Engine {
 	gin.SetMode(gin.ReleaseMode)
 	gin.DefaultWriter = io.Discard
@@ -39,7 +46,9 @@ func SetupRouter(
 	router.Use(middleware.CORS(cfg.CORS))
 
 	jwtAuthMiddleware := middleware.JWTAuth(publicKey)
+	optionalJWTAuthMiddleware := middleware.OptionalJWTAuth(publicKey)
 	allowManagerAndAdminMiddleware := middleware.RequireRoles(model.RoleManager, model.RoleAdmin)
+	allowAdminMiddleware := middleware.RequireRoles(model.RoleAdmin)
 	apiKeyMiddleware := middleware.APIKeyAuthMiddleware(apiKeyRepo)
 
 	router.HandleMethodNotAllowed = true
@@ -61,7 +70,7 @@ func SetupRouter(
 	RegisterAdminUserRoutes(userPath, userHdl, jwtAuthMiddleware, allowManagerAndAdminMiddleware)
 
 	requestPath := basePath.Group("/check")
-	RegisterRequestRoutes(requestPath, reqHdl)
+	RegisterRequestRoutes(requestPath, reqHdl, optionalJWTAuthMiddleware)
 
 	articleGroup := basePath.Group("/article")
 	RegisterArticleRoutes(articleGroup, articleHdl, jwtAuthMiddleware, allowManagerAndAdminMiddleware)
@@ -81,5 +90,30 @@ func SetupRouter(
 	faqPath := basePath.Group("/faq")
 	RegisterFAQRoutes(faqPath, faqHdl, jwtAuthMiddleware, allowManagerAndAdminMiddleware)
 
+	streamPath := basePath.Group("/ws")
+	RegisterStreamRoutes(streamPath, streamHdl, jwtAuthMiddleware)
+
+	agentPath := basePath.Group("/admin/agents")
+	RegisterAdminAgentRoutes(agentPath, agentHdl, jwtAuthMiddleware, allowManagerAndAdminMiddleware, allowAdminMiddleware)
+
+	topicPath := basePath.Group("/admin/topics")
+	RegisterAdminTopicRoutes(topicPath, topicHdl, jwtAuthMiddleware, allowManagerAndAdminMiddleware)
+
+	poolPath := basePath.Group("/agents")
+	RegisterAgentPoolRoutes(poolPath, agentHdl, jwtAuthMiddleware)
+
+	organizationPath := basePath.Group("/organizations")
+	RegisterOrganizationRoutes(organizationPath, organizationHdl, jwtAuthMiddleware)
+
+	monitorPath := basePath.Group("/monitors")
+	RegisterMonitorRoutes(monitorPath, monitorHdl, jwtAuthMiddleware)
+
+	gatewayPath := basePath.Group("/agent")
+	RegisterAgentEnrollRoutes(gatewayPath, agentHdl)
+
+	if cfg.Gateway.Enabled {
+		RegisterAgentGatewayRoutes(gatewayPath, gatewayHdl, middleware.AgentGatewayAuth(gatewayAuth))
+	}
+
 	return router
 }
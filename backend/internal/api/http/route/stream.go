@@ -0,0 +1,13 @@
+package route
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+type StreamHandler interface {
+	Connect(c *gin.Context)
+}
+
+func RegisterStreamRoutes(g *gin.RouterGroup, h StreamHandler, jwtAuthMiddleware gin.HandlerFunc) {
+	g.GET("", jwtAuthMiddleware, h.Connect)
+}
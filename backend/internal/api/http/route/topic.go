@@ -0,0 +1,14 @@
+package route
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+type TopicHandler interface {
+	GetTopics(c *gin.Context)
+}
+
+func RegisterAdminTopicRoutes(g *gin.RouterGroup, h TopicHandler, jwtAuthMiddleware, allowManagerAndAdminMiddleware gin.HandlerFunc) {
+	protected := g.Group("", jwtAuthMiddleware, allowManagerAndAdminMiddleware)
+	protected.GET("", h.GetTopics)
+}
@@ -3,24 +3,31 @@ package app
 import (
 	"context"
 	"crypto/ecdsa"
+	"crypto/ed25519"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"hackathon-back/internal/msg/inbox"
 	elasticsearch "hackathon-back/pkg/article"
+	"io/fs"
 	"net"
+	"os"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"go.uber.org/zap"
+	"hackathon-contract"
 
 	"hackathon-back/internal/api/http/handler"
 	"hackathon-back/internal/api/http/route"
 	"hackathon-back/internal/apperrors"
 	"hackathon-back/internal/config"
 	"hackathon-back/internal/model"
+	"hackathon-back/internal/msg/heartbeat"
 	"hackathon-back/internal/msg/outbox"
+	"hackathon-back/internal/msg/stream"
 	"hackathon-back/internal/repository"
 	"hackathon-back/internal/service"
 	"hackathon-back/pkg/geoip"
@@ -28,6 +35,7 @@ import (
 	"hackathon-back/pkg/kafka"
 	"hackathon-back/pkg/mailer"
 	"hackathon-back/pkg/postgres"
+	"hackathon-back/pkg/rdns"
 	"hackathon-back/pkg/redis"
 	"hackathon-back/pkg/server"
 )
@@ -213,6 +221,7 @@ type OutboxRepository interface {
 	InsertMessage(ctx context.Context, ext repository.RepoExtension, message model.OutboxMessage) error
 	UpdateAsSent(ctx context.Context, ext repository.RepoExtension, messageID uuid.UUID) error
 	SelectUnsentBatch(ctx context.Context, ext repository.RepoExtension, batchSize int) ([]model.OutboxMessage, error)
+	ClaimUnsentBatch(ctx context.Context, ext repository.RepoExtension, topic string, agentID uuid.UUID, batchSize int) ([]model.OutboxMessage, error)
 }
 
 type InboxRepository interface {
@@ -225,13 +234,158 @@ type Publisher interface {
 	Run(ctx context.Context)
 }
 
+type InboxHandler interface {
+	Handle(ctx context.Context, messageID uuid.UUID, payload []byte) error
+}
+
 type Subscriber interface {
 	Run(ctx context.Context)
 }
 
 type AgentRepository interface {
+	Pool() *pgxpool.Pool
+
 	SelectAgents(ctx context.Context, ext repository.RepoExtension) ([]*model.Agent, error)
-	SelectAgentByRegion(ctx context.Context, ext repository.RepoExtension, region string) (*model.Agent, error)
+	SelectOnlineAgents(ctx context.Context, ext repository.RepoExtension) ([]*model.Agent, error)
+	SelectAgentByID(ctx context.Context, ext repository.RepoExtension, id uuid.UUID) (*model.Agent, error)
+	SelectAgentByGatewayToken(ctx context.Context, ext repository.RepoExtension, id uuid.UUID, tokenHash []byte) (*model.Agent, error)
+	UpdateHeartbeat(ctx context.Context, ext repository.RepoExtension, hb *model.AgentHeartbeat) (wasOnline bool, err error)
+	UpdateStaleAsOffline(ctx context.Context, ext repository.RepoExtension, offlineAfter time.Duration) ([]*model.Agent, error)
+	SelectAgentLatencies(ctx context.Context, ext repository.RepoExtension, asn int, window time.Duration) (map[uuid.UUID]float64, error)
+	InsertAgent(ctx context.Context, ext repository.RepoExtension, agent *model.Agent) error
+	UpdateAgent(ctx context.Context, ext repository.RepoExtension, id uuid.UUID, upd *model.AgentUpdateRequest) (*model.Agent, bool, error)
+	UpdateAgentCredentials(ctx context.Context, ext repository.RepoExtension, id uuid.UUID, publicKey, gatewayTokenHash []byte) error
+	DeleteAgent(ctx context.Context, ext repository.RepoExtension, id uuid.UUID) error
+	SelectAgentsByOwner(ctx context.Context, ext repository.RepoExtension, userID uuid.UUID, orgIDs []uuid.UUID) ([]*model.Agent, error)
+	SelectPoolUsage(ctx context.Context, ext repository.RepoExtension, ownerUserID, ownerOrgID *uuid.UUID) (agents int, regions []string, err error)
+}
+
+type OrganizationRepository interface {
+	Pool() *pgxpool.Pool
+
+	InsertOrganization(ctx context.Context, ext repository.RepoExtension, org *model.Organization) error
+	InsertMember(ctx context.Context, ext repository.RepoExtension, orgID, userID uuid.UUID) error
+	DeleteMember(ctx context.Context, ext repository.RepoExtension, orgID, userID uuid.UUID) error
+	SelectOrganizationsByUser(ctx context.Context, ext repository.RepoExtension, userID uuid.UUID) ([]*model.Organization, error)
+	SelectMembers(ctx context.Context, ext repository.RepoExtension, orgID uuid.UUID) ([]*model.OrganizationMember, error)
+}
+
+type MonitorRepository interface {
+	Pool() *pgxpool.Pool
+
+	InsertMonitor(ctx context.Context, ext repository.RepoExtension, monitor *model.Monitor) error
+	UpdateMonitor(ctx context.Context, ext repository.RepoExtension, monitor *model.Monitor, reschedule bool) error
+	DeleteMonitor(ctx context.Context, ext repository.RepoExtension, id uuid.UUID) error
+	SelectMonitorByID(ctx context.Context, ext repository.RepoExtension, id uuid.UUID) (*model.Monitor, error)
+	SelectMonitorsByUser(ctx context.Context, ext repository.RepoExtension, userID uuid.UUID) ([]*model.Monitor, error)
+	CountMonitorsByUser(ctx context.Context, ext repository.RepoExtension, userID uuid.UUID) (int, error)
+	ClaimDueMonitor(ctx context.Context, ext repository.RepoExtension) (*model.Monitor, error)
+	UpdateMonitorSchedule(ctx context.Context, ext repository.RepoExtension, id uuid.UUID, lastRunAt, nextRunAt time.Time) error
+	InsertMonitorRun(ctx context.Context, ext repository.RepoExtension, run *model.MonitorRun) error
+	SelectMonitorRuns(ctx context.Context, ext repository.RepoExtension, monitorID uuid.UUID, limit int) ([]*model.MonitorRun, error)
+}
+
+type MonitorService interface {
+	CreateMonitor(ctx context.Context, userID uuid.UUID, req model.MonitorCreateRequest) (*model.Monitor, error)
+	GetMonitors(ctx context.Context, userID uuid.UUID) ([]*model.Monitor, error)
+	GetMonitor(ctx context.Context, userID, id uuid.UUID) (*model.Monitor, error)
+	UpdateMonitor(ctx context.Context, userID, id uuid.UUID, req model.MonitorUpdateRequest) (*model.Monitor, error)
+	DeleteMonitor(ctx context.Context, userID, id uuid.UUID) error
+	GetRuns(ctx context.Context, userID, id uuid.UUID, limit int) ([]*model.MonitorRun, error)
+	RunScheduler(ctx context.Context)
+}
+
+type MonitorHandler interface {
+	CreateMonitor(c *gin.Context)
+	GetMonitors(c *gin.Context)
+	GetMonitor(c *gin.Context)
+	UpdateMonitor(c *gin.Context)
+	DeleteMonitor(c *gin.Context)
+	GetMonitorRuns(c *gin.Context)
+}
+
+type OrganizationService interface {
+	CreateOrganization(ctx context.Context, userID uuid.UUID, req model.OrganizationCreateRequest) (*model.Organization, error)
+	GetOrganizations(ctx context.Context, userID uuid.UUID) ([]*model.Organization, error)
+	GetMembers(ctx context.Context, userID, orgID uuid.UUID) ([]*model.OrganizationMember, error)
+	AddMember(ctx context.Context, userID, orgID, memberID uuid.UUID) error
+	RemoveMember(ctx context.Context, userID, orgID, memberID uuid.UUID) error
+	PoolAccess(ctx context.Context, userID uuid.UUID) (*model.PoolAccess, error)
+}
+
+type OrganizationHandler interface {
+	CreateOrganization(c *gin.Context)
+	GetOrganizations(c *gin.Context)
+	GetMembers(c *gin.Context)
+	AddMember(c *gin.Context)
+	RemoveMember(c *gin.Context)
+}
+
+type AgentEnrollmentRepository interface {
+	InsertEnrollment(ctx context.Context, ext repository.RepoExtension, enrollment *model.AgentEnrollment, tokenHash []byte) error
+	ExpireUnusedEnrollments(ctx context.Context, ext repository.RepoExtension, agentID uuid.UUID) error
+	ClaimEnrollment(ctx context.Context, ext repository.RepoExtension, tokenHash []byte, agentVersion, ip string) (*model.AgentEnrollment, error)
+	SelectEnrollments(ctx context.Context, ext repository.RepoExtension, agentID uuid.UUID) ([]*model.AgentEnrollment, error)
+}
+
+type AgentService interface {
+	Heartbeat(ctx context.Context, hb contract.Heartbeat) error
+	RunSweeper(ctx context.Context)
+	GetFleet(ctx context.Context) (*model.FleetResponse, error)
+	GetAgent(ctx context.Context, id uuid.UUID) (*model.Agent, error)
+}
+
+type TopicService interface {
+	EnsureRegionTopic(ctx context.Context, region string) error
+	VerifyTopics(ctx context.Context) error
+	GetTopics(ctx context.Context) ([]*model.TopicHealth, error)
+}
+
+type TopicHandler interface {
+	GetTopics(c *gin.Context)
+}
+
+type AgentRegistryService interface {
+	CreateAgent(ctx context.Context, req model.AgentCreateRequest, access *model.PoolAccess) (*model.AgentCreateResponse, error)
+	GetPoolAgents(ctx context.Context, access *model.PoolAccess) ([]*model.Agent, error)
+	UpdateAgent(ctx context.Context, id uuid.UUID, req model.AgentUpdateRequest, access *model.PoolAccess) (*model.Agent, error)
+	DeleteAgent(ctx context.Context, id uuid.UUID, access *model.PoolAccess) error
+	IssueEnrollmentToken(ctx context.Context, id uuid.UUID, access *model.PoolAccess) (*model.AgentEnrollmentToken, error)
+	GetEnrollments(ctx context.Context, id uuid.UUID, access *model.PoolAccess) ([]*model.AgentEnrollment, error)
+	Enroll(ctx context.Context, req contract.EnrollRequest, ip string) (*contract.EnrollResponse, error)
+}
+
+type AgentHandler interface {
+	GetFleet(c *gin.Context)
+	GetAgent(c *gin.Context)
+	CreateAgent(c *gin.Context)
+	UpdateAgent(c *gin.Context)
+	DeleteAgent(c *gin.Context)
+	IssueEnrollmentToken(c *gin.Context)
+	GetEnrollments(c *gin.Context)
+	Enroll(c *gin.Context)
+
+	GetPoolAgents(c *gin.Context)
+	CreatePoolAgent(c *gin.Context)
+	UpdatePoolAgent(c *gin.Context)
+	DeletePoolAgent(c *gin.Context)
+	IssuePoolEnrollmentToken(c *gin.Context)
+	GetPoolEnrollments(c *gin.Context)
+}
+
+type GatewayService interface {
+	Poll(ctx context.Context, agentID uuid.UUID, wait time.Duration) ([]contract.GatewayMessage, error)
+	Publish(ctx context.Context, agentID uuid.UUID, msg contract.GatewayMessage) error
+	Authenticate(ctx context.Context, agentID uuid.UUID, token string) error
+}
+
+type StreamHandler interface {
+	Connect(c *gin.Context)
+}
+
+type GatewayHandler interface {
+	PollTasks(c *gin.Context)
+	PublishMessage(c *gin.Context)
 }
 
 type RequestRepository interface {
@@ -240,18 +394,65 @@ type RequestRepository interface {
 	SelectResultsByRequestID(ctx context.Context, ext repository.RepoExtension, requestID uuid.UUID) ([]model.CheckResultResponse, error)
 	InsertRequest(ctx context.Context, ext repository.RepoExtension, request *model.Request) error
 	InsertAssignment(ctx context.Context, ext repository.RepoExtension, assignment *model.Assignment) error
-	InsertCheckResult(ctx context.Context, ext repository.RepoExtension, checkResult *model.CheckResult) error
+	InsertCheckResult(ctx context.Context, ext repository.RepoExtension, checkResult *model.CheckResult) (inserted bool, err error)
+	UpsertCheckProgress(ctx context.Context, ext repository.RepoExtension, progress *model.CheckProgress) (updated bool, err error)
+	SelectCheckProgressPayload(ctx context.Context, ext repository.RepoExtension, assignmentID uuid.UUID, checkIndex int) (json.RawMessage, error)
+	FinishCheckProgress(ctx context.Context, ext repository.RepoExtension, assignmentID uuid.UUID, checkIndex int, checkType string) error
+	SelectProgressByRequestID(ctx context.Context, ext repository.RepoExtension, requestID uuid.UUID) ([]model.CheckProgress, error)
+	SelectAgentAssignment(ctx context.Context, ext repository.RepoExtension, assignmentID, requestID, agentID uuid.UUID) (*model.Assignment, error)
+	SelectRequestStatus(ctx context.Context, ext repository.RepoExtension, requestID uuid.UUID) (string, error)
+	UpdateAssignmentStatus(ctx context.Context, ext repository.RepoExtension, id uuid.UUID, to string, from []string, errorText string) (requestID uuid.UUID, changed bool, err error)
+	UpdateAssignmentStatusByOutboxID(ctx context.Context, ext repository.RepoExtension, outboxID uuid.UUID, to string, from []string) (requestID uuid.UUID, changed bool, err error)
+	CompleteAssignment(ctx context.Context, ext repository.RepoExtension, id uuid.UUID, from []string) (requestID uuid.UUID, status string, changed bool, err error)
+	UpdateOverdueAssignments(ctx context.Context, ext repository.RepoExtension, grace time.Duration, from []string) ([]*model.Assignment, error)
+	UpdateRequestStatus(ctx context.Context, ext repository.RepoExtension, requestID uuid.UUID) (status string, changed bool, err error)
+}
+
+// EnvelopeService подпись задач и проверка подписанных сообщений агентов.
+type EnvelopeService interface {
+	SealTask(payload []byte, timeout time.Duration) ([]byte, error)
+	OpenHeartbeat(ctx context.Context, data []byte) (contract.Heartbeat, error)
+	OpenResult(ctx context.Context, data []byte) (contract.CheckResult, *model.Assignment, error)
 }
 
 type RequestService interface {
-	CreateRequest(ctx context.Context, req model.TaskMessageRequest, ip net.IP, ua string) (*model.Request, error)
+	CreateRequest(ctx context.Context, req model.TaskMessageRequest, ip net.IP, ua string, userID uuid.UUID) (*model.Request, error)
+	GetLocations(ctx context.Context, userID uuid.UUID) ([]*model.Location, error)
+	ValidateRequest(ctx context.Context, req model.TaskMessageRequest, userID uuid.UUID) error
+	CreateMonitorRequest(ctx context.Context, tx repository.RepoExtension, monitor *model.Monitor) (*model.Request, error)
 	GetResultsByRequestID(ctx context.Context, requestID uuid.UUID) ([]model.CheckResultResponse, error)
+	GetAgentResultsByRequestID(ctx context.Context, requestID uuid.UUID) ([]model.AgentResults, error)
+	GetProgressByRequestID(ctx context.Context, requestID uuid.UUID) ([]model.CheckProgress, error)
+	GetRequestStatus(ctx context.Context, requestID uuid.UUID) (string, error)
+}
+
+type LifecycleService interface {
+	Dispatched(ctx context.Context, outboxID uuid.UUID) error
+	Acknowledged(ctx context.Context, assignmentID uuid.UUID) error
+	ResultRecorded(ctx context.Context, tx repository.RepoExtension, assignmentID uuid.UUID) (requestStatus string, changed bool, err error)
+	RunSweeper(ctx context.Context)
+}
+
+// StreamHub события по топикам для websocket, общие для всех реплик, см. stream.Hub.
+type StreamHub interface {
+	Publish(ctx context.Context, event model.StreamEvent) error
+	Subscribe(topic string) (events <-chan model.StreamEvent, unsubscribe func())
+	Seq(ctx context.Context, topic string) (int64, error)
+	Replay(ctx context.Context, topic string, afterSeq int64) (events []model.StreamEvent, complete bool, err error)
+	Run(ctx context.Context)
+}
+
+type EnrichmentService interface {
+	EnrichResult(ctx context.Context, assignmentID uuid.UUID, res *contract.CheckResult) error
 }
 
 type RequestHandler interface {
 	CreateRequest(c *gin.Context)
+	GetLocations(c *gin.Context)
 	GetResults(c *gin.Context)
+	GetAgentResults(c *gin.Context)
 	StreamResults(c *gin.Context)
+	StreamResultEvents(c *gin.Context)
 }
 
 type App struct {
@@ -265,7 +466,9 @@ type App struct {
 	Mailer     mailer.Mailer
 	HTTPServer server.HTTPServer
 	EBus       *EBus
+	StreamHub  StreamHub
 	GeoDB      geoip.GeoIP
+	KafkaAdmin kafka.Admin
 }
 
 // ДОБАВИТЬ FAQRepository в структуру Repository
@@ -280,38 +483,60 @@ type Repository struct {
 	InboxRepository   InboxRepository
 	AgentRepository   AgentRepository
 	RequestRepository RequestRepository
+
+	AgentEnrollmentRepository AgentEnrollmentRepository
+	OrganizationRepository    OrganizationRepository
+	MonitorRepository         MonitorRepository
 }
 
 // ДОБАВИТЬ FAQService в структуру Service
 type Service struct {
-	HealthService  HealthService
-	AuthService    AuthService
-	UserService    *service.UserService
-	RequestService RequestService
-	ArticleService ArticleService
-	APIKeyService  APIKeyService
-	FAQService     FAQService // ДОБАВИТЬ
+	HealthService       HealthService
+	AuthService         AuthService
+	UserService         *service.UserService
+	RequestService      RequestService
+	EnrichmentService   EnrichmentService
+	EnvelopeService     EnvelopeService
+	LifecycleService    LifecycleService
+	AgentService        AgentService
+	AgentRegistry       AgentRegistryService
+	OrganizationService OrganizationService
+	MonitorService      MonitorService
+	TopicService        TopicService
+	GatewayService      GatewayService
+	ArticleService      ArticleService
+	APIKeyService       APIKeyService
+	FAQService          FAQService // ДОБАВИТЬ
 }
 
 // ДОБАВИТЬ FAQHandler в структуру Handler
 type Handler struct {
-	RequestHandler RequestHandler
-	HealthHandler  HealthHandler
-	AuthHandler    AuthHandler
-	UserHandler    UserHandler
-	ArticleHandler ArticleHandler
-	APIKeyHandler  APIKeyHandler
-	FAQHandler     FAQHandler // ДОБАВИТЬ
+	RequestHandler      RequestHandler
+	AgentHandler        AgentHandler
+	OrganizationHandler OrganizationHandler
+	MonitorHandler      MonitorHandler
+	TopicHandler        TopicHandler
+	GatewayHandler      GatewayHandler
+	StreamHandler       StreamHandler
+	HealthHandler       HealthHandler
+	AuthHandler         AuthHandler
+	UserHandler         UserHandler
+	ArticleHandler      ArticleHandler
+	APIKeyHandler       APIKeyHandler
+	FAQHandler          FAQHandler // ДОБАВИТЬ
 }
 
 type Security struct {
-	PrivateKey *ecdsa.PrivateKey
-	PublicKey  *ecdsa.PublicKey
+	PrivateKey     *ecdsa.PrivateKey
+	PublicKey      *ecdsa.PublicKey
+	TaskSigningKey ed25519.PrivateKey // TaskSigningKey ключ подписи задач агентам
 }
 
 type EBus struct {
-	OutboxPublisher Publisher
-	InboxSubscriber Subscriber
+	OutboxPublisher     Publisher
+	InboxSubscriber     Subscriber
+	InboxHandler        InboxHandler // InboxHandler тот же inbox subscriber, через него gateway пишет результаты http агентов
+	HeartbeatSubscriber Subscriber
 }
 
 func New(cfg *config.Config, log *zap.Logger) (*App, error) {
@@ -330,7 +555,7 @@ func New(cfg *config.Config, log *zap.Logger) (*App, error) {
 		return nil, fmt.Errorf("failed to initialize redis: %w", err)
 	}
 
-	sec, err := initSecurity(log, cfg.Key)
+	sec, err := initSecurity(log, cfg.Key, &cfg.Signing)
 	if err != nil {
 		log.Error("Failed to initialize security", zap.Error(err))
 		return nil, fmt.Errorf("failed to initialize security: %w", err)
@@ -356,17 +581,38 @@ func New(cfg *config.Config, log *zap.Logger) (*App, error) {
 		return nil, fmt.Errorf("failed to initialize geo: %w", err)
 	}
 
-	svc := initService(log, &cfg.JWT, sec, repo, mlr, rdb, geo)
+	ptr := initPTR(log, &cfg.Geo)
+
+	hub := initStreamHub(log, &cfg.Stream, rdb)
+
+	routers, err := initRouters(log, &cfg.Routing, repo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize routers: %w", err)
+	}
+
+	kafkaAdmin, err := initKafkaAdmin(log, &cfg.Kafka)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize kafka admin: %w", err)
+	}
 
-	hdl := initHandler(log, &cfg.JWT, svc)
+	svc := initService(log, &cfg.JWT, &cfg.Presence, &cfg.Signing, &cfg.Lifecycle, &cfg.Kafka, &cfg.Monitors, &cfg.Pools, sec, repo, mlr, rdb, hub, geo, ptr, routers, kafkaAdmin, enrollmentSettings(cfg, sec))
 
-	httpServer := initHTTPServer(log, cfg, sec.PublicKey, hdl, repo)
+	if err := svc.TopicService.VerifyTopics(ctx); err != nil {
+		log.Error("Failed to verify kafka topics", zap.Error(err))
+		return nil, fmt.Errorf("failed to verify kafka topics: %w", err)
+	}
 
-	eBus, err := initEBus(log, &cfg.Kafka, repo)
+	eBus, err := initEBus(log, &cfg.Kafka, repo, svc.EnrichmentService, svc.EnvelopeService, svc.AgentService, svc.LifecycleService, hub)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize ebus: %w", err)
 	}
 
+	svc.GatewayService = initGatewayService(log, &cfg.Gateway, &cfg.Kafka, repo, svc.EnvelopeService, svc.AgentService, svc.LifecycleService, eBus.InboxHandler)
+
+	hdl := initHandler(log, &cfg.JWT, &cfg.Stream, svc, hub)
+
+	httpServer := initHTTPServer(log, cfg, sec.PublicKey, hdl, repo, svc)
+
 	return &App{
 		Cfg:        cfg,
 		Log:        log,
@@ -377,7 +623,9 @@ func New(cfg *config.Config, log *zap.Logger) (*App, error) {
 		Mailer:     mlr,
 		HTTPServer: httpServer,
 		EBus:       eBus,
+		StreamHub:  hub,
 		GeoDB:      geo,
+		KafkaAdmin: kafkaAdmin,
 	}, nil
 }
 
@@ -407,6 +655,26 @@ func (a *App) Run(ctx context.Context) error {
 		a.EBus.InboxSubscriber.Run(ctx)
 	}()
 
+	go func() {
+		a.EBus.HeartbeatSubscriber.Run(ctx)
+	}()
+
+	go func() {
+		a.Service.AgentService.RunSweeper(ctx)
+	}()
+
+	go func() {
+		a.Service.LifecycleService.RunSweeper(ctx)
+	}()
+
+	go func() {
+		a.Service.MonitorService.RunScheduler(ctx)
+	}()
+
+	go func() {
+		a.StreamHub.Run(ctx)
+	}()
+
 	if err := <-errs; err != nil {
 		return err
 	}
@@ -433,11 +701,17 @@ func (a *App) Shutdown() error {
 	a.Log.Debug("Http server shutdown")
 
 	if geoErr := a.GeoDB.Close(); geoErr != nil {
-		err = fmt.Errorf("%w, failed to close GeoDB: %w", err, &geoErr)
+		err = fmt.Errorf("%w, failed to close GeoDB: %w", err, geoErr)
 	}
 
 	a.Log.Debug("GeoDB closed")
 
+	if adminErr := a.KafkaAdmin.Close(); adminErr != nil {
+		err = fmt.Errorf("%w, failed to close kafka admin: %w", err, adminErr)
+	}
+
+	a.Log.Debug("Kafka admin closed")
+
 	if !errors.Is(err, apperrors.ErrShutdown) {
 		return err
 	}
@@ -519,7 +793,7 @@ func initElastic(log *zap.Logger, cfg *config.Elastic) (elasticsearch.Elasticsea
 	return client, nil
 }
 
-func initSecurity(log *zap.Logger, cfg config.Key) (*Security, error) {
+func initSecurity(log *zap.Logger, cfg config.Key, signingCfg *config.Signing) (*Security, error) {
 	privateKey, err := jwt.LoadECDSAPrivateKey(cfg.PrivateKey)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load private key: %w", err)
@@ -534,14 +808,26 @@ func initSecurity(log *zap.Logger, cfg config.Key) (*Security, error) {
 
 	log.Debug("Public key loaded")
 
+	taskSigningKey, generated, err := contract.LoadOrGenerateKey(signingCfg.PrivateKey, signingCfg.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load task signing key: %w", err)
+	}
+
+	if generated {
+		log.Warn("Task signing key generated, distribute the public key to agents", zap.String("public_key", signingCfg.PublicKey))
+	}
+
+	log.Debug("Task signing key loaded")
+
 	return &Security{
-		PrivateKey: privateKey,
-		PublicKey:  publicKey,
+		PrivateKey:     privateKey,
+		PublicKey:      publicKey,
+		TaskSigningKey: taskSigningKey,
 	}, nil
 }
 
 // ОБНОВИТЬ initHandler - добавить FAQHandler
-func initHandler(log *zap.Logger, jwtCfg *config.JWT, svc *Service) *Handler {
+func initHandler(log *zap.Logger, jwtCfg *config.JWT, streamCfg *config.Stream, svc *Service, hub StreamHub) *Handler {
 	healthHandler := handler.NewHealthHandler(log, svc.HealthService)
 	log.Debug("Health handler initialized")
 
@@ -561,17 +847,41 @@ func initHandler(log *zap.Logger, jwtCfg *config.JWT, svc *Service) *Handler {
 	faqHandler := handler.NewFAQHandler(svc.FAQService)
 	log.Debug("FAQ handler initialized")
 
-	requestHandler := handler.NewRequestHandler(log, svc.RequestService)
+	requestHandler := handler.NewRequestHandler(log, svc.RequestService, hub, streamCfg.ResyncInterval)
 	log.Debug("Request handler initialized")
 
+	agentHandler := handler.NewAgentHandler(svc.AgentService, svc.AgentRegistry, svc.OrganizationService)
+	log.Debug("Agent handler initialized")
+
+	organizationHandler := handler.NewOrganizationHandler(svc.OrganizationService)
+	log.Debug("Organization handler initialized")
+
+	monitorHandler := handler.NewMonitorHandler(svc.MonitorService)
+	log.Debug("Monitor handler initialized")
+
+	topicHandler := handler.NewTopicHandler(svc.TopicService)
+	log.Debug("Topic handler initialized")
+
+	gatewayHandler := handler.NewGatewayHandler(log, svc.GatewayService)
+	log.Debug("Gateway handler initialized")
+
+	streamHandler := handler.NewStreamHandler(log, svc.RequestService, svc.AgentService, svc.MonitorService, hub, streamCfg.HeartbeatInterval)
+	log.Debug("Stream handler initialized")
+
 	return &Handler{
-		RequestHandler: requestHandler,
-		HealthHandler:  healthHandler,
-		AuthHandler:    authHandler,
-		UserHandler:    userHandler,
-		ArticleHandler: articleHandler,
-		APIKeyHandler:  apiKeyHandler,
-		FAQHandler:     faqHandler, // ДОБАВИТЬ
+		RequestHandler:      requestHandler,
+		AgentHandler:        agentHandler,
+		OrganizationHandler: organizationHandler,
+		MonitorHandler:      monitorHandler,
+		TopicHandler:        topicHandler,
+		GatewayHandler:      gatewayHandler,
+		StreamHandler:       streamHandler,
+		HealthHandler:       healthHandler,
+		AuthHandler:         authHandler,
+		UserHandler:         userHandler,
+		ArticleHandler:      articleHandler,
+		APIKeyHandler:       apiKeyHandler,
+		FAQHandler:          faqHandler, // ДОБАВИТЬ
 	}
 }
 
@@ -579,11 +889,22 @@ func initHandler(log *zap.Logger, jwtCfg *config.JWT, svc *Service) *Handler {
 func initService(
 	log *zap.Logger,
 	jwtCfg *config.JWT,
+	presenceCfg *config.Presence,
+	signingCfg *config.Signing,
+	lifecycleCfg *config.Lifecycle,
+	kafkaCfg *config.Kafka,
+	monitorsCfg *config.Monitors,
+	poolsCfg *config.Pools,
 	sec *Security,
 	repo *Repository,
 	mlr mailer.Mailer,
 	rdb redis.Redis,
+	hub StreamHub,
 	geoDB geoip.GeoIP,
+	ptr *rdns.Resolver,
+	routers *service.Routers,
+	kafkaAdmin kafka.Admin,
+	enrollment service.EnrollmentSettings,
 ) *Service {
 	healthSvc := service.NewHealthService(log, repo.HealthRepository)
 	log.Debug("Health service initialized")
@@ -594,9 +915,50 @@ func initService(
 	userSvc := service.NewUserService(repo.UserRepository, mlr)
 	log.Debug("User service initialized")
 
-	requestSvc := service.NewRequestService(log, repo.RequestRepository, repo.OutboxRepository, repo.AgentRepository, geoDB)
+	envelopeSvc := service.NewEnvelopeService(sec.TaskSigningKey, signingCfg.TaskTTL, repo.AgentRepository, repo.RequestRepository)
+	log.Debug("Envelope service initialized")
+
+	organizationSvc := service.NewOrganizationService(log, repo.OrganizationRepository)
+	log.Debug("Organization service initialized")
+
+	requestSvc := service.NewRequestService(log, repo.RequestRepository, repo.OutboxRepository, repo.AgentRepository, envelopeSvc, geoDB, routers, organizationSvc)
 	log.Debug("Request service initialized")
 
+	lifecycleSvc := service.NewLifecycleService(log, repo.RequestRepository, hub, lifecycleCfg.Grace, lifecycleCfg.SweepInterval)
+	log.Debug("Lifecycle service initialized")
+
+	enrichmentSvc := service.NewEnrichmentService(log, repo.RequestRepository, geoDB, ptr)
+	log.Debug("Enrichment service initialized")
+
+	agentSvc := service.NewAgentService(log, repo.AgentRepository, hub, presenceCfg.OfflineAfter, presenceCfg.SweepInterval)
+	log.Debug("Agent service initialized")
+
+	topicSvc := service.NewTopicService(log, kafkaAdmin, repo.AgentRepository, service.TopicSettings{
+		AutoCreate: kafkaCfg.Topics.AutoCreate,
+		Spec: kafka.TopicSpec{
+			Partitions:        kafkaCfg.Topics.Partitions,
+			ReplicationFactor: kafkaCfg.Topics.ReplicationFactor,
+			Retention:         kafkaCfg.Topics.Retention,
+		},
+		ResultsTopic:   kafkaCfg.Subscriber.Topic,
+		HeartbeatTopic: kafkaCfg.Heartbeat.Topic,
+	})
+	log.Debug("Topic service initialized")
+
+	registrySvc := service.NewAgentRegistryService(log, repo.AgentRepository, repo.AgentEnrollmentRepository, hub, topicSvc, enrollment, service.PoolLimits{
+		MaxAgents:  poolsCfg.MaxAgentsPerOwner,
+		MaxRegions: poolsCfg.MaxRegionsPerOwner,
+	})
+	log.Debug("Agent registry service initialized")
+
+	monitorSvc := service.NewMonitorService(log, repo.MonitorRepository, requestSvc, hub, service.MonitorSettings{
+		PollInterval: monitorsCfg.PollInterval,
+		BatchSize:    monitorsCfg.BatchSize,
+		MinInterval:  monitorsCfg.MinInterval,
+		MaxPerUser:   monitorsCfg.MaxPerUser,
+	})
+	log.Debug("Monitor service initialized")
+
 	articleSvc := service.NewArticleService(repo.ArticleRepository)
 	log.Debug("Article service initialized")
 
@@ -608,13 +970,21 @@ func initService(
 	log.Debug("FAQ service initialized")
 
 	return &Service{
-		RequestService: requestSvc,
-		HealthService:  healthSvc,
-		AuthService:    authSvc,
-		UserService:    userSvc,
-		ArticleService: articleSvc,
-		APIKeyService:  apiKeySvc,
-		FAQService:     faqSvc, // ДОБАВИТЬ
+		RequestService:      requestSvc,
+		EnrichmentService:   enrichmentSvc,
+		EnvelopeService:     envelopeSvc,
+		LifecycleService:    lifecycleSvc,
+		AgentService:        agentSvc,
+		AgentRegistry:       registrySvc,
+		OrganizationService: organizationSvc,
+		MonitorService:      monitorSvc,
+		TopicService:        topicSvc,
+		HealthService:       healthSvc,
+		AuthService:         authSvc,
+		UserService:         userSvc,
+		ArticleService:      articleSvc,
+		APIKeyService:       apiKeySvc,
+		FAQService:          faqSvc, // ДОБАВИТЬ
 	}
 }
 
@@ -641,6 +1011,15 @@ func initRepository(log *zap.Logger, db postgres.Postgres, es elasticsearch.Elas
 	agentRepo := repository.NewAgentRepository(db.Pool())
 	log.Debug("Agent repository initialized")
 
+	agentEnrollmentRepo := repository.NewAgentEnrollmentRepository(db.Pool())
+	log.Debug("Agent enrollment repository initialized")
+
+	organizationRepo := repository.NewOrganizationRepository(db.Pool())
+	log.Debug("Organization repository initialized")
+
+	monitorRepo := repository.NewMonitorRepository(db.Pool())
+	log.Debug("Monitor repository initialized")
+
 	articleRepo := repository.NewElasticRepository(es.Client())
 	log.Debug("Article repository initialized")
 
@@ -662,11 +1041,15 @@ func initRepository(log *zap.Logger, db postgres.Postgres, es elasticsearch.Elas
 		ArticleRepository: articleRepo,
 		APIKeyRepository:  apiKeyRepo,
 		FAQRepository:     faqRepo, // ДОБАВИТЬ
+
+		AgentEnrollmentRepository: agentEnrollmentRepo,
+		OrganizationRepository:    organizationRepo,
+		MonitorRepository:         monitorRepo,
 	}
 }
 
 // ОБНОВИТЬ initHTTPServer - добавить FAQHandler в вызов SetupRouter
-func initHTTPServer(log *zap.Logger, cfg *config.Config, publicKey *ecdsa.PublicKey, hdl *Handler, repo *Repository) server.HTTPServer {
+func initHTTPServer(log *zap.Logger, cfg *config.Config, publicKey *ecdsa.PublicKey, hdl *Handler, repo *Repository, svc *Service) server.HTTPServer {
 	router := route.SetupRouter(
 		log,
 		cfg,
@@ -678,6 +1061,13 @@ func initHTTPServer(log *zap.Logger, cfg *config.Config, publicKey *ecdsa.Public
 		repo.APIKeyRepository,
 		hdl.FAQHandler,
 		hdl.RequestHandler,
+		hdl.AgentHandler,
+		hdl.OrganizationHandler,
+		hdl.MonitorHandler,
+		hdl.TopicHandler,
+		hdl.GatewayHandler,
+		svc.GatewayService,
+		hdl.StreamHandler,
 	)
 
 	httpServer := server.NewHTTPServer(
@@ -689,7 +1079,27 @@ func initHTTPServer(log *zap.Logger, cfg *config.Config, publicKey *ecdsa.Public
 	return httpServer
 }
 
-func initEBus(log *zap.Logger, cfg *config.Kafka, repo *Repository) (*EBus, error) {
+func initKafkaAdmin(log *zap.Logger, cfg *config.Kafka) (kafka.Admin, error) {
+	admin, err := kafka.NewAdmin(cfg.Brokers)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Debug("Kafka admin initialized")
+
+	return admin, nil
+}
+
+func initEBus(
+	log *zap.Logger,
+	cfg *config.Kafka,
+	repo *Repository,
+	enricher EnrichmentService,
+	envelopes EnvelopeService,
+	presence AgentService,
+	lifecycle LifecycleService,
+	events StreamHub,
+) (*EBus, error) {
 	producer, err := kafka.NewProducer(
 		cfg.Brokers,
 		kafka.WithBalancer(kafka.RoundRobin),
@@ -713,6 +1123,7 @@ func initEBus(log *zap.Logger, cfg *config.Kafka, repo *Repository) (*EBus, erro
 		outboxCfg,
 		producer,
 		repo.OutboxRepository,
+		lifecycle,
 	)
 
 	log.Debug("Outbox publisher initialized")
@@ -747,16 +1158,112 @@ func initEBus(log *zap.Logger, cfg *config.Kafka, repo *Repository) (*EBus, erro
 		consumerGroup,
 		repo.InboxRepository,
 		repo.RequestRepository,
+		envelopes,
+		enricher,
+		lifecycle,
+		events,
+	)
+
+	log.Debug("Inbox subscriber initialized")
+
+	heartbeatConsumer, err := kafka.NewConsumerGroupRunner(
+		cfg.Brokers,
+		cfg.Heartbeat.GroupID,
+		[]string{cfg.Heartbeat.Topic},
+		consumerBufferSize,
+		kafka.WithBalancerConsumer(kafka.RoundrobinBalanceStrategy),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create heartbeat consumer group: %w", err)
+	}
+
+	go func() {
+		startAndRunningStr := <-heartbeatConsumer.Info()
+
+		log.Info(startAndRunningStr)
+	}()
+
+	heartbeatCfg := heartbeat.Config{
+		Name:  cfg.Heartbeat.Name,
+		Topic: cfg.Heartbeat.Topic,
+	}
+
+	heartbeatSubscriber := heartbeat.NewSubscriber(
+		log,
+		heartbeatCfg,
+		heartbeatConsumer,
+		envelopes,
+		presence,
 	)
 
+	log.Debug("Heartbeat subscriber initialized")
+
 	return &EBus{
-		OutboxPublisher: publisher,
-		InboxSubscriber: subscriber,
+		OutboxPublisher:     publisher,
+		InboxSubscriber:     subscriber,
+		InboxHandler:        subscriber,
+		HeartbeatSubscriber: heartbeatSubscriber,
 	}, err
 }
 
+// initGatewayService HTTP транспорт агентов пишет результаты и heartbeat туда же, куда их пишут подписчики Kafka.
+func initGatewayService(
+	log *zap.Logger,
+	cfg *config.Gateway,
+	kafkaCfg *config.Kafka,
+	repo *Repository,
+	envelopes EnvelopeService,
+	presence AgentService,
+	lifecycle LifecycleService,
+	inboxHandler InboxHandler,
+) *service.GatewayService {
+	gatewaySvc := service.NewGatewayService(
+		log,
+		repo.OutboxRepository,
+		repo.AgentRepository,
+		inboxHandler,
+		envelopes,
+		presence,
+		lifecycle,
+		kafkaCfg.Subscriber.Topic,
+		kafkaCfg.Heartbeat.Topic,
+		cfg.MaxWait,
+		cfg.PollInterval,
+		cfg.BatchSize,
+	)
+
+	log.Debug("Gateway service initialized", zap.Bool("enabled", cfg.Enabled))
+
+	return gatewaySvc
+}
+
+func initStreamHub(log *zap.Logger, cfg *config.Stream, rdb redis.Redis) *stream.Hub {
+	hub := stream.NewHub(log, stream.Config{
+		Channel:     cfg.Channel,
+		BufferSize:  cfg.BufferSize,
+		HistorySize: cfg.HistorySize,
+		HistoryTTL:  cfg.HistoryTTL,
+	}, rdb)
+
+	log.Debug("Stream hub initialized")
+
+	return hub
+}
+
 func initGeo(log *zap.Logger, cfg *config.Geo) (geoip.GeoIP, error) {
-	geo, err := geoip.NewGeo(cfg.GeoLiteCountryPath, cfg.GeoLiteASNPath)
+	// City база нужна только для координат и городов хопов: без файла бэкенд работает без них
+	cityPath := cfg.GeoLiteCityPath
+	if cityPath != "" {
+		if _, err := os.Stat(cityPath); errors.Is(err, fs.ErrNotExist) {
+			log.Warn("GeoLite2-City database not found, hops are enriched without coordinates and city",
+				zap.String("path", cityPath),
+			)
+
+			cityPath = ""
+		}
+	}
+
+	geo, err := geoip.NewGeo(cfg.GeoLiteCountryPath, cfg.GeoLiteASNPath, cityPath)
 	if err != nil {
 		return geo, fmt.Errorf("failed to init geoip: %w", err)
 	}
@@ -765,3 +1272,44 @@ func initGeo(log *zap.Logger, cfg *config.Geo) (geoip.GeoIP, error) {
 
 	return geo, nil
 }
+
+// enrollmentSettings то, что агент получает при регистрации: топики и брокеры бэкенда, собственный токен gateway,
+// если он включён, и ключ, которым подписываются задачи.
+func enrollmentSettings(cfg *config.Config, sec *Security) service.EnrollmentSettings {
+	settings := service.EnrollmentSettings{
+		TokenTTL:         cfg.Enrollment.TokenTTL,
+		ResultsTopic:     cfg.Kafka.Subscriber.Topic,
+		HeartbeatTopic:   cfg.Kafka.Heartbeat.Topic,
+		Brokers:          cfg.Kafka.Brokers,
+		GatewayEnabled:   cfg.Gateway.Enabled,
+		BackendPublicKey: sec.TaskSigningKey.Public().(ed25519.PublicKey),
+	}
+
+	return settings
+}
+
+func initRouters(log *zap.Logger, cfg *config.Routing, repo *Repository) (*service.Routers, error) {
+	geo := service.NewGeoRouter()
+
+	routers, err := service.NewRouters(cfg.DefaultStrategy,
+		geo,
+		service.NewRoundRobinRouter(),
+		service.NewLeastLoadedRouter(),
+		service.NewLatencyRouter(repo.AgentRepository, cfg.LatencyWindow, geo),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Debug("Routers initialized", zap.String("default_strategy", cfg.DefaultStrategy))
+
+	return routers, nil
+}
+
+func initPTR(log *zap.Logger, cfg *config.Geo) *rdns.Resolver {
+	ptr := rdns.NewResolver(cfg.PTRTimeout, cfg.PTRCacheTTL, cfg.PTRCacheSize)
+
+	log.Debug("PTR resolver initialized")
+
+	return ptr
+}
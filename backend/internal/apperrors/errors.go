@@ -25,4 +25,39 @@ var (
 
 	ErrFAQAlreadyExists = errors.New("faq already exists")
 	ErrFAQNotFound      = errors.New("faq does not exist")
+
+	ErrRequestDoesNotExist       = errors.New("request does not exist")
+	ErrCheckProgressDoesNotExist = errors.New("check progress does not exist")
+
+	ErrUnknownStreamTopic   = errors.New("unknown stream topic")
+	ErrStreamTopicForbidden = errors.New("not allowed to subscribe to stream topic")
+	ErrTooManyStreamTopics  = errors.New("too many stream subscriptions")
+
+	ErrAgentDoesNotExist  = errors.New("agent does not exist")
+	ErrNoOnlineAgents     = errors.New("no online agents")
+	ErrNoCapableAgents    = errors.New("no online agent can run the requested checks")
+	ErrAgentDisabled      = errors.New("agent is disabled")
+	ErrAgentNotRegistered = errors.New("agent is not registered or has no enrolled key")
+
+	ErrEnrollmentTokenInvalid = errors.New("enrollment token is invalid, expired or already used")
+
+	ErrOrganizationDoesNotExist = errors.New("organization does not exist")
+	ErrMemberAlreadyExists      = errors.New("user is already a member of the organization")
+	ErrMemberDoesNotExist       = errors.New("user is not a member of the organization")
+	ErrAgentPoolForbidden       = errors.New("private agents can only be targeted by their owner")
+	ErrAgentLimitExceeded       = errors.New("private agent limit exceeded")
+	ErrAgentPoolLimitExceeded   = errors.New("private agent pool limit exceeded")
+
+	ErrMonitorDoesNotExist  = errors.New("monitor does not exist")
+	ErrMonitorLimitExceeded = errors.New("monitor limit exceeded")
+
+	ErrTopicDoesNotExist = errors.New("kafka topic does not exist")
+
+	ErrUnknownGatewayTopic   = errors.New("unknown gateway topic")
+	ErrInvalidAgentMessage   = errors.New("invalid agent message")
+	ErrAgentIdentityMismatch = errors.New("message agent id does not match authenticated agent")
+	ErrInvalidAgentSignature = errors.New("invalid agent message signature")
+	ErrAgentKeyNotRegistered = errors.New("agent public key is not registered")
+	ErrInvalidAgentToken     = errors.New("invalid agent gateway token")
+	ErrAgentNotAssigned      = errors.New("agent is not assigned to the task")
 )
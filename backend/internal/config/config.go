@@ -11,7 +11,10 @@ import (
 	"gopkg.in/yaml.v3"
 )
 
-var ErrConfigPathIsEmpty = errors.New("config path is empty")
+var (
+	ErrConfigPathIsEmpty = errors.New("config path is empty")
+	ErrInvalidConfig     = errors.New("invalid config")
+)
 
 type Config struct {
 	App        `yaml:"app"`
@@ -24,6 +27,15 @@ type Config struct {
 	Kafka      `yaml:"kafka"`
 	Elastic    `yaml:"elastic"`
 	Geo        `yaml:"geo"`
+	Presence   `yaml:"presence"`
+	Gateway    `yaml:"gateway"`
+	Signing    `yaml:"signing"`
+	Lifecycle  `yaml:"lifecycle"`
+	Stream     `yaml:"stream"`
+	Routing    `yaml:"routing"`
+	Enrollment `yaml:"enrollment"`
+	Pools      `yaml:"pools"`
+	Monitors   `yaml:"monitors"`
 }
 
 type App struct {
@@ -130,6 +142,8 @@ type Kafka struct {
 	Brokers    []string   `yaml:"brokers"`
 	Subscriber Subscriber `yaml:"subscriber"`
 	Producer   Producer   `yaml:"producer"`
+	Heartbeat  Heartbeat  `yaml:"heartbeat"`
+	Topics     Topics     `yaml:"topics"`
 }
 
 type Subscriber struct {
@@ -146,9 +160,101 @@ type Producer struct {
 	BatchSize    int           `yaml:"batch_size"`
 }
 
+type Heartbeat struct {
+	Name    string `yaml:"name"`
+	Topic   string `yaml:"topic"`
+	GroupID string `yaml:"group_id"`
+}
+
+// Topics с AutoCreate бэкенд создаёт топик задач региона при заведении в нём агента, а при старте —
+// недостающие топики результатов, heartbeat и регионов известных агентов. Retention 0 — по умолчанию брокера.
+type Topics struct {
+	AutoCreate        bool          `yaml:"auto_create"`
+	Partitions        int32         `yaml:"partitions"`
+	ReplicationFactor int16         `yaml:"replication_factor"`
+	Retention         time.Duration `yaml:"retention"`
+}
+
 type Geo struct {
-	GeoLiteCountryPath string `yaml:"geo_lite_country_path"`
-	GeoLiteASNPath     string `yaml:"geo_lite_asn_path"`
+	GeoLiteCountryPath string        `yaml:"geo_lite_country_path"`
+	GeoLiteASNPath     string        `yaml:"geo_lite_asn_path"`
+	GeoLiteCityPath    string        `yaml:"geo_lite_city_path"`
+	PTRTimeout         time.Duration `yaml:"ptr_timeout"`
+	PTRCacheTTL        time.Duration `yaml:"ptr_cache_ttl"`
+	PTRCacheSize       int           `yaml:"ptr_cache_size"`
+}
+
+// Presence агент считается offline, если от него не было heartbeat дольше OfflineAfter.
+type Presence struct {
+	OfflineAfter  time.Duration `yaml:"offline_after"`
+	SweepInterval time.Duration `yaml:"sweep_interval"`
+}
+
+// Gateway HTTP транспорт для агентов, у которых нет доступа к брокерам Kafka. Токен gateway у каждого агента свой,
+// его выдаёт регистрация агента. MaxWait должен быть меньше http_server.timeout.write и timeout.request.
+type Gateway struct {
+	Enabled      bool          `yaml:"enabled"`
+	MaxWait      time.Duration `yaml:"max_wait"`
+	PollInterval time.Duration `yaml:"poll_interval"`
+	BatchSize    int           `yaml:"batch_size"`
+}
+
+// Signing ключ Ed25519, которым бэкенд подписывает задачи агентам. Если PrivateKey нет, пара создаётся
+// при старте, а PublicKey нужно раздать агентам (identity.backend_public_key).
+// TaskTTL срок доставки задачи сверх её таймаута, после него агент задачу не выполняет.
+type Signing struct {
+	PrivateKey string        `yaml:"private_key"`
+	PublicKey  string        `yaml:"public_key"`
+	TaskTTL    time.Duration `yaml:"task_ttl"`
+}
+
+// Lifecycle назначение, по которому результаты не пришли за timeout_seconds запроса плюс Grace,
+// переводится в TIMEOUT; проверка раз в SweepInterval.
+type Lifecycle struct {
+	Grace         time.Duration `yaml:"grace"`
+	SweepInterval time.Duration `yaml:"sweep_interval" env-default:"10s"`
+}
+
+// Stream события по запросам идут открытым websocket через канал Redis Channel, общий для всех реплик.
+// BufferSize — сколько событий ждёт медленного подписчика, ResyncInterval — как часто websocket
+// сверяет статус запроса с БД на случай потерянного события. Последние HistorySize событий каждого
+// топика хранятся HistoryTTL для досылки после переподключения, HeartbeatInterval — период heartbeat
+// мультиплексного websocket.
+type Stream struct {
+	Channel           string        `yaml:"channel"`
+	BufferSize        int           `yaml:"buffer_size"`
+	ResyncInterval    time.Duration `yaml:"resync_interval" env-default:"15s"`
+	HistorySize       int           `yaml:"history_size"`
+	HistoryTTL        time.Duration `yaml:"history_ttl"`
+	HeartbeatInterval time.Duration `yaml:"heartbeat_interval" env-default:"20s"`
+}
+
+// Routing DefaultStrategy — стратегия выбора агентов для запросов без strategy: geo, round_robin,
+// least_loaded или latency. LatencyWindow — за какой период стратегия latency учитывает результаты ping.
+type Routing struct {
+	DefaultStrategy string        `yaml:"default_strategy"`
+	LatencyWindow   time.Duration `yaml:"latency_window"`
+}
+
+// Enrollment TokenTTL — сколько действует токен регистрации агента, выданный через /admin/agents.
+type Enrollment struct {
+	TokenTTL time.Duration `yaml:"token_ttl"`
+}
+
+// Pools ограничения частных пулов агентов: сколько агентов и регионов (топиков задач) может завести один владелец.
+type Pools struct {
+	MaxAgentsPerOwner  int `yaml:"max_agents_per_owner"`
+	MaxRegionsPerOwner int `yaml:"max_regions_per_owner"`
+}
+
+// Monitors планировщик мониторов раз в PollInterval запускает до BatchSize наступивших мониторов,
+// реплики делят их между собой через блокировки в БД. MinInterval — минимальный период монитора,
+// MaxPerUser — сколько мониторов может завести пользователь.
+type Monitors struct {
+	PollInterval time.Duration `yaml:"poll_interval" env-default:"5s"`
+	BatchSize    int           `yaml:"batch_size" env-default:"50"`
+	MinInterval  time.Duration `yaml:"min_interval"`
+	MaxPerUser   int           `yaml:"max_per_user"`
 }
 
 func MustLoadConfig() *Config {
@@ -176,9 +282,34 @@ func LoadConfig() (*Config, error) {
 		panic("failed to read config: " + err.Error())
 	}
 
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+
 	return &config, nil
 }
 
+// validate проверяет периоды фоновых задач: они уходят в time.NewTicker, который паникует на нуле,
+// и размер пачки планировщика мониторов, с нулём он молча ничего не запускает.
+// Незаданные значения к этому моменту уже заполнены из env-default.
+func (c *Config) validate() error {
+	return errors.Join(
+		positive("stream.resync_interval", c.Stream.ResyncInterval),
+		positive("stream.heartbeat_interval", c.Stream.HeartbeatInterval),
+		positive("lifecycle.sweep_interval", c.Lifecycle.SweepInterval),
+		positive("monitors.poll_interval", c.Monitors.PollInterval),
+		positive("monitors.batch_size", c.Monitors.BatchSize),
+	)
+}
+
+func positive[T time.Duration | int](name string, value T) error {
+	if value <= 0 {
+		return fmt.Errorf("%w: %s must be positive, got %v", ErrInvalidConfig, name, value)
+	}
+
+	return nil
+}
+
 func MustPrintConfig(cfg *Config) {
 	if err := PrintConfig(cfg); err != nil {
 		panic(err)
@@ -0,0 +1,90 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ilyakaznacheev/cleanenv"
+)
+
+func readTestConfig(t *testing.T, data string) *Config {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "config.yml")
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	var cfg Config
+	if err := cleanenv.ReadConfig(path, &cfg); err != nil {
+		t.Fatalf("ReadConfig: %v", err)
+	}
+
+	return &cfg
+}
+
+func TestConfigDefaults(t *testing.T) {
+	cfg := readTestConfig(t, "app:\n  service_name: test\n")
+
+	if err := cfg.validate(); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+
+	if cfg.Stream.ResyncInterval != 15*time.Second {
+		t.Fatalf("stream.resync_interval = %s", cfg.Stream.ResyncInterval)
+	}
+
+	if cfg.Stream.HeartbeatInterval != 20*time.Second {
+		t.Fatalf("stream.heartbeat_interval = %s", cfg.Stream.HeartbeatInterval)
+	}
+
+	if cfg.Lifecycle.SweepInterval != 10*time.Second {
+		t.Fatalf("lifecycle.sweep_interval = %s", cfg.Lifecycle.SweepInterval)
+	}
+
+	if cfg.Monitors.PollInterval != 5*time.Second || cfg.Monitors.BatchSize != 50 {
+		t.Fatalf("monitors = %+v", cfg.Monitors)
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr error
+	}{
+		{name: "explicit values", data: "stream:\n  resync_interval: 5s\nlifecycle:\n  sweep_interval: 1s\n"},
+		{name: "negative resync interval", data: "stream:\n  resync_interval: -1s\n", wantErr: ErrInvalidConfig},
+		{name: "negative heartbeat interval", data: "stream:\n  heartbeat_interval: -20s\n", wantErr: ErrInvalidConfig},
+		{name: "negative sweep interval", data: "lifecycle:\n  sweep_interval: -10s\n", wantErr: ErrInvalidConfig},
+		{name: "negative monitor poll interval", data: "monitors:\n  poll_interval: -5s\n", wantErr: ErrInvalidConfig},
+		{name: "negative monitor batch size", data: "monitors:\n  batch_size: -1\n", wantErr: ErrInvalidConfig},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := readTestConfig(t, tt.data).validate(); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("validate = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConfigValidateZero(t *testing.T) {
+	cfg := readTestConfig(t, "app:\n  service_name: test\n")
+	cfg.Stream.ResyncInterval = 0
+
+	if err := cfg.validate(); !errors.Is(err, ErrInvalidConfig) {
+		t.Fatalf("validate = %v, want %v", err, ErrInvalidConfig)
+	}
+
+	cfg = readTestConfig(t, "app:\n  service_name: test\n")
+	cfg.Monitors.BatchSize = 0
+
+	if err := cfg.validate(); !errors.Is(err, ErrInvalidConfig) {
+		t.Fatalf("validate = %v, want %v", err, ErrInvalidConfig)
+	}
+}
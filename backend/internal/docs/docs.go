@@ -917,7 +917,7 @@ const docTemplate = `{
         },
         "/check/task": {
             "post": {
-                "description": "Принимает проверки, достаёт IP клиента, определяет регион, пишет в checks=PENDING и в outbox кладёт.",
+                "description": "Принимает проверки, достаёт IP клиента, определяет регион, пишет в checks=PENDING и в outbox кладёт.\nПроверки получают только online агенты, которые могут их выполнить (тип проверки, IPv4/IPv6, raw ICMP),\nпри необходимости проверки делятся между агентами. Проверки, которые не может выполнить никто, перечислены в unassigned.\ntargets задаёт, где выполнять проверки: регионы, страны, ASN, конкретные агенты или spread — N агентов из разных регионов.\nЕсли селектору не нашлось online агентов, ответ 400 с причиной по каждому значению (например targets.countries[1]).\nТокен необязателен. С токеном в targets можно указать регионы и агентов своих приватных пулов и пулов своих организаций,\nбез явного указания приватные агенты проверки не получают.",
                 "consumes": [
                     "application/json"
                 ],
@@ -959,9 +959,9 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "Invalid JSON body",
+                        "description": "Invalid JSON body, check params or targets, or targets can't be satisfied",
                         "schema": {
-                            "$ref": "#/definitions/_ResponseWithMessage"
+                            "$ref": "#/definitions/_ResponseWithErrors"
                         }
                     },
                     "500": {
@@ -969,13 +969,37 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/_ResponseWithMessage"
                         }
+                    },
+                    "503": {
+                        "description": "No online agents to run checks",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    },
+                    "422": {
+                        "description": "No online agent can run any of the checks, message explains each check",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid or expired token",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Targets name a private pool of another owner",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
                     }
                 }
             }
         },
         "/check/ws/check/{request_id}": {
             "get": {
-                "description": "Открывает WS и присылает актуальные результаты по request_id до завершения всех проверок.",
+                "description": "Открывает WS и сначала присылает текущее состояние: type=snapshot со всеми результатами, type=progress\nс промежуточными состояниями долгих проверок (traceroute, ping) и type=status со статусом запроса.\nДальше приходят только изменения: result — новый итоговый результат одной проверки, progress — новое\nпромежуточное состояние одной проверки, status — новый статус запроса (PENDING, DISPATCHED, RUNNING, DONE, FAILED, TIMEOUT).\nКогда запрос завершён, приходит done с итоговыми результатами, и соединение закрывается.\nИзменения могут повторять строки snapshot, ключ строки — assignmentId и checkIndex.",
                 "produces": [
                     "application/json"
                 ],
@@ -1972,241 +1996,3191 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "APIKey": {
-            "description": "Модель API ключа пользователя.",
-            "type": "object",
-            "properties": {
-                "created_at": {
-                    "description": "Дата создания",
-                    "type": "string",
-                    "format": "date-time",
-                    "example": "2025-10-25T13:40:00Z"
-                },
-                "expires_at": {
-                    "description": "Дата истечения (если задана)",
-                    "type": "string",
-                    "format": "date-time",
-                    "example": "2025-11-25T13:40:00Z"
-                },
-                "id": {
-                    "description": "ID ключа",
-                    "type": "string",
-                    "example": "7b2aab2e-4d1f-45b5-90c5-4d5d4db5ef11"
-                },
-                "name": {
-                    "description": "Название ключа (для понимания)",
-                    "type": "string",
-                    "example": "mobile_app"
-                },
-                "revoked": {
-                    "description": "Отозван ли ключ",
-                    "type": "boolean",
-                    "example": false
-                },
-                "user_id": {
-                    "description": "ID пользователя",
-                    "type": "string",
-                    "example": "1a2b3c4d-5678-90ab-cdef-1234567890ab"
-                }
-            }
         },
-        "APIKeyCreateRequest": {
-            "description": "Запрос на создание API ключа.",
-            "type": "object",
-            "required": [
-                "name"
-            ],
-            "properties": {
-                "name": {
-                    "description": "Имя ключа",
-                    "type": "string",
-                    "example": "partner_api"
+        "/admin/agents": {
+            "get": {
+                "description": "Возвращает всех агентов с online/last_seen и загрузкой из последнего heartbeat. Доступно для пользователей с ролью manager и выше.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Agent"
+                ],
+                "summary": "Состояние агентов",
+                "responses": {
+                    "200": {
+                        "description": "Success",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/_ResponseWithData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/FleetResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Не авторизован",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Недостаточно прав",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка при получении агентов",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    }
                 },
-                "ttl_hours": {
-                    "description": "Время жизни в часах (0 — бессрочно)",
-                    "type": "integer",
-                    "example": 720
-                }
-            }
-        },
-        "APIKeyCreateResponse": {
-            "description": "Ответ при создании API ключа.",
-            "type": "object",
-            "properties": {
-                "api_key": {
-                    "description": "Секретный API ключ (показывается один раз)",
-                    "type": "string",
-                    "example": "KJHsT9W-2oP3sA1Q-LzM8fD4eC"
-                }
-            }
-        },
-        "APIKeyListResponse": {
-            "description": "Ответ со списком ключей пользователя.",
-            "type": "object",
-            "properties": {
-                "keys": {
-                    "description": "Список всех активных ключей",
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/APIKey"
+                "security": [
+                    {
+                        "AccessToken": []
+                    },
+                    {
+                        "RefreshToken": []
                     }
-                }
-            }
-        },
-        "APIKeyRevokeRequest": {
-            "description": "Запрос на отзыв API ключа.",
-            "type": "object",
-            "required": [
-                "id"
-            ],
-            "properties": {
-                "id": {
-                    "description": "ID ключа",
-                    "type": "string",
-                    "example": "7b2aab2e-4d1f-45b5-90c5-4d5d4db5ef11"
-                }
+                ]
+            },
+            "post": {
+                "description": "Создаёт агента в реестре и выдаёт одноразовый токен регистрации. Токен показывается один раз, его нужно передать агенту\n(enrollment.token): при первом запуске агент обменяет его на свой id, регион, топики и ключи.\nС organizationId агент становится частным агентом организации. Доступно только admin.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Agent"
+                ],
+                "summary": "Завести агента",
+                "parameters": [
+                    {
+                        "description": "Агент",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/AgentCreateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Success",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/_ResponseWithData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/AgentCreateResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Неверные поля агента",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithErrors"
+                        }
+                    },
+                    "401": {
+                        "description": "Не авторизован",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Недостаточно прав",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка при создании агента",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    },
+                    "404": {
+                        "description": "Организация не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    },
+                    "409": {
+                        "description": "У организации уже максимум частных агентов или регионов",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "AccessToken": []
+                    },
+                    {
+                        "RefreshToken": []
+                    }
+                ]
             }
         },
-        "Article": {
-            "description": "модель статьи.",
-            "type": "object",
-            "required": [
-                "content_en",
-                "content_ru",
-                "createdAt",
-                "id",
-                "title_en",
-                "title_ru",
-                "updatedAt"
-            ],
-            "properties": {
-                "content_en": {
-                    "description": "Содержимое статьи на английском",
-                    "type": "string",
-                    "example": "Example"
-                },
-                "content_ru": {
-                    "description": "Содержимое статьи на русском",
+        "/admin/agents/{agent_id}": {
+            "get": {
+                "description": "Возвращает агента с online/last_seen и загрузкой из последнего heartbeat. Доступно для пользователей с ролью manager и выше.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Agent"
+                ],
+                "summary": "Состояние агента по ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Agent UUID",
+                        "name": "agent_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/_ResponseWithData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/Agent"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный параметр пути",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    },
+                    "401": {
+                        "description": "Не авторизован",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Недостаточно прав",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    },
+                    "404": {
+                        "description": "Агент не найден",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка при получении агента",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "AccessToken": []
+                    },
+                    {
+                        "RefreshToken": []
+                    }
+                ]
+            },
+            "delete": {
+                "description": "Удаляет агента и его токены регистрации, назначения и результаты остаются. Работающий агент сначала нужно выключить\nили остановить: по heartbeat он заведётся заново как агент без реестра. Доступно только admin.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Agent"
+                ],
+                "summary": "Удалить агента",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Agent UUID",
+                        "name": "agent_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Агент удалён",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный параметр пути",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    },
+                    "401": {
+                        "description": "Не авторизован",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Недостаточно прав",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    },
+                    "404": {
+                        "description": "Агент не найден",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка при удалении агента",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "AccessToken": []
+                    },
+                    {
+                        "RefreshToken": []
+                    }
+                ]
+            },
+            "patch": {
+                "description": "Меняет имя, страну, ASN, координаты агента или выключает его (disabled). Выключенный агент сразу уходит в offline,\nне получает задач и не выходит online по heartbeat. После правки heartbeat не перезаписывает страну, ASN и координаты. Доступно только admin.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Agent"
+                ],
+                "summary": "Изменить агента",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Agent UUID",
+                        "name": "agent_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Изменения",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/AgentUpdateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/_ResponseWithData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/Agent"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Неверные поля агента",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithErrors"
+                        }
+                    },
+                    "401": {
+                        "description": "Не авторизован",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Недостаточно прав",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    },
+                    "404": {
+                        "description": "Агент не найден",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка при изменении агента",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "AccessToken": []
+                    },
+                    {
+                        "RefreshToken": []
+                    }
+                ]
+            }
+        },
+        "/agent/messages": {
+            "post": {
+                "description": "HTTP транспорт агентов без доступа к Kafka. Принимает результат проверки или heartbeat, адресованный\nтопику результатов или heartbeat, и обрабатывает его так же, как сообщение из Kafka. value — конверт, подписанный ключом агента.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Agent gateway"
+                ],
+                "summary": "Сообщение от агента",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer токен gateway, выданный агенту при регистрации",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Agent UUID",
+                        "name": "X-Agent-ID",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Сообщение",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/contract.GatewayMessage"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Принято",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверное сообщение или неизвестный топик",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    },
+                    "401": {
+                        "description": "Неверный токен или id агента",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Сообщение другого агента, неверная подпись, неизвестный агент, задача не назначена агенту или агент выключен",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка при обработке сообщения",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    }
+                }
+            }
+        },
+        "/agent/tasks": {
+            "get": {
+                "description": "HTTP транспорт агентов без доступа к Kafka. Ждёт до wait секунд задачи, назначенные агенту, и отдаёт их пачкой,\nвыданные задачи больше никому не отдаются. Пустой messages — за время ожидания задач не было.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Agent gateway"
+                ],
+                "summary": "Long-poll задач для агента",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer токен gateway, выданный агенту при регистрации",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Agent UUID",
+                        "name": "X-Agent-ID",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Сколько секунд ждать задач",
+                        "name": "wait",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/_ResponseWithData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/contract.GatewayPoll"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Неверные параметры запроса",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    },
+                    "401": {
+                        "description": "Неверный токен или id агента",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка при получении задач",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Агент не прошёл регистрацию или выключен администратором",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    }
+                }
+            }
+        },
+        "/check/{request_id}/agents": {
+            "get": {
+                "description": "Результаты по request_id, сгруппированные по агенту и региону, который выполнил проверки.\nДля broadcast запроса каждый агент выполняет проверки отдельно и получает свою группу.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Checks"
+                ],
+                "summary": "Получить результаты сетевых проверок по агентам.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Request UUID",
+                        "name": "request_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/_ResponseWithData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/hackathon-back_internal_model.AgentResults"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid path param",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get results",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    }
+                }
+            }
+        },
+        "/ws": {
+            "get": {
+                "description": "Одно соединение на клиента. Клиент шлёт JSON-команды: {\"type\":\"subscribe\",\"topic\":\"request:<request_id>\",\"lastSeq\":12},\n{\"type\":\"unsubscribe\",\"topic\":\"...\"}, {\"type\":\"ping\"}; поле id команды возвращается в ответе. Топики:\nrequest:<request_id> — результаты, промежуточные состояния и статус запроса; monitor:<monitor_id> — запуски своего монитора;\nfleet — агенты online/offline, только для manager и admin.\nНа subscribe приходит subscribed с текущим seq топика, за ним snapshot с состоянием целиком (для запроса — status, results, progress,\nдля монитора — monitor и последние runs, для fleet — как GET /admin/agents). Дальше приходят event: event — тип события (result, progress,\nstatus, agent_online, agent_offline, monitor_run), seq — номер события в топике, data — его данные. Если в subscribe передан lastSeq и история топика\nещё хранит всё после него, snapshot не приходит (subscribed с resumed=true), пропущенные события досылаются по порядку.\nСервер раз в heartbeat_interval шлёт heartbeat, на ping отвечает pong, ошибки команд приходят как error с id и topic.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Stream"
+                ],
+                "summary": "Мультиплексный WebSocket: подписки на запросы и парк агентов.",
+                "responses": {
+                    "401": {
+                        "description": "Не авторизован",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "AccessToken": []
+                    },
+                    {
+                        "RefreshToken": []
+                    }
+                ]
+            }
+        },
+        "/check/{request_id}/events": {
+            "get": {
+                "description": "Те же сообщения, что и у websocket /check/ws/check/{request_id}, для клиентов за прокси, которые не пропускают websocket:\nсначала snapshot, progress и status с текущим состоянием, дальше result, progress и status по мере изменений,\ndone с итоговыми результатами, когда запрос завершён, после чего поток закрывается. Данные каждого события — JSON.\nid события — его номер в топике запроса: после переподключения клиент передаёт последний полученный id в Last-Event-ID\nи получает текущий status и только пропущенные события, а если они уже не хранятся — snapshot заново.",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "Checks"
+                ],
+                "summary": "Стрим результатов сетевых проверок через Server-Sent Events.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Request UUID",
+                        "name": "request_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "id последнего полученного события",
+                        "name": "Last-Event-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "400": {
+                        "description": "Invalid path param",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    },
+                    "404": {
+                        "description": "Request not found",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get request",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/agents/{agent_id}/enrollments": {
+            "get": {
+                "description": "Выданные агенту токены: когда истекают, когда использованы, версия агента и IP, с которого он зарегистрировался.\nДоступно для пользователей с ролью manager и выше.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Agent"
+                ],
+                "summary": "История регистраций агента",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Agent UUID",
+                        "name": "agent_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/_ResponseWithData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/AgentEnrollment"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный параметр пути",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    },
+                    "401": {
+                        "description": "Не авторизован",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Недостаточно прав",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    },
+                    "404": {
+                        "description": "Агент не найден",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка при получении регистраций",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "AccessToken": []
+                    },
+                    {
+                        "RefreshToken": []
+                    }
+                ]
+            },
+            "post": {
+                "description": "Выдаёт агенту новый одноразовый токен, например после переустановки; прежние неиспользованные токены отзываются.\nПосле регистрации по новому токену прежний ключ агента больше не принимается. Доступно только admin.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Agent"
+                ],
+                "summary": "Новый токен регистрации агента",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Agent UUID",
+                        "name": "agent_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Success",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/_ResponseWithData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/AgentEnrollmentToken"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный параметр пути",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    },
+                    "401": {
+                        "description": "Не авторизован",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Недостаточно прав",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    },
+                    "404": {
+                        "description": "Агент не найден",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    },
+                    "409": {
+                        "description": "Агент выключен",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка при выдаче токена",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "AccessToken": []
+                    },
+                    {
+                        "RefreshToken": []
+                    }
+                ]
+            }
+        },
+        "/agent/enroll": {
+            "post": {
+                "description": "Агент при первом запуске обменивает одноразовый токен на свой id, регион, страну, ASN, координаты, топики,\nадреса брокеров, токен gateway и ключ, которым бэкенд подписывает задачи. publicKey агента привязывается к нему,\nрегистрация записывается с версией агента и IP. Повторно токен не принимается.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Agent gateway"
+                ],
+                "summary": "Регистрация агента по токену",
+                "parameters": [
+                    {
+                        "description": "Токен и ключ агента",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/contract.EnrollRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/_ResponseWithData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/contract.EnrollResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный запрос",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithErrors"
+                        }
+                    },
+                    "401": {
+                        "description": "Токен неизвестен, истёк или уже использован",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Агент выключен",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка при регистрации",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/topics": {
+            "get": {
+                "description": "Возвращает топики результатов, heartbeat и задач всех регионов агентов: партиции, лидеров, реплики и ISR. Доступно для пользователей с ролью manager и выше.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Agent"
+                ],
+                "summary": "Состояние топиков Kafka",
+                "responses": {
+                    "200": {
+                        "description": "Success",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/_ResponseWithData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/TopicHealth"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Не авторизован",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Недостаточно прав",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    },
+                    "503": {
+                        "description": "Kafka недоступна",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "AccessToken": []
+                    },
+                    {
+                        "RefreshToken": []
+                    }
+                ]
+            }
+        },
+        "/agents": {
+            "get": {
+                "description": "Возвращает частных агентов пользователя и организаций, в которых он состоит.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Agent pool"
+                ],
+                "summary": "Мои частные агенты",
+                "responses": {
+                    "200": {
+                        "description": "Success",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/_ResponseWithData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/Agent"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Не авторизован",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка при получении агентов",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "AccessToken": []
+                    },
+                    {
+                        "RefreshToken": []
+                    }
+                ]
+            },
+            "post": {
+                "description": "Создаёт агента пользователя или, с organizationId, его организации и выдаёт одноразовый токен регистрации.\nregion — метка внутри пула (например OFFICE), агент получает регион вида USER-<id>-OFFICE или ORG-<id>-OFFICE\nи свой топик задач. Задачи частному агенту направляет только владелец, явно выбрав его регион или id в targets.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Agent pool"
+                ],
+                "summary": "Завести частного агента",
+                "parameters": [
+                    {
+                        "description": "Агент",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/AgentCreateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Success",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/_ResponseWithData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/AgentCreateResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Неверные поля агента",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithErrors"
+                        }
+                    },
+                    "401": {
+                        "description": "Не авторизован",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    },
+                    "404": {
+                        "description": "Организация не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка при создании агента",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    },
+                    "409": {
+                        "description": "У владельца уже максимум частных агентов или регионов",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "AccessToken": []
+                    },
+                    {
+                        "RefreshToken": []
+                    }
+                ]
+            }
+        },
+        "/agents/{agent_id}": {
+            "delete": {
+                "description": "Удаляет агента пула и его токены регистрации.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Agent pool"
+                ],
+                "summary": "Удалить частного агента",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Agent UUID",
+                        "name": "agent_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Агент удалён",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный параметр пути",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    },
+                    "401": {
+                        "description": "Не авторизован",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    },
+                    "404": {
+                        "description": "Агент не найден в пулах пользователя",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка при удалении агента",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "AccessToken": []
+                    },
+                    {
+                        "RefreshToken": []
+                    }
+                ]
+            },
+            "patch": {
+                "description": "Меняет имя, страну, ASN, координаты агента пула или выключает его (disabled).",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Agent pool"
+                ],
+                "summary": "Изменить частного агента",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Agent UUID",
+                        "name": "agent_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Изменения",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/AgentUpdateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/_ResponseWithData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/Agent"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Неверные поля агента",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithErrors"
+                        }
+                    },
+                    "401": {
+                        "description": "Не авторизован",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    },
+                    "404": {
+                        "description": "Агент не найден в пулах пользователя",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка при изменении агента",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "AccessToken": []
+                    },
+                    {
+                        "RefreshToken": []
+                    }
+                ]
+            }
+        },
+        "/agents/{agent_id}/enrollments": {
+            "get": {
+                "description": "Выданные агенту пула токены: когда истекают, когда использованы, версия агента и IP.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Agent pool"
+                ],
+                "summary": "История регистраций частного агента",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Agent UUID",
+                        "name": "agent_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/_ResponseWithData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/AgentEnrollment"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный параметр пути",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    },
+                    "401": {
+                        "description": "Не авторизован",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    },
+                    "404": {
+                        "description": "Агент не найден в пулах пользователя",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка при получении регистраций",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "AccessToken": []
+                    },
+                    {
+                        "RefreshToken": []
+                    }
+                ]
+            },
+            "post": {
+                "description": "Выдаёт агенту пула новый одноразовый токен, прежние неиспользованные токены отзываются.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Agent pool"
+                ],
+                "summary": "Новый токен регистрации частного агента",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Agent UUID",
+                        "name": "agent_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Success",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/_ResponseWithData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/AgentEnrollmentToken"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный параметр пути",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    },
+                    "401": {
+                        "description": "Не авторизован",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    },
+                    "404": {
+                        "description": "Агент не найден в пулах пользователя",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    },
+                    "409": {
+                        "description": "Агент выключен",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка при выдаче токена",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "AccessToken": []
+                    },
+                    {
+                        "RefreshToken": []
+                    }
+                ]
+            }
+        },
+        "/check/locations": {
+            "get": {
+                "description": "Возвращает регионы, в которых можно выполнять проверки: публичные регионы и, если передан токен,\nприватные пулы пользователя и его организаций. Значение region подставляется в targets.regions.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Checks"
+                ],
+                "summary": "Получить доступные локации.",
+                "responses": {
+                    "200": {
+                        "description": "Success",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/_ResponseWithData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/Location"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid or expired token",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get locations",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    }
+                }
+            }
+        },
+        "/organizations": {
+            "get": {
+                "description": "Возвращает организации, в которых состоит пользователь.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organization"
+                ],
+                "summary": "Мои организации",
+                "responses": {
+                    "200": {
+                        "description": "Success",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/_ResponseWithData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/Organization"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Не авторизован",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка при получении организаций",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "AccessToken": []
+                    },
+                    {
+                        "RefreshToken": []
+                    }
+                ]
+            },
+            "post": {
+                "description": "Создаёт организацию, создатель становится её участником. Частных агентов организации видят и выбирают все участники.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organization"
+                ],
+                "summary": "Создать организацию",
+                "parameters": [
+                    {
+                        "description": "Организация",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/OrganizationCreateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Success",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/_ResponseWithData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/Organization"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Неверное имя",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithErrors"
+                        }
+                    },
+                    "401": {
+                        "description": "Не авторизован",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка при создании организации",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "AccessToken": []
+                    },
+                    {
+                        "RefreshToken": []
+                    }
+                ]
+            }
+        },
+        "/organizations/{organization_id}/members": {
+            "get": {
+                "description": "Возвращает участников организации. Доступно только участникам.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organization"
+                ],
+                "summary": "Участники организации",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Organization UUID",
+                        "name": "organization_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/_ResponseWithData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/OrganizationMember"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный параметр пути",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    },
+                    "401": {
+                        "description": "Не авторизован",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    },
+                    "404": {
+                        "description": "Организация не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка при получении участников",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "AccessToken": []
+                    },
+                    {
+                        "RefreshToken": []
+                    }
+                ]
+            },
+            "post": {
+                "description": "Добавляет пользователя в организацию. Доступно только участникам.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organization"
+                ],
+                "summary": "Добавить участника в организацию",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Organization UUID",
+                        "name": "organization_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Пользователь",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/OrganizationMemberRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Участник добавлен",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный запрос",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    },
+                    "401": {
+                        "description": "Не авторизован",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    },
+                    "404": {
+                        "description": "Организация или пользователь не найдены",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    },
+                    "409": {
+                        "description": "Пользователь уже участник",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка при добавлении участника",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "AccessToken": []
+                    },
+                    {
+                        "RefreshToken": []
+                    }
+                ]
+            }
+        },
+        "/organizations/{organization_id}/members/{user_id}": {
+            "delete": {
+                "description": "Убирает пользователя из организации, в том числе самого себя. Агенты организации остаются ей. Доступно только участникам.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organization"
+                ],
+                "summary": "Убрать участника из организации",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Organization UUID",
+                        "name": "organization_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "User UUID",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Участник удалён",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный параметр пути",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    },
+                    "401": {
+                        "description": "Не авторизован",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    },
+                    "404": {
+                        "description": "Организация или участник не найдены",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка при удалении участника",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "AccessToken": []
+                    },
+                    {
+                        "RefreshToken": []
+                    }
+                ]
+            }
+        },
+        "/monitors": {
+            "get": {
+                "description": "Возвращает мониторы пользователя.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Monitor"
+                ],
+                "summary": "Мои мониторы",
+                "responses": {
+                    "200": {
+                        "description": "Success",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/_ResponseWithData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/Monitor"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Не авторизован",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка при получении мониторов",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "AccessToken": []
+                    },
+                    {
+                        "RefreshToken": []
+                    }
+                ]
+            },
+            "post": {
+                "description": "Заводит периодическую задачу: task в формате /check/task (где выполнять проверки — task.targets)\nи расписание — intervalSeconds или cron из 5 полей в UTC. Монитор с интервалом запускается сразу,\nпо cron — в ближайшее время расписания. Каждый запуск создаёт обычный запрос, его результаты — в /check/{request_id}.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Monitor"
+                ],
+                "summary": "Создать монитор",
+                "parameters": [
+                    {
+                        "description": "Монитор",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/MonitorCreateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Success",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/_ResponseWithData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/Monitor"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Неверные поля монитора или задачи",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithErrors"
+                        }
+                    },
+                    "401": {
+                        "description": "Не авторизован",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "task.targets указывает на чужой частный пул",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    },
+                    "409": {
+                        "description": "Превышено число мониторов пользователя",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка при создании монитора",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "AccessToken": []
+                    },
+                    {
+                        "RefreshToken": []
+                    }
+                ]
+            }
+        },
+        "/monitors/{monitor_id}": {
+            "get": {
+                "description": "Возвращает монитор пользователя.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Monitor"
+                ],
+                "summary": "Монитор",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Monitor UUID",
+                        "name": "monitor_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/_ResponseWithData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/Monitor"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный параметр пути",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    },
+                    "401": {
+                        "description": "Не авторизован",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    },
+                    "404": {
+                        "description": "Монитор не найден",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка при получении монитора",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "AccessToken": []
+                    },
+                    {
+                        "RefreshToken": []
+                    }
+                ]
+            },
+            "delete": {
+                "description": "Удаляет монитор и историю его запусков, созданные запросы и их результаты остаются.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Monitor"
+                ],
+                "summary": "Удалить монитор",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Monitor UUID",
+                        "name": "monitor_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Монитор удалён",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный параметр пути",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    },
+                    "401": {
+                        "description": "Не авторизован",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    },
+                    "404": {
+                        "description": "Монитор не найден",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка при удалении монитора",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "AccessToken": []
+                    },
+                    {
+                        "RefreshToken": []
+                    }
+                ]
+            },
+            "patch": {
+                "description": "Меняет имя, задачу, расписание или enabled. Новое расписание или включение монитора\nзаново отсчитывает следующий запуск от текущего времени.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Monitor"
+                ],
+                "summary": "Изменить монитор",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Monitor UUID",
+                        "name": "monitor_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Изменения",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/MonitorUpdateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/_ResponseWithData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/Monitor"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Неверные поля монитора или задачи",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithErrors"
+                        }
+                    },
+                    "401": {
+                        "description": "Не авторизован",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "task.targets указывает на чужой частный пул",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    },
+                    "404": {
+                        "description": "Монитор не найден",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка при изменении монитора",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "AccessToken": []
+                    },
+                    {
+                        "RefreshToken": []
+                    }
+                ]
+            }
+        },
+        "/monitors/{monitor_id}/runs": {
+            "get": {
+                "description": "Последние запуски монитора, новые первыми: созданный запрос и его статус или причина, по которой запуск не состоялся.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Monitor"
+                ],
+                "summary": "История запусков монитора",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Monitor UUID",
+                        "name": "monitor_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Сколько запусков вернуть, по умолчанию 50, не больше 500",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/_ResponseWithData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/MonitorRun"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный параметр",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    },
+                    "401": {
+                        "description": "Не авторизован",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    },
+                    "404": {
+                        "description": "Монитор не найден",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка при получении запусков",
+                        "schema": {
+                            "$ref": "#/definitions/_ResponseWithMessage"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "AccessToken": []
+                    },
+                    {
+                        "RefreshToken": []
+                    }
+                ]
+            }
+        }
+    },
+    "definitions": {
+        "APIKey": {
+            "description": "Модель API ключа пользователя.",
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "description": "Дата создания",
+                    "type": "string",
+                    "format": "date-time",
+                    "example": "2025-10-25T13:40:00Z"
+                },
+                "expires_at": {
+                    "description": "Дата истечения (если задана)",
+                    "type": "string",
+                    "format": "date-time",
+                    "example": "2025-11-25T13:40:00Z"
+                },
+                "id": {
+                    "description": "ID ключа",
+                    "type": "string",
+                    "example": "7b2aab2e-4d1f-45b5-90c5-4d5d4db5ef11"
+                },
+                "name": {
+                    "description": "Название ключа (для понимания)",
+                    "type": "string",
+                    "example": "mobile_app"
+                },
+                "revoked": {
+                    "description": "Отозван ли ключ",
+                    "type": "boolean",
+                    "example": false
+                },
+                "user_id": {
+                    "description": "ID пользователя",
+                    "type": "string",
+                    "example": "1a2b3c4d-5678-90ab-cdef-1234567890ab"
+                }
+            }
+        },
+        "APIKeyCreateRequest": {
+            "description": "Запрос на создание API ключа.",
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "name": {
+                    "description": "Имя ключа",
+                    "type": "string",
+                    "example": "partner_api"
+                },
+                "ttl_hours": {
+                    "description": "Время жизни в часах (0 — бессрочно)",
+                    "type": "integer",
+                    "example": 720
+                }
+            }
+        },
+        "APIKeyCreateResponse": {
+            "description": "Ответ при создании API ключа.",
+            "type": "object",
+            "properties": {
+                "api_key": {
+                    "description": "Секретный API ключ (показывается один раз)",
+                    "type": "string",
+                    "example": "KJHsT9W-2oP3sA1Q-LzM8fD4eC"
+                }
+            }
+        },
+        "APIKeyListResponse": {
+            "description": "Ответ со списком ключей пользователя.",
+            "type": "object",
+            "properties": {
+                "keys": {
+                    "description": "Список всех активных ключей",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/APIKey"
+                    }
+                }
+            }
+        },
+        "APIKeyRevokeRequest": {
+            "description": "Запрос на отзыв API ключа.",
+            "type": "object",
+            "required": [
+                "id"
+            ],
+            "properties": {
+                "id": {
+                    "description": "ID ключа",
+                    "type": "string",
+                    "example": "7b2aab2e-4d1f-45b5-90c5-4d5d4db5ef11"
+                }
+            }
+        },
+        "Article": {
+            "description": "модель статьи.",
+            "type": "object",
+            "required": [
+                "content_en",
+                "content_ru",
+                "createdAt",
+                "id",
+                "title_en",
+                "title_ru",
+                "updatedAt"
+            ],
+            "properties": {
+                "content_en": {
+                    "description": "Содержимое статьи на английском",
+                    "type": "string",
+                    "example": "Example"
+                },
+                "content_ru": {
+                    "description": "Содержимое статьи на русском",
+                    "type": "string",
+                    "example": "Пример"
+                },
+                "createdAt": {
+                    "description": "Timestamp создания аккаунта",
+                    "type": "string",
+                    "format": "date-time",
+                    "example": "2006-01-02T15:04:05Z"
+                },
+                "id": {
+                    "description": "ID статьи (UUID)",
+                    "type": "string",
+                    "example": "b4b03119-1290-44bc-b599-6a5e91d6611f"
+                },
+                "title_en": {
+                    "description": "Заголовок статьи на английском языке",
+                    "type": "string",
+                    "example": "Example"
+                },
+                "title_ru": {
+                    "description": "Заголовок статьи на русском языке",
+                    "type": "string",
+                    "example": "Пример"
+                },
+                "updatedAt": {
+                    "description": "Timestamp последнего обновления аккаунта",
+                    "type": "string",
+                    "format": "date-time",
+                    "example": "2006-01-02T15:04:05Z"
+                }
+            }
+        },
+        "ArticleCreateRequest": {
+            "description": "Данные, передаваемые для создания статьи.",
+            "type": "object",
+            "required": [
+                "content_en",
+                "content_ru",
+                "title_en",
+                "title_ru"
+            ],
+            "properties": {
+                "content_en": {
+                    "description": "Содержимое статьи на английском",
+                    "type": "string"
+                },
+                "content_ru": {
+                    "description": "Содержимое статьи на русском",
+                    "type": "string"
+                },
+                "title_en": {
+                    "description": "Заголовок статьи на английском языке",
+                    "type": "string"
+                },
+                "title_ru": {
+                    "description": "Заголовок статьи на русском языке",
+                    "type": "string"
+                }
+            }
+        },
+        "AuthRequest": {
+            "description": "Данные, передаваемые в json для регистрации.",
+            "type": "object",
+            "required": [
+                "email",
+                "password",
+                "username"
+            ],
+            "properties": {
+                "email": {
+                    "description": "Электронная почта пользователя",
+                    "type": "string",
+                    "format": "email",
+                    "example": "Dimka228@gmail.com"
+                },
+                "password": {
+                    "description": "Пароль пользователя",
+                    "type": "string",
+                    "format": "password",
+                    "minLength": 8,
+                    "example": "12345678"
+                },
+                "username": {
+                    "description": "Имя пользователя",
+                    "type": "string",
+                    "example": "Dimka228"
+                }
+            }
+        },
+        "AuthResponse": {
+            "description": "Данные, которые получает пользователь после регистрации.",
+            "type": "object",
+            "required": [
+                "token"
+            ],
+            "properties": {
+                "token": {
+                    "description": "Токен для подтверждения регистрации",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "user": {
+                    "$ref": "#/definitions/User"
+                }
+            }
+        },
+        "AuthToken": {
+            "description": "Токен для подтверждения регистрации.",
+            "type": "object",
+            "required": [
+                "token"
+            ],
+            "properties": {
+                "token": {
+                    "description": "Токен для подтверждения регистрации",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "CheckRequestRequest": {
+            "description": "описание одной проверки",
+            "type": "object",
+            "required": [
+                "params",
+                "type"
+            ],
+            "properties": {
+                "params": {
+                    "description": "Params параметры проверки",
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "type": {
+                    "description": "Type тип проверки: http|ping|tcp|traceroute|dns|websocket|grpc|throughput",
+                    "type": "string",
+                    "example": "http"
+                }
+            }
+        },
+        "ConfirmationRequest": {
+            "description": "Запрос на подтверждение регистрации.",
+            "type": "object",
+            "required": [
+                "code",
+                "token"
+            ],
+            "properties": {
+                "code": {
+                    "description": "Код, полученный с email",
+                    "type": "string",
+                    "example": "0228"
+                },
+                "token": {
+                    "description": "Токен, который вернул handler register/resend-confirmation",
+                    "type": "string",
+                    "example": "89as098ga0998=asdg=+afgk=="
+                }
+            }
+        },
+        "ForgotPasswordRequest": {
+            "description": "Запрос на восстановление пароля.",
+            "type": "object",
+            "required": [
+                "email"
+            ],
+            "properties": {
+                "email": {
+                    "description": "Почта, на которую придёт письмо для восстановления пароля",
+                    "type": "string"
+                }
+            }
+        },
+        "LoginRequest": {
+            "description": "Данные, для входа.",
+            "type": "object",
+            "required": [
+                "email",
+                "password"
+            ],
+            "properties": {
+                "email": {
+                    "description": "Электронная почта пользователя",
+                    "type": "string",
+                    "format": "email",
+                    "example": "Dimka228@gmail.com"
+                },
+                "password": {
+                    "description": "Пароль пользователя",
+                    "type": "string",
+                    "format": "password",
+                    "minLength": 8,
+                    "example": "12345678"
+                }
+            }
+        },
+        "RefreshRequest": {
+            "description": "Запрос, в котор передаёт refresh токен мобильное приложение",
+            "type": "object",
+            "properties": {
+                "refreshToken": {
+                    "description": "Refresh токен",
+                    "type": "string"
+                }
+            }
+        },
+        "ResendRequest": {
+            "description": "Запрос на переотправку кода подтверждения.",
+            "type": "object",
+            "required": [
+                "email"
+            ],
+            "properties": {
+                "email": {
+                    "description": "Электронная почта пользователя",
+                    "type": "string",
+                    "format": "email",
+                    "example": "Dimka228@gmail.com"
+                }
+            }
+        },
+        "ResetPasswordRequest": {
+            "description": "Запрос на сброс пароля.",
+            "type": "object",
+            "required": [
+                "newPassword",
+                "token"
+            ],
+            "properties": {
+                "newPassword": {
+                    "description": "Новый пароль",
+                    "type": "string"
+                },
+                "token": {
+                    "description": "Токен, полученный по ссылке из письма",
+                    "type": "string"
+                }
+            }
+        },
+        "TaskMessageRequest": {
+            "description": "Task для выполнения проверок сети (HTTP, ping, TCP, traceroute, DNS, WebSocket, gRPC, throughput)",
+            "type": "object",
+            "required": [
+                "checks",
+                "target",
+                "timeoutSeconds"
+            ],
+            "properties": {
+                "broadcast": {
+                    "description": "Отправлять ли запрос на агенты всех регионов или берётся ближайший 1 агент к клиенту",
+                    "type": "boolean",
+                    "example": false
+                },
+                "checks": {
+                    "description": "Checks список проверок",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/CheckRequestRequest"
+                    }
+                },
+                "target": {
+                    "description": "Target домен или IP, который нужно проверить",
                     "type": "string",
-                    "example": "Пример"
+                    "example": "example.com"
+                },
+                "timeoutSeconds": {
+                    "description": "TimeoutSeconds время выполнения всех задачи в секундах",
+                    "type": "integer",
+                    "example": 20
+                },
+                "targets": {
+                    "description": "Targets где выполнять проверки; с broadcast — фильтр агентов",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/TargetSelector"
+                        }
+                    ]
+                },
+                "strategy": {
+                    "description": "Strategy порядок выбора агентов: geo, round_robin, least_loaded, latency; по умолчанию из конфига",
+                    "type": "string",
+                    "example": "geo"
+                }
+            }
+        },
+        "TokenResponse": {
+            "description": "Ответ, содержащий access и refresh токены",
+            "type": "object",
+            "properties": {
+                "accessToken": {
+                    "description": "Access токен",
+                    "type": "string"
+                },
+                "refreshToken": {
+                    "description": "Refresh токен",
+                    "type": "string"
+                }
+            }
+        },
+        "User": {
+            "description": "Модель пользователя, хз что ещё сказать можно по этому поводу.",
+            "type": "object",
+            "required": [
+                "blocked",
+                "confirmed",
+                "createdAt",
+                "deleted",
+                "email",
+                "id",
+                "role",
+                "updatedAt"
+            ],
+            "properties": {
+                "blocked": {
+                    "description": "Заблокирован ли пользователь",
+                    "type": "boolean",
+                    "example": false
+                },
+                "confirmed": {
+                    "description": "Подтверждён ли пользователь",
+                    "type": "boolean",
+                    "example": true
                 },
                 "createdAt": {
                     "description": "Timestamp создания аккаунта",
                     "type": "string",
-                    "format": "date-time",
-                    "example": "2006-01-02T15:04:05Z"
+                    "format": "date-time",
+                    "example": "2006-01-02T15:04:05Z"
+                },
+                "deleted": {
+                    "description": "Удалён ли пользователь",
+                    "type": "boolean",
+                    "example": true
+                },
+                "email": {
+                    "description": "Электронная почта пользователя",
+                    "type": "string",
+                    "example": "Dimka228@gmail.com"
+                },
+                "id": {
+                    "description": "ID пользователя",
+                    "type": "string",
+                    "example": "b4b03119-1290-44bc-b599-6a5e91d6611f"
+                },
+                "role": {
+                    "description": "Роль пользователя",
+                    "type": "string",
+                    "example": "user"
+                },
+                "updatedAt": {
+                    "description": "Timestamp последнего обновления аккаунта",
+                    "type": "string",
+                    "format": "date-time",
+                    "example": "2006-01-02T15:04:05Z"
+                },
+                "username": {
+                    "description": "Имя пользователя",
+                    "type": "string",
+                    "example": "Dimka228"
+                }
+            }
+        },
+        "_ResponseWithData": {
+            "description": "Общий ответ success/error, содержащий произвольные данные.",
+            "type": "object",
+            "properties": {
+                "data": {
+                    "description": "Объект полезной нагрузки"
+                },
+                "status": {
+                    "description": "Результат запроса",
+                    "type": "string"
+                }
+            }
+        },
+        "_ResponseWithMessage": {
+            "description": "Общий простой ответ, который передает только понятное для человека сообщение.",
+            "type": "object",
+            "properties": {
+                "message": {
+                    "description": "Человеко-читаемое сообщение",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "Результат запроса",
+                    "type": "string"
+                }
+            }
+        },
+        "hackathon-back_internal_model.CheckResultResponse": {
+            "type": "object",
+            "properties": {
+                "agentId": {
+                    "type": "string"
+                },
+                "agentRegion": {
+                    "type": "string"
+                },
+                "finishedAt": {
+                    "type": "string"
+                },
+                "payload": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "requestId": {
+                    "type": "string"
+                },
+                "startedAt": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "assignmentId": {
+                    "type": "string"
+                },
+                "checkIndex": {
+                    "type": "integer"
+                }
+            }
+        },
+        "hackathon-back_internal_model.FAQ": {
+            "type": "object",
+            "properties": {
+                "answer": {
+                    "type": "string",
+                    "example": "Для восстановления пароля используйте форму 'Забыли пароль' на странице входа."
+                },
+                "category": {
+                    "type": "string",
+                    "example": "authentication"
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2024-01-15T10:30:00Z"
+                },
+                "created_by": {
+                    "type": "string",
+                    "example": "1a2b3c4d-5678-90ab-cdef-1234567890ab"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "7b2aab2e-4d1f-45b5-90c5-4d5d4db5ef11"
+                },
+                "is_active": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "order": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "question": {
+                    "type": "string",
+                    "example": "Как восстановить пароль?"
+                },
+                "updated_at": {
+                    "type": "string",
+                    "example": "2024-01-15T10:30:00Z"
+                }
+            }
+        },
+        "hackathon-back_internal_model.FAQCategoryResponse": {
+            "type": "object",
+            "properties": {
+                "category": {
+                    "type": "string"
+                },
+                "faqs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/hackathon-back_internal_model.FAQ"
+                    }
+                }
+            }
+        },
+        "hackathon-back_internal_model.FAQCreateRequest": {
+            "type": "object",
+            "required": [
+                "answer",
+                "category",
+                "question"
+            ],
+            "properties": {
+                "answer": {
+                    "type": "string",
+                    "example": "Для восстановления пароля используйте форму 'Забыли пароль' на странице входа."
+                },
+                "category": {
+                    "type": "string",
+                    "example": "authentication"
+                },
+                "order": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "question": {
+                    "type": "string",
+                    "example": "Как восстановить пароль?"
+                }
+            }
+        },
+        "hackathon-back_internal_model.FAQListResponse": {
+            "type": "object",
+            "properties": {
+                "faqs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/hackathon-back_internal_model.FAQ"
+                    }
                 },
-                "id": {
-                    "description": "ID статьи (UUID)",
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "hackathon-back_internal_model.FAQUpdateRequest": {
+            "type": "object",
+            "properties": {
+                "answer": {
                     "type": "string",
-                    "example": "b4b03119-1290-44bc-b599-6a5e91d6611f"
+                    "example": "Используйте кнопку 'Забыли пароль' на странице входа."
                 },
-                "title_en": {
-                    "description": "Заголовок статьи на английском языке",
+                "category": {
                     "type": "string",
-                    "example": "Example"
+                    "example": "auth"
                 },
-                "title_ru": {
-                    "description": "Заголовок статьи на русском языке",
+                "is_active": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "order": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "question": {
                     "type": "string",
-                    "example": "Пример"
+                    "example": "Как сбросить пароль?"
+                }
+            }
+        },
+        "hackathon-back_internal_model.Request": {
+            "type": "object",
+            "properties": {
+                "broadcast": {
+                    "type": "boolean"
+                },
+                "checkTypes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "clientASN": {
+                    "type": "integer"
+                },
+                "clientCC": {
+                    "type": "string"
+                },
+                "clientIP": {
+                    "type": "string"
+                },
+                "clientRegion": {
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "requestJSON": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "status": {
+                    "type": "string"
+                },
+                "target": {
+                    "type": "string"
+                },
+                "timeoutSeconds": {
+                    "type": "integer"
                 },
                 "updatedAt": {
-                    "description": "Timestamp последнего обновления аккаунта",
+                    "type": "string"
+                },
+                "userAgent": {
+                    "type": "string"
+                },
+                "unassigned": {
+                    "description": "Unassigned проверки, которые не может выполнить ни один online агент, они не запускаются",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/UnassignedCheck"
+                    }
+                },
+                "targets": {
+                    "$ref": "#/definitions/TargetSelector"
+                },
+                "strategy": {
+                    "description": "Strategy стратегия, которой выбраны агенты",
+                    "type": "string"
+                }
+            }
+        },
+        "hackathon-back_internal_model.SearchResult": {
+            "type": "object",
+            "properties": {
+                "article": {
+                    "$ref": "#/definitions/Article"
+                },
+                "highlight": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "_FieldError": {
+            "description": "Ошибка валидации одного поля запроса.",
+            "type": "object",
+            "properties": {
+                "field": {
+                    "description": "Путь до поля в теле запроса",
                     "type": "string",
-                    "format": "date-time",
-                    "example": "2006-01-02T15:04:05Z"
+                    "example": "checks[0].params.count"
+                },
+                "message": {
+                    "description": "Что не так со значением",
+                    "type": "string",
+                    "example": "must be <= 100"
                 }
             }
         },
-        "ArticleCreateRequest": {
-            "description": "Данные, передаваемые для создания статьи.",
+        "_ResponseWithErrors": {
+            "description": "Ответ на невалидный запрос со списком ошибок по конкретным полям.",
             "type": "object",
-            "required": [
-                "content_en",
-                "content_ru",
-                "title_en",
-                "title_ru"
-            ],
             "properties": {
-                "content_en": {
-                    "description": "Содержимое статьи на английском",
+                "errors": {
+                    "description": "Ошибки по полям",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/_FieldError"
+                    }
+                },
+                "message": {
+                    "description": "Человеко-читаемое сообщение",
                     "type": "string"
                 },
-                "content_ru": {
-                    "description": "Содержимое статьи на русском",
+                "status": {
+                    "description": "Результат запроса",
                     "type": "string"
+                }
+            }
+        },
+        "Agent": {
+            "description": "агент и его последнее известное состояние по heartbeat",
+            "type": "object",
+            "properties": {
+                "asn": {
+                    "type": "integer"
                 },
-                "title_en": {
-                    "description": "Заголовок статьи на английском языке",
+                "id": {
                     "type": "string"
                 },
-                "title_ru": {
-                    "description": "Заголовок статьи на русском языке",
+                "inFlight": {
+                    "description": "InFlight задачи в работе",
+                    "type": "integer"
+                },
+                "lastSeen": {
+                    "description": "LastSeen время последнего heartbeat, null — агент ни разу не выходил на связь",
+                    "type": "string"
+                },
+                "online": {
+                    "type": "boolean"
+                },
+                "queueDepth": {
+                    "description": "QueueDepth задачи в очереди агента",
+                    "type": "integer"
+                },
+                "region": {
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "type": "string"
+                },
+                "uptimeSeconds": {
+                    "description": "UptimeSeconds аптайм агента на момент последнего heartbeat",
+                    "type": "integer"
+                },
+                "version": {
+                    "description": "Version версия сборки агента",
+                    "type": "string"
+                },
+                "capabilities": {
+                    "description": "Capabilities возможности агента, nil — агент их не сообщал",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/contract.Capabilities"
+                        }
+                    ]
+                },
+                "transport": {
+                    "description": "Transport kafka или http, определяет, как агент получает задачи",
+                    "type": "string",
+                    "example": "kafka"
+                },
+                "publicKey": {
+                    "description": "PublicKey ключ Ed25519 агента, привязанный при регистрации",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "country": {
+                    "description": "Country страна размещения из реестра, ISO 3166-1 alpha-2",
+                    "type": "string"
+                },
+                "lat": {
+                    "description": "Lat, Lon координаты из реестра, nil — не заданы",
+                    "type": "number"
+                },
+                "lon": {
+                    "type": "number"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "disabled": {
+                    "description": "Disabled агент выключен администратором: не получает задач и не выходит online",
+                    "type": "boolean"
+                },
+                "name": {
+                    "description": "Name имя агента в реестре",
+                    "type": "string"
+                },
+                "registered": {
+                    "description": "Registered агент заведён через реестр или прошёл регистрацию, heartbeat принимается только от таких агентов",
+                    "type": "boolean"
+                },
+                "organizationId": {
+                    "description": "OwnerOrgID владелец частного агента-организация",
+                    "type": "string"
+                },
+                "ownerUserId": {
+                    "description": "OwnerUserID владелец частного агента-пользователь",
                     "type": "string"
                 }
             }
         },
-        "AuthRequest": {
-            "description": "Данные, передаваемые в json для регистрации.",
+        "FleetResponse": {
+            "description": "состояние всех агентов",
             "type": "object",
-            "required": [
-                "email",
-                "password",
-                "username"
-            ],
             "properties": {
-                "email": {
-                    "description": "Электронная почта пользователя",
-                    "type": "string",
-                    "format": "email",
-                    "example": "Dimka228@gmail.com"
+                "agents": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/Agent"
+                    }
                 },
-                "password": {
-                    "description": "Пароль пользователя",
+                "offline": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "online": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
+        "UnassignedCheck": {
+            "description": "проверка, которую не может выполнить ни один online агент, и почему",
+            "type": "object",
+            "properties": {
+                "index": {
+                    "description": "Index номер проверки в запросе",
+                    "type": "integer",
+                    "example": 2
+                },
+                "reason": {
+                    "description": "Reason почему ни один агент не может её выполнить",
                     "type": "string",
-                    "format": "password",
-                    "minLength": 8,
-                    "example": "12345678"
+                    "example": "agent has no raw ICMP privilege"
                 },
-                "username": {
-                    "description": "Имя пользователя",
+                "type": {
+                    "description": "Type тип проверки",
                     "type": "string",
-                    "example": "Dimka228"
+                    "example": "traceroute"
                 }
             }
         },
-        "AuthResponse": {
-            "description": "Данные, которые получает пользователь после регистрации.",
+        "contract.Capabilities": {
             "type": "object",
-            "required": [
-                "token"
-            ],
             "properties": {
-                "token": {
-                    "description": "Токен для подтверждения регистрации",
+                "checks": {
+                    "description": "Checks включённые проверки, для которых на хосте есть нужные утилиты",
                     "type": "array",
                     "items": {
-                        "type": "integer"
+                        "type": "string"
                     }
                 },
-                "user": {
-                    "$ref": "#/definitions/User"
+                "icmp": {
+                    "description": "ICMP агент может открыть raw сокет, без него traceroute работает только в режиме udp",
+                    "type": "boolean"
+                },
+                "ipv4": {
+                    "type": "boolean"
+                },
+                "ipv6": {
+                    "type": "boolean"
                 }
             }
         },
-        "AuthToken": {
-            "description": "Токен для подтверждения регистрации.",
+        "contract.GatewayMessage": {
             "type": "object",
-            "required": [
-                "token"
-            ],
             "properties": {
-                "token": {
-                    "description": "Токен для подтверждения регистрации",
+                "key": {
+                    "type": "string"
+                },
+                "topic": {
+                    "type": "string"
+                },
+                "value": {
                     "type": "array",
                     "items": {
                         "type": "integer"
@@ -2214,481 +5188,644 @@ const docTemplate = `{
                 }
             }
         },
-        "CheckRequestRequest": {
-            "description": "описание одной проверки",
+        "contract.GatewayPoll": {
             "type": "object",
-            "required": [
-                "params",
-                "type"
-            ],
             "properties": {
-                "params": {
-                    "description": "Params параметры проверки",
-                    "type": "object",
-                    "additionalProperties": true
-                },
-                "type": {
-                    "description": "Type тип проверки: http|ping|tcp|traceroute|dns",
-                    "type": "string",
-                    "example": "http"
+                "messages": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/contract.GatewayMessage"
+                    }
                 }
             }
         },
-        "ConfirmationRequest": {
-            "description": "Запрос на подтверждение регистрации.",
+        "hackathon-back_internal_model.AgentResults": {
             "type": "object",
-            "required": [
-                "code",
-                "token"
-            ],
             "properties": {
-                "code": {
-                    "description": "Код, полученный с email",
-                    "type": "string",
-                    "example": "0228"
+                "agentId": {
+                    "type": "string"
                 },
-                "token": {
-                    "description": "Токен, который вернул handler register/resend-confirmation",
-                    "type": "string",
-                    "example": "89as098ga0998=asdg=+afgk=="
+                "agentRegion": {
+                    "type": "string"
+                },
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/hackathon-back_internal_model.CheckResultResponse"
+                    }
                 }
             }
         },
-        "ForgotPasswordRequest": {
-            "description": "Запрос на восстановление пароля.",
+        "TargetSelector": {
+            "description": "где выполнять проверки. Разные поля сужают выбор агентов вместе, значения внутри поля — любое из них. Каждое значение самого точного заданного поля (agentIds, затем countries, asns, regions) получает своего агента, spread — N агентов из разных регионов. Если какому-то значению не нашлось online агента, запрос отклоняется с причиной.",
             "type": "object",
-            "required": [
-                "email"
-            ],
             "properties": {
-                "email": {
-                    "description": "Почта, на которую придёт письмо для восстановления пароля",
-                    "type": "string"
+                "agentIds": {
+                    "description": "AgentIDs конкретные агенты",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "6d40a8b9-a135-4b67-b96b-0579c6ae0f76"
+                    ]
+                },
+                "asns": {
+                    "description": "ASNs автономные системы сетей агентов",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        24940
+                    ]
+                },
+                "countries": {
+                    "description": "Countries страны агентов, ISO 3166-1 alpha-2",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "DE",
+                        "FI"
+                    ]
+                },
+                "regions": {
+                    "description": "Regions регионы агентов",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "EU",
+                        "US"
+                    ]
+                },
+                "spread": {
+                    "description": "Spread N агентов из N разных регионов",
+                    "type": "integer",
+                    "example": 3
                 }
             }
         },
-        "LoginRequest": {
-            "description": "Данные, для входа.",
+        "AgentCreateRequest": {
+            "description": "новый агент реестра. Регион после создания не меняется: агент читает топик задач своего региона",
             "type": "object",
             "required": [
-                "email",
-                "password"
+                "region"
             ],
             "properties": {
-                "email": {
-                    "description": "Электронная почта пользователя",
+                "asn": {
+                    "type": "integer",
+                    "example": 24940
+                },
+                "country": {
+                    "description": "Country ISO 3166-1 alpha-2",
                     "type": "string",
-                    "format": "email",
-                    "example": "Dimka228@gmail.com"
+                    "example": "DE"
                 },
-                "password": {
-                    "description": "Пароль пользователя",
+                "lat": {
+                    "description": "Lat, Lon задаются вместе",
+                    "type": "number",
+                    "example": 50.11
+                },
+                "lon": {
+                    "type": "number",
+                    "example": 8.68
+                },
+                "name": {
                     "type": "string",
-                    "format": "password",
-                    "minLength": 8,
-                    "example": "12345678"
+                    "example": "fra-1"
+                },
+                "region": {
+                    "type": "string",
+                    "example": "EU"
+                },
+                "transport": {
+                    "description": "Transport kafka или http, по умолчанию kafka",
+                    "type": "string",
+                    "example": "kafka"
+                },
+                "organizationId": {
+                    "description": "OrganizationID частный агент организации",
+                    "type": "string",
+                    "example": "3f1c2a9e-8a4b-4c1e-9a55-0d7c1e2b6f10"
                 }
             }
         },
-        "RefreshRequest": {
-            "description": "Запрос, в котор передаёт refresh токен мобильное приложение",
+        "AgentCreateResponse": {
+            "description": "созданный агент и токен, который нужно передать агенту (enrollment.token)",
             "type": "object",
             "properties": {
-                "refreshToken": {
-                    "description": "Refresh токен",
-                    "type": "string"
+                "agent": {
+                    "$ref": "#/definitions/Agent"
+                },
+                "enrollment": {
+                    "$ref": "#/definitions/AgentEnrollmentToken"
                 }
             }
         },
-        "ResendRequest": {
-            "description": "Запрос на переотправку кода подтверждения.",
+        "AgentEnrollment": {
+            "description": "выданный токен регистрации и его использование",
             "type": "object",
-            "required": [
-                "email"
-            ],
             "properties": {
-                "email": {
-                    "description": "Электронная почта пользователя",
+                "agentId": {
+                    "type": "string"
+                },
+                "agentVersion": {
+                    "description": "AgentVersion версия агента при регистрации",
                     "type": "string",
-                    "format": "email",
-                    "example": "Dimka228@gmail.com"
+                    "example": "1.4.0"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "expiresAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "ip": {
+                    "description": "IP адрес, с которого агент зарегистрировался",
+                    "type": "string",
+                    "example": "203.0.113.7"
+                },
+                "usedAt": {
+                    "description": "UsedAt когда агент обменял токен, null — ещё не использован",
+                    "type": "string"
                 }
             }
         },
-        "ResetPasswordRequest": {
-            "description": "Запрос на сброс пароля.",
+        "AgentEnrollmentToken": {
+            "description": "одноразовый токен регистрации, показывается один раз",
             "type": "object",
-            "required": [
-                "newPassword",
-                "token"
-            ],
             "properties": {
-                "newPassword": {
-                    "description": "Новый пароль",
+                "agentId": {
+                    "type": "string",
+                    "example": "6d40a8b9-a135-4b67-b96b-0579c6ae0f76"
+                },
+                "expiresAt": {
                     "type": "string"
                 },
                 "token": {
-                    "description": "Токен, полученный по ссылке из письма",
-                    "type": "string"
+                    "type": "string",
+                    "example": "Zm9vYmFy..."
                 }
             }
         },
-        "TaskMessageRequest": {
-            "description": "Task для выполнения проверок сети (HTTP, ping, TCP, traceroute, DNS)",
+        "AgentUpdateRequest": {
+            "description": "изменение агента реестра, не заданные поля не меняются",
             "type": "object",
-            "required": [
-                "checks",
-                "target",
-                "timeoutSeconds"
-            ],
             "properties": {
-                "broadcast": {
-                    "description": "Отправлять ли запрос на агенты всех регионов или берётся ближайший 1 агент к клиенту",
+                "asn": {
+                    "type": "integer",
+                    "example": 24940
+                },
+                "country": {
+                    "type": "string",
+                    "example": "DE"
+                },
+                "disabled": {
+                    "description": "Disabled выключить агента или включить обратно",
                     "type": "boolean",
-                    "example": false
+                    "example": true
                 },
-                "checks": {
-                    "description": "Checks список проверок",
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/CheckRequestRequest"
-                    }
+                "lat": {
+                    "description": "Lat, Lon задаются вместе",
+                    "type": "number",
+                    "example": 50.11
                 },
-                "target": {
-                    "description": "Target домен или IP, который нужно проверить",
-                    "type": "string",
-                    "example": "example.com"
+                "lon": {
+                    "type": "number",
+                    "example": 8.68
                 },
-                "timeoutSeconds": {
-                    "description": "TimeoutSeconds время выполнения всех задачи в секундах",
-                    "type": "integer",
-                    "example": 20
+                "name": {
+                    "type": "string",
+                    "example": "fra-1"
                 }
             }
         },
-        "TokenResponse": {
-            "description": "Ответ, содержащий access и refresh токены",
+        "contract.EnrollRequest": {
             "type": "object",
             "properties": {
-                "accessToken": {
-                    "description": "Access токен",
+                "agentVersion": {
+                    "description": "AgentVersion версия сборки агента, сохраняется в истории регистраций",
                     "type": "string"
                 },
-                "refreshToken": {
-                    "description": "Refresh токен",
+                "publicKey": {
+                    "description": "PublicKey публичный ключ Ed25519 агента, base64",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "token": {
+                    "type": "string"
+                },
+                "version": {
+                    "description": "Version версия контракта, см. SchemaVersion",
                     "type": "string"
                 }
             }
         },
-        "User": {
-            "description": "Модель пользователя, хз что ещё сказать можно по этому поводу.",
+        "contract.EnrollResponse": {
             "type": "object",
-            "required": [
-                "blocked",
-                "confirmed",
-                "createdAt",
-                "deleted",
-                "email",
-                "id",
-                "role",
-                "updatedAt"
-            ],
             "properties": {
-                "blocked": {
-                    "description": "Заблокирован ли пользователь",
-                    "type": "boolean",
-                    "example": false
-                },
-                "confirmed": {
-                    "description": "Подтверждён ли пользователь",
-                    "type": "boolean",
-                    "example": true
+                "agentId": {
+                    "type": "string"
                 },
-                "createdAt": {
-                    "description": "Timestamp создания аккаунта",
-                    "type": "string",
-                    "format": "date-time",
-                    "example": "2006-01-02T15:04:05Z"
+                "asn": {
+                    "type": "integer"
                 },
-                "deleted": {
-                    "description": "Удалён ли пользователь",
-                    "type": "boolean",
-                    "example": true
+                "backendPublicKey": {
+                    "description": "BackendPublicKey ключ Ed25519, которым бэкенд подписывает задачи, base64",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
                 },
-                "email": {
-                    "description": "Электронная почта пользователя",
-                    "type": "string",
-                    "example": "Dimka228@gmail.com"
+                "brokers": {
+                    "description": "Brokers адреса Kafka для TransportKafka",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
                 },
-                "id": {
-                    "description": "ID пользователя",
-                    "type": "string",
-                    "example": "b4b03119-1290-44bc-b599-6a5e91d6611f"
+                "country": {
+                    "type": "string"
                 },
-                "role": {
-                    "description": "Роль пользователя",
-                    "type": "string",
-                    "example": "user"
+                "gatewayToken": {
+                    "description": "GatewayToken токен HTTP транспорта, выданный только этому агенту; пусто — gateway выключен",
+                    "type": "string"
                 },
-                "updatedAt": {
-                    "description": "Timestamp последнего обновления аккаунта",
-                    "type": "string",
-                    "format": "date-time",
-                    "example": "2006-01-02T15:04:05Z"
+                "location": {
+                    "$ref": "#/definitions/contract.Location"
                 },
-                "username": {
-                    "description": "Имя пользователя",
-                    "type": "string",
-                    "example": "Dimka228"
+                "region": {
+                    "type": "string"
+                },
+                "topics": {
+                    "$ref": "#/definitions/contract.EnrollTopics"
+                },
+                "transport": {
+                    "description": "Transport TransportKafka или TransportHTTP",
+                    "type": "string"
                 }
             }
         },
-        "_ResponseWithData": {
-            "description": "Общий ответ success/error, содержащий произвольные данные.",
+        "contract.EnrollTopics": {
             "type": "object",
             "properties": {
-                "data": {
-                    "description": "Объект полезной нагрузки"
+                "heartbeat": {
+                    "type": "string"
                 },
-                "status": {
-                    "description": "Результат запроса",
+                "results": {
+                    "type": "string"
+                },
+                "tasks": {
                     "type": "string"
                 }
             }
         },
-        "_ResponseWithMessage": {
-            "description": "Общий простой ответ, который передает только понятное для человека сообщение.",
+        "contract.Location": {
             "type": "object",
             "properties": {
-                "message": {
-                    "description": "Человеко-читаемое сообщение",
-                    "type": "string"
+                "lat": {
+                    "type": "number"
                 },
-                "status": {
-                    "description": "Результат запроса",
-                    "type": "string"
+                "lon": {
+                    "type": "number"
                 }
             }
         },
-        "hackathon-back_internal_model.CheckResultResponse": {
+        "PartitionHealth": {
+            "description": "состояние партиции топика",
             "type": "object",
             "properties": {
-                "agentId": {
-                    "type": "string"
-                },
-                "agentRegion": {
+                "error": {
                     "type": "string"
                 },
-                "finishedAt": {
-                    "type": "string"
+                "id": {
+                    "type": "integer"
                 },
-                "payload": {
+                "isr": {
+                    "description": "ISR синхронные реплики",
                     "type": "array",
                     "items": {
                         "type": "integer"
                     }
                 },
-                "requestId": {
-                    "type": "string"
-                },
-                "startedAt": {
-                    "type": "string"
-                },
-                "status": {
-                    "type": "string"
+                "leader": {
+                    "description": "Leader id брокера-лидера, -1 — лидера нет",
+                    "type": "integer"
                 },
-                "type": {
-                    "type": "string"
+                "replicas": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
                 }
             }
         },
-        "hackathon-back_internal_model.FAQ": {
+        "TopicHealth": {
+            "description": "состояние топика Kafka по метаданным кластера",
             "type": "object",
             "properties": {
-                "answer": {
-                    "type": "string",
-                    "example": "Для восстановления пароля используйте форму 'Забыли пароль' на странице входа."
+                "error": {
+                    "description": "Error ошибка метаданных топика",
+                    "type": "string"
                 },
-                "category": {
-                    "type": "string",
-                    "example": "authentication"
+                "exists": {
+                    "description": "Exists топик есть в кластере",
+                    "type": "boolean"
                 },
-                "created_at": {
+                "healthy": {
+                    "description": "Healthy у всех партиций есть лидер и полный ISR",
+                    "type": "boolean"
+                },
+                "name": {
                     "type": "string",
-                    "example": "2024-01-15T10:30:00Z"
+                    "example": "hosts-check-EU"
                 },
-                "created_by": {
+                "offline": {
+                    "description": "Offline партиции без лидера",
+                    "type": "integer"
+                },
+                "partitions": {
+                    "description": "Partitions партиции топика",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/PartitionHealth"
+                    }
+                },
+                "region": {
+                    "description": "Region регион топика задач",
                     "type": "string",
-                    "example": "1a2b3c4d-5678-90ab-cdef-1234567890ab"
+                    "example": "EU"
                 },
-                "id": {
+                "replicationFactor": {
+                    "description": "ReplicationFactor число реплик первой партиции",
+                    "type": "integer",
+                    "example": 1
+                },
+                "role": {
+                    "description": "Role tasks — задачи региона, results — результаты агентов, heartbeat — heartbeat агентов",
                     "type": "string",
-                    "example": "7b2aab2e-4d1f-45b5-90c5-4d5d4db5ef11"
+                    "example": "tasks"
                 },
-                "is_active": {
-                    "type": "boolean",
-                    "example": true
+                "underReplicated": {
+                    "description": "UnderReplicated партиции, у которых ISR меньше числа реплик",
+                    "type": "integer"
+                }
+            }
+        },
+        "Location": {
+            "description": "регион, который можно выбрать в targets.regions: публичный или пула частных агентов",
+            "type": "object",
+            "properties": {
+                "agents": {
+                    "description": "Agents агентов в регионе",
+                    "type": "integer",
+                    "example": 3
                 },
-                "order": {
+                "countries": {
+                    "description": "Countries страны агентов региона",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "DE",
+                        "FI"
+                    ]
+                },
+                "online": {
+                    "description": "Online из них online",
                     "type": "integer",
-                    "example": 1
+                    "example": 2
                 },
-                "question": {
+                "organizationId": {
+                    "description": "OrganizationID организация-владелец пула",
                     "type": "string",
-                    "example": "Как восстановить пароль?"
+                    "example": "3f1c2a9e-8a4b-4c1e-9a55-0d7c1e2b6f10"
                 },
-                "updated_at": {
+                "private": {
+                    "description": "Private регион частных агентов пользователя или его организации",
+                    "type": "boolean"
+                },
+                "region": {
                     "type": "string",
-                    "example": "2024-01-15T10:30:00Z"
+                    "example": "EU"
                 }
             }
         },
-        "hackathon-back_internal_model.FAQCategoryResponse": {
+        "Organization": {
+            "description": "организация пользователей, владеющая пулом частных агентов",
             "type": "object",
             "properties": {
-                "category": {
+                "createdAt": {
                     "type": "string"
                 },
-                "faqs": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/hackathon-back_internal_model.FAQ"
-                    }
+                "createdBy": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "3f1c2a9e-8a4b-4c1e-9a55-0d7c1e2b6f10"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Acme"
                 }
             }
         },
-        "hackathon-back_internal_model.FAQCreateRequest": {
+        "OrganizationCreateRequest": {
+            "description": "новая организация, создатель становится её участником",
             "type": "object",
             "required": [
-                "answer",
-                "category",
-                "question"
+                "name"
             ],
             "properties": {
-                "answer": {
+                "name": {
                     "type": "string",
-                    "example": "Для восстановления пароля используйте форму 'Забыли пароль' на странице входа."
+                    "example": "Acme"
+                }
+            }
+        },
+        "OrganizationMember": {
+            "description": "участник организации",
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "description": "CreatedAt когда пользователь добавлен в организацию",
+                    "type": "string"
                 },
-                "category": {
+                "email": {
                     "type": "string",
-                    "example": "authentication"
+                    "example": "Dimka228@gmail.com"
                 },
-                "order": {
-                    "type": "integer",
-                    "example": 1
+                "userId": {
+                    "type": "string"
                 },
-                "question": {
+                "username": {
                     "type": "string",
-                    "example": "Как восстановить пароль?"
+                    "example": "Dimka228"
                 }
             }
         },
-        "hackathon-back_internal_model.FAQListResponse": {
+        "OrganizationMemberRequest": {
+            "description": "пользователь, которого нужно добавить в организацию",
             "type": "object",
+            "required": [
+                "userId"
+            ],
             "properties": {
-                "faqs": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/hackathon-back_internal_model.FAQ"
-                    }
-                },
-                "total": {
-                    "type": "integer"
+                "userId": {
+                    "type": "string",
+                    "example": "b4b03119-1290-44bc-b599-6a5e91d6611f"
                 }
             }
         },
-        "hackathon-back_internal_model.FAQUpdateRequest": {
+        "Monitor": {
+            "description": "периодическая задача проверок пользователя",
             "type": "object",
             "properties": {
-                "answer": {
-                    "type": "string",
-                    "example": "Используйте кнопку 'Забыли пароль' на странице входа."
+                "createdAt": {
+                    "type": "string"
                 },
-                "category": {
+                "cron": {
+                    "description": "Cron расписание в формате cron из 5 полей, UTC",
                     "type": "string",
-                    "example": "auth"
+                    "example": "*/5 * * * *"
                 },
-                "is_active": {
+                "enabled": {
                     "type": "boolean",
-                    "example": false
+                    "example": true
                 },
-                "order": {
+                "id": {
+                    "type": "string",
+                    "example": "0b6f3a52-54a1-4f55-9c1e-2f7d1c9a8e11"
+                },
+                "intervalSeconds": {
+                    "description": "IntervalSeconds период запуска; задаётся либо он, либо cron",
                     "type": "integer",
-                    "example": 2
+                    "example": 300
                 },
-                "question": {
+                "lastRunAt": {
+                    "type": "string"
+                },
+                "name": {
                     "type": "string",
-                    "example": "Как сбросить пароль?"
+                    "example": "example.com availability"
+                },
+                "nextRunAt": {
+                    "type": "string"
+                },
+                "task": {
+                    "description": "Task задача, которая ставится в очередь при каждом запуске",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/TaskMessageRequest"
+                        }
+                    ]
+                },
+                "updatedAt": {
+                    "type": "string"
+                },
+                "userId": {
+                    "type": "string"
                 }
             }
         },
-        "hackathon-back_internal_model.Request": {
+        "MonitorCreateRequest": {
+            "description": "новый монитор: задача в формате /check/task и расписание — intervalSeconds или cron. Где выполнять проверки, задаёт task.targets.",
             "type": "object",
+            "required": [
+                "name",
+                "task"
+            ],
             "properties": {
-                "broadcast": {
-                    "type": "boolean"
-                },
-                "checkTypes": {
-                    "type": "array",
-                    "items": {
-                        "type": "string"
-                    }
+                "cron": {
+                    "type": "string",
+                    "example": "*/5 * * * *"
                 },
-                "clientASN": {
-                    "type": "integer"
+                "enabled": {
+                    "description": "Enabled по умолчанию true",
+                    "type": "boolean",
+                    "example": true
                 },
-                "clientCC": {
-                    "type": "string"
+                "intervalSeconds": {
+                    "type": "integer",
+                    "example": 300
                 },
-                "clientIP": {
-                    "type": "string"
+                "name": {
+                    "type": "string",
+                    "example": "example.com availability"
                 },
-                "clientRegion": {
+                "task": {
+                    "$ref": "#/definitions/TaskMessageRequest"
+                }
+            }
+        },
+        "MonitorRun": {
+            "description": "запуск монитора",
+            "type": "object",
+            "properties": {
+                "createdAt": {
                     "type": "string"
                 },
-                "createdAt": {
+                "error": {
                     "type": "string"
                 },
                 "id": {
                     "type": "string"
                 },
-                "requestJSON": {
-                    "type": "array",
-                    "items": {
-                        "type": "integer"
-                    }
-                },
-                "status": {
+                "monitorId": {
                     "type": "string"
                 },
-                "target": {
+                "requestId": {
+                    "description": "RequestID запрос, результаты которого смотреть в /check/{request_id}",
                     "type": "string"
                 },
-                "timeoutSeconds": {
-                    "type": "integer"
-                },
-                "updatedAt": {
+                "requestStatus": {
+                    "description": "RequestStatus статус созданного запроса",
                     "type": "string"
                 },
-                "userAgent": {
+                "scheduledAt": {
+                    "description": "ScheduledAt на когда был запланирован запуск",
                     "type": "string"
+                },
+                "status": {
+                    "description": "Status DISPATCHED или FAILED",
+                    "type": "string",
+                    "example": "DISPATCHED"
                 }
             }
         },
-        "hackathon-back_internal_model.SearchResult": {
+        "MonitorUpdateRequest": {
+            "description": "изменение монитора, не заданные поля не меняются. Заданный intervalSeconds или cron заменяет расписание.",
             "type": "object",
             "properties": {
-                "article": {
-                    "$ref": "#/definitions/Article"
+                "cron": {
+                    "type": "string",
+                    "example": "0 * * * *"
                 },
-                "highlight": {
-                    "type": "object",
-                    "additionalProperties": {
-                        "type": "array",
-                        "items": {
-                            "type": "string"
-                        }
-                    }
+                "enabled": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "intervalSeconds": {
+                    "type": "integer",
+                    "example": 600
+                },
+                "name": {
+                    "type": "string",
+                    "example": "example.com availability"
+                },
+                "task": {
+                    "$ref": "#/definitions/TaskMessageRequest"
                 }
             }
         }
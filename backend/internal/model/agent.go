@@ -4,12 +4,136 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"hackathon-contract"
 )
 
+// Agent
+// @Description агент и его последнее известное состояние по heartbeat
 type Agent struct {
-	ID        uuid.UUID `db:"id" json:"id"`
-	Region    string    `db:"region" json:"region"`
-	ASN       int       `db:"asn" json:"asn"`
-	Online    bool      `db:"online" json:"online"`
-	UpdatedAt time.Time `db:"updated_at" json:"updatedAt"`
+	ID            uuid.UUID              `db:"id" json:"id"`
+	Name          string                 `db:"name" json:"name,omitempty"` // Name имя агента в реестре
+	Region        string                 `db:"region" json:"region"`
+	Country       string                 `db:"country" json:"country,omitempty"` // Country страна размещения из реестра, ISO 3166-1 alpha-2
+	ASN           int                    `db:"asn" json:"asn"`
+	Lat           *float64               `db:"lat" json:"lat,omitempty"` // Lat, Lon координаты из реестра, nil — не заданы
+	Lon           *float64               `db:"lon" json:"lon,omitempty"`
+	Online        bool                   `db:"online" json:"online"`
+	UpdatedAt     time.Time              `db:"updated_at" json:"updatedAt"`
+	LastSeen      *time.Time             `db:"last_seen" json:"lastSeen"`                  // LastSeen время последнего heartbeat, null — агент ни разу не выходил на связь
+	Version       string                 `db:"version" json:"version"`                     // Version версия сборки агента
+	UptimeSeconds int64                  `db:"uptime_seconds" json:"uptimeSeconds"`        // UptimeSeconds аптайм агента на момент последнего heartbeat
+	InFlight      int                    `db:"in_flight" json:"inFlight"`                  // InFlight задачи в работе
+	QueueDepth    int                    `db:"queue_depth" json:"queueDepth"`              // QueueDepth задачи в очереди агента
+	Capabilities  *contract.Capabilities `db:"capabilities" json:"capabilities,omitempty"` // Capabilities возможности агента, nil — агент их не сообщал
+	Transport     string                 `db:"transport" json:"transport" example:"kafka"` // Transport kafka или http, определяет, как агент получает задачи
+	PublicKey     []byte                 `db:"public_key" json:"publicKey,omitempty"`      // PublicKey ключ Ed25519 агента, привязанный при регистрации
+	Registered    bool                   `db:"registered" json:"registered"`               // Registered агент заведён через реестр или прошёл регистрацию, heartbeat принимается только от таких агентов
+	Disabled      bool                   `db:"disabled" json:"disabled"`                   // Disabled агент выключен администратором: не получает задач и не выходит online
+	CreatedAt     time.Time              `db:"created_at" json:"createdAt"`
+	OwnerUserID   *uuid.UUID             `db:"owner_user_id" json:"ownerUserId,omitempty"`   // OwnerUserID владелец частного агента-пользователь
+	OwnerOrgID    *uuid.UUID             `db:"owner_org_id" json:"organizationId,omitempty"` // OwnerOrgID владелец частного агента-организация
+} // @Name Agent
+
+// IsPrivate агент принадлежит пользователю или организации и получает задачи только от владельца.
+func (a *Agent) IsPrivate() bool {
+	return a.OwnerUserID != nil || a.OwnerOrgID != nil
+}
+
+// IsPublic агент без владельца вне регионов частных пулов. Агент без владельца в регионе пула
+// не публичный и не частный: задач он не получает.
+func (a *Agent) IsPublic() bool {
+	return !a.IsPrivate() && !IsPrivateRegion(a.Region)
+}
+
+// CanRun проверяет, что агент может выполнить проверку. Агент без capabilities (до версии 1.4) считается способным на всё.
+func (a *Agent) CanRun(target string, check contract.CheckRequest) error {
+	if a.Capabilities == nil {
+		return nil
+	}
+
+	return a.Capabilities.CanRun(target, check)
+}
+
+// AgentHeartbeat состояние агента из heartbeat, которое сохраняется в domain.agents.
+type AgentHeartbeat struct {
+	AgentID       uuid.UUID
+	Version       string
+	UptimeSeconds int64
+	InFlight      int
+	QueueDepth    int
+	Capabilities  *contract.Capabilities
+	Transport     string
+}
+
+// UnassignedCheck
+// @Description проверка, которую не может выполнить ни один online агент, и почему
+type UnassignedCheck struct {
+	Index  int    `json:"index" example:"2"`                                // Index номер проверки в запросе
+	Type   string `json:"type" example:"traceroute"`                        // Type тип проверки
+	Reason string `json:"reason" example:"agent has no raw ICMP privilege"` // Reason почему ни один агент не может её выполнить
+} // @Name UnassignedCheck
+
+// FleetResponse
+// @Description состояние всех агентов
+type FleetResponse struct {
+	Total   int      `json:"total" example:"3"`
+	Online  int      `json:"online" example:"2"`
+	Offline int      `json:"offline" example:"1"`
+	Agents  []*Agent `json:"agents"`
+} // @Name FleetResponse
+
+// AgentCreateRequest
+// @Description новый агент реестра. Регион после создания не меняется: агент читает топик задач своего региона
+type AgentCreateRequest struct {
+	Name      string   `json:"name" example:"fra-1"`
+	Region    string   `json:"region" binding:"required" example:"EU"`
+	Country   string   `json:"country,omitempty" example:"DE"` // Country ISO 3166-1 alpha-2
+	ASN       int      `json:"asn,omitempty" example:"24940"`
+	Lat       *float64 `json:"lat,omitempty" example:"50.11"` // Lat, Lon задаются вместе
+	Lon       *float64 `json:"lon,omitempty" example:"8.68"`
+	Transport string   `json:"transport,omitempty" example:"kafka"` // Transport kafka или http, по умолчанию kafka
+
+	OrganizationID *uuid.UUID `json:"organizationId,omitempty" example:"3f1c2a9e-8a4b-4c1e-9a55-0d7c1e2b6f10"` // OrganizationID частный агент организации
+} // @Name AgentCreateRequest
+
+// AgentUpdateRequest
+// @Description изменение агента реестра, не заданные поля не меняются
+type AgentUpdateRequest struct {
+	Name     *string  `json:"name,omitempty" example:"fra-1"`
+	Country  *string  `json:"country,omitempty" example:"DE"`
+	ASN      *int     `json:"asn,omitempty" example:"24940"`
+	Lat      *float64 `json:"lat,omitempty" example:"50.11"` // Lat, Lon задаются вместе
+	Lon      *float64 `json:"lon,omitempty" example:"8.68"`
+	Disabled *bool    `json:"disabled,omitempty" example:"true"` // Disabled выключить агента или включить обратно
+} // @Name AgentUpdateRequest
+
+// AgentEnrollmentToken
+// @Description одноразовый токен регистрации, показывается один раз
+type AgentEnrollmentToken struct {
+	AgentID   uuid.UUID `json:"agentId" example:"6d40a8b9-a135-4b67-b96b-0579c6ae0f76"`
+	Token     string    `json:"token" example:"Zm9vYmFy..."`
+	ExpiresAt time.Time `json:"expiresAt"`
+} // @Name AgentEnrollmentToken
+
+// AgentCreateResponse
+// @Description созданный агент и токен, который нужно передать агенту (enrollment.token)
+type AgentCreateResponse struct {
+	Agent      *Agent               `json:"agent"`
+	Enrollment AgentEnrollmentToken `json:"enrollment"`
+} // @Name AgentCreateResponse
+
+// AgentEnrollment
+// @Description выданный токен регистрации и его использование
+type AgentEnrollment struct {
+	ID           uuid.UUID  `db:"id" json:"id"`
+	AgentID      uuid.UUID  `db:"agent_id" json:"agentId"`
+	CreatedAt    time.Time  `db:"created_at" json:"createdAt"`
+	ExpiresAt    time.Time  `db:"expires_at" json:"expiresAt"`
+	UsedAt       *time.Time `db:"used_at" json:"usedAt"`                                       // UsedAt когда агент обменял токен, null — ещё не использован
+	AgentVersion string     `db:"agent_version" json:"agentVersion,omitempty" example:"1.4.0"` // AgentVersion версия агента при регистрации
+	IP           string     `db:"ip" json:"ip,omitempty" example:"203.0.113.7"`                // IP адрес, с которого агент зарегистрировался
+} // @Name AgentEnrollment
+
+type AgentIDPathParam struct {
+	ID string `uri:"agent_id" binding:"required,uuid" example:"6d40a8b9-a135-4b67-b96b-0579c6ae0f76"`
 }
@@ -0,0 +1,11 @@
+package model
+
+// AgentIDKey ключ контекста с id агента, прошедшего авторизацию gateway.
+const AgentIDKey = "agent_id"
+
+// AgentIDHeader заголовок, в котором агент передаёт свой id.
+const AgentIDHeader = "X-Agent-ID"
+
+type GatewayPollQuery struct {
+	Wait int `form:"wait" binding:"omitempty,min=0" example:"25"` // Wait сколько секунд ждать задач, не больше gateway.max_wait
+}
@@ -8,18 +8,63 @@ import (
 )
 
 // TaskMessageRequest представляет задачу для агента
-// @Description Task для выполнения проверок сети (HTTP, ping, TCP, traceroute, DNS)
+// @Description Task для выполнения проверок сети (HTTP, ping, TCP, traceroute, DNS, WebSocket, gRPC, throughput)
 type TaskMessageRequest struct {
 	Target         string                `binding:"required" json:"target" example:"example.com"` // Target домен или IP, который нужно проверить
 	TimeoutSeconds int                   `binding:"required" json:"timeoutSeconds" example:"20"`  // TimeoutSeconds время выполнения всех задачи в секундах
 	Broadcast      bool                  `json:"broadcast" example:"false"`                       // Отправлять ли запрос на агенты всех регионов или берётся ближайший 1 агент к клиенту
+	Targets        *TargetSelector       `json:"targets,omitempty"`                               // Targets где выполнять проверки; с broadcast — фильтр агентов
+	Strategy       string                `json:"strategy,omitempty" example:"geo"`                // Strategy порядок выбора агентов: geo, round_robin, least_loaded, latency; по умолчанию из конфига
 	Checks         []CheckRequestRequest `binding:"required" json:"checks"`                       // Checks список проверок
 } // @Name TaskMessageRequest
 
+// TargetSelector
+// @Description где выполнять проверки. Разные поля сужают выбор агентов вместе, значения внутри поля — любое из них.
+// @Description Каждое значение самого точного заданного поля (agentIds, затем countries, asns, regions) получает своего агента,
+// @Description spread — N агентов из разных регионов. Если какому-то значению не нашлось online агента, запрос отклоняется с причиной.
+type TargetSelector struct {
+	Regions   []string    `json:"regions,omitempty" example:"EU,US"`                                 // Regions регионы агентов
+	Countries []string    `json:"countries,omitempty" example:"DE,FI"`                               // Countries страны агентов, ISO 3166-1 alpha-2
+	ASNs      []int       `json:"asns,omitempty" example:"24940"`                                    // ASNs автономные системы сетей агентов
+	AgentIDs  []uuid.UUID `json:"agentIds,omitempty" example:"6d40a8b9-a135-4b67-b96b-0579c6ae0f76"` // AgentIDs конкретные агенты
+	Spread    int         `json:"spread,omitempty" example:"3"`                                      // Spread N агентов из N разных регионов
+} // @Name TargetSelector
+
+// IsEmpty селектор ничего не задаёт: агенты выбираются как без него.
+func (t *TargetSelector) IsEmpty() bool {
+	return t == nil || len(t.Regions) == 0 && len(t.Countries) == 0 && len(t.ASNs) == 0 && len(t.AgentIDs) == 0 && t.Spread == 0
+}
+
+// Matches агент подходит под все заданные поля селектора.
+func (t *TargetSelector) Matches(agent *Agent) bool {
+	if t == nil {
+		return true
+	}
+
+	return matchAny(t.Regions, agent.Region) &&
+		matchAny(t.Countries, agent.Country) &&
+		matchAny(t.ASNs, agent.ASN) &&
+		matchAny(t.AgentIDs, agent.ID)
+}
+
+func matchAny[T comparable](values []T, v T) bool {
+	if len(values) == 0 {
+		return true
+	}
+
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+
+	return false
+}
+
 // CheckRequestRequest
 // @Description описание одной проверки
 type CheckRequestRequest struct {
-	Type   string                 `binding:"required" json:"type" example:"http"` // Type тип проверки: http|ping|tcp|traceroute|dns
+	Type   string                 `binding:"required" json:"type" example:"http"` // Type тип проверки: http|ping|tcp|traceroute|dns|websocket|grpc|throughput
 	Params map[string]interface{} `binding:"required" json:"params"`              // Params параметры проверки
 } // @Name CheckRequestRequest
 
@@ -64,95 +109,84 @@ type DNSParamsRequest struct {
 	Resolver string   `binding:"required" json:"resolver,omitempty" example:"8.8.8.8"`
 } // @Name DNSParamsRequest
 
-type TaskMessage struct {
-	ID             uuid.UUID         `json:"id"`                 // ID уникальный идентификатор задачи
-	Target         string            `json:"target"`             // Target домен или IP, который нужно проверить
-	TimeoutSeconds int               `json:"timeoutSeconds"`     // TimeoutSeconds время выполнения всех задачи в секундах
-	ClientContext  ClientContext     `json:"clientContext"`      // ClientContext информация о клиенте, от которого инициирована проверка
-	Checks         []CheckRequest    `json:"checks"`             // Checks список проверок
-	Metadata       map[string]string `json:"metadata,omitempty"` // Metadata дополнительная информация
-}
-
-type ClientContext struct {
-	IP        string `json:"ip,omitempty"`
-	ASN       int    `json:"asn,omitempty"`
-	Geo       Geo    `json:"geo,omitempty"`
-	UserAgent string `json:"userAgent,omitempty"`
-}
-
-type Geo struct {
-	Region    string `json:"region,omitempty"`
-	Continent string `json:"continent,omitempty"`
-}
-
-type CheckRequest struct {
-	Type   string                 `json:"type"`
-	Params map[string]interface{} `json:"params"`
-}
-
-type HTTPParams struct {
-	Scheme              string            `json:"scheme"`
-	Path                string            `json:"path"`
-	Headers             map[string]string `json:"headers,omitempty"`
-	ExpectedStatusRange [2]int            `json:"expectedStatusRange"`
-	FollowRedirects     bool              `json:"followRedirects"`
-	MaxBodyBytes        int               `json:"maxBodyBytes"`
-}
-
-type PingParams struct {
-	Count      int `json:"count" example:"4"`
-	IntervalMs int `json:"intervalMs" example:"1000"`
-}
-
-type TCPParams struct {
-	Port             int `json:"port"`
-	ConnectTimeoutMs int `json:"connectTimeoutMs"`
-}
+// WebSocketParamsRequest
+// @Description параметры WebSocket: upgrade, опционально отправка сообщения и ожидание ответа
+type WebSocketParamsRequest struct {
+	Scheme        string            `json:"scheme" example:"wss"`
+	Path          string            `json:"path" example:"/ws"`
+	Port          int               `json:"port,omitempty" example:"443"`
+	Headers       map[string]string `json:"headers,omitempty"`
+	Subprotocols  []string          `json:"subprotocols,omitempty" example:"[\"graphql-ws\"]"`
+	Message       string            `json:"message,omitempty" example:"ping"`
+	Expect        string            `json:"expect,omitempty" example:"pong"`
+	ReadTimeoutMs int               `json:"readTimeoutMs" example:"3000"`
+} // @Name WebSocketParamsRequest
 
-type TracerouteParams struct {
-	Mode    string `json:"mode"`
-	Port    int    `json:"port"`
-	MaxHops int    `json:"maxHops"`
-}
+// GRPCParamsRequest
+// @Description параметры gRPC: вызов grpc.health.v1.Health/Check
+type GRPCParamsRequest struct {
+	Port               int    `json:"port" example:"443"`
+	Service            string `json:"service,omitempty" example:"my.package.Service"`
+	TLS                bool   `json:"tls" example:"true"`
+	InsecureSkipVerify bool   `json:"insecureSkipVerify,omitempty" example:"false"`
+	Authority          string `json:"authority,omitempty" example:"api.example.com"`
+} // @Name GRPCParamsRequest
 
-type DNSParams struct {
-	Records  []string `json:"records"`
-	Resolver string   `json:"resolver,omitempty"`
-}
+// ThroughputParamsRequest
+// @Description параметры замера скорости загрузки: URL (или диапазон байт) качается до лимита по объёму/времени
+type ThroughputParamsRequest struct {
+	Scheme           string            `json:"scheme" example:"https"`
+	Path             string            `json:"path" example:"/static/100mb.bin"`
+	Headers          map[string]string `json:"headers,omitempty"`
+	RangeStart       int64             `json:"rangeStart,omitempty" example:"0"`
+	RangeEnd         int64             `json:"rangeEnd,omitempty" example:"10485759"`
+	MaxBytes         int64             `json:"maxBytes" example:"10485760"`
+	MaxDurationMs    int               `json:"maxDurationMs" example:"10000"`
+	SampleIntervalMs int               `json:"sampleIntervalMs" example:"250"`
+	StallThresholdMs int               `json:"stallThresholdMs" example:"500"`
+} // @Name ThroughputParamsRequest
 
 type Request struct {
-	ID             uuid.UUID `db:"id" json:"id"`
-	Target         string    `db:"target" json:"target"`
-	TimeoutSeconds int       `db:"timeout_seconds" json:"timeoutSeconds"`
-	Broadcast      bool      `db:"broadcast" json:"broadcast"`
-	ClientIP       string    `db:"client_ip" json:"clientIP"`
-	UserAgent      string    `db:"user_agent" json:"userAgent"`
-	ClientASN      int       `db:"client_asn" json:"clientASN"`
-	ClientCC       string    `db:"client_cc" json:"clientCC"`
-	ClientRegion   string    `db:"client_region" json:"clientRegion"`
-	Status         string    `db:"status" json:"status"`
-	ChecksTypes    []string  `db:"checks_types" json:"checkTypes"`
-	RequestJSON    []byte    `db:"request_json" json:"requestJSON"`
-	CreatedAt      time.Time `db:"created_at" json:"createdAt"`
-	UpdatedAt      time.Time `db:"updated_at" json:"updatedAt"`
+	ID             uuid.UUID       `db:"id" json:"id"`
+	Target         string          `db:"target" json:"target"`
+	TimeoutSeconds int             `db:"timeout_seconds" json:"timeoutSeconds"`
+	Broadcast      bool            `db:"broadcast" json:"broadcast"`
+	Targets        *TargetSelector `db:"targets" json:"targets,omitempty"`
+	Strategy       string          `db:"strategy" json:"strategy"` // Strategy стратегия, которой выбраны агенты
+	ClientIP       string          `db:"client_ip" json:"clientIP"`
+	UserAgent      string          `db:"user_agent" json:"userAgent"`
+	ClientASN      int             `db:"client_asn" json:"clientASN"`
+	ClientCC       string          `db:"client_cc" json:"clientCC"`
+	ClientRegion   string          `db:"client_region" json:"clientRegion"`
+	Status         string          `db:"status" json:"status"`
+	ChecksTypes    []string        `db:"checks_types" json:"checkTypes"`
+	RequestJSON    []byte          `db:"request_json" json:"requestJSON"`
+	CreatedAt      time.Time       `db:"created_at" json:"createdAt"`
+	UpdatedAt      time.Time       `db:"updated_at" json:"updatedAt"`
+
+	Unassigned []UnassignedCheck `db:"-" json:"unassigned,omitempty"` // Unassigned проверки, которые не может выполнить ни один online агент, они не запускаются
 }
 
 type Assignment struct {
-	ID          uuid.UUID `db:"id" json:"id"`
-	RequestID   uuid.UUID `db:"request_id" json:"requestId"`
-	AgentID     uuid.UUID `db:"agent_id" json:"agentId"`
-	AgentRegion string    `db:"agent_region" json:"agentRegion"`
-	Status      string    `db:"status" json:"status"`
-	EnqueuedAt  time.Time `db:"enqueued_at" json:"enqueuedAt"`
-	StartedAt   time.Time `db:"started_at" json:"startedAt"`
-	FinishedAt  time.Time `db:"finished_at" json:"finishedAt"`
-	ErrorText   string    `db:"error_text" json:"errorText"`
-	OutboxID    uuid.UUID `db:"outbox_id" json:"outboxId"`
+	ID           uuid.UUID `db:"id" json:"id"`
+	RequestID    uuid.UUID `db:"request_id" json:"requestId"`
+	AgentID      uuid.UUID `db:"agent_id" json:"agentId"`
+	AgentRegion  string    `db:"agent_region" json:"agentRegion"`
+	Status       string    `db:"status" json:"status"`
+	ChecksTotal  int       `db:"checks_total" json:"checksTotal"` // ChecksTotal сколько проверок отдано агенту, столько итоговых результатов ждём
+	EnqueuedAt   time.Time `db:"enqueued_at" json:"enqueuedAt"`
+	DispatchedAt time.Time `db:"dispatched_at" json:"dispatchedAt"` // DispatchedAt задача записана в Kafka или выдана через gateway
+	StartedAt    time.Time `db:"started_at" json:"startedAt"`
+	FinishedAt   time.Time `db:"finished_at" json:"finishedAt"`
+	ErrorText    string    `db:"error_text" json:"errorText"`
+	OutboxID     uuid.UUID `db:"outbox_id" json:"outboxId"`
 }
 
 type CheckResult struct {
 	ID           uuid.UUID `db:"id" json:"id"`
 	AssignmentId uuid.UUID `db:"assignment_id" json:"assignmentId"`
+	AgentID      uuid.UUID `db:"agent_id" json:"agentId"`       // AgentID агент, выполнивший проверку
+	CheckIndex   int       `db:"check_index" json:"checkIndex"` // CheckIndex номер проверки в запросе
 	Type         string    `db:"type" json:"type"`
 	Status       string    `db:"status" json:"status"`
 	StartedAt    time.Time `db:"started_at" json:"startedAt"`
@@ -160,27 +194,37 @@ type CheckResult struct {
 	Payload      []byte    `db:"payload" json:"payload"`
 }
 
-type CheckResultFromAgent struct {
-	TaskID     uuid.UUID       `json:"taskId"`
-	CheckIndex int             `json:"checkIndex"`
-	Type       string          `json:"type"`
-	Target     string          `json:"target"`
-	StartedAt  time.Time       `json:"startedAt"`
-	DurationMs int64           `json:"durationMs"`
-	OK         bool            `json:"ok"`
-	Error      string          `json:"error,omitempty"`
-	Payload    json.RawMessage `json:"payload,omitempty"` // разный по проверкам
+// CheckProgress последнее промежуточное состояние проверки, пока не пришёл итоговый результат.
+type CheckProgress struct {
+	AssignmentID uuid.UUID       `db:"assignment_id" json:"assignmentId"`
+	AgentID      uuid.UUID       `db:"agent_id" json:"agentId"`
+	AgentRegion  string          `db:"agent_region" json:"agentRegion"`
+	CheckIndex   int             `db:"check_index" json:"checkIndex"`
+	Type         string          `db:"type" json:"type"`
+	Seq          int             `db:"seq" json:"seq"`
+	StartedAt    time.Time       `db:"started_at" json:"startedAt"`
+	UpdatedAt    time.Time       `db:"updated_at" json:"updatedAt"`
+	Payload      json.RawMessage `db:"payload" json:"payload,omitempty"`
 }
 
 type CheckResultResponse struct {
-	RequestID   uuid.UUID       `db:"request_id" json:"requestId" `
-	AgentID     uuid.UUID       `db:"agent_id" json:"agentId"`
-	AgentRegion string          `db:"agent_region" json:"agentRegion"`
-	Type        string          `db:"type" json:"type"`
-	Status      string          `db:"status" json:"status"`
-	StartedAt   time.Time       `db:"started_at" json:"startedAt"`
-	FinishedAt  time.Time       `db:"finished_at" json:"finishedAt"`
-	Payload     json.RawMessage `db:"payload" json:"payload,omitempty"`
+	RequestID    uuid.UUID       `db:"request_id" json:"requestId" `
+	AssignmentID uuid.UUID       `db:"assignment_id" json:"assignmentId"`
+	AgentID      uuid.UUID       `db:"agent_id" json:"agentId"`
+	AgentRegion  string          `db:"agent_region" json:"agentRegion"`
+	CheckIndex   int             `db:"check_index" json:"checkIndex"`
+	Type         string          `db:"type" json:"type"`
+	Status       string          `db:"status" json:"status"`
+	StartedAt    time.Time       `db:"started_at" json:"startedAt"`
+	FinishedAt   time.Time       `db:"finished_at" json:"finishedAt"`
+	Payload      json.RawMessage `db:"payload" json:"payload,omitempty"`
+}
+
+// AgentResults результаты одного агента: в broadcast запросе каждый агент выполняет проверки отдельно.
+type AgentResults struct {
+	AgentID     uuid.UUID             `json:"agentId"`
+	AgentRegion string                `json:"agentRegion"`
+	Results     []CheckResultResponse `json:"results"`
 }
 
 type RequestIDPathParam struct {
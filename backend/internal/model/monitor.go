@@ -0,0 +1,71 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Статусы запуска монитора.
+const (
+	MonitorRunDispatched = "DISPATCHED" // запрос создан, задачи поставлены в outbox
+	MonitorRunFailed     = "FAILED"     // запрос не создан, причина в error
+)
+
+// Monitor
+// @Description периодическая задача проверок пользователя
+type Monitor struct {
+	ID              uuid.UUID          `db:"id" json:"id" example:"0b6f3a52-54a1-4f55-9c1e-2f7d1c9a8e11"`
+	UserID          uuid.UUID          `db:"user_id" json:"userId"`
+	Name            string             `db:"name" json:"name" example:"example.com availability"`
+	Task            TaskMessageRequest `db:"task" json:"task"`                                                // Task задача, которая ставится в очередь при каждом запуске
+	IntervalSeconds int                `db:"interval_seconds" json:"intervalSeconds,omitempty" example:"300"` // IntervalSeconds период запуска; задаётся либо он, либо cron
+	Cron            string             `db:"cron" json:"cron,omitempty" example:"*/5 * * * *"`                // Cron расписание в формате cron из 5 полей, UTC
+	Enabled         bool               `db:"enabled" json:"enabled" example:"true"`
+	NextRunAt       time.Time          `db:"next_run_at" json:"nextRunAt"`
+	LastRunAt       *time.Time         `db:"last_run_at" json:"lastRunAt,omitempty"`
+	CreatedAt       time.Time          `db:"created_at" json:"createdAt"`
+	UpdatedAt       time.Time          `db:"updated_at" json:"updatedAt"`
+} // @Name Monitor
+
+// MonitorCreateRequest
+// @Description новый монитор: задача в формате /check/task и расписание — intervalSeconds или cron.
+// @Description Где выполнять проверки, задаёт task.targets.
+type MonitorCreateRequest struct {
+	Name            string             `json:"name" binding:"required" example:"example.com availability"`
+	Task            TaskMessageRequest `json:"task" binding:"required"`
+	IntervalSeconds int                `json:"intervalSeconds,omitempty" example:"300"`
+	Cron            string             `json:"cron,omitempty" example:"*/5 * * * *"`
+	Enabled         *bool              `json:"enabled,omitempty" example:"true"` // Enabled по умолчанию true
+} // @Name MonitorCreateRequest
+
+// MonitorUpdateRequest
+// @Description изменение монитора, не заданные поля не меняются. Заданный intervalSeconds или cron заменяет расписание.
+type MonitorUpdateRequest struct {
+	Name            *string             `json:"name,omitempty" example:"example.com availability"`
+	Task            *TaskMessageRequest `json:"task,omitempty"`
+	IntervalSeconds *int                `json:"intervalSeconds,omitempty" example:"600"`
+	Cron            *string             `json:"cron,omitempty" example:"0 * * * *"`
+	Enabled         *bool               `json:"enabled,omitempty" example:"false"`
+} // @Name MonitorUpdateRequest
+
+// MonitorRun
+// @Description запуск монитора
+type MonitorRun struct {
+	ID            uuid.UUID  `db:"id" json:"id"`
+	MonitorID     uuid.UUID  `db:"monitor_id" json:"monitorId"`
+	RequestID     *uuid.UUID `db:"request_id" json:"requestId,omitempty"`         // RequestID запрос, результаты которого смотреть в /check/{request_id}
+	Status        string     `db:"status" json:"status" example:"DISPATCHED"`     // Status DISPATCHED или FAILED
+	RequestStatus string     `db:"request_status" json:"requestStatus,omitempty"` // RequestStatus статус созданного запроса
+	Error         string     `db:"error_text" json:"error,omitempty"`
+	ScheduledAt   time.Time  `db:"scheduled_at" json:"scheduledAt"` // ScheduledAt на когда был запланирован запуск
+	CreatedAt     time.Time  `db:"created_at" json:"createdAt"`
+} // @Name MonitorRun
+
+type MonitorIDPathParam struct {
+	ID string `uri:"monitor_id" binding:"required,uuid" example:"0b6f3a52-54a1-4f55-9c1e-2f7d1c9a8e11"`
+}
+
+type MonitorRunsQuery struct {
+	Limit int `form:"limit" binding:"omitempty,min=1,max=500" example:"50"`
+}
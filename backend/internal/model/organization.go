@@ -0,0 +1,46 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Organization
+// @Description организация пользователей, владеющая пулом частных агентов
+type Organization struct {
+	ID        uuid.UUID `db:"id" json:"id" example:"3f1c2a9e-8a4b-4c1e-9a55-0d7c1e2b6f10"`
+	Name      string    `db:"name" json:"name" example:"Acme"`
+	CreatedBy uuid.UUID `db:"created_by" json:"createdBy"`
+	CreatedAt time.Time `db:"created_at" json:"createdAt"`
+} // @Name Organization
+
+// OrganizationCreateRequest
+// @Description новая организация, создатель становится её участником
+type OrganizationCreateRequest struct {
+	Name string `json:"name" binding:"required" example:"Acme"`
+} // @Name OrganizationCreateRequest
+
+// OrganizationMember
+// @Description участник организации
+type OrganizationMember struct {
+	UserID    uuid.UUID `db:"user_id" json:"userId"`
+	Username  string    `db:"username" json:"username" example:"Dimka228"`
+	Email     string    `db:"email" json:"email" example:"Dimka228@gmail.com"`
+	CreatedAt time.Time `db:"created_at" json:"createdAt"` // CreatedAt когда пользователь добавлен в организацию
+} // @Name OrganizationMember
+
+// OrganizationMemberRequest
+// @Description пользователь, которого нужно добавить в организацию
+type OrganizationMemberRequest struct {
+	UserID uuid.UUID `json:"userId" binding:"required" example:"b4b03119-1290-44bc-b599-6a5e91d6611f"`
+} // @Name OrganizationMemberRequest
+
+type OrganizationIDPathParam struct {
+	ID string `uri:"organization_id" binding:"required,uuid" example:"3f1c2a9e-8a4b-4c1e-9a55-0d7c1e2b6f10"`
+}
+
+type OrganizationMemberPathParam struct {
+	OrganizationID string `uri:"organization_id" binding:"required,uuid" example:"3f1c2a9e-8a4b-4c1e-9a55-0d7c1e2b6f10"`
+	UserID         string `uri:"user_id" binding:"required,uuid" example:"b4b03119-1290-44bc-b599-6a5e91d6611f"`
+}
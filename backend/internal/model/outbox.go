@@ -10,6 +10,7 @@ type OutboxMessage struct {
 	ID        uuid.UUID  `db:"id"`
 	Topic     string     `db:"topic"`
 	Payload   []byte     `db:"payload"`
+	Transport string     `db:"transport"` // Transport kafka — отправит outbox publisher, http — заберёт агент long-poll'ом
 	CreatedAt time.Time  `db:"created_at"`
 	Sent      bool       `db:"sent"`
 	SentAt    *time.Time `db:"sent_at"`
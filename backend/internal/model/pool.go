@@ -0,0 +1,85 @@
+package model
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// Префиксы регионов частных агентов. Регион частного агента включает id владельца,
+// поэтому у каждого пула свой топик задач и его регионы не пересекаются с публичными.
+const (
+	PrivateRegionUserPrefix = "USER-"
+	PrivateRegionOrgPrefix  = "ORG-"
+)
+
+// PrivateRegion регион частного агента: метка владельца (например OFFICE) в пространстве пользователя или организации.
+func PrivateRegion(ownerUserID, ownerOrgID *uuid.UUID, label string) string {
+	label = strings.ToUpper(label)
+
+	if ownerOrgID != nil {
+		return strings.ToUpper(fmt.Sprintf("%s%s-%s", PrivateRegionOrgPrefix, ownerOrgID, label))
+	}
+
+	return strings.ToUpper(fmt.Sprintf("%s%s-%s", PrivateRegionUserPrefix, ownerUserID, label))
+}
+
+// IsPrivateRegion регион принадлежит пулу частных агентов.
+func IsPrivateRegion(region string) bool {
+	return strings.HasPrefix(region, PrivateRegionUserPrefix) || strings.HasPrefix(region, PrivateRegionOrgPrefix)
+}
+
+// PoolAccess пользователь и организации, в которых он состоит: чьи частные агенты ему доступны.
+// nil — анонимный запрос, доступны только публичные агенты.
+type PoolAccess struct {
+	UserID          uuid.UUID
+	OrganizationIDs []uuid.UUID
+}
+
+// Owns агент частный и принадлежит пользователю или одной из его организаций.
+func (p *PoolAccess) Owns(agent *Agent) bool {
+	if p == nil {
+		return false
+	}
+
+	switch {
+	case agent.OwnerUserID != nil:
+		return *agent.OwnerUserID == p.UserID
+	case agent.OwnerOrgID != nil:
+		return slices.Contains(p.OrganizationIDs, *agent.OwnerOrgID)
+	default:
+		return false
+	}
+}
+
+// OwnsRegion регион частный и принадлежит пулу пользователя или одной из его организаций.
+func (p *PoolAccess) OwnsRegion(region string) bool {
+	if p == nil {
+		return false
+	}
+
+	if strings.HasPrefix(region, PrivateRegionUserPrefix+strings.ToUpper(p.UserID.String())+"-") {
+		return true
+	}
+
+	for _, orgID := range p.OrganizationIDs {
+		if strings.HasPrefix(region, PrivateRegionOrgPrefix+strings.ToUpper(orgID.String())+"-") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Location
+// @Description регион, который можно выбрать в targets.regions: публичный или пула частных агентов
+type Location struct {
+	Region         string     `json:"region" example:"EU"`
+	Private        bool       `json:"private"`                                                                 // Private регион частных агентов пользователя или его организации
+	OrganizationID *uuid.UUID `json:"organizationId,omitempty" example:"3f1c2a9e-8a4b-4c1e-9a55-0d7c1e2b6f10"` // OrganizationID организация-владелец пула
+	Countries      []string   `json:"countries,omitempty" example:"DE,FI"`                                     // Countries страны агентов региона
+	Agents         int        `json:"agents" example:"3"`                                                      // Agents агентов в регионе
+	Online         int        `json:"online" example:"2"`                                                      // Online из них online
+} // @Name Location
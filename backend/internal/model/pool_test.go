@@ -0,0 +1,94 @@
+package model
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestPoolAccessOwnsRegion(t *testing.T) {
+	userID := uuid.MustParse("7b6e3c1a-2f4d-4e8a-9c0b-1d2e3f4a5b6c")
+	orgID := uuid.MustParse("3f1c2a9e-8a4b-4c1e-9a55-0d7c1e2b6f10")
+	otherID := uuid.MustParse("a0b1c2d3-e4f5-4a6b-8c7d-9e0f1a2b3c4d")
+
+	access := &PoolAccess{UserID: userID, OrganizationIDs: []uuid.UUID{orgID}}
+
+	tests := []struct {
+		name   string
+		access *PoolAccess
+		region string
+		want   bool
+	}{
+		{name: "user pool", access: access, region: PrivateRegion(&userID, nil, "office"), want: true},
+		{name: "organization pool", access: access, region: PrivateRegion(nil, &orgID, "dc-1"), want: true},
+		{name: "other user pool", access: access, region: PrivateRegion(&otherID, nil, "office")},
+		{name: "other organization pool", access: access, region: PrivateRegion(nil, &otherID, "office")},
+		{name: "user id as organization", access: access, region: PrivateRegion(nil, &userID, "office")},
+		{name: "public region", access: access, region: "EU"},
+		{name: "owner id without label", access: access, region: PrivateRegionUserPrefix + strings.ToUpper(userID.String())},
+		{name: "lower case owner id", access: access, region: PrivateRegionUserPrefix + userID.String() + "-OFFICE"},
+		{name: "anonymous", region: PrivateRegion(&userID, nil, "office")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.access.OwnsRegion(tt.region); got != tt.want {
+				t.Fatalf("OwnsRegion(%q) = %v, want %v", tt.region, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPoolAccessOwns(t *testing.T) {
+	userID := uuid.New()
+	orgID := uuid.New()
+	otherID := uuid.New()
+
+	access := &PoolAccess{UserID: userID, OrganizationIDs: []uuid.UUID{orgID}}
+
+	tests := []struct {
+		name   string
+		access *PoolAccess
+		agent  *Agent
+		want   bool
+	}{
+		{name: "user agent", access: access, agent: &Agent{OwnerUserID: &userID}, want: true},
+		{name: "organization agent", access: access, agent: &Agent{OwnerOrgID: &orgID}, want: true},
+		{name: "other user agent", access: access, agent: &Agent{OwnerUserID: &otherID}},
+		{name: "other organization agent", access: access, agent: &Agent{OwnerOrgID: &otherID}},
+		{name: "public agent", access: access, agent: &Agent{Region: "EU"}},
+		{name: "anonymous", agent: &Agent{OwnerUserID: &userID}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.access.Owns(tt.agent); got != tt.want {
+				t.Fatalf("Owns = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAgentIsPublic(t *testing.T) {
+	userID := uuid.New()
+
+	tests := []struct {
+		name  string
+		agent *Agent
+		want  bool
+	}{
+		{name: "public", agent: &Agent{Region: "EU"}, want: true},
+		{name: "private agent", agent: &Agent{Region: PrivateRegion(&userID, nil, "office"), OwnerUserID: &userID}},
+		{name: "ownerless agent in private region", agent: &Agent{Region: PrivateRegion(&userID, nil, "office")}},
+		{name: "owned agent in public region", agent: &Agent{Region: "EU", OwnerUserID: &userID}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.agent.IsPublic(); got != tt.want {
+				t.Fatalf("IsPublic = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
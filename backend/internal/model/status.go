@@ -0,0 +1,46 @@
+package model
+
+// Статусы запроса, назначения и результата проверки (domain.check_status).
+// Результат проверки бывает только DONE или FAILED.
+const (
+	StatusPending    = "PENDING"    // StatusPending назначение создано, задача лежит в outbox
+	StatusDispatched = "DISPATCHED" // StatusDispatched задача записана в Kafka или выдана агенту через gateway
+	StatusRunning    = "RUNNING"    // StatusRunning агент подтвердил, что начал выполнять проверки
+	StatusDone       = "DONE"       // StatusDone пришли все результаты и все успешные
+	StatusFailed     = "FAILED"     // StatusFailed пришли все результаты, хотя бы один неуспешный
+	StatusTimeout    = "TIMEOUT"    // StatusTimeout результаты не пришли за timeout_seconds
+)
+
+// AssignmentTransitions допустимые переходы назначения. Этапы можно пропускать: подтверждение агента
+// может прийти раньше, чем outbox отметит задачу отправленной, а агенты до версии 2.2 его не шлют,
+// и назначение переходит в RUNNING по первому результату.
+var AssignmentTransitions = map[string][]string{
+	StatusPending:    {StatusDispatched, StatusRunning, StatusDone, StatusFailed, StatusTimeout},
+	StatusDispatched: {StatusRunning, StatusDone, StatusFailed, StatusTimeout},
+	StatusRunning:    {StatusDone, StatusFailed, StatusTimeout},
+}
+
+// StatusesFrom статусы, из которых назначение может перейти в to.
+func StatusesFrom(to string) []string {
+	var from []string
+
+	for status, next := range AssignmentTransitions {
+		for _, s := range next {
+			if s == to {
+				from = append(from, status)
+			}
+		}
+	}
+
+	return from
+}
+
+// IsTerminalStatus из терминального статуса переходов нет.
+func IsTerminalStatus(status string) bool {
+	switch status {
+	case StatusDone, StatusFailed, StatusTimeout:
+		return true
+	default:
+		return false
+	}
+}
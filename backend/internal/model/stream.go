@@ -0,0 +1,102 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"hackathon-back/internal/apperrors"
+)
+
+const (
+	StreamEventResult       = "result"        // StreamEventResult пришёл итоговый результат проверки
+	StreamEventProgress     = "progress"      // StreamEventProgress обновилось промежуточное состояние проверки
+	StreamEventStatus       = "status"        // StreamEventStatus сменился статус запроса
+	StreamEventAgentOnline  = "agent_online"  // StreamEventAgentOnline агент прислал heartbeat после offline
+	StreamEventAgentOffline = "agent_offline" // StreamEventAgentOffline агент пропустил heartbeat
+	StreamEventMonitorRun   = "monitor_run"   // StreamEventMonitorRun монитор запущен: запрос создан или запуск не состоялся
+)
+
+const (
+	TopicFleet         = "fleet"    // TopicFleet события парка агентов, только для manager и admin
+	TopicRequestPrefix = "request:" // TopicRequestPrefix события запроса, после префикса — его id
+	TopicMonitorPrefix = "monitor:" // TopicMonitorPrefix запуски монитора, после префикса — его id, только для владельца
+)
+
+// RequestTopic топик событий запроса.
+func RequestTopic(requestID uuid.UUID) string {
+	return TopicRequestPrefix + requestID.String()
+}
+
+// MonitorTopic топик запусков монитора.
+func MonitorTopic(monitorID uuid.UUID) string {
+	return TopicMonitorPrefix + monitorID.String()
+}
+
+// StreamTopic разобранное имя топика: для топика запроса заполнен RequestID, для топика монитора — MonitorID,
+// для fleet оба пустые.
+type StreamTopic struct {
+	RequestID uuid.UUID
+	MonitorID uuid.UUID
+}
+
+// ParseTopic проверяет имя топика и достаёт из него id запроса или монитора.
+func ParseTopic(topic string) (StreamTopic, error) {
+	var (
+		parsed StreamTopic
+		err    error
+	)
+
+	switch {
+	case topic == TopicFleet:
+		return parsed, nil
+	case strings.HasPrefix(topic, TopicRequestPrefix):
+		parsed.RequestID, err = uuid.Parse(strings.TrimPrefix(topic, TopicRequestPrefix))
+	case strings.HasPrefix(topic, TopicMonitorPrefix):
+		parsed.MonitorID, err = uuid.Parse(strings.TrimPrefix(topic, TopicMonitorPrefix))
+	default:
+		return parsed, fmt.Errorf("%w %q", apperrors.ErrUnknownStreamTopic, topic)
+	}
+
+	if err != nil {
+		return StreamTopic{}, fmt.Errorf("%w %q: %w", apperrors.ErrUnknownStreamTopic, topic, err)
+	}
+
+	return parsed, nil
+}
+
+// StreamEvent изменение, которое рассылается всем репликам бэкенда через Redis, а ими — открытым websocket.
+// Topic — RequestTopic для событий запроса, MonitorTopic для запусков монитора или TopicFleet. Seq задаёт stream.Hub при публикации:
+// номер растёт в рамках топика, по нему клиент досылает пропущенное после переподключения.
+// Заполнено одно из полей данных по Type.
+type StreamEvent struct {
+	Topic     string               `json:"topic"`
+	Seq       int64                `json:"seq"`
+	RequestID uuid.UUID            `json:"requestId,omitempty"`
+	MonitorID uuid.UUID            `json:"monitorId,omitempty"`
+	Type      string               `json:"type"`
+	Result    *CheckResultResponse `json:"result,omitempty"`
+	Progress  *CheckProgress       `json:"progress,omitempty"`
+	Status    string               `json:"status,omitempty"`
+	Agent     *Agent               `json:"agent,omitempty"`
+	Run       *MonitorRun          `json:"run,omitempty"`
+}
+
+// Data данные события по Type.
+func (e StreamEvent) Data() any {
+	switch e.Type {
+	case StreamEventResult:
+		return e.Result
+	case StreamEventProgress:
+		return e.Progress
+	case StreamEventStatus:
+		return e.Status
+	case StreamEventAgentOnline, StreamEventAgentOffline:
+		return e.Agent
+	case StreamEventMonitorRun:
+		return e.Run
+	default:
+		return nil
+	}
+}
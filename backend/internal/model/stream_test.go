@@ -0,0 +1,45 @@
+package model
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"hackathon-back/internal/apperrors"
+)
+
+func TestParseTopic(t *testing.T) {
+	id := uuid.MustParse("6d40a8b9-a135-4b67-b96b-0579c6ae0f76")
+
+	tests := []struct {
+		name  string
+		topic string
+		want  StreamTopic
+	}{
+		{name: "fleet", topic: TopicFleet},
+		{name: "request", topic: RequestTopic(id), want: StreamTopic{RequestID: id}},
+		{name: "monitor", topic: MonitorTopic(id), want: StreamTopic{MonitorID: id}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseTopic(tt.topic)
+			if err != nil {
+				t.Fatalf("ParseTopic(%q): %v", tt.topic, err)
+			}
+
+			if got != tt.want {
+				t.Fatalf("ParseTopic(%q) = %+v, want %+v", tt.topic, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTopicErrors(t *testing.T) {
+	for _, topic := range []string{"", "agents", "request:", "monitor:42", "request:" + TopicFleet} {
+		if _, err := ParseTopic(topic); !errors.Is(err, apperrors.ErrUnknownStreamTopic) {
+			t.Errorf("ParseTopic(%q) = %v, want %v", topic, err, apperrors.ErrUnknownStreamTopic)
+		}
+	}
+}
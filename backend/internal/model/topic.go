@@ -0,0 +1,33 @@
+package model
+
+// Роли топиков Kafka бэкенда.
+const (
+	TopicRoleTasks     = "tasks"
+	TopicRoleResults   = "results"
+	TopicRoleHeartbeat = "heartbeat"
+)
+
+// TopicHealth
+// @Description состояние топика Kafka по метаданным кластера
+type TopicHealth struct {
+	Name              string             `json:"name" example:"hosts-check-EU"`
+	Role              string             `json:"role" example:"tasks"`          // Role tasks — задачи региона, results — результаты агентов, heartbeat — heartbeat агентов
+	Region            string             `json:"region,omitempty" example:"EU"` // Region регион топика задач
+	Exists            bool               `json:"exists"`                        // Exists топик есть в кластере
+	Healthy           bool               `json:"healthy"`                       // Healthy у всех партиций есть лидер и полный ISR
+	ReplicationFactor int                `json:"replicationFactor" example:"1"` // ReplicationFactor число реплик первой партиции
+	UnderReplicated   int                `json:"underReplicated"`               // UnderReplicated партиции, у которых ISR меньше числа реплик
+	Offline           int                `json:"offline"`                       // Offline партиции без лидера
+	Partitions        []*PartitionHealth `json:"partitions"`                    // Partitions партиции топика
+	Error             string             `json:"error,omitempty"`               // Error ошибка метаданных топика
+} // @Name TopicHealth
+
+// PartitionHealth
+// @Description состояние партиции топика
+type PartitionHealth struct {
+	ID       int32   `json:"id"`
+	Leader   int32   `json:"leader"` // Leader id брокера-лидера, -1 — лидера нет
+	Replicas []int32 `json:"replicas"`
+	ISR      []int32 `json:"isr"` // ISR синхронные реплики
+	Error    string  `json:"error,omitempty"`
+} // @Name PartitionHealth
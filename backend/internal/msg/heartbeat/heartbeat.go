@@ -0,0 +1,85 @@
+package heartbeat
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/zap"
+	"hackathon-contract"
+
+	"hackathon-back/pkg/kafka"
+)
+
+// Presence принимает heartbeat агентов. Heartbeat идемпотентен и быстро устаревает,
+// поэтому в inbox он не пишется.
+type Presence interface {
+	Heartbeat(ctx context.Context, hb contract.Heartbeat) error
+}
+
+// Opener проверяет подпись конверта heartbeat, см. service.EnvelopeService.
+type Opener interface {
+	OpenHeartbeat(ctx context.Context, data []byte) (contract.Heartbeat, error)
+}
+
+type Config struct {
+	Name  string
+	Topic string
+}
+
+type Subscriber struct {
+	l        *zap.Logger
+	cfg      Config
+	consumer kafka.ConsumerGroupRunner
+	opener   Opener
+	presence Presence
+}
+
+func NewSubscriber(l *zap.Logger, cfg Config, consumer kafka.ConsumerGroupRunner, opener Opener, presence Presence) *Subscriber {
+	return &Subscriber{
+		l:        l,
+		cfg:      cfg,
+		consumer: consumer,
+		opener:   opener,
+		presence: presence,
+	}
+}
+
+func (s *Subscriber) Run(ctx context.Context) {
+	go func() {
+		s.consumer.Run()
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.l.Info("Context canceled, stopping heartbeat subscriber")
+
+			return
+		case msg, ok := <-s.consumer.Messages():
+			if !ok {
+				s.l.Info("Heartbeat messages channel closed")
+
+				return
+			}
+
+			if err := s.process(ctx, msg); err != nil {
+				s.l.Error("Error processing heartbeat", zap.Error(err))
+			}
+
+			msg.Mark()
+		}
+	}
+}
+
+func (s *Subscriber) process(ctx context.Context, message *kafka.MessageWithMarkFunc) error {
+	hb, err := s.opener.OpenHeartbeat(ctx, message.Message.Value)
+	if err != nil {
+		return fmt.Errorf("failed to open heartbeat: %w", err)
+	}
+
+	if err := s.presence.Heartbeat(ctx, hb); err != nil {
+		return fmt.Errorf("failed to process heartbeat from agent %s: %w", hb.AgentID, err)
+	}
+
+	return nil
+}
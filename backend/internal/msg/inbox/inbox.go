@@ -12,6 +12,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"go.uber.org/zap"
+	"hackathon-contract"
 )
 
 const messagePipeBuffer = 1000
@@ -27,7 +28,29 @@ type RequestRepository interface {
 
 	InsertRequest(ctx context.Context, ext repository.RepoExtension, request *model.Request) error
 	InsertAssignment(ctx context.Context, ext repository.RepoExtension, assignment *model.Assignment) error
-	InsertCheckResult(ctx context.Context, ext repository.RepoExtension, checkResult *model.CheckResult) error
+	InsertCheckResult(ctx context.Context, ext repository.RepoExtension, checkResult *model.CheckResult) (inserted bool, err error)
+	UpsertCheckProgress(ctx context.Context, ext repository.RepoExtension, progress *model.CheckProgress) (updated bool, err error)
+	FinishCheckProgress(ctx context.Context, ext repository.RepoExtension, assignmentID uuid.UUID, checkIndex int, checkType string) error
+}
+
+type Enricher interface {
+	EnrichResult(ctx context.Context, assignmentID uuid.UUID, res *contract.CheckResult) error
+}
+
+// ResultOpener проверяет подпись результата и назначение задачи агенту, см. service.EnvelopeService.
+type ResultOpener interface {
+	OpenResult(ctx context.Context, data []byte) (contract.CheckResult, *model.Assignment, error)
+}
+
+// Lifecycle переводит назначение по сообщениям агента, см. service.LifecycleService.
+type Lifecycle interface {
+	Acknowledged(ctx context.Context, assignmentID uuid.UUID) error
+	ResultRecorded(ctx context.Context, tx repository.RepoExtension, assignmentID uuid.UUID) (requestStatus string, changed bool, err error)
+}
+
+// Events рассылает изменения открытым websocket, см. stream.Hub.
+type Events interface {
+	Publish(ctx context.Context, event model.StreamEvent) error
 }
 
 type Config struct {
@@ -43,15 +66,33 @@ type Subscriber struct {
 	consumer    kafka.ConsumerGroupRunner
 	inboxRepo   InboxRepository
 	requestRepo RequestRepository
+	opener      ResultOpener
+	enricher    Enricher
+	lifecycle   Lifecycle
+	events      Events
 }
 
-func NewSubscriber(l *zap.Logger, cfg Config, consumer kafka.ConsumerGroupRunner, inboxRepo InboxRepository, requestRepo RequestRepository) *Subscriber {
+func NewSubscriber(
+	l *zap.Logger,
+	cfg Config,
+	consumer kafka.ConsumerGroupRunner,
+	inboxRepo InboxRepository,
+	requestRepo RequestRepository,
+	opener ResultOpener,
+	enricher Enricher,
+	lifecycle Lifecycle,
+	events Events,
+) *Subscriber {
 	return &Subscriber{
 		l:           l,
 		cfg:         cfg,
 		consumer:    consumer,
 		inboxRepo:   inboxRepo,
 		requestRepo: requestRepo,
+		opener:      opener,
+		enricher:    enricher,
+		lifecycle:   lifecycle,
+		events:      events,
 	}
 }
 
@@ -127,37 +168,64 @@ func (s *Subscriber) worker(ctx context.Context, id int, messagePipe <-chan *kaf
 	}
 }
 
-func (s *Subscriber) process(ctx context.Context, message *kafka.MessageWithMarkFunc) (err error) {
+func (s *Subscriber) process(ctx context.Context, message *kafka.MessageWithMarkFunc) error {
 	messageID, err := uuid.FromBytes(message.Message.Key)
 	if err != nil {
 		return fmt.Errorf("failed to parse message id: %w", err)
 	}
 
+	return s.Handle(ctx, messageID, message.Message.Value)
+}
+
+// Handle принимает результат проверки от агента так же, как сообщение из топика inbox.
+// Через него HTTP транспорт агентов пишет результаты в те же inbox и check_results.
+// payload — подписанный конверт, в inbox сохраняется он целиком.
+func (s *Subscriber) Handle(ctx context.Context, messageID uuid.UUID, payload []byte) (err error) {
 	messageInbox := model.InboxMessage{
 		ID:      messageID,
 		Topic:   s.cfg.Topic,
-		Payload: message.Message.Value,
+		Payload: payload,
+	}
+
+	checkResultFromAgent, assignment, err := s.opener.OpenResult(ctx, payload)
+	if err != nil {
+		return fmt.Errorf("failed to accept checkResult: %w", err)
 	}
 
-	var checkResultFromAgent model.CheckResultFromAgent
-	if err := json.Unmarshal(message.Message.Value, &checkResultFromAgent); err != nil {
-		return fmt.Errorf("failed to unmarshal checkResult: %w", err)
+	// любое сообщение агента по назначению, включая подтверждение старта без payload, значит, что он его выполняет
+	if err := s.lifecycle.Acknowledged(ctx, assignment.ID); err != nil {
+		return fmt.Errorf("failed to acknowledge assignment: %w", err)
+	}
+
+	// обогащение не критично: без него результат сохраняется как прислал агент
+	if err := s.enricher.EnrichResult(ctx, assignment.ID, &checkResultFromAgent); err != nil {
+		s.l.Warn("Failed to enrich checkResult", zap.String("task_id", checkResultFromAgent.TaskID.String()), zap.Error(err))
+	}
+
+	if !checkResultFromAgent.IsFinal() {
+		return s.processProgress(ctx, assignment, checkResultFromAgent)
 	}
 
 	checkResult := &model.CheckResult{
 		ID:           uuid.New(),
-		AssignmentId: checkResultFromAgent.TaskID,
+		AssignmentId: assignment.ID,
+		AgentID:      checkResultFromAgent.AgentID,
+		CheckIndex:   checkResultFromAgent.CheckIndex,
 		Type:         checkResultFromAgent.Type,
-		Status:       "DONE",
+		Status:       model.StatusDone,
 		StartedAt:    checkResultFromAgent.StartedAt,
 		FinishedAt:   time.Now(),
-		Payload:      message.Message.Value,
+	}
+
+	checkResult.Payload, err = json.Marshal(checkResultFromAgent)
+	if err != nil {
+		return fmt.Errorf("failed to marshal checkResult: %w", err)
 	}
 
 	if checkResultFromAgent.OK {
-		checkResult.Status = "DONE"
+		checkResult.Status = model.StatusDone
 	} else {
-		checkResult.Status = "FAILED"
+		checkResult.Status = model.StatusFailed
 	}
 
 	tx, err := s.requestRepo.Pool().Begin(ctx)
@@ -177,13 +245,113 @@ func (s *Subscriber) process(ctx context.Context, message *kafka.MessageWithMark
 		return fmt.Errorf("failed to insert message: %w", err)
 	}
 
-	if err := s.requestRepo.InsertCheckResult(ctx, tx, checkResult); err != nil {
+	inserted, err := s.requestRepo.InsertCheckResult(ctx, tx, checkResult)
+	if err != nil {
 		return fmt.Errorf("failed to insert checkResult: %w", err)
 	}
 
+	// результат этой проверки уже сохранён: повтор только фиксируется в inbox, назначение второй раз не завершается
+	if !inserted {
+		if err = tx.Commit(ctx); err != nil {
+			return fmt.Errorf("failed to commit transaction: %w", err)
+		}
+
+		s.l.Debug("Duplicate checkResult skipped",
+			zap.String("assignment_id", assignment.ID.String()),
+			zap.Int("check_index", checkResult.CheckIndex),
+		)
+
+		return nil
+	}
+
+	if err := s.requestRepo.FinishCheckProgress(ctx, tx, assignment.ID, checkResult.CheckIndex, checkResult.Type); err != nil {
+		return fmt.Errorf("failed to finish checkProgress: %w", err)
+	}
+
+	requestStatus, statusChanged, err := s.lifecycle.ResultRecorded(ctx, tx, assignment.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update assignment status: %w", err)
+	}
+
 	if err := tx.Commit(ctx); err != nil {
 		return fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
+	s.publish(ctx, model.StreamEvent{
+		Topic:     model.RequestTopic(assignment.RequestID),
+		RequestID: assignment.RequestID,
+		Type:      model.StreamEventResult,
+		Result: &model.CheckResultResponse{
+			RequestID:    assignment.RequestID,
+			AssignmentID: assignment.ID,
+			AgentID:      checkResult.AgentID,
+			AgentRegion:  assignment.AgentRegion,
+			CheckIndex:   checkResult.CheckIndex,
+			Type:         checkResult.Type,
+			Status:       checkResult.Status,
+			StartedAt:    checkResult.StartedAt,
+			FinishedAt:   checkResult.FinishedAt,
+			Payload:      checkResult.Payload,
+		},
+	})
+
+	if statusChanged {
+		s.publish(ctx, model.StreamEvent{
+			Topic:     model.RequestTopic(assignment.RequestID),
+			RequestID: assignment.RequestID,
+			Type:      model.StreamEventStatus,
+			Status:    requestStatus,
+		})
+	}
+
+	return nil
+}
+
+// publish рассылка не критична: websocket, пропустивший событие, дочитает состояние из БД.
+func (s *Subscriber) publish(ctx context.Context, event model.StreamEvent) {
+	if err := s.events.Publish(ctx, event); err != nil {
+		s.l.Warn("Failed to publish stream event",
+			zap.String("topic", event.Topic),
+			zap.String("type", event.Type),
+			zap.Error(err),
+		)
+	}
+}
+
+// processProgress сохраняет промежуточный результат: в inbox он не пишется,
+// хранится только последнее состояние проверки.
+func (s *Subscriber) processProgress(ctx context.Context, assignment *model.Assignment, res contract.CheckResult) error {
+	if len(res.Payload) == 0 {
+		return nil
+	}
+
+	progress := &model.CheckProgress{
+		AssignmentID: assignment.ID,
+		AgentID:      res.AgentID,
+		AgentRegion:  assignment.AgentRegion,
+		CheckIndex:   res.CheckIndex,
+		Type:         res.Type,
+		Seq:          res.Seq,
+		StartedAt:    res.StartedAt,
+		Payload:      res.Payload,
+	}
+
+	updated, err := s.requestRepo.UpsertCheckProgress(ctx, nil, progress)
+	if err != nil {
+		return fmt.Errorf("failed to upsert checkProgress: %w", err)
+	}
+
+	// устаревшее сообщение (seq меньше сохранённого) не рассылается, чтобы не откатить состояние у клиентов
+	if updated {
+		progress.UpdatedAt = time.Now()
+
+		s.publish(ctx, model.StreamEvent{
+			Topic:     model.RequestTopic(assignment.RequestID),
+			RequestID: assignment.RequestID,
+			Type:      model.StreamEventProgress,
+			Progress:  progress,
+		})
+	}
+
 	return nil
 }
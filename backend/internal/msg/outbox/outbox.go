@@ -20,6 +20,11 @@ type Repository interface {
 	SelectUnsentBatch(ctx context.Context, ext repository.RepoExtension, batchSize int) ([]model.OutboxMessage, error)
 }
 
+// Lifecycle отмечает назначение задачи выданным, см. service.LifecycleService.
+type Lifecycle interface {
+	Dispatched(ctx context.Context, outboxID uuid.UUID) error
+}
+
 type Config struct {
 	Name         string
 	WorkerCount  int
@@ -32,14 +37,16 @@ type Publisher struct {
 	cfg        Config
 	producer   kafka.Producer
 	outboxRepo Repository
+	lifecycle  Lifecycle
 }
 
-func NewPublisher(l *zap.Logger, cfg Config, producer kafka.Producer, outboxRepo Repository) *Publisher {
+func NewPublisher(l *zap.Logger, cfg Config, producer kafka.Producer, outboxRepo Repository, lifecycle Lifecycle) *Publisher {
 	return &Publisher{
 		l:          l,
 		cfg:        cfg,
 		producer:   producer,
 		outboxRepo: outboxRepo,
+		lifecycle:  lifecycle,
 	}
 }
 
@@ -122,5 +129,10 @@ func (p *Publisher) sendAndMark(ctx context.Context, message model.OutboxMessage
 		return 0, 0, fmt.Errorf("failed to update as sent: %w", err)
 	}
 
+	// сообщение уже в Kafka: ошибка статуса не повод отправлять его повторно
+	if err := p.lifecycle.Dispatched(ctx, message.ID); err != nil {
+		p.l.Warn("Failed to mark assignment dispatched", zap.Error(err), zap.String("message_id", message.ID.String()))
+	}
+
 	return partition, offset, nil
 }
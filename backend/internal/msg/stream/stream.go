@@ -0,0 +1,254 @@
+// Package stream рассылка изменений открытым websocket. События публикуются в канал Redis,
+// его слушает каждая реплика бэкенда и раздаёт события своим подписчикам, поэтому websocket на одной
+// реплике видит результаты, принятые другой. Каждое событие получает номер в рамках своего топика,
+// последние события топика хранятся в Redis, чтобы клиент после переподключения дочитал пропущенное.
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+
+	"hackathon-back/internal/model"
+	"hackathon-back/pkg/redis"
+)
+
+// publishScript атомарно выдаёт событию номер, кладёт его в историю топика и публикует в канал:
+// порядок номеров в канале совпадает с порядком в истории.
+// KEYS[1] — счётчик топика, KEYS[2] — история; ARGV — событие, размер истории, TTL в секундах, канал.
+var publishScript = goredis.NewScript(`
+local seq = redis.call('INCR', KEYS[1])
+local msg = '{"seq":' .. seq .. ',"event":' .. ARGV[1] .. '}'
+redis.call('ZADD', KEYS[2], seq, msg)
+redis.call('ZREMRANGEBYRANK', KEYS[2], 0, -(tonumber(ARGV[2]) + 1))
+redis.call('EXPIRE', KEYS[1], ARGV[3])
+redis.call('EXPIRE', KEYS[2], ARGV[3])
+redis.call('PUBLISH', ARGV[4], msg)
+return seq
+`)
+
+type Config struct {
+	Channel     string
+	BufferSize  int
+	HistorySize int
+	HistoryTTL  time.Duration
+}
+
+// envelope событие в канале и в истории: номер присваивает publishScript.
+type envelope struct {
+	Seq   int64             `json:"seq"`
+	Event model.StreamEvent `json:"event"`
+}
+
+type subscription struct {
+	ch chan model.StreamEvent
+}
+
+// Hub подписки websocket на топики событий, см. model.ParseTopic.
+type Hub struct {
+	l   *zap.Logger
+	cfg Config
+	rdb redis.Redis
+
+	mu   sync.Mutex
+	subs map[string]map[*subscription]struct{}
+}
+
+func NewHub(l *zap.Logger, cfg Config, rdb redis.Redis) *Hub {
+	return &Hub{
+		l:    l,
+		cfg:  cfg,
+		rdb:  rdb,
+		subs: make(map[string]map[*subscription]struct{}),
+	}
+}
+
+// Publish отправляет событие всем репликам, включая эту, и сохраняет его в историю топика event.Topic.
+// Публиковать нужно после коммита транзакции, которая записала изменение.
+func (h *Hub) Publish(ctx context.Context, event model.StreamEvent) error {
+	b, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal stream event: %w", err)
+	}
+
+	err = publishScript.Run(ctx, h.rdb.RDB(),
+		[]string{h.seqKey(event.Topic), h.historyKey(event.Topic)},
+		string(b), h.cfg.HistorySize, int64(h.cfg.HistoryTTL/time.Second), h.cfg.Channel,
+	).Err()
+	if err != nil {
+		return fmt.Errorf("failed to publish stream event: %w", err)
+	}
+
+	return nil
+}
+
+// Seq номер последнего события топика, 0 — событий не было или история истекла.
+func (h *Hub) Seq(ctx context.Context, topic string) (int64, error) {
+	seq, err := h.rdb.RDB().Get(ctx, h.seqKey(topic)).Int64()
+	if err != nil && !errors.Is(err, goredis.Nil) {
+		return 0, fmt.Errorf("failed to get stream seq: %w", err)
+	}
+
+	return seq, nil
+}
+
+// Replay события топика с номером больше afterSeq из истории. complete == false, если часть
+// этих событий уже вытеснена из истории или счётчик топика сбросился: тогда клиенту нужен снимок из БД.
+func (h *Hub) Replay(ctx context.Context, topic string, afterSeq int64) (events []model.StreamEvent, complete bool, err error) {
+	var (
+		seqCmd     *goredis.StringCmd
+		historyCmd *goredis.StringSliceCmd
+	)
+
+	_, err = h.rdb.RDB().TxPipelined(ctx, func(pipe goredis.Pipeliner) error {
+		seqCmd = pipe.Get(ctx, h.seqKey(topic))
+		historyCmd = pipe.ZRangeByScore(ctx, h.historyKey(topic), &goredis.ZRangeBy{
+			Min: "(" + strconv.FormatInt(afterSeq, 10),
+			Max: "+inf",
+		})
+
+		return nil
+	})
+	if err != nil && !errors.Is(err, goredis.Nil) {
+		return nil, false, fmt.Errorf("failed to read stream history: %w", err)
+	}
+
+	seq, err := seqCmd.Int64()
+	if err != nil && !errors.Is(err, goredis.Nil) {
+		return nil, false, fmt.Errorf("failed to get stream seq: %w", err)
+	}
+
+	return replayHistory(historyCmd.Val(), seq, afterSeq)
+}
+
+// replayHistory разбирает события истории с номером больше afterSeq при текущем номере топика seq.
+// История полна, если она начинается сразу после afterSeq, а без событий — если после afterSeq их и не было.
+func replayHistory(history []string, seq, afterSeq int64) (events []model.StreamEvent, complete bool, err error) {
+	for _, raw := range history {
+		var env envelope
+		if err := json.Unmarshal([]byte(raw), &env); err != nil {
+			return nil, false, fmt.Errorf("failed to unmarshal stream event: %w", err)
+		}
+
+		env.Event.Seq = env.Seq
+		events = append(events, env.Event)
+	}
+
+	if len(events) == 0 {
+		return nil, seq == afterSeq, nil
+	}
+
+	return events, events[0].Seq == afterSeq+1, nil
+}
+
+// Subscribe подписывает на события топика. Если подписчик не успевает читать и буфер
+// переполнен, канал закрывается: подписчик должен заново прочитать состояние и подписаться снова.
+// unsubscribe нужно вызвать, когда события больше не нужны.
+func (h *Hub) Subscribe(topic string) (events <-chan model.StreamEvent, unsubscribe func()) {
+	sub := &subscription{ch: make(chan model.StreamEvent, h.cfg.BufferSize)}
+
+	h.mu.Lock()
+	if h.subs[topic] == nil {
+		h.subs[topic] = make(map[*subscription]struct{})
+	}
+	h.subs[topic][sub] = struct{}{}
+	h.mu.Unlock()
+
+	return sub.ch, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+
+		h.remove(topic, sub)
+	}
+}
+
+// Run слушает канал Redis до отмены ctx. При обрыве соединения go-redis переподключается сам,
+// события за время обрыва подписчики дочитывают из истории по номерам.
+func (h *Hub) Run(ctx context.Context) {
+	pubsub := h.rdb.RDB().Subscribe(ctx, h.cfg.Channel)
+	defer func() {
+		if err := pubsub.Close(); err != nil {
+			h.l.Warn("Failed to close stream subscription", zap.Error(err))
+		}
+	}()
+
+	h.l.Info("Stream hub started", zap.String("channel", h.cfg.Channel))
+
+	messages := pubsub.Channel()
+
+	for {
+		select {
+		case <-ctx.Done():
+			h.l.Info("Context canceled, stopping stream hub")
+
+			return
+		case msg, ok := <-messages:
+			if !ok {
+				h.l.Info("Stream channel closed")
+
+				return
+			}
+
+			var env envelope
+			if err := json.Unmarshal([]byte(msg.Payload), &env); err != nil {
+				h.l.Error("Error parsing stream event", zap.Error(err))
+
+				continue
+			}
+
+			env.Event.Seq = env.Seq
+			h.dispatch(env.Event)
+		}
+	}
+}
+
+func (h *Hub) dispatch(event model.StreamEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for sub := range h.subs[event.Topic] {
+		select {
+		case sub.ch <- event:
+		default:
+			h.l.Warn("Stream subscriber is too slow, dropping subscription",
+				zap.String("topic", event.Topic),
+			)
+
+			h.remove(event.Topic, sub)
+		}
+	}
+}
+
+// remove вызывается под h.mu. Канал закрывается один раз: повторный вызов для удалённой подписки ничего не делает.
+func (h *Hub) remove(topic string, sub *subscription) {
+	subs, ok := h.subs[topic]
+	if !ok {
+		return
+	}
+
+	if _, ok := subs[sub]; !ok {
+		return
+	}
+
+	delete(subs, sub)
+	close(sub.ch)
+
+	if len(subs) == 0 {
+		delete(h.subs, topic)
+	}
+}
+
+func (h *Hub) seqKey(topic string) string {
+	return h.cfg.Channel + ":seq:" + topic
+}
+
+func (h *Hub) historyKey(topic string) string {
+	return h.cfg.Channel + ":history:" + topic
+}
@@ -0,0 +1,111 @@
+package stream
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"hackathon-back/internal/model"
+)
+
+const testTopic = "request:6d40a8b9-a135-4b67-b96b-0579c6ae0f76"
+
+// historyEntry запись истории в том виде, в котором её сохраняет publishScript.
+func historyEntry(t *testing.T, seq int64) string {
+	t.Helper()
+
+	b, err := json.Marshal(model.StreamEvent{Topic: testTopic, Type: model.StreamEventStatus, Status: fmt.Sprintf("status-%d", seq)})
+	if err != nil {
+		t.Fatalf("marshal event: %v", err)
+	}
+
+	return fmt.Sprintf(`{"seq":%d,"event":%s}`, seq, b)
+}
+
+func TestReplayHistory(t *testing.T) {
+	tests := []struct {
+		name     string
+		history  []int64
+		seq      int64
+		afterSeq int64
+		want     []int64
+		complete bool
+	}{
+		{name: "continues after afterSeq", history: []int64{4, 5, 6}, seq: 6, afterSeq: 3, want: []int64{4, 5, 6}, complete: true},
+		{name: "nothing new", seq: 6, afterSeq: 6, complete: true},
+		{name: "new topic", seq: 0, afterSeq: 0, complete: true},
+		{name: "events evicted from history", history: []int64{8, 9}, seq: 9, afterSeq: 3, want: []int64{8, 9}},
+		{name: "history expired", seq: 9, afterSeq: 3},
+		{name: "counter reset", seq: 2, afterSeq: 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			history := make([]string, 0, len(tt.history))
+			for _, seq := range tt.history {
+				history = append(history, historyEntry(t, seq))
+			}
+
+			events, complete, err := replayHistory(history, tt.seq, tt.afterSeq)
+			if err != nil {
+				t.Fatalf("replayHistory: %v", err)
+			}
+
+			if complete != tt.complete {
+				t.Fatalf("complete = %v, want %v", complete, tt.complete)
+			}
+
+			if len(events) != len(tt.want) {
+				t.Fatalf("got %d events, want %d", len(events), len(tt.want))
+			}
+
+			for i, event := range events {
+				if event.Seq != tt.want[i] || event.Status != fmt.Sprintf("status-%d", tt.want[i]) || event.Topic != testTopic {
+					t.Fatalf("event %d = %+v, want seq %d", i, event, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestReplayHistoryInvalidEntry(t *testing.T) {
+	if _, _, err := replayHistory([]string{"not json"}, 1, 0); err == nil {
+		t.Fatal("expected error for invalid history entry")
+	}
+}
+
+func TestHubDispatch(t *testing.T) {
+	hub := NewHub(zap.NewNop(), Config{BufferSize: 1}, nil)
+
+	events, unsubscribe := hub.Subscribe(testTopic)
+	other, unsubscribeOther := hub.Subscribe("agents")
+
+	defer unsubscribeOther()
+
+	hub.dispatch(model.StreamEvent{Topic: testTopic, Seq: 1})
+
+	if event := <-events; event.Seq != 1 {
+		t.Fatalf("got seq %d, want 1", event.Seq)
+	}
+
+	if len(other) != 0 {
+		t.Fatal("event delivered to another topic")
+	}
+
+	// второе событие не помещается в буфер: подписка медленная и закрывается
+	hub.dispatch(model.StreamEvent{Topic: testTopic, Seq: 2})
+	hub.dispatch(model.StreamEvent{Topic: testTopic, Seq: 3})
+
+	if event := <-events; event.Seq != 2 {
+		t.Fatalf("got seq %d, want 2", event.Seq)
+	}
+
+	if _, ok := <-events; ok {
+		t.Fatal("slow subscription was not closed")
+	}
+
+	// отписка после закрытия ничего не делает
+	unsubscribe()
+}
@@ -2,11 +2,20 @@ package repository
 
 import (
 	"context"
-	"hackathon-back/internal/model"
+	"errors"
+	"time"
 
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
+
+	"hackathon-back/internal/apperrors"
+	"hackathon-back/internal/model"
 )
 
+const agentColumns = `id, COALESCE(name, ''), region, COALESCE(country, ''), COALESCE(asn, 0), lat, lon, online, updated_at, last_seen, COALESCE(version, ''), uptime_seconds, in_flight, queue_depth, capabilities, transport, public_key, registered, disabled, created_at, owner_user_id, owner_org_id`
+
 type AgentRepository struct {
 	db *pgxpool.Pool
 }
@@ -26,11 +35,31 @@ func (r *AgentRepository) SelectAgents(ctx context.Context, ext RepoExtension) (
 		ext = r.db
 	}
 
-	var agents []*model.Agent
+	const query = `
+		SELECT ` + agentColumns + `
+		FROM domain.agents
+		ORDER BY region, id;
+	`
+
+	rows, err := ext.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanAgents(rows)
+}
+
+// SelectOnlineAgents агенты, от которых heartbeat пришёл вовремя, только им можно отправлять задачи.
+func (r *AgentRepository) SelectOnlineAgents(ctx context.Context, ext RepoExtension) ([]*model.Agent, error) {
+	if ext == nil {
+		ext = r.db
+	}
 
 	const query = `
-		SELECT id, region, asn, online, updated_at 
-		FROM domain.agents;
+		SELECT ` + agentColumns + `
+		FROM domain.agents
+		WHERE online AND NOT disabled
+		ORDER BY region, id;
 	`
 
 	rows, err := ext.Query(ctx, query)
@@ -38,50 +67,389 @@ func (r *AgentRepository) SelectAgents(ctx context.Context, ext RepoExtension) (
 		return nil, err
 	}
 
-	defer rows.Close()
+	return scanAgents(rows)
+}
 
-	for rows.Next() {
-		var agent model.Agent
-		if err := rows.Scan(
-			&agent.ID,
-			&agent.Region,
-			&agent.ASN,
-			&agent.Online,
-			&agent.UpdatedAt,
-		); err != nil {
-			return nil, err
+// SelectAgentsByOwner частные агенты пользователя и организаций, в которых он состоит.
+func (r *AgentRepository) SelectAgentsByOwner(ctx context.Context, ext RepoExtension, userID uuid.UUID, orgIDs []uuid.UUID) ([]*model.Agent, error) {
+	if ext == nil {
+		ext = r.db
+	}
+
+	const query = `
+		SELECT ` + agentColumns + `
+		FROM domain.agents
+		WHERE owner_user_id = $1 OR owner_org_id = ANY($2)
+		ORDER BY region, id;
+	`
+
+	rows, err := ext.Query(ctx, query, userID, orgIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanAgents(rows)
+}
+
+// SelectPoolUsage сколько частных агентов у владельца и в каких регионах. Задаётся один из ownerUserID и ownerOrgID.
+func (r *AgentRepository) SelectPoolUsage(ctx context.Context, ext RepoExtension, ownerUserID, ownerOrgID *uuid.UUID) (agents int, regions []string, err error) {
+	if ext == nil {
+		ext = r.db
+	}
+
+	const query = `
+		SELECT COUNT(*), COALESCE(array_agg(DISTINCT region), '{}')
+		FROM domain.agents
+		WHERE owner_user_id = $1 OR owner_org_id = $2;
+	`
+
+	if err := ext.QueryRow(ctx, query, ownerUserID, ownerOrgID).Scan(&agents, &regions); err != nil {
+		return 0, nil, err
+	}
+
+	return agents, regions, nil
+}
+
+func (r *AgentRepository) SelectAgentByID(ctx context.Context, ext RepoExtension, id uuid.UUID) (*model.Agent, error) {
+	if ext == nil {
+		ext = r.db
+	}
+
+	const query = `
+		SELECT ` + agentColumns + `
+		FROM domain.agents
+		WHERE id = $1;
+	`
+
+	agent, err := scanAgent(ext.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apperrors.ErrAgentDoesNotExist
 		}
 
-		agents = append(agents, &agent)
+		return nil, err
 	}
 
-	if err := rows.Err(); err != nil {
+	return agent, nil
+}
+
+// SelectAgentByGatewayToken агент id, которому при регистрации выдан gateway токен с хешем tokenHash.
+// Другой токен, агент без токена или неизвестный агент — apperrors.ErrAgentDoesNotExist.
+func (r *AgentRepository) SelectAgentByGatewayToken(ctx context.Context, ext RepoExtension, id uuid.UUID, tokenHash []byte) (*model.Agent, error) {
+	if ext == nil {
+		ext = r.db
+	}
+
+	const query = `
+		SELECT ` + agentColumns + `
+		FROM domain.agents
+		WHERE id = $1 AND gateway_token_hash = $2;
+	`
+
+	agent, err := scanAgent(ext.QueryRow(ctx, query, id, tokenHash))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apperrors.ErrAgentDoesNotExist
+		}
+
 		return nil, err
 	}
 
-	return agents, nil
+	return agent, nil
 }
 
-func (r *AgentRepository) SelectAgentByRegion(ctx context.Context, ext RepoExtension, region string) (*model.Agent, error) {
+// UpdateHeartbeat отмечает онлайн агента реестра с привязанным при регистрации ключом. Неизвестный агент,
+// агент не из реестра, ещё не прошедший регистрацию или агент без владельца в регионе частного пула
+// получает apperrors.ErrAgentNotRegistered: heartbeat не заводит агентов сам.
+// last_seen берётся по часам бэкенда, чтобы рассинхрон часов агента не влиял на порог offline.
+// Регион, страну, ASN, координаты и ключ агента heartbeat не меняет: их задаёт реестр, выключенный агент не выходит online.
+func (r *AgentRepository) UpdateHeartbeat(ctx context.Context, ext RepoExtension, hb *model.AgentHeartbeat) (wasOnline bool, err error) {
 	if ext == nil {
 		ext = r.db
 	}
 
-	var agent model.Agent
+	const query = `
+		WITH prev AS (
+			SELECT online FROM domain.agents WHERE id = $1 FOR UPDATE
+		)
+		UPDATE domain.agents SET
+			online         = NOT disabled,
+			last_seen      = NOW(),
+			version        = $2,
+			uptime_seconds = $3,
+			in_flight      = $4,
+			queue_depth    = $5,
+			capabilities   = COALESCE($6, capabilities),
+			transport      = $7,
+			updated_at     = NOW()
+		WHERE id = $1 AND registered AND public_key IS NOT NULL
+		  AND (owner_user_id IS NOT NULL OR owner_org_id IS NOT NULL OR NOT (starts_with(region, $8) OR starts_with(region, $9)))
+		RETURNING (SELECT online FROM prev);
+	`
+
+	if err := ext.QueryRow(ctx, query,
+		hb.AgentID,
+		hb.Version,
+		hb.UptimeSeconds,
+		hb.InFlight,
+		hb.QueueDepth,
+		hb.Capabilities,
+		hb.Transport,
+		model.PrivateRegionUserPrefix,
+		model.PrivateRegionOrgPrefix,
+	).Scan(&wasOnline); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, apperrors.ErrAgentNotRegistered
+		}
+
+		return false, err
+	}
+
+	return wasOnline, nil
+}
+
+// UpdateStaleAsOffline переводит в offline агентов без heartbeat дольше offlineAfter и возвращает их.
+func (r *AgentRepository) UpdateStaleAsOffline(ctx context.Context, ext RepoExtension, offlineAfter time.Duration) ([]*model.Agent, error) {
+	if ext == nil {
+		ext = r.db
+	}
 
 	const query = `
-		SELECT id, region, asn, online, updated_at
-		FROM domain.agents
-		WHERE region = $1;
+		UPDATE domain.agents
+		SET online = FALSE, in_flight = 0, queue_depth = 0, updated_at = NOW()
+		WHERE online AND (last_seen IS NULL OR last_seen < NOW() - $1 * INTERVAL '1 second')
+		RETURNING ` + agentColumns + `;
+	`
+
+	rows, err := ext.Query(ctx, query, offlineAfter.Seconds())
+	if err != nil {
+		return nil, err
+	}
+
+	return scanAgents(rows)
+}
+
+// InsertAgent заводит агента реестра, он offline до первого heartbeat.
+func (r *AgentRepository) InsertAgent(ctx context.Context, ext RepoExtension, agent *model.Agent) error {
+	if ext == nil {
+		ext = r.db
+	}
+
+	const query = `
+		INSERT INTO domain.agents (id, name, region, country, asn, lat, lon, transport, owner_user_id, owner_org_id, registered, online)
+		VALUES ($1, NULLIF($2, ''), $3, NULLIF($4, ''), NULLIF($5, 0), $6, $7, $8, $9, $10, TRUE, FALSE)
+		RETURNING created_at, updated_at;
+	`
+
+	err := ext.QueryRow(ctx, query,
+		agent.ID,
+		agent.Name,
+		agent.Region,
+		agent.Country,
+		agent.ASN,
+		agent.Lat,
+		agent.Lon,
+		agent.Transport,
+		agent.OwnerUserID,
+		agent.OwnerOrgID,
+	).Scan(&agent.CreatedAt, &agent.UpdatedAt)
+	if err != nil {
+		var pgErr *pgconn.PgError
+
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			return apperrors.ErrOrganizationDoesNotExist
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// UpdateAgent меняет заданные поля агента. После правки агент считается заведённым в реестре:
+// heartbeat больше не перезаписывает его страну, ASN и координаты. Выключенный агент сразу уходит в offline.
+func (r *AgentRepository) UpdateAgent(ctx context.Context, ext RepoExtension, id uuid.UUID, upd *model.AgentUpdateRequest) (agent *model.Agent, wasOnline bool, err error) {
+	if ext == nil {
+		ext = r.db
+	}
+
+	const query = `
+		WITH prev AS (
+			SELECT online FROM domain.agents WHERE id = $1
+		)
+		UPDATE domain.agents SET
+			name       = COALESCE($2, name),
+			country    = COALESCE($3, country),
+			asn        = COALESCE($4, asn),
+			lat        = COALESCE($5, lat),
+			lon        = COALESCE($6, lon),
+			disabled   = COALESCE($7, disabled),
+			online     = online AND NOT COALESCE($7, disabled),
+			registered = TRUE,
+			updated_at = NOW()
+		WHERE id = $1
+		RETURNING ` + agentColumns + `, (SELECT online FROM prev);
+	`
+
+	row := ext.QueryRow(ctx, query, id, upd.Name, upd.Country, upd.ASN, upd.Lat, upd.Lon, upd.Disabled)
+
+	agent, err = scanAgent(row, &wasOnline)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, false, apperrors.ErrAgentDoesNotExist
+		}
+
+		return nil, false, err
+	}
+
+	return agent, wasOnline, nil
+}
+
+// UpdateAgentCredentials привязывает к агенту ключ и хеш gateway токена из регистрации, прежние больше не принимаются.
+// gatewayTokenHash nil — gateway выключен. Агент, заведённый миграцией, после регистрации становится агентом реестра.
+func (r *AgentRepository) UpdateAgentCredentials(ctx context.Context, ext RepoExtension, id uuid.UUID, publicKey, gatewayTokenHash []byte) error {
+	if ext == nil {
+		ext = r.db
+	}
+
+	const query = `
+		UPDATE domain.agents
+		SET public_key = $2, gateway_token_hash = $3, registered = TRUE, updated_at = NOW()
+		WHERE id = $1;
+	`
+
+	tag, err := ext.Exec(ctx, query, id, publicKey, gatewayTokenHash)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return apperrors.ErrAgentDoesNotExist
+	}
+
+	return nil
+}
+
+// DeleteAgent удаляет агента вместе с его токенами регистрации. Назначения и результаты остаются.
+func (r *AgentRepository) DeleteAgent(ctx context.Context, ext RepoExtension, id uuid.UUID) error {
+	if ext == nil {
+		ext = r.db
+	}
+
+	const query = `
+		DELETE FROM domain.agents WHERE id = $1;
+	`
+
+	tag, err := ext.Exec(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return apperrors.ErrAgentDoesNotExist
+	}
+
+	return nil
+}
+
+// SelectAgentLatencies минимальный RTT ping (мс) от каждого агента до адресов сети asn за последние window.
+// ASN адреса проставляет EnrichmentService, агенты без таких измерений в ответ не попадают.
+func (r *AgentRepository) SelectAgentLatencies(ctx context.Context, ext RepoExtension, asn int, window time.Duration) (map[uuid.UUID]float64, error) {
+	if ext == nil {
+		ext = r.db
+	}
+
+	const query = `
+		SELECT c.agent_id::UUID, MIN((reply->>'timeMs')::DOUBLE PRECISION)
+		FROM domain.check_results c
+		CROSS JOIN LATERAL jsonb_array_elements(c.payload->'payload'->'replies') reply
+		WHERE c.type = 'ping'
+		  AND c.status = 'DONE'
+		  AND (c.payload->'payload'->>'asn')::INTEGER = $1
+		  AND c.finished_at > NOW() - $2 * INTERVAL '1 second'
+		  AND c.agent_id IS NOT NULL
+		GROUP BY c.agent_id;
 	`
 
-	if err := ext.QueryRow(ctx, query, region).Scan(
+	rows, err := ext.Query(ctx, query, asn, window.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	latencies := make(map[uuid.UUID]float64)
+
+	for rows.Next() {
+		var (
+			agentID uuid.UUID
+			rtt     float64
+		)
+
+		if err := rows.Scan(&agentID, &rtt); err != nil {
+			return nil, err
+		}
+
+		latencies[agentID] = rtt
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return latencies, nil
+}
+
+func scanAgents(rows pgx.Rows) ([]*model.Agent, error) {
+	defer rows.Close()
+
+	var agents []*model.Agent
+
+	for rows.Next() {
+		agent, err := scanAgent(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		agents = append(agents, agent)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return agents, nil
+}
+
+// scanAgent читает колонки agentColumns, extra — колонки запроса после них.
+func scanAgent(row pgx.Row, extra ...any) (*model.Agent, error) {
+	var agent model.Agent
+
+	dest := []any{
 		&agent.ID,
+		&agent.Name,
 		&agent.Region,
+		&agent.Country,
 		&agent.ASN,
+		&agent.Lat,
+		&agent.Lon,
 		&agent.Online,
 		&agent.UpdatedAt,
-	); err != nil {
+		&agent.LastSeen,
+		&agent.Version,
+		&agent.UptimeSeconds,
+		&agent.InFlight,
+		&agent.QueueDepth,
+		&agent.Capabilities,
+		&agent.Transport,
+		&agent.PublicKey,
+		&agent.Registered,
+		&agent.Disabled,
+		&agent.CreatedAt,
+		&agent.OwnerUserID,
+		&agent.OwnerOrgID,
+	}
+
+	if err := row.Scan(append(dest, extra...)...); err != nil {
 		return nil, err
 	}
 
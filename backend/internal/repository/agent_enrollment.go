@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"hackathon-back/internal/apperrors"
+	"hackathon-back/internal/model"
+)
+
+const enrollmentColumns = `id, agent_id, created_at, expires_at, used_at, COALESCE(agent_version, ''), COALESCE(ip, '')`
+
+type AgentEnrollmentRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewAgentEnrollmentRepository(db *pgxpool.Pool) *AgentEnrollmentRepository {
+	return &AgentEnrollmentRepository{
+		db: db,
+	}
+}
+
+func (r *AgentEnrollmentRepository) Pool() *pgxpool.Pool {
+	return r.db
+}
+
+// InsertEnrollment сохраняет выданный токен, в БД попадает только его хеш.
+func (r *AgentEnrollmentRepository) InsertEnrollment(ctx context.Context, ext RepoExtension, enrollment *model.AgentEnrollment, tokenHash []byte) error {
+	if ext == nil {
+		ext = r.db
+	}
+
+	const query = `
+		INSERT INTO domain.agent_enrollments (id, agent_id, token_hash, expires_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING created_at;
+	`
+
+	return ext.QueryRow(ctx, query,
+		enrollment.ID,
+		enrollment.AgentID,
+		tokenHash,
+		enrollment.ExpiresAt,
+	).Scan(&enrollment.CreatedAt)
+}
+
+// ExpireUnusedEnrollments отзывает неиспользованные токены агента, чтобы действовал только последний выданный.
+func (r *AgentEnrollmentRepository) ExpireUnusedEnrollments(ctx context.Context, ext RepoExtension, agentID uuid.UUID) error {
+	if ext == nil {
+		ext = r.db
+	}
+
+	const query = `
+		UPDATE domain.agent_enrollments
+		SET expires_at = NOW()
+		WHERE agent_id = $1 AND used_at IS NULL AND expires_at > NOW();
+	`
+
+	_, err := ext.Exec(ctx, query, agentID)
+
+	return err
+}
+
+// ClaimEnrollment отмечает токен использованным и записывает версию и IP агента. Истёкший, уже использованный
+// или неизвестный токен — apperrors.ErrEnrollmentTokenInvalid: из двух одновременных обменов проходит один.
+func (r *AgentEnrollmentRepository) ClaimEnrollment(ctx context.Context, ext RepoExtension, tokenHash []byte, agentVersion, ip string) (*model.AgentEnrollment, error) {
+	if ext == nil {
+		ext = r.db
+	}
+
+	const query = `
+		UPDATE domain.agent_enrollments
+		SET used_at = NOW(), agent_version = NULLIF($2, ''), ip = NULLIF($3, '')
+		WHERE token_hash = $1 AND used_at IS NULL AND expires_at > NOW()
+		RETURNING ` + enrollmentColumns + `;
+	`
+
+	enrollment, err := scanEnrollment(ext.QueryRow(ctx, query, tokenHash, agentVersion, ip))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apperrors.ErrEnrollmentTokenInvalid
+		}
+
+		return nil, err
+	}
+
+	return enrollment, nil
+}
+
+// SelectEnrollments токены агента, новые первыми.
+func (r *AgentEnrollmentRepository) SelectEnrollments(ctx context.Context, ext RepoExtension, agentID uuid.UUID) ([]*model.AgentEnrollment, error) {
+	if ext == nil {
+		ext = r.db
+	}
+
+	const query = `
+		SELECT ` + enrollmentColumns + `
+		FROM domain.agent_enrollments
+		WHERE agent_id = $1
+		ORDER BY created_at DESC;
+	`
+
+	rows, err := ext.Query(ctx, query, agentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var enrollments []*model.AgentEnrollment
+
+	for rows.Next() {
+		enrollment, err := scanEnrollment(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		enrollments = append(enrollments, enrollment)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return enrollments, nil
+}
+
+func scanEnrollment(row pgx.Row) (*model.AgentEnrollment, error) {
+	var enrollment model.AgentEnrollment
+
+	if err := row.Scan(
+		&enrollment.ID,
+		&enrollment.AgentID,
+		&enrollment.CreatedAt,
+		&enrollment.ExpiresAt,
+		&enrollment.UsedAt,
+		&enrollment.AgentVersion,
+		&enrollment.IP,
+	); err != nil {
+		return nil, err
+	}
+
+	return &enrollment, nil
+}
@@ -0,0 +1,326 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"hackathon-back/internal/apperrors"
+	"hackathon-back/internal/model"
+)
+
+const monitorColumns = `id, user_id, name, task, COALESCE(interval_seconds, 0), COALESCE(cron, ''),
+	enabled, next_run_at, last_run_at, created_at, updated_at`
+
+type MonitorRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewMonitorRepository(db *pgxpool.Pool) *MonitorRepository {
+	return &MonitorRepository{
+		db: db,
+	}
+}
+
+func (r *MonitorRepository) Pool() *pgxpool.Pool {
+	return r.db
+}
+
+func (r *MonitorRepository) InsertMonitor(ctx context.Context, ext RepoExtension, monitor *model.Monitor) error {
+	if ext == nil {
+		ext = r.db
+	}
+
+	const query = `
+		INSERT INTO domain.monitors (id, user_id, name, task, interval_seconds, cron, enabled, next_run_at)
+		VALUES ($1, $2, $3, $4, NULLIF($5, 0), NULLIF($6, ''), $7, $8)
+		RETURNING created_at, updated_at;
+	`
+
+	return ext.QueryRow(ctx, query,
+		monitor.ID,
+		monitor.UserID,
+		monitor.Name,
+		monitor.Task,
+		monitor.IntervalSeconds,
+		monitor.Cron,
+		monitor.Enabled,
+		monitor.NextRunAt,
+	).Scan(&monitor.CreatedAt, &monitor.UpdatedAt)
+}
+
+// UpdateMonitor записывает имя, задачу, расписание и enabled монитора. next_run_at меняется только с reschedule,
+// иначе остаётся тем, что записал планировщик.
+func (r *MonitorRepository) UpdateMonitor(ctx context.Context, ext RepoExtension, monitor *model.Monitor, reschedule bool) error {
+	if ext == nil {
+		ext = r.db
+	}
+
+	const query = `
+		UPDATE domain.monitors
+		SET name = $2, task = $3, interval_seconds = NULLIF($4, 0), cron = NULLIF($5, ''), enabled = $6,
+		    next_run_at = CASE WHEN $8 THEN $7 ELSE next_run_at END, updated_at = NOW()
+		WHERE id = $1
+		RETURNING next_run_at, last_run_at, updated_at;
+	`
+
+	err := ext.QueryRow(ctx, query,
+		monitor.ID,
+		monitor.Name,
+		monitor.Task,
+		monitor.IntervalSeconds,
+		monitor.Cron,
+		monitor.Enabled,
+		monitor.NextRunAt,
+		reschedule,
+	).Scan(&monitor.NextRunAt, &monitor.LastRunAt, &monitor.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return apperrors.ErrMonitorDoesNotExist
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+func (r *MonitorRepository) DeleteMonitor(ctx context.Context, ext RepoExtension, id uuid.UUID) error {
+	if ext == nil {
+		ext = r.db
+	}
+
+	const query = `
+		DELETE FROM domain.monitors WHERE id = $1;
+	`
+
+	tag, err := ext.Exec(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return apperrors.ErrMonitorDoesNotExist
+	}
+
+	return nil
+}
+
+func (r *MonitorRepository) SelectMonitorByID(ctx context.Context, ext RepoExtension, id uuid.UUID) (*model.Monitor, error) {
+	if ext == nil {
+		ext = r.db
+	}
+
+	const query = `
+		SELECT ` + monitorColumns + `
+		FROM domain.monitors
+		WHERE id = $1;
+	`
+
+	monitor, err := scanMonitor(ext.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apperrors.ErrMonitorDoesNotExist
+		}
+
+		return nil, err
+	}
+
+	return monitor, nil
+}
+
+// SelectMonitorsByUser мониторы пользователя, новые первыми.
+func (r *MonitorRepository) SelectMonitorsByUser(ctx context.Context, ext RepoExtension, userID uuid.UUID) ([]*model.Monitor, error) {
+	if ext == nil {
+		ext = r.db
+	}
+
+	const query = `
+		SELECT ` + monitorColumns + `
+		FROM domain.monitors
+		WHERE user_id = $1
+		ORDER BY created_at DESC;
+	`
+
+	rows, err := ext.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var monitors []*model.Monitor
+
+	for rows.Next() {
+		monitor, err := scanMonitor(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		monitors = append(monitors, monitor)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return monitors, nil
+}
+
+func (r *MonitorRepository) CountMonitorsByUser(ctx context.Context, ext RepoExtension, userID uuid.UUID) (int, error) {
+	if ext == nil {
+		ext = r.db
+	}
+
+	const query = `
+		SELECT COUNT(*) FROM domain.monitors WHERE user_id = $1;
+	`
+
+	var count int
+
+	if err := ext.QueryRow(ctx, query, userID).Scan(&count); err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// ClaimDueMonitor блокирует до конца транзакции ext один включённый монитор, время запуска которого наступило.
+// Мониторы, заблокированные другими репликами, пропускаются. Наступивших запусков нет — apperrors.ErrMonitorDoesNotExist.
+func (r *MonitorRepository) ClaimDueMonitor(ctx context.Context, ext RepoExtension) (*model.Monitor, error) {
+	if ext == nil {
+		ext = r.db
+	}
+
+	const query = `
+		SELECT ` + monitorColumns + `
+		FROM domain.monitors
+		WHERE enabled AND next_run_at <= NOW()
+		ORDER BY next_run_at
+		LIMIT 1
+		FOR UPDATE SKIP LOCKED;
+	`
+
+	monitor, err := scanMonitor(ext.QueryRow(ctx, query))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apperrors.ErrMonitorDoesNotExist
+		}
+
+		return nil, err
+	}
+
+	return monitor, nil
+}
+
+func (r *MonitorRepository) UpdateMonitorSchedule(ctx context.Context, ext RepoExtension, id uuid.UUID, lastRunAt, nextRunAt time.Time) error {
+	if ext == nil {
+		ext = r.db
+	}
+
+	const query = `
+		UPDATE domain.monitors
+		SET last_run_at = $2, next_run_at = $3
+		WHERE id = $1;
+	`
+
+	_, err := ext.Exec(ctx, query, id, lastRunAt, nextRunAt)
+
+	return err
+}
+
+func (r *MonitorRepository) InsertMonitorRun(ctx context.Context, ext RepoExtension, run *model.MonitorRun) error {
+	if ext == nil {
+		ext = r.db
+	}
+
+	const query = `
+		INSERT INTO domain.monitor_runs (id, monitor_id, request_id, status, error_text, scheduled_at)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6)
+		RETURNING created_at;
+	`
+
+	return ext.QueryRow(ctx, query,
+		run.ID,
+		run.MonitorID,
+		run.RequestID,
+		run.Status,
+		run.Error,
+		run.ScheduledAt,
+	).Scan(&run.CreatedAt)
+}
+
+// SelectMonitorRuns последние limit запусков монитора со статусом созданных запросов, новые первыми.
+func (r *MonitorRepository) SelectMonitorRuns(ctx context.Context, ext RepoExtension, monitorID uuid.UUID, limit int) ([]*model.MonitorRun, error) {
+	if ext == nil {
+		ext = r.db
+	}
+
+	const query = `
+		SELECT mr.id, mr.monitor_id, mr.request_id, mr.status, COALESCE(r.status::text, ''),
+		       COALESCE(mr.error_text, ''), mr.scheduled_at, mr.created_at
+		FROM domain.monitor_runs mr
+		LEFT JOIN domain.requests r ON r.id = mr.request_id
+		WHERE mr.monitor_id = $1
+		ORDER BY mr.created_at DESC
+		LIMIT $2;
+	`
+
+	rows, err := ext.Query(ctx, query, monitorID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var runs []*model.MonitorRun
+
+	for rows.Next() {
+		var run model.MonitorRun
+
+		if err := rows.Scan(
+			&run.ID,
+			&run.MonitorID,
+			&run.RequestID,
+			&run.Status,
+			&run.RequestStatus,
+			&run.Error,
+			&run.ScheduledAt,
+			&run.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+
+		runs = append(runs, &run)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return runs, nil
+}
+
+func scanMonitor(row pgx.Row) (*model.Monitor, error) {
+	var monitor model.Monitor
+
+	if err := row.Scan(
+		&monitor.ID,
+		&monitor.UserID,
+		&monitor.Name,
+		&monitor.Task,
+		&monitor.IntervalSeconds,
+		&monitor.Cron,
+		&monitor.Enabled,
+		&monitor.NextRunAt,
+		&monitor.LastRunAt,
+		&monitor.CreatedAt,
+		&monitor.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	return &monitor, nil
+}
@@ -0,0 +1,169 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"hackathon-back/internal/apperrors"
+	"hackathon-back/internal/model"
+)
+
+type OrganizationRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewOrganizationRepository(db *pgxpool.Pool) *OrganizationRepository {
+	return &OrganizationRepository{
+		db: db,
+	}
+}
+
+func (r *OrganizationRepository) Pool() *pgxpool.Pool {
+	return r.db
+}
+
+func (r *OrganizationRepository) InsertOrganization(ctx context.Context, ext RepoExtension, org *model.Organization) error {
+	if ext == nil {
+		ext = r.db
+	}
+
+	const query = `
+		INSERT INTO domain.organizations (id, name, created_by)
+		VALUES ($1, $2, $3)
+		RETURNING created_at;
+	`
+
+	return ext.QueryRow(ctx, query, org.ID, org.Name, org.CreatedBy).Scan(&org.CreatedAt)
+}
+
+// InsertMember добавляет пользователя в организацию. Неизвестный пользователь — apperrors.ErrUserDoesNotExist.
+func (r *OrganizationRepository) InsertMember(ctx context.Context, ext RepoExtension, orgID, userID uuid.UUID) error {
+	if ext == nil {
+		ext = r.db
+	}
+
+	const query = `
+		INSERT INTO domain.organization_members (organization_id, user_id)
+		VALUES ($1, $2);
+	`
+
+	if _, err := ext.Exec(ctx, query, orgID, userID); err != nil {
+		var pgErr *pgconn.PgError
+
+		if errors.As(err, &pgErr) {
+			switch pgErr.Code {
+			case "23505":
+				return apperrors.ErrMemberAlreadyExists
+			case "23503":
+				return apperrors.ErrUserDoesNotExist
+			}
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+func (r *OrganizationRepository) DeleteMember(ctx context.Context, ext RepoExtension, orgID, userID uuid.UUID) error {
+	if ext == nil {
+		ext = r.db
+	}
+
+	const query = `
+		DELETE FROM domain.organization_members
+		WHERE organization_id = $1 AND user_id = $2;
+	`
+
+	tag, err := ext.Exec(ctx, query, orgID, userID)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return apperrors.ErrMemberDoesNotExist
+	}
+
+	return nil
+}
+
+// SelectOrganizationsByUser организации, в которых состоит пользователь.
+func (r *OrganizationRepository) SelectOrganizationsByUser(ctx context.Context, ext RepoExtension, userID uuid.UUID) ([]*model.Organization, error) {
+	if ext == nil {
+		ext = r.db
+	}
+
+	const query = `
+		SELECT o.id, o.name, o.created_by, o.created_at
+		FROM domain.organizations o
+		JOIN domain.organization_members m ON m.organization_id = o.id
+		WHERE m.user_id = $1
+		ORDER BY o.name, o.id;
+	`
+
+	rows, err := ext.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var orgs []*model.Organization
+
+	for rows.Next() {
+		var org model.Organization
+
+		if err := rows.Scan(&org.ID, &org.Name, &org.CreatedBy, &org.CreatedAt); err != nil {
+			return nil, err
+		}
+
+		orgs = append(orgs, &org)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return orgs, nil
+}
+
+func (r *OrganizationRepository) SelectMembers(ctx context.Context, ext RepoExtension, orgID uuid.UUID) ([]*model.OrganizationMember, error) {
+	if ext == nil {
+		ext = r.db
+	}
+
+	const query = `
+		SELECT m.user_id, u.username, u.email, m.created_at
+		FROM domain.organization_members m
+		JOIN sso.users u ON u.id = m.user_id
+		WHERE m.organization_id = $1
+		ORDER BY m.created_at, m.user_id;
+	`
+
+	rows, err := ext.Query(ctx, query, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var members []*model.OrganizationMember
+
+	for rows.Next() {
+		var member model.OrganizationMember
+
+		if err := rows.Scan(&member.UserID, &member.Username, &member.Email, &member.CreatedAt); err != nil {
+			return nil, err
+		}
+
+		members = append(members, &member)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return members, nil
+}
@@ -25,12 +25,12 @@ func (r *OutboxRepository) InsertMessage(ctx context.Context, ext RepoExtension,
 	}
 
 	const query = `
-        INSERT INTO messages.outbox_messages (id, topic, payload)
-		VALUES ($1, $2, $3)
+        INSERT INTO messages.outbox_messages (id, topic, payload, transport)
+		VALUES ($1, $2, $3, COALESCE(NULLIF($4, ''), 'kafka'))
         ON CONFLICT DO NOTHING;
     `
 
-	_, err := ext.Exec(ctx, query, message.ID, message.Topic, message.Payload)
+	_, err := ext.Exec(ctx, query, message.ID, message.Topic, message.Payload, message.Transport)
 	if err != nil {
 		return err
 	}
@@ -57,6 +57,7 @@ func (r *OutboxRepository) UpdateAsSent(ctx context.Context, ext RepoExtension,
 	return nil
 }
 
+// SelectUnsentBatch неотправленные сообщения для Kafka. Задачи http агентов забирает ClaimUnsentBatch.
 func (r *OutboxRepository) SelectUnsentBatch(ctx context.Context, ext RepoExtension, batchSize int) ([]model.OutboxMessage, error) {
 	if ext == nil {
 		ext = r.db
@@ -65,9 +66,9 @@ func (r *OutboxRepository) SelectUnsentBatch(ctx context.Context, ext RepoExtens
 	var messages []model.OutboxMessage
 
 	const query = `
-        SELECT id, topic, payload, created_at, sent, sent_at
+        SELECT id, topic, payload, transport, created_at, sent, sent_at
         FROM messages.outbox_messages
-        WHERE sent = false
+        WHERE sent = false AND transport = 'kafka'
         ORDER BY created_at
         LIMIT $1;
     `
@@ -85,6 +86,7 @@ func (r *OutboxRepository) SelectUnsentBatch(ctx context.Context, ext RepoExtens
 			&message.ID,
 			&message.Topic,
 			&message.Payload,
+			&message.Transport,
 			&message.CreatedAt,
 			&message.Sent,
 			&message.SentAt,
@@ -97,3 +99,58 @@ func (r *OutboxRepository) SelectUnsentBatch(ctx context.Context, ext RepoExtens
 
 	return messages, nil
 }
+
+// ClaimUnsentBatch забирает до batchSize неотправленных задач http транспорта из topic, назначенных агенту agentID,
+// и сразу отмечает их отправленными. SKIP LOCKED не даёт двум одновременным опросам одного агента получить одну задачу.
+func (r *OutboxRepository) ClaimUnsentBatch(ctx context.Context, ext RepoExtension, topic string, agentID uuid.UUID, batchSize int) ([]model.OutboxMessage, error) {
+	if ext == nil {
+		ext = r.db
+	}
+
+	var messages []model.OutboxMessage
+
+	const query = `
+        UPDATE messages.outbox_messages
+        SET sent = true, sent_at = NOW()
+        WHERE id IN (
+            SELECT o.id
+            FROM messages.outbox_messages o
+            JOIN domain.assignments a ON a.outbox_id = o.id
+            WHERE o.sent = false AND o.transport = 'http' AND o.topic = $1 AND a.agent_id = $2
+            ORDER BY o.created_at
+            LIMIT $3
+            FOR UPDATE OF o SKIP LOCKED
+        )
+        RETURNING id, topic, payload, transport, created_at, sent, sent_at;
+    `
+
+	rows, err := ext.Query(ctx, query, topic, agentID.String(), batchSize)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var message model.OutboxMessage
+		if err := rows.Scan(
+			&message.ID,
+			&message.Topic,
+			&message.Payload,
+			&message.Transport,
+			&message.CreatedAt,
+			&message.Sent,
+			&message.SentAt,
+		); err != nil {
+			return nil, err
+		}
+
+		messages = append(messages, message)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return messages, nil
+}
@@ -2,10 +2,15 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 
+	"hackathon-back/internal/apperrors"
 	"hackathon-back/internal/model"
 )
 
@@ -39,8 +44,10 @@ func (r *RequestRepository) InsertRequest(ctx context.Context, ext RepoExtension
 		                             client_cc,
 		                             client_region,
 		                             checks_types,
-		                             request_json)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		                             request_json,
+		                             targets,
+		                             strategy)
+		VALUES ($1, $2, $3, $4, NULLIF($5, '')::inet, $6, $7, $8, $9, $10, $11, $12, $13)
 		RETURNING status, created_at, updated_at;
 	`
 
@@ -56,6 +63,8 @@ func (r *RequestRepository) InsertRequest(ctx context.Context, ext RepoExtension
 		request.ClientRegion,
 		request.ChecksTypes,
 		request.RequestJSON,
+		request.Targets,
+		request.Strategy,
 	).Scan(&request.Status, &request.CreatedAt, &request.UpdatedAt)
 	if err != nil {
 		return err
@@ -75,9 +84,10 @@ func (r *RequestRepository) InsertAssignment(ctx context.Context, ext RepoExtens
 		                                request_id,
 		                                agent_id,
 		                                agent_region,
+		                                checks_total,
 		                                outbox_id
 		)
-		VALUES ($1, $2, $3, $4, $5);
+		VALUES ($1, $2, $3, $4, $5, $6);
 	`
 
 	_, err := ext.Exec(ctx, query,
@@ -85,6 +95,7 @@ func (r *RequestRepository) InsertAssignment(ctx context.Context, ext RepoExtens
 		assignment.RequestID,
 		assignment.AgentID,
 		assignment.AgentRegion,
+		assignment.ChecksTotal,
 		assignment.OutboxID,
 	)
 	if err != nil {
@@ -94,7 +105,272 @@ func (r *RequestRepository) InsertAssignment(ctx context.Context, ext RepoExtens
 	return nil
 }
 
-func (r *RequestRepository) InsertCheckResult(ctx context.Context, ext RepoExtension, checkResult *model.CheckResult) error {
+// SelectAgentAssignment назначение запроса requestID агенту agentID: результат принимается только от агента,
+// которому назначена задача. assignmentID из результата агента сужает выбор до одной строки,
+// без него (агенты до версии 2.1) берётся первое назначение агенту в запросе.
+func (r *RequestRepository) SelectAgentAssignment(ctx context.Context, ext RepoExtension, assignmentID, requestID, agentID uuid.UUID) (*model.Assignment, error) {
+	if ext == nil {
+		ext = r.db
+	}
+
+	const query = `
+		SELECT id, request_id, agent_id, agent_region
+		FROM domain.assignments
+		WHERE request_id = $2
+		  AND ($1::uuid IS NULL OR id = $1)
+		  AND agent_id = $3
+		ORDER BY enqueued_at
+		LIMIT 1;
+	`
+
+	var id any
+	if assignmentID != uuid.Nil {
+		id = assignmentID
+	}
+
+	var assignment model.Assignment
+
+	if err := ext.QueryRow(ctx, query, id, requestID, agentID.String()).Scan(
+		&assignment.ID,
+		&assignment.RequestID,
+		&assignment.AgentID,
+		&assignment.AgentRegion,
+	); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apperrors.ErrAgentNotAssigned
+		}
+
+		return nil, err
+	}
+
+	return &assignment, nil
+}
+
+// assignmentTransitionSet отметки времени назначения ставятся по статусу, в который оно переходит.
+const assignmentTransitionSet = `
+		SET status        = $2::TEXT::domain.check_status,
+		    dispatched_at = CASE WHEN $2::TEXT = 'DISPATCHED' THEN COALESCE(dispatched_at, NOW()) ELSE dispatched_at END,
+		    started_at    = CASE WHEN $2::TEXT = 'RUNNING' THEN COALESCE(started_at, NOW()) ELSE started_at END,
+		    finished_at   = CASE WHEN $2::TEXT IN ('DONE', 'FAILED', 'TIMEOUT') THEN NOW() ELSE finished_at END,
+		    error_text    = COALESCE(NULLIF($4::TEXT, ''), error_text)
+`
+
+// UpdateAssignmentStatus переводит назначение в статус to, если сейчас оно в одном из from.
+// changed false — назначение уже в другом статусе, и переход не выполнен.
+func (r *RequestRepository) UpdateAssignmentStatus(ctx context.Context, ext RepoExtension, id uuid.UUID, to string, from []string, errorText string) (requestID uuid.UUID, changed bool, err error) {
+	if ext == nil {
+		ext = r.db
+	}
+
+	const query = `
+		UPDATE domain.assignments` + assignmentTransitionSet + `
+		WHERE id = $1 AND status::TEXT = ANY($3::TEXT[])
+		RETURNING request_id;
+	`
+
+	if err := ext.QueryRow(ctx, query, id, to, from, errorText).Scan(&requestID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return uuid.Nil, false, nil
+		}
+
+		return uuid.Nil, false, err
+	}
+
+	return requestID, true, nil
+}
+
+// UpdateAssignmentStatusByOutboxID то же, что UpdateAssignmentStatus, для назначения задачи из outbox.
+func (r *RequestRepository) UpdateAssignmentStatusByOutboxID(ctx context.Context, ext RepoExtension, outboxID uuid.UUID, to string, from []string) (requestID uuid.UUID, changed bool, err error) {
+	if ext == nil {
+		ext = r.db
+	}
+
+	const query = `
+		UPDATE domain.assignments` + assignmentTransitionSet + `
+		WHERE outbox_id = $1 AND status::TEXT = ANY($3::TEXT[])
+		RETURNING request_id;
+	`
+
+	if err := ext.QueryRow(ctx, query, outboxID, to, from, "").Scan(&requestID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return uuid.Nil, false, nil
+		}
+
+		return uuid.Nil, false, err
+	}
+
+	return requestID, true, nil
+}
+
+// CompleteAssignment завершает назначение, если по нему пришли итоговые результаты всех проверок:
+// DONE, если все успешные, иначе FAILED. Вызывается в транзакции, которая записала результат;
+// строка назначения блокируется до подсчёта, чтобы параллельный результат того же назначения
+// увидел этот и последний из них завершил назначение.
+func (r *RequestRepository) CompleteAssignment(ctx context.Context, ext RepoExtension, id uuid.UUID, from []string) (requestID uuid.UUID, status string, changed bool, err error) {
+	if ext == nil {
+		ext = r.db
+	}
+
+	const lockQuery = `
+		SELECT id FROM domain.assignments WHERE id = $1 FOR UPDATE;
+	`
+
+	const query = `
+		UPDATE domain.assignments a
+		SET status      = CASE WHEN c.failed > 0 THEN 'FAILED' ELSE 'DONE' END::domain.check_status,
+		    started_at  = COALESCE(a.started_at, c.started_at),
+		    finished_at = NOW()
+		FROM (
+			SELECT COUNT(DISTINCT check_index)               AS total,
+			       COUNT(*) FILTER (WHERE status = 'FAILED') AS failed,
+			       MIN(started_at)                           AS started_at
+			FROM domain.check_results
+			WHERE assignment_id = $1
+		) c
+		WHERE a.id = $1
+		  AND a.status::TEXT = ANY($2::TEXT[])
+		  AND c.total >= a.checks_total
+		RETURNING a.request_id, a.status;
+	`
+
+	if _, err := ext.Exec(ctx, lockQuery, id); err != nil {
+		return uuid.Nil, "", false, err
+	}
+
+	if err := ext.QueryRow(ctx, query, id, from).Scan(&requestID, &status); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return uuid.Nil, "", false, nil
+		}
+
+		return uuid.Nil, "", false, err
+	}
+
+	return requestID, status, true, nil
+}
+
+// UpdateOverdueAssignments переводит в TIMEOUT назначения из from, по которым не пришли результаты
+// за timeout_seconds запроса плюс grace. Отсчёт идёт от старта на агенте, а если его не было —
+// от выдачи задачи или создания назначения.
+func (r *RequestRepository) UpdateOverdueAssignments(ctx context.Context, ext RepoExtension, grace time.Duration, from []string) ([]*model.Assignment, error) {
+	if ext == nil {
+		ext = r.db
+	}
+
+	const query = `
+		UPDATE domain.assignments a
+		SET status      = 'TIMEOUT',
+		    finished_at = NOW(),
+		    error_text  = COALESCE(a.error_text, 'no results within timeout')
+		FROM domain.requests r
+		WHERE r.id = a.request_id
+		  AND a.status::TEXT = ANY($1::TEXT[])
+		  AND COALESCE(a.started_at, a.dispatched_at, a.enqueued_at)
+		      + (r.timeout_seconds + $2::FLOAT8) * INTERVAL '1 second' < NOW()
+		RETURNING a.id, a.request_id, a.agent_id, a.agent_region, a.status;
+	`
+
+	rows, err := ext.Query(ctx, query, from, grace.Seconds())
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var assignments []*model.Assignment
+
+	for rows.Next() {
+		var a model.Assignment
+
+		if err := rows.Scan(&a.ID, &a.RequestID, &a.AgentID, &a.AgentRegion, &a.Status); err != nil {
+			return nil, err
+		}
+
+		assignments = append(assignments, &a)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return assignments, nil
+}
+
+// UpdateRequestStatus пересчитывает статус запроса по его назначениям:
+// все DONE — DONE, все завершены — TIMEOUT или FAILED (по худшему),
+// хотя бы одно начато или завершено — RUNNING, хотя бы одно выдано — DISPATCHED, иначе PENDING.
+// Завершённый запрос не пересчитывается. Строка запроса блокируется до подсчёта, чтобы назначения,
+// завершившиеся одновременно, не оставили запрос в RUNNING; ext должен быть транзакцией.
+func (r *RequestRepository) UpdateRequestStatus(ctx context.Context, ext RepoExtension, requestID uuid.UUID) (status string, changed bool, err error) {
+	if ext == nil {
+		ext = r.db
+	}
+
+	const lockQuery = `
+		SELECT id FROM domain.requests WHERE id = $1 FOR UPDATE;
+	`
+
+	const query = `
+		UPDATE domain.requests r
+		SET status     = s.status,
+		    updated_at = NOW()
+		FROM (
+			SELECT CASE
+			           WHEN bool_and(status = 'DONE') THEN 'DONE'
+			           WHEN bool_and(status IN ('DONE', 'FAILED', 'TIMEOUT')) THEN
+			               CASE WHEN bool_or(status = 'TIMEOUT') THEN 'TIMEOUT' ELSE 'FAILED' END
+			           WHEN bool_or(status <> 'PENDING' AND status <> 'DISPATCHED') THEN 'RUNNING'
+			           WHEN bool_or(status = 'DISPATCHED') THEN 'DISPATCHED'
+			           ELSE 'PENDING'
+			       END::domain.check_status AS status
+			FROM domain.assignments
+			WHERE request_id = $1
+		) s
+		WHERE r.id = $1
+		  AND r.status NOT IN ('DONE', 'FAILED', 'TIMEOUT')
+		  AND r.status <> s.status
+		RETURNING r.status;
+	`
+
+	if _, err := ext.Exec(ctx, lockQuery, requestID); err != nil {
+		return "", false, err
+	}
+
+	if err := ext.QueryRow(ctx, query, requestID).Scan(&status); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", false, nil
+		}
+
+		return "", false, err
+	}
+
+	return status, true, nil
+}
+
+func (r *RequestRepository) SelectRequestStatus(ctx context.Context, ext RepoExtension, requestID uuid.UUID) (string, error) {
+	if ext == nil {
+		ext = r.db
+	}
+
+	const query = `
+		SELECT status FROM domain.requests WHERE id = $1;
+	`
+
+	var status string
+
+	if err := ext.QueryRow(ctx, query, requestID).Scan(&status); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", apperrors.ErrRequestDoesNotExist
+		}
+
+		return "", err
+	}
+
+	return status, nil
+}
+
+// InsertCheckResult сохраняет итоговый результат проверки.
+// inserted false — результат этой проверки назначения уже сохранён (повторная доставка), запись не добавлена.
+func (r *RequestRepository) InsertCheckResult(ctx context.Context, ext RepoExtension, checkResult *model.CheckResult) (inserted bool, err error) {
 	if ext == nil {
 		ext = r.db
 	}
@@ -103,18 +379,23 @@ func (r *RequestRepository) InsertCheckResult(ctx context.Context, ext RepoExten
 		INSERT INTO domain.check_results (
 		                                  id,
 		                                  assignment_id,
+		                                  agent_id,
+		                                  check_index,
 		                                  type,
 		                                  status,
 		                                  started_at,
 		                                  finished_at,
 		                                  payload
 		)
-		VALUES ($1, $2, $3, $4, $5, $6, $7);
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (assignment_id, check_index) DO NOTHING;
 	`
 
-	_, err := ext.Exec(ctx, query,
+	tag, err := ext.Exec(ctx, query,
 		checkResult.ID,
 		checkResult.AssignmentId,
+		checkResult.AgentID.String(),
+		checkResult.CheckIndex,
 		checkResult.Type,
 		checkResult.Status,
 		checkResult.StartedAt,
@@ -122,10 +403,10 @@ func (r *RequestRepository) InsertCheckResult(ctx context.Context, ext RepoExten
 		checkResult.Payload,
 	)
 	if err != nil {
-		return err
+		return false, err
 	}
 
-	return nil
+	return tag.RowsAffected() > 0, nil
 }
 
 func (r *RequestRepository) SelectResultsByRequestID(ctx context.Context, ext RepoExtension, requestID uuid.UUID) ([]model.CheckResultResponse, error) {
@@ -136,11 +417,12 @@ func (r *RequestRepository) SelectResultsByRequestID(ctx context.Context, ext Re
 	var result []model.CheckResultResponse
 
 	const query = `
-		SELECT a.request_id, a.agent_id, a.agent_region, c.type, c.status, c.started_at, c.finished_at, c.payload
-		FROM domain.assignments a
-		JOIN domain.check_results c ON a.request_id = c.assignment_id
+		SELECT a.request_id, a.id, COALESCE(c.agent_id, a.agent_id) AS agent_id, a.agent_region,
+		       c.check_index, c.type, c.status, c.started_at, c.finished_at, c.payload
+		FROM domain.check_results c
+		JOIN domain.assignments a ON a.id = c.assignment_id
 		WHERE a.request_id = $1
-		ORDER BY a.agent_id;
+		ORDER BY a.agent_region, agent_id, c.check_index;
 	`
 
 	rows, err := ext.Query(ctx, query, requestID)
@@ -155,8 +437,10 @@ func (r *RequestRepository) SelectResultsByRequestID(ctx context.Context, ext Re
 
 		if err := rows.Scan(
 			&r.RequestID,
+			&r.AssignmentID,
 			&r.AgentID,
 			&r.AgentRegion,
+			&r.CheckIndex,
 			&r.Type,
 			&r.Status,
 			&r.StartedAt,
@@ -171,3 +455,140 @@ func (r *RequestRepository) SelectResultsByRequestID(ctx context.Context, ext Re
 
 	return result, nil
 }
+
+// UpsertCheckProgress сохраняет промежуточное состояние проверки, если оно новее уже сохранённого
+// и итоговый результат проверки ещё не пришёл (см. FinishCheckProgress).
+// updated false — сохранённое состояние новее или проверка завершена, и запись не изменилась.
+func (r *RequestRepository) UpsertCheckProgress(ctx context.Context, ext RepoExtension, progress *model.CheckProgress) (updated bool, err error) {
+	if ext == nil {
+		ext = r.db
+	}
+
+	const query = `
+		INSERT INTO domain.check_progress (
+		                                   assignment_id,
+		                                   agent_id,
+		                                   check_index,
+		                                   type,
+		                                   seq,
+		                                   started_at,
+		                                   payload
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (assignment_id, check_index) DO UPDATE
+		SET seq = EXCLUDED.seq,
+		    payload = EXCLUDED.payload,
+		    updated_at = NOW()
+		WHERE NOT domain.check_progress.final AND domain.check_progress.seq < EXCLUDED.seq;
+	`
+
+	tag, err := ext.Exec(ctx, query,
+		progress.AssignmentID,
+		progress.AgentID.String(),
+		progress.CheckIndex,
+		progress.Type,
+		progress.Seq,
+		progress.StartedAt,
+		progress.Payload,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+// SelectCheckProgressPayload последнее сохранённое промежуточное состояние незавершённой проверки.
+func (r *RequestRepository) SelectCheckProgressPayload(ctx context.Context, ext RepoExtension, assignmentID uuid.UUID, checkIndex int) (json.RawMessage, error) {
+	if ext == nil {
+		ext = r.db
+	}
+
+	const query = `
+		SELECT payload
+		FROM domain.check_progress
+		WHERE assignment_id = $1 AND check_index = $2 AND NOT final;
+	`
+
+	var payload json.RawMessage
+
+	if err := ext.QueryRow(ctx, query, assignmentID, checkIndex).Scan(&payload); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apperrors.ErrCheckProgressDoesNotExist
+		}
+
+		return nil, err
+	}
+
+	return payload, nil
+}
+
+// FinishCheckProgress помечает промежуточное состояние проверки итоговым вместо удаления: строка остаётся
+// и конфликтом по первичному ключу не даёт запоздавшему промежуточному сообщению записать прогресс заново.
+func (r *RequestRepository) FinishCheckProgress(ctx context.Context, ext RepoExtension, assignmentID uuid.UUID, checkIndex int, checkType string) error {
+	if ext == nil {
+		ext = r.db
+	}
+
+	const query = `
+		INSERT INTO domain.check_progress (assignment_id, check_index, type, seq, payload, final)
+		VALUES ($1, $2, $3, 0, '{}', TRUE)
+		ON CONFLICT (assignment_id, check_index) DO UPDATE
+		SET final = TRUE,
+		    payload = '{}',
+		    updated_at = NOW();
+	`
+
+	_, err := ext.Exec(ctx, query, assignmentID, checkIndex, checkType)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *RequestRepository) SelectProgressByRequestID(ctx context.Context, ext RepoExtension, requestID uuid.UUID) ([]model.CheckProgress, error) {
+	if ext == nil {
+		ext = r.db
+	}
+
+	var result []model.CheckProgress
+
+	const query = `
+		SELECT p.assignment_id, COALESCE(p.agent_id, a.agent_id), a.agent_region,
+		       p.check_index, p.type, p.seq, p.started_at, p.updated_at, p.payload
+		FROM domain.check_progress p
+		JOIN domain.assignments a ON a.id = p.assignment_id
+		WHERE a.request_id = $1 AND NOT p.final
+		ORDER BY a.agent_region, p.check_index;
+	`
+
+	rows, err := ext.Query(ctx, query, requestID)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var p model.CheckProgress
+
+		if err := rows.Scan(
+			&p.AssignmentID,
+			&p.AgentID,
+			&p.AgentRegion,
+			&p.CheckIndex,
+			&p.Type,
+			&p.Seq,
+			&p.StartedAt,
+			&p.UpdatedAt,
+			&p.Payload,
+		); err != nil {
+			return nil, err
+		}
+
+		result = append(result, p)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,189 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"hackathon-contract"
+
+	"hackathon-back/internal/apperrors"
+	"hackathon-back/internal/model"
+)
+
+// FleetEvents рассылает смену присутствия агентов в топик model.TopicFleet, см. stream.Hub.
+type FleetEvents interface {
+	Publish(ctx context.Context, event model.StreamEvent) error
+}
+
+// AgentService ведёт присутствие агентов: принимает heartbeat и переводит в offline тех, кто перестал их слать.
+type AgentService struct {
+	log           *zap.Logger
+	agentRepo     AgentRepository
+	events        FleetEvents
+	offlineAfter  time.Duration
+	sweepInterval time.Duration
+}
+
+func NewAgentService(log *zap.Logger, agentRepo AgentRepository, events FleetEvents, offlineAfter, sweepInterval time.Duration) *AgentService {
+	return &AgentService{
+		log:           log,
+		agentRepo:     agentRepo,
+		events:        events,
+		offlineAfter:  offlineAfter,
+		sweepInterval: sweepInterval,
+	}
+}
+
+func (s *AgentService) Heartbeat(ctx context.Context, hb contract.Heartbeat) error {
+	if err := contract.CheckVersion(hb.Version); err != nil {
+		return fmt.Errorf("failed to accept heartbeat: %w", err)
+	}
+
+	if hb.AgentID == uuid.Nil {
+		return fmt.Errorf("failed to accept heartbeat: agentId is required")
+	}
+
+	wasOnline, err := s.agentRepo.UpdateHeartbeat(ctx, nil, &model.AgentHeartbeat{
+		AgentID:       hb.AgentID,
+		Version:       hb.AgentVersion,
+		UptimeSeconds: hb.UptimeSeconds,
+		InFlight:      hb.InFlight,
+		QueueDepth:    hb.QueueDepth,
+		Capabilities:  hb.Capabilities,
+		Transport:     agentTransport(hb.Transport),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update heartbeat: %w", err)
+	}
+
+	if !wasOnline {
+		agent, err := s.agentRepo.SelectAgentByID(ctx, nil, hb.AgentID)
+		if err != nil {
+			return fmt.Errorf("failed to select agent: %w", err)
+		}
+
+		// выключенный агент остаётся offline: heartbeat только обновляет его состояние
+		if agent.Disabled {
+			return fmt.Errorf("failed to accept heartbeat from %s: %w", hb.AgentID, apperrors.ErrAgentDisabled)
+		}
+
+		s.log.Info("Agent is online",
+			zap.String("agent_id", hb.AgentID.String()),
+			zap.String("region", agent.Region),
+			zap.String("version", hb.AgentVersion),
+		)
+
+		s.publish(ctx, model.StreamEventAgentOnline, agent)
+	}
+
+	return nil
+}
+
+// RunSweeper раз в sweepInterval переводит в offline агентов, пропустивших heartbeat дольше offlineAfter.
+func (s *AgentService) RunSweeper(ctx context.Context) {
+	ticker := time.NewTicker(s.sweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.log.Info("Context canceled, stopping agent sweeper")
+
+			return
+		case <-ticker.C:
+			if err := s.sweep(ctx); err != nil {
+				s.log.Error("Failed to sweep agents", zap.Error(err))
+			}
+		}
+	}
+}
+
+func (s *AgentService) sweep(ctx context.Context) error {
+	agents, err := s.agentRepo.UpdateStaleAsOffline(ctx, nil, s.offlineAfter)
+	if err != nil {
+		return fmt.Errorf("failed to update stale agents: %w", err)
+	}
+
+	for _, agent := range agents {
+		s.log.Warn("Agent is offline, heartbeat missed",
+			zap.String("agent_id", agent.ID.String()),
+			zap.String("region", agent.Region),
+			zap.Timep("last_seen", agent.LastSeen),
+		)
+
+		s.publish(ctx, model.StreamEventAgentOffline, agent)
+	}
+
+	return nil
+}
+
+// publish рассылка не критична: подписчик fleet, пропустивший событие, получит состояние парка снимком.
+func (s *AgentService) publish(ctx context.Context, eventType string, agent *model.Agent) {
+	if err := s.events.Publish(ctx, model.StreamEvent{
+		Topic: model.TopicFleet,
+		Type:  eventType,
+		Agent: agent,
+	}); err != nil {
+		s.log.Warn("Failed to publish fleet event",
+			zap.String("agent_id", agent.ID.String()),
+			zap.String("type", eventType),
+			zap.Error(err),
+		)
+	}
+}
+
+func (s *AgentService) GetFleet(ctx context.Context) (*model.FleetResponse, error) {
+	agents, err := s.agentRepo.SelectAgents(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select agents: %w", err)
+	}
+
+	fleet := &model.FleetResponse{
+		Total:  len(agents),
+		Agents: agents,
+	}
+
+	for _, agent := range agents {
+		if agent.Online {
+			fleet.Online++
+		}
+	}
+
+	fleet.Offline = fleet.Total - fleet.Online
+
+	if fleet.Agents == nil {
+		fleet.Agents = []*model.Agent{}
+	}
+
+	return fleet, nil
+}
+
+func (s *AgentService) GetAgent(ctx context.Context, id uuid.UUID) (*model.Agent, error) {
+	agent, err := s.agentRepo.SelectAgentByID(ctx, nil, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select agent: %w", err)
+	}
+
+	return agent, nil
+}
+
+// agentLocation координаты агента, вне диапазона — как не заданные.
+func agentLocation(loc *contract.Location) *contract.Location {
+	if loc == nil || loc.Lat < -90 || loc.Lat > 90 || loc.Lon < -180 || loc.Lon > 180 {
+		return nil
+	}
+
+	return loc
+}
+
+// agentTransport транспорт из heartbeat: агенты до версии 1.5 его не присылают и работают через Kafka.
+func agentTransport(transport string) string {
+	if transport == contract.TransportHTTP {
+		return contract.TransportHTTP
+	}
+
+	return contract.TransportKafka
+}
@@ -0,0 +1,135 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"hackathon-contract"
+
+	"hackathon-back/internal/model"
+)
+
+// dispatch проверки, которые уходят одному агенту.
+type dispatch struct {
+	agent  *model.Agent
+	checks []contract.CheckRequest
+}
+
+// planDispatch распределяет проверки по online агентам с учётом их возможностей, агенты — в порядке Router.Rank.
+// broadcast: каждый агент получает все проверки, которые может выполнить.
+// Иначе берётся первый агент, способный выполнить всё, а если такого нет — проверки делятся между агентами.
+// Проверки, которые не может выполнить никто, возвращаются вторым значением с причиной.
+func planDispatch(target string, checks []contract.CheckRequest, candidates []*model.Agent, broadcast bool) ([]dispatch, []model.UnassignedCheck) {
+	if broadcast {
+		return planBroadcast(target, checks, candidates)
+	}
+
+	for _, agent := range candidates {
+		if canRunAll(agent, target, checks) {
+			return []dispatch{{agent: agent, checks: checks}}, nil
+		}
+	}
+
+	var (
+		dispatches []dispatch
+		unassigned []model.UnassignedCheck
+		byAgent    = make(map[*model.Agent]int)
+	)
+
+	for i, check := range checks {
+		agent, reason := firstCapable(candidates, target, check)
+		if agent == nil {
+			unassigned = append(unassigned, newUnassigned(i, check, reason))
+
+			continue
+		}
+
+		idx, ok := byAgent[agent]
+		if !ok {
+			idx = len(dispatches)
+			byAgent[agent] = idx
+			dispatches = append(dispatches, dispatch{agent: agent})
+		}
+
+		dispatches[idx].checks = append(dispatches[idx].checks, check)
+	}
+
+	return dispatches, unassigned
+}
+
+func planBroadcast(target string, checks []contract.CheckRequest, agents []*model.Agent) ([]dispatch, []model.UnassignedCheck) {
+	var dispatches []dispatch
+
+	runnable := make([]bool, len(checks))
+
+	for _, agent := range agents {
+		d := dispatch{agent: agent}
+
+		for i, check := range checks {
+			if agent.CanRun(target, check) == nil {
+				d.checks = append(d.checks, check)
+				runnable[i] = true
+			}
+		}
+
+		if len(d.checks) > 0 {
+			dispatches = append(dispatches, d)
+		}
+	}
+
+	var unassigned []model.UnassignedCheck
+
+	for i, check := range checks {
+		if !runnable[i] {
+			_, reason := firstCapable(agents, target, check)
+			unassigned = append(unassigned, newUnassigned(i, check, reason))
+		}
+	}
+
+	return dispatches, unassigned
+}
+
+func canRunAll(agent *model.Agent, target string, checks []contract.CheckRequest) bool {
+	for _, check := range checks {
+		if agent.CanRun(target, check) != nil {
+			return false
+		}
+	}
+
+	return true
+}
+
+// firstCapable первый агент, который может выполнить проверку, либо причина отказа первого кандидата.
+func firstCapable(agents []*model.Agent, target string, check contract.CheckRequest) (*model.Agent, error) {
+	var firstErr error
+
+	for _, agent := range agents {
+		err := agent.CanRun(target, check)
+		if err == nil {
+			return agent, nil
+		}
+
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return nil, firstErr
+}
+
+func newUnassigned(pos int, check contract.CheckRequest, reason error) model.UnassignedCheck {
+	return model.UnassignedCheck{
+		Index:  check.CheckIndex(pos),
+		Type:   check.Type,
+		Reason: reason.Error(),
+	}
+}
+
+func describeUnassigned(unassigned []model.UnassignedCheck) string {
+	parts := make([]string, 0, len(unassigned))
+	for _, u := range unassigned {
+		parts = append(parts, fmt.Sprintf("checks[%d] (%s): %s", u.Index, u.Type, u.Reason))
+	}
+
+	return strings.Join(parts, "; ")
+}
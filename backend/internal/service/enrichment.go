@@ -0,0 +1,180 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/netip"
+	"sync"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"hackathon-contract"
+
+	"hackathon-back/internal/apperrors"
+	"hackathon-back/internal/repository"
+)
+
+// cgnatPrefix Carrier-grade NAT 100.64.0.0/10, netip не считает его приватным.
+var cgnatPrefix = netip.MustParsePrefix("100.64.0.0/10")
+
+type PTRResolver interface {
+	Lookup(ctx context.Context, ip string) string
+}
+
+type ProgressRepository interface {
+	SelectCheckProgressPayload(ctx context.Context, ext repository.RepoExtension, assignmentID uuid.UUID, checkIndex int) (json.RawMessage, error)
+}
+
+// EnrichmentService дополняет результаты агентов данными, которые агент сам не собирает:
+// хопы traceroute получают координаты, город, ASN и PTR, адрес ping — ASN для стратегии latency.
+type EnrichmentService struct {
+	log          *zap.Logger
+	progressRepo ProgressRepository
+	geo          GeoIPDB
+	ptr          PTRResolver
+}
+
+func NewEnrichmentService(log *zap.Logger, progressRepo ProgressRepository, geo GeoIPDB, ptr PTRResolver) *EnrichmentService {
+	return &EnrichmentService{
+		log:          log,
+		progressRepo: progressRepo,
+		geo:          geo,
+		ptr:          ptr,
+	}
+}
+
+// EnrichResult дополняет результат проверки назначения assignmentID, промежуточный или итоговый.
+func (s *EnrichmentService) EnrichResult(ctx context.Context, assignmentID uuid.UUID, res *contract.CheckResult) error {
+	switch res.Type {
+	case contract.CheckTraceroute:
+		return s.enrichTraceroute(ctx, assignmentID, res)
+	case contract.CheckPing:
+		return s.enrichPing(res)
+	default:
+		return nil
+	}
+}
+
+// enrichTraceroute каждое сообщение traceroute несёт все найденные хопы, поэтому хопы, уже обогащённые
+// в последнем сохранённом промежуточном состоянии, переносятся из него, а резолвятся только новые.
+func (s *EnrichmentService) enrichTraceroute(ctx context.Context, assignmentID uuid.UUID, res *contract.CheckResult) error {
+	var payload contract.TraceroutePayload
+	if err := res.DecodePayload(&payload); err != nil {
+		return fmt.Errorf("failed to decode traceroute payload: %w", err)
+	}
+
+	if len(payload.Hops) == 0 {
+		return nil
+	}
+
+	known := s.enrichedHops(ctx, assignmentID, res.CheckIndex)
+
+	// PTR ходит в сеть, поэтому хопы резолвятся параллельно
+	var wg sync.WaitGroup
+
+	for i := range payload.Hops {
+		if i < len(known) && known[i].IP == payload.Hops[i].IP {
+			payload.Hops[i] = known[i]
+
+			continue
+		}
+
+		addr, err := netip.ParseAddr(payload.Hops[i].IP)
+		if err != nil || !isPublicAddr(addr) {
+			continue
+		}
+
+		wg.Add(1)
+
+		go func(hop *contract.Hop) {
+			defer wg.Done()
+
+			s.enrichHop(ctx, hop, addr)
+		}(&payload.Hops[i])
+	}
+
+	wg.Wait()
+
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal traceroute payload: %w", err)
+	}
+
+	res.Payload = raw
+
+	return nil
+}
+
+// enrichedHops хопы последнего сохранённого промежуточного состояния traceroute. Без него все хопы резолвятся заново.
+func (s *EnrichmentService) enrichedHops(ctx context.Context, assignmentID uuid.UUID, checkIndex int) []contract.Hop {
+	raw, err := s.progressRepo.SelectCheckProgressPayload(ctx, nil, assignmentID, checkIndex)
+	if err != nil {
+		if !errors.Is(err, apperrors.ErrCheckProgressDoesNotExist) {
+			s.log.Warn("Failed to select check progress, resolving all hops",
+				zap.String("assignment_id", assignmentID.String()),
+				zap.Error(err),
+			)
+		}
+
+		return nil
+	}
+
+	var payload contract.TraceroutePayload
+	if err := json.Unmarshal(raw, &payload); err != nil {
+		return nil
+	}
+
+	return payload.Hops
+}
+
+func (s *EnrichmentService) enrichPing(res *contract.CheckResult) error {
+	var payload contract.PingPayload
+	if err := res.DecodePayload(&payload); err != nil {
+		return fmt.Errorf("failed to decode ping payload: %w", err)
+	}
+
+	addr, err := netip.ParseAddr(payload.IP)
+	if err != nil || !isPublicAddr(addr) {
+		return nil
+	}
+
+	payload.ASN = s.geo.Lookup(addr.AsSlice()).ASN
+	if payload.ASN == 0 {
+		return nil
+	}
+
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal ping payload: %w", err)
+	}
+
+	res.Payload = raw
+
+	return nil
+}
+
+func (s *EnrichmentService) enrichHop(ctx context.Context, hop *contract.Hop, addr netip.Addr) {
+	gi := s.geo.Lookup(addr.AsSlice())
+
+	hop.Country = gi.CC
+	hop.City = gi.City
+	hop.ASN = gi.ASN
+	hop.ASOrg = gi.ASOrg
+
+	if gi.HasLocation {
+		lat, lon := gi.Lat, gi.Lon
+		hop.Lat, hop.Lon = &lat, &lon
+	}
+
+	hop.Hostname = s.ptr.Lookup(ctx, addr.String())
+}
+
+func isPublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+
+	return addr.IsGlobalUnicast() &&
+		!addr.IsPrivate() &&
+		!cgnatPrefix.Contains(addr)
+}
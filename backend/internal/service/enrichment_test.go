@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"hackathon-contract"
+
+	"hackathon-back/internal/apperrors"
+	"hackathon-back/internal/repository"
+	"hackathon-back/pkg/geoip"
+)
+
+type fakeGeo struct {
+	mu      sync.Mutex
+	lookups []string
+}
+
+func (g *fakeGeo) Lookup(ip net.IP) geoip.GeoInfo {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.lookups = append(g.lookups, ip.String())
+
+	return geoip.GeoInfo{ASN: 3320, CC: "DE", City: "Frankfurt"}
+}
+
+type fakePTR struct{}
+
+func (fakePTR) Lookup(_ context.Context, ip string) string {
+	return "host-" + ip
+}
+
+type fakeProgressRepository struct {
+	payload json.RawMessage
+}
+
+func (r fakeProgressRepository) SelectCheckProgressPayload(_ context.Context, _ repository.RepoExtension, _ uuid.UUID, _ int) (json.RawMessage, error) {
+	if r.payload == nil {
+		return nil, apperrors.ErrCheckProgressDoesNotExist
+	}
+
+	return r.payload, nil
+}
+
+func tracerouteResult(t *testing.T, hops ...contract.Hop) *contract.CheckResult {
+	t.Helper()
+
+	raw, err := json.Marshal(contract.TraceroutePayload{Hops: hops})
+	if err != nil {
+		t.Fatalf("marshal payload: %v", err)
+	}
+
+	return &contract.CheckResult{Type: contract.CheckTraceroute, Payload: raw}
+}
+
+func TestEnrichTracerouteResolvesOnlyNewHops(t *testing.T) {
+	stored, err := json.Marshal(contract.TraceroutePayload{Hops: []contract.Hop{
+		{IP: "192.168.0.1"},
+		{IP: "1.1.1.1", ASN: 13335, City: "Stored", Hostname: "stored.example"},
+	}})
+	if err != nil {
+		t.Fatalf("marshal payload: %v", err)
+	}
+
+	geo := &fakeGeo{}
+	svc := NewEnrichmentService(zap.NewNop(), fakeProgressRepository{payload: stored}, geo, fakePTR{})
+
+	res := tracerouteResult(t, contract.Hop{IP: "192.168.0.1"}, contract.Hop{IP: "1.1.1.1"}, contract.Hop{IP: "8.8.8.8"}, contract.Hop{IP: "*"})
+
+	if err := svc.EnrichResult(context.Background(), uuid.New(), res); err != nil {
+		t.Fatalf("EnrichResult: %v", err)
+	}
+
+	var payload contract.TraceroutePayload
+	if err := res.DecodePayload(&payload); err != nil {
+		t.Fatalf("decode payload: %v", err)
+	}
+
+	if len(geo.lookups) != 1 || geo.lookups[0] != "8.8.8.8" {
+		t.Fatalf("geo lookups %v, want only the new hop", geo.lookups)
+	}
+
+	if hop := payload.Hops[1]; hop.City != "Stored" || hop.Hostname != "stored.example" {
+		t.Fatalf("known hop %+v was not taken from stored progress", hop)
+	}
+
+	if hop := payload.Hops[2]; hop.City != "Frankfurt" || hop.ASN != 3320 || hop.Hostname != "host-8.8.8.8" {
+		t.Fatalf("new hop %+v was not enriched", hop)
+	}
+
+	if hop := payload.Hops[0]; hop.Hostname != "" || hop.ASN != 0 {
+		t.Fatalf("private hop %+v was enriched", hop)
+	}
+}
+
+func TestEnrichTracerouteResolvesChangedHops(t *testing.T) {
+	stored, err := json.Marshal(contract.TraceroutePayload{Hops: []contract.Hop{
+		{IP: "100.64.0.1"},
+		{IP: "1.1.1.1", City: "Stored"},
+	}})
+	if err != nil {
+		t.Fatalf("marshal payload: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		repo fakeProgressRepository
+	}{
+		{name: "route changed", repo: fakeProgressRepository{payload: stored}},
+		{name: "no stored progress", repo: fakeProgressRepository{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			geo := &fakeGeo{}
+			svc := NewEnrichmentService(zap.NewNop(), tt.repo, geo, fakePTR{})
+
+			res := tracerouteResult(t, contract.Hop{IP: "100.64.0.1"}, contract.Hop{IP: "9.9.9.9"})
+
+			if err := svc.EnrichResult(context.Background(), uuid.New(), res); err != nil {
+				t.Fatalf("EnrichResult: %v", err)
+			}
+
+			if len(geo.lookups) != 1 || geo.lookups[0] != "9.9.9.9" {
+				t.Fatalf("geo lookups %v, want 9.9.9.9 only", geo.lookups)
+			}
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"hackathon-contract"
+
+	"hackathon-back/internal/apperrors"
+	"hackathon-back/internal/model"
+	"hackathon-back/internal/repository"
+)
+
+type AgentKeyRepository interface {
+	SelectAgentByID(ctx context.Context, ext repository.RepoExtension, id uuid.UUID) (*model.Agent, error)
+}
+
+type AssignmentRepository interface {
+	SelectAgentAssignment(ctx context.Context, ext repository.RepoExtension, assignmentID, requestID, agentID uuid.UUID) (*model.Assignment, error)
+}
+
+// EnvelopeService подписывает задачи ключом бэкенда и проверяет конверты агентов.
+// Ключ агента привязывается только при регистрации по токену, не из самих сообщений: heartbeat и результаты
+// принимаются только от агентов с привязанным ключом, результаты — только по задачам, назначенным самому агенту.
+type EnvelopeService struct {
+	key            ed25519.PrivateKey
+	taskTTL        time.Duration
+	agentRepo      AgentKeyRepository
+	assignmentRepo AssignmentRepository
+	now            func() time.Time
+}
+
+func NewEnvelopeService(key ed25519.PrivateKey, taskTTL time.Duration, agentRepo AgentKeyRepository, assignmentRepo AssignmentRepository) *EnvelopeService {
+	return &EnvelopeService{
+		key:            key,
+		taskTTL:        taskTTL,
+		agentRepo:      agentRepo,
+		assignmentRepo: assignmentRepo,
+		now:            time.Now,
+	}
+}
+
+// SealTask подписывает задачу. Срок действия — taskTTL на доставку плюс таймаут самой задачи:
+// задача, пролежавшая в очереди дольше, агентом не выполняется.
+func (s *EnvelopeService) SealTask(payload []byte, timeout time.Duration) ([]byte, error) {
+	env, err := contract.Seal(s.key, contract.BackendSigner, payload, s.now(), s.taskTTL+timeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to seal task: %w", err)
+	}
+
+	b, err := json.Marshal(env)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal envelope: %w", err)
+	}
+
+	return b, nil
+}
+
+// OpenHeartbeat проверяет подпись heartbeat ключом, привязанным к агенту при регистрации.
+// Heartbeat неизвестного агента или агента без ключа отклоняется с apperrors.ErrAgentKeyNotRegistered.
+func (s *EnvelopeService) OpenHeartbeat(ctx context.Context, data []byte) (contract.Heartbeat, error) {
+	var hb contract.Heartbeat
+
+	env, err := contract.OpenEnvelope(data)
+	if err != nil {
+		return hb, fmt.Errorf("%w: %w", apperrors.ErrInvalidAgentMessage, err)
+	}
+
+	if err := json.Unmarshal(env.Payload, &hb); err != nil {
+		return hb, fmt.Errorf("%w: %w", apperrors.ErrInvalidAgentMessage, err)
+	}
+
+	agent, err := s.selectAgentWithKey(ctx, hb.AgentID)
+	if err != nil {
+		return hb, err
+	}
+
+	if err := env.Verify(agent.PublicKey, hb.AgentID.String(), s.now()); err != nil {
+		return hb, fmt.Errorf("%w: %w", apperrors.ErrInvalidAgentSignature, err)
+	}
+
+	return hb, nil
+}
+
+// OpenResult проверяет подпись результата ключом агента-подписанта и что задача была назначена именно ему.
+// Возвращает назначение, к которому относится результат.
+func (s *EnvelopeService) OpenResult(ctx context.Context, data []byte) (contract.CheckResult, *model.Assignment, error) {
+	var res contract.CheckResult
+
+	env, err := contract.OpenEnvelope(data)
+	if err != nil {
+		return res, nil, fmt.Errorf("%w: %w", apperrors.ErrInvalidAgentMessage, err)
+	}
+
+	agentID, err := uuid.Parse(env.Signer)
+	if err != nil {
+		return res, nil, fmt.Errorf("%w: signer is not an agent id", apperrors.ErrInvalidAgentSignature)
+	}
+
+	agent, err := s.selectAgentWithKey(ctx, agentID)
+	if err != nil {
+		return res, nil, err
+	}
+
+	if err := env.Verify(agent.PublicKey, env.Signer, s.now()); err != nil {
+		return res, nil, fmt.Errorf("%w: %w", apperrors.ErrInvalidAgentSignature, err)
+	}
+
+	if err := json.Unmarshal(env.Payload, &res); err != nil {
+		return res, nil, fmt.Errorf("%w: %w", apperrors.ErrInvalidAgentMessage, err)
+	}
+
+	if err := contract.CheckVersion(res.Version); err != nil {
+		return res, nil, fmt.Errorf("%w: %w", apperrors.ErrInvalidAgentMessage, err)
+	}
+
+	if res.AgentID != agentID {
+		return res, nil, fmt.Errorf("%w: result agentId %s signed by %s", apperrors.ErrAgentIdentityMismatch, res.AgentID, agentID)
+	}
+
+	assignment, err := s.assignmentRepo.SelectAgentAssignment(ctx, nil, res.AssignmentID, res.TaskID, agentID)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrAgentNotAssigned) {
+			return res, nil, fmt.Errorf("%w: task %s, agent %s", apperrors.ErrAgentNotAssigned, res.TaskID, agentID)
+		}
+
+		return res, nil, fmt.Errorf("failed to select assignment: %w", err)
+	}
+
+	return res, assignment, nil
+}
+
+// selectAgentWithKey агент с привязанным ключом; неизвестный агент и агент без ключа — apperrors.ErrAgentKeyNotRegistered.
+func (s *EnvelopeService) selectAgentWithKey(ctx context.Context, agentID uuid.UUID) (*model.Agent, error) {
+	agent, err := s.agentRepo.SelectAgentByID(ctx, nil, agentID)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrAgentDoesNotExist) {
+			return nil, fmt.Errorf("%w: agent %s", apperrors.ErrAgentKeyNotRegistered, agentID)
+		}
+
+		return nil, fmt.Errorf("failed to select agent: %w", err)
+	}
+
+	if len(agent.PublicKey) == 0 {
+		return nil, fmt.Errorf("%w: agent %s", apperrors.ErrAgentKeyNotRegistered, agentID)
+	}
+
+	return agent, nil
+}
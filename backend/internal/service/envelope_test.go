@@ -0,0 +1,273 @@
+package service
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"hackathon-contract"
+
+	"hackathon-back/internal/apperrors"
+	"hackathon-back/internal/model"
+	"hackathon-back/internal/repository"
+)
+
+type fakeAgentKeys map[uuid.UUID]ed25519.PublicKey
+
+func (f fakeAgentKeys) SelectAgentByID(_ context.Context, _ repository.RepoExtension, id uuid.UUID) (*model.Agent, error) {
+	pub, ok := f[id]
+	if !ok {
+		return nil, apperrors.ErrAgentDoesNotExist
+	}
+
+	return &model.Agent{ID: id, PublicKey: pub}, nil
+}
+
+// fakeAssignments назначения по id, назначены агенту agentID.
+type fakeAssignments struct {
+	agentID uuid.UUID
+	ids     map[uuid.UUID]struct{}
+}
+
+func (f fakeAssignments) SelectAgentAssignment(_ context.Context, _ repository.RepoExtension, assignmentID, requestID, agentID uuid.UUID) (*model.Assignment, error) {
+	if _, ok := f.ids[assignmentID]; !ok || agentID != f.agentID {
+		return nil, apperrors.ErrAgentNotAssigned
+	}
+
+	return &model.Assignment{ID: assignmentID, RequestID: requestID, AgentID: agentID}, nil
+}
+
+func testKeyPair(t *testing.T) (ed25519.PublicKey, ed25519.PrivateKey) {
+	t.Helper()
+
+	pub, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+
+	return pub, key
+}
+
+func sealAgentMessage(t *testing.T, key ed25519.PrivateKey, signer string, msg any) []byte {
+	t.Helper()
+
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		t.Fatalf("marshal message: %v", err)
+	}
+
+	env, err := contract.Seal(key, signer, payload, time.Now(), 0)
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+
+	b, err := json.Marshal(env)
+	if err != nil {
+		t.Fatalf("marshal envelope: %v", err)
+	}
+
+	return b
+}
+
+func TestEnvelopeServiceOpenResult(t *testing.T) {
+	agentPub, agentKey := testKeyPair(t)
+	otherPub, otherKey := testKeyPair(t)
+	_, strangerKey := testKeyPair(t)
+
+	agentID := uuid.New()
+	otherID := uuid.New()
+	unenrolledID := uuid.New()
+	assignmentID := uuid.New()
+
+	_, backendKey := testKeyPair(t)
+
+	svc := NewEnvelopeService(backendKey, time.Minute,
+		fakeAgentKeys{agentID: agentPub, otherID: otherPub, unenrolledID: nil},
+		fakeAssignments{agentID: agentID, ids: map[uuid.UUID]struct{}{assignmentID: {}}},
+	)
+
+	result := func(agent, assignment uuid.UUID) contract.CheckResult {
+		return contract.CheckResult{
+			Version:      contract.SchemaVersion,
+			TaskID:       uuid.New(),
+			AgentID:      agent,
+			AssignmentID: assignment,
+			Type:         contract.CheckHTTP,
+			OK:           true,
+		}
+	}
+
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr error
+	}{
+		{
+			name: "assigned agent",
+			data: sealAgentMessage(t, agentKey, agentID.String(), result(agentID, assignmentID)),
+		},
+		{
+			name:    "result agentId differs from signer",
+			data:    sealAgentMessage(t, otherKey, otherID.String(), result(agentID, assignmentID)),
+			wantErr: apperrors.ErrAgentIdentityMismatch,
+		},
+		{
+			name:    "signed with another agent key",
+			data:    sealAgentMessage(t, otherKey, agentID.String(), result(agentID, assignmentID)),
+			wantErr: apperrors.ErrInvalidAgentSignature,
+		},
+		{
+			name:    "unknown agent",
+			data:    sealAgentMessage(t, strangerKey, uuid.NewString(), result(agentID, assignmentID)),
+			wantErr: apperrors.ErrAgentKeyNotRegistered,
+		},
+		{
+			name:    "agent without enrolled key",
+			data:    sealAgentMessage(t, strangerKey, unenrolledID.String(), result(unenrolledID, assignmentID)),
+			wantErr: apperrors.ErrAgentKeyNotRegistered,
+		},
+		{
+			name:    "signer is not an agent",
+			data:    sealAgentMessage(t, agentKey, contract.BackendSigner, result(agentID, assignmentID)),
+			wantErr: apperrors.ErrInvalidAgentSignature,
+		},
+		{
+			name:    "not assigned to the agent",
+			data:    sealAgentMessage(t, otherKey, otherID.String(), result(otherID, assignmentID)),
+			wantErr: apperrors.ErrAgentNotAssigned,
+		},
+		{
+			name:    "not an envelope",
+			data:    []byte(`not json`),
+			wantErr: apperrors.ErrInvalidAgentMessage,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, assignment, err := svc.OpenResult(context.Background(), tt.data)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("OpenResult = %v, want %v", err, tt.wantErr)
+			}
+
+			if err == nil && (assignment.ID != assignmentID || res.AgentID != agentID) {
+				t.Fatalf("got result %+v, assignment %+v", res, assignment)
+			}
+		})
+	}
+}
+
+func TestEnvelopeServiceOpenResultTampered(t *testing.T) {
+	agentPub, agentKey := testKeyPair(t)
+	_, backendKey := testKeyPair(t)
+
+	agentID := uuid.New()
+	assignmentID := uuid.New()
+
+	svc := NewEnvelopeService(backendKey, time.Minute,
+		fakeAgentKeys{agentID: agentPub},
+		fakeAssignments{agentID: agentID, ids: map[uuid.UUID]struct{}{assignmentID: {}}},
+	)
+
+	data := sealAgentMessage(t, agentKey, agentID.String(), contract.CheckResult{
+		Version:      contract.SchemaVersion,
+		AgentID:      agentID,
+		AssignmentID: assignmentID,
+		OK:           false,
+	})
+
+	var env contract.Envelope
+	if err := json.Unmarshal(data, &env); err != nil {
+		t.Fatalf("unmarshal envelope: %v", err)
+	}
+
+	var res map[string]interface{}
+	if err := json.Unmarshal(env.Payload, &res); err != nil {
+		t.Fatalf("unmarshal result: %v", err)
+	}
+
+	res["ok"] = true
+
+	payload, err := json.Marshal(res)
+	if err != nil {
+		t.Fatalf("marshal result: %v", err)
+	}
+
+	env.Payload = payload
+
+	tampered, err := json.Marshal(env)
+	if err != nil {
+		t.Fatalf("marshal envelope: %v", err)
+	}
+
+	if _, _, err := svc.OpenResult(context.Background(), tampered); !errors.Is(err, apperrors.ErrInvalidAgentSignature) {
+		t.Fatalf("got %v, want %v", err, apperrors.ErrInvalidAgentSignature)
+	}
+}
+
+func TestEnvelopeServiceOpenHeartbeat(t *testing.T) {
+	agentPub, agentKey := testKeyPair(t)
+	_, otherKey := testKeyPair(t)
+	_, backendKey := testKeyPair(t)
+
+	agentID := uuid.New()
+
+	svc := NewEnvelopeService(backendKey, time.Minute, fakeAgentKeys{agentID: agentPub}, fakeAssignments{})
+
+	heartbeat := func(id uuid.UUID) contract.Heartbeat {
+		return contract.Heartbeat{Version: contract.SchemaVersion, AgentID: id, Region: "EU"}
+	}
+
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr error
+	}{
+		{name: "enrolled agent", data: sealAgentMessage(t, agentKey, agentID.String(), heartbeat(agentID))},
+		{name: "signed with another key", data: sealAgentMessage(t, otherKey, agentID.String(), heartbeat(agentID)), wantErr: apperrors.ErrInvalidAgentSignature},
+		{name: "signer differs from agentId", data: sealAgentMessage(t, agentKey, uuid.NewString(), heartbeat(agentID)), wantErr: apperrors.ErrInvalidAgentSignature},
+		{name: "unknown agent", data: sealAgentMessage(t, otherKey, uuid.NewString(), heartbeat(uuid.New())), wantErr: apperrors.ErrAgentKeyNotRegistered},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hb, err := svc.OpenHeartbeat(context.Background(), tt.data)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("OpenHeartbeat = %v, want %v", err, tt.wantErr)
+			}
+
+			if err == nil && hb.AgentID != agentID {
+				t.Fatalf("got heartbeat %+v", hb)
+			}
+		})
+	}
+}
+
+func TestEnvelopeServiceSealTaskExpires(t *testing.T) {
+	backendPub, backendKey := testKeyPair(t)
+
+	svc := NewEnvelopeService(backendKey, time.Minute, fakeAgentKeys{}, fakeAssignments{})
+
+	data, err := svc.SealTask([]byte(`{"target":"example.com"}`), 30*time.Second)
+	if err != nil {
+		t.Fatalf("SealTask: %v", err)
+	}
+
+	env, err := contract.OpenEnvelope(data)
+	if err != nil {
+		t.Fatalf("OpenEnvelope: %v", err)
+	}
+
+	if err := env.Verify(backendPub, contract.BackendSigner, time.Now()); err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+
+	if err := env.Verify(backendPub, contract.BackendSigner, time.Now().Add(2*time.Minute)); !errors.Is(err, contract.ErrEnvelopeExpired) {
+		t.Fatalf("got %v, want %v", err, contract.ErrEnvelopeExpired)
+	}
+}
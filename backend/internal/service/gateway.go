@@ -0,0 +1,227 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"hackathon-contract"
+
+	"hackathon-back/internal/apperrors"
+	"hackathon-back/internal/model"
+	"hackathon-back/internal/repository"
+)
+
+// InboxHandler принимает результат проверки так же, как inbox subscriber из Kafka.
+type InboxHandler interface {
+	Handle(ctx context.Context, messageID uuid.UUID, payload []byte) error
+}
+
+type Presence interface {
+	Heartbeat(ctx context.Context, hb contract.Heartbeat) error
+}
+
+// Lifecycle отмечает назначение задачи выданным, см. LifecycleService.
+type Lifecycle interface {
+	Dispatched(ctx context.Context, outboxID uuid.UUID) error
+}
+
+// HeartbeatOpener проверяет подпись heartbeat, см. EnvelopeService.
+type HeartbeatOpener interface {
+	OpenHeartbeat(ctx context.Context, data []byte) (contract.Heartbeat, error)
+}
+
+// GatewayAgents агенты реестра: gateway пускает агента по его собственному токену и не отдаёт задач выключенному.
+type GatewayAgents interface {
+	SelectAgentByID(ctx context.Context, ext repository.RepoExtension, id uuid.UUID) (*model.Agent, error)
+	SelectAgentByGatewayToken(ctx context.Context, ext repository.RepoExtension, id uuid.UUID, tokenHash []byte) (*model.Agent, error)
+}
+
+// GatewayService HTTP транспорт для агентов без доступа к брокерам: отдаёт назначенные агенту задачи
+// long-poll'ом из outbox и принимает результаты и heartbeat, как если бы они пришли из топиков.
+// Задача отмечается отправленной в момент выдачи агенту, как и в outbox publisher после записи в Kafka.
+type GatewayService struct {
+	log            *zap.Logger
+	outboxRepo     OutboxRepository
+	agentRepo      GatewayAgents
+	inbox          InboxHandler
+	opener         HeartbeatOpener
+	presence       Presence
+	lifecycle      Lifecycle
+	resultsTopic   string
+	heartbeatTopic string
+	maxWait        time.Duration
+	pollInterval   time.Duration
+	batchSize      int
+}
+
+func NewGatewayService(
+	log *zap.Logger,
+	outboxRepo OutboxRepository,
+	agentRepo GatewayAgents,
+	inbox InboxHandler,
+	opener HeartbeatOpener,
+	presence Presence,
+	lifecycle Lifecycle,
+	resultsTopic, heartbeatTopic string,
+	maxWait, pollInterval time.Duration,
+	batchSize int,
+) *GatewayService {
+	return &GatewayService{
+		log:            log,
+		outboxRepo:     outboxRepo,
+		agentRepo:      agentRepo,
+		inbox:          inbox,
+		opener:         opener,
+		presence:       presence,
+		lifecycle:      lifecycle,
+		resultsTopic:   resultsTopic,
+		heartbeatTopic: heartbeatTopic,
+		maxWait:        maxWait,
+		pollInterval:   pollInterval,
+		batchSize:      batchSize,
+	}
+}
+
+// Authenticate проверяет, что token — gateway токен, выданный агенту agentID при регистрации.
+// Чужой, устаревший токен или неизвестный агент — apperrors.ErrInvalidAgentToken.
+func (s *GatewayService) Authenticate(ctx context.Context, agentID uuid.UUID, token string) error {
+	if token == "" {
+		return apperrors.ErrInvalidAgentToken
+	}
+
+	if _, err := s.agentRepo.SelectAgentByGatewayToken(ctx, nil, agentID, hashAgentToken(token)); err != nil {
+		if errors.Is(err, apperrors.ErrAgentDoesNotExist) {
+			return apperrors.ErrInvalidAgentToken
+		}
+
+		return fmt.Errorf("failed to select agent: %w", err)
+	}
+
+	return nil
+}
+
+// Poll ждёт задачи, назначенные агенту, не дольше wait (и не дольше maxWait). Пустой результат — задач не было.
+// Задачи берутся только из топика региона агента по реестру. Неизвестный или не прошедший регистрацию агент
+// получает apperrors.ErrAgentNotRegistered, выключенный — apperrors.ErrAgentDisabled.
+func (s *GatewayService) Poll(ctx context.Context, agentID uuid.UUID, wait time.Duration) ([]contract.GatewayMessage, error) {
+	agent, err := s.agentRepo.SelectAgentByID(ctx, nil, agentID)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrAgentDoesNotExist) {
+			return nil, apperrors.ErrAgentNotRegistered
+		}
+
+		return nil, fmt.Errorf("failed to select agent: %w", err)
+	}
+
+	switch {
+	case !agent.Registered || len(agent.PublicKey) == 0:
+		return nil, apperrors.ErrAgentNotRegistered
+	case agent.Disabled:
+		return nil, apperrors.ErrAgentDisabled
+	}
+
+	if wait <= 0 || wait > s.maxWait {
+		wait = s.maxWait
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, wait)
+	defer cancel()
+
+	ticker := time.NewTicker(s.pollInterval)
+	defer ticker.Stop()
+
+	topic := regionTopic(agent.Region)
+
+	for {
+		claimed, err := s.outboxRepo.ClaimUnsentBatch(ctx, nil, topic, agentID, s.batchSize)
+		if err != nil {
+			if ctx.Err() != nil {
+				return []contract.GatewayMessage{}, nil
+			}
+
+			return nil, fmt.Errorf("failed to claim outbox messages: %w", err)
+		}
+
+		if len(claimed) > 0 {
+			messages := make([]contract.GatewayMessage, 0, len(claimed))
+			for _, msg := range claimed {
+				messages = append(messages, contract.GatewayMessage{
+					Topic: msg.Topic,
+					Key:   msg.ID,
+					Value: msg.Payload,
+				})
+
+				// задачи уже забраны: ошибка статуса не должна их потерять
+				if err := s.lifecycle.Dispatched(ctx, msg.ID); err != nil {
+					s.log.Warn("Failed to mark assignment dispatched", zap.String("message_id", msg.ID.String()), zap.Error(err))
+				}
+			}
+
+			s.log.Info("Tasks handed to agent over gateway",
+				zap.String("agent_id", agentID.String()),
+				zap.String("topic", topic),
+				zap.Int("count", len(messages)),
+			)
+
+			return messages, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return []contract.GatewayMessage{}, nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Publish принимает сообщение агента, адресованное топику результатов или heartbeat.
+// Подписи проверяют inbox и EnvelopeService, здесь — только что агент шлёт сообщения от своего имени.
+func (s *GatewayService) Publish(ctx context.Context, agentID uuid.UUID, msg contract.GatewayMessage) error {
+	switch msg.Topic {
+	case s.resultsTopic:
+		env, err := contract.OpenEnvelope(msg.Value)
+		if err != nil {
+			return fmt.Errorf("%w: %w", apperrors.ErrInvalidAgentMessage, err)
+		}
+
+		if env.Signer != agentID.String() {
+			return apperrors.ErrAgentIdentityMismatch
+		}
+
+		if msg.Key == uuid.Nil {
+			return fmt.Errorf("%w: key is required", apperrors.ErrInvalidAgentMessage)
+		}
+
+		if err := s.inbox.Handle(ctx, msg.Key, msg.Value); err != nil {
+			return fmt.Errorf("failed to handle check result: %w", err)
+		}
+
+		return nil
+
+	case s.heartbeatTopic:
+		hb, err := s.opener.OpenHeartbeat(ctx, msg.Value)
+		if err != nil {
+			return fmt.Errorf("failed to open heartbeat: %w", err)
+		}
+
+		if hb.AgentID != agentID {
+			return apperrors.ErrAgentIdentityMismatch
+		}
+
+		// агент, который ходит через gateway, получает задачи только через него
+		hb.Transport = contract.TransportHTTP
+
+		if err := s.presence.Heartbeat(ctx, hb); err != nil {
+			return fmt.Errorf("failed to accept heartbeat: %w", err)
+		}
+
+		return nil
+
+	default:
+		return fmt.Errorf("%w %q", apperrors.ErrUnknownGatewayTopic, msg.Topic)
+	}
+}
@@ -0,0 +1,215 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+
+	"hackathon-back/internal/model"
+	"hackathon-back/internal/repository"
+)
+
+type LifecycleRepository interface {
+	Pool() *pgxpool.Pool
+
+	UpdateAssignmentStatus(ctx context.Context, ext repository.RepoExtension, id uuid.UUID, to string, from []string, errorText string) (requestID uuid.UUID, changed bool, err error)
+	UpdateAssignmentStatusByOutboxID(ctx context.Context, ext repository.RepoExtension, outboxID uuid.UUID, to string, from []string) (requestID uuid.UUID, changed bool, err error)
+	CompleteAssignment(ctx context.Context, ext repository.RepoExtension, id uuid.UUID, from []string) (requestID uuid.UUID, status string, changed bool, err error)
+	UpdateOverdueAssignments(ctx context.Context, ext repository.RepoExtension, grace time.Duration, from []string) ([]*model.Assignment, error)
+	UpdateRequestStatus(ctx context.Context, ext repository.RepoExtension, requestID uuid.UUID) (status string, changed bool, err error)
+}
+
+// StatusEvents рассылает смену статуса запроса открытым websocket, см. stream.Hub.
+type StatusEvents interface {
+	Publish(ctx context.Context, event model.StreamEvent) error
+}
+
+// LifecycleService ведёт статусы назначений и запросов по model.AssignmentTransitions:
+// outbox или gateway выдали задачу — DISPATCHED, агент подтвердил старт — RUNNING,
+// пришли результаты всех проверок — DONE или FAILED, не пришли за timeout_seconds — TIMEOUT (sweeper).
+// Статус запроса пересчитывается из статусов его назначений после каждого перехода.
+type LifecycleService struct {
+	log           *zap.Logger
+	requestRepo   LifecycleRepository
+	events        StatusEvents
+	grace         time.Duration
+	sweepInterval time.Duration
+}
+
+func NewLifecycleService(log *zap.Logger, requestRepo LifecycleRepository, events StatusEvents, grace, sweepInterval time.Duration) *LifecycleService {
+	return &LifecycleService{
+		log:           log,
+		requestRepo:   requestRepo,
+		events:        events,
+		grace:         grace,
+		sweepInterval: sweepInterval,
+	}
+}
+
+// Dispatched задача из outbox записана в Kafka или выдана http агенту.
+func (s *LifecycleService) Dispatched(ctx context.Context, outboxID uuid.UUID) error {
+	return s.inTx(ctx, func(tx pgx.Tx) (uuid.UUID, string, bool, error) {
+		requestID, changed, err := s.requestRepo.UpdateAssignmentStatusByOutboxID(ctx, tx, outboxID, model.StatusDispatched, model.StatusesFrom(model.StatusDispatched))
+		if err != nil {
+			return uuid.Nil, "", false, fmt.Errorf("failed to update assignment status: %w", err)
+		}
+
+		if !changed {
+			return uuid.Nil, "", false, nil
+		}
+
+		status, changed, err := s.refreshRequest(ctx, tx, requestID)
+
+		return requestID, status, changed, err
+	})
+}
+
+// Acknowledged агент прислал по назначению первое сообщение: подтверждение старта или результат.
+func (s *LifecycleService) Acknowledged(ctx context.Context, assignmentID uuid.UUID) error {
+	return s.inTx(ctx, func(tx pgx.Tx) (uuid.UUID, string, bool, error) {
+		requestID, changed, err := s.requestRepo.UpdateAssignmentStatus(ctx, tx, assignmentID, model.StatusRunning, model.StatusesFrom(model.StatusRunning), "")
+		if err != nil {
+			return uuid.Nil, "", false, fmt.Errorf("failed to update assignment status: %w", err)
+		}
+
+		if !changed {
+			return uuid.Nil, "", false, nil
+		}
+
+		status, changed, err := s.refreshRequest(ctx, tx, requestID)
+
+		return requestID, status, changed, err
+	})
+}
+
+// ResultRecorded вызывается в транзакции, которая записала итоговый результат проверки:
+// когда пришли результаты всех проверок назначения, оно завершается. Новый статус запроса
+// вызывающий рассылает сам после коммита.
+func (s *LifecycleService) ResultRecorded(ctx context.Context, tx repository.RepoExtension, assignmentID uuid.UUID) (requestStatus string, changed bool, err error) {
+	requestID, status, changed, err := s.requestRepo.CompleteAssignment(ctx, tx, assignmentID, model.StatusesFrom(model.StatusDone))
+	if err != nil {
+		return "", false, fmt.Errorf("failed to complete assignment: %w", err)
+	}
+
+	if !changed {
+		return "", false, nil
+	}
+
+	s.log.Debug("Assignment finished",
+		zap.String("assignment_id", assignmentID.String()),
+		zap.String("status", status),
+	)
+
+	return s.refreshRequest(ctx, tx, requestID)
+}
+
+// RunSweeper раз в sweepInterval переводит в TIMEOUT назначения, по которым результаты не пришли вовремя.
+func (s *LifecycleService) RunSweeper(ctx context.Context) {
+	ticker := time.NewTicker(s.sweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.log.Info("Context canceled, stopping lifecycle sweeper")
+
+			return
+		case <-ticker.C:
+			if err := s.sweep(ctx); err != nil {
+				s.log.Error("Failed to sweep assignments", zap.Error(err))
+			}
+		}
+	}
+}
+
+func (s *LifecycleService) sweep(ctx context.Context) error {
+	assignments, err := s.requestRepo.UpdateOverdueAssignments(ctx, nil, s.grace, model.StatusesFrom(model.StatusTimeout))
+	if err != nil {
+		return fmt.Errorf("failed to update overdue assignments: %w", err)
+	}
+
+	requests := make(map[uuid.UUID]struct{}, len(assignments))
+
+	for _, a := range assignments {
+		s.log.Warn("Assignment timed out",
+			zap.String("assignment_id", a.ID.String()),
+			zap.String("request_id", a.RequestID.String()),
+			zap.String("agent_id", a.AgentID.String()),
+			zap.String("region", a.AgentRegion),
+		)
+
+		requests[a.RequestID] = struct{}{}
+	}
+
+	for requestID := range requests {
+		if err := s.inTx(ctx, func(tx pgx.Tx) (uuid.UUID, string, bool, error) {
+			status, changed, err := s.refreshRequest(ctx, tx, requestID)
+
+			return requestID, status, changed, err
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *LifecycleService) refreshRequest(ctx context.Context, tx repository.RepoExtension, requestID uuid.UUID) (status string, changed bool, err error) {
+	status, changed, err = s.requestRepo.UpdateRequestStatus(ctx, tx, requestID)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to update request status: %w", err)
+	}
+
+	if changed && model.IsTerminalStatus(status) {
+		s.log.Info("Request finished",
+			zap.String("request_id", requestID.String()),
+			zap.String("status", status),
+		)
+	}
+
+	return status, changed, nil
+}
+
+// inTx выполняет переход в транзакции и после коммита рассылает новый статус запроса, если он сменился.
+func (s *LifecycleService) inTx(ctx context.Context, fn func(tx pgx.Tx) (requestID uuid.UUID, status string, changed bool, err error)) (err error) {
+	tx, err := s.requestRepo.Pool().Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		if err != nil {
+			if rErr := tx.Rollback(ctx); rErr != nil {
+				err = fmt.Errorf("%w, failed to roll back transaction: %w", err, rErr)
+			}
+		}
+	}()
+
+	requestID, status, changed, err := fn(tx)
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	if changed {
+		// рассылка не критична: websocket, пропустивший событие, дочитает статус из БД
+		if pErr := s.events.Publish(ctx, model.StreamEvent{
+			Topic:     model.RequestTopic(requestID),
+			RequestID: requestID,
+			Type:      model.StreamEventStatus,
+			Status:    status,
+		}); pErr != nil {
+			s.log.Warn("Failed to publish request status", zap.String("request_id", requestID.String()), zap.Error(pErr))
+		}
+	}
+
+	return nil
+}
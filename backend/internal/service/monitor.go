@@ -0,0 +1,521 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+	"hackathon-contract"
+
+	"hackathon-back/internal/apperrors"
+	"hackathon-back/internal/model"
+	"hackathon-back/internal/repository"
+	"hackathon-back/pkg/cron"
+)
+
+const (
+	maxMonitorNameLength   = 128
+	defaultMonitorRunLimit = 50
+	monitorRetryDelay      = 5 * time.Minute // monitorRetryDelay через сколько повторить монитор, следующий запуск которого не вычислить
+	monitorInternalError   = "internal error, the run will be retried on schedule"
+)
+
+type MonitorRepository interface {
+	Pool() *pgxpool.Pool
+
+	InsertMonitor(ctx context.Context, ext repository.RepoExtension, monitor *model.Monitor) error
+	UpdateMonitor(ctx context.Context, ext repository.RepoExtension, monitor *model.Monitor, reschedule bool) error
+	DeleteMonitor(ctx context.Context, ext repository.RepoExtension, id uuid.UUID) error
+	SelectMonitorByID(ctx context.Context, ext repository.RepoExtension, id uuid.UUID) (*model.Monitor, error)
+	SelectMonitorsByUser(ctx context.Context, ext repository.RepoExtension, userID uuid.UUID) ([]*model.Monitor, error)
+	CountMonitorsByUser(ctx context.Context, ext repository.RepoExtension, userID uuid.UUID) (int, error)
+	ClaimDueMonitor(ctx context.Context, ext repository.RepoExtension) (*model.Monitor, error)
+	UpdateMonitorSchedule(ctx context.Context, ext repository.RepoExtension, id uuid.UUID, lastRunAt, nextRunAt time.Time) error
+	InsertMonitorRun(ctx context.Context, ext repository.RepoExtension, run *model.MonitorRun) error
+	SelectMonitorRuns(ctx context.Context, ext repository.RepoExtension, monitorID uuid.UUID, limit int) ([]*model.MonitorRun, error)
+}
+
+// MonitorRequests постановка задач мониторов в очередь, см. RequestService.
+type MonitorRequests interface {
+	ValidateRequest(ctx context.Context, req model.TaskMessageRequest, userID uuid.UUID) error
+	CreateMonitorRequest(ctx context.Context, tx repository.RepoExtension, monitor *model.Monitor) (*model.Request, error)
+}
+
+// MonitorEvents рассылает запуски мониторов в топик model.MonitorTopic, см. stream.Hub.
+type MonitorEvents interface {
+	Publish(ctx context.Context, event model.StreamEvent) error
+}
+
+// MonitorSettings планировщик раз в PollInterval запускает до BatchSize наступивших мониторов.
+// MinInterval — минимальный intervalSeconds, MaxPerUser — сколько мониторов может завести пользователь.
+type MonitorSettings struct {
+	PollInterval time.Duration
+	BatchSize    int
+	MinInterval  time.Duration
+	MaxPerUser   int
+}
+
+// MonitorService мониторы пользователей и планировщик их запусков. Каждая реплика запускает планировщик:
+// наступивший монитор забирается через FOR UPDATE SKIP LOCKED, и в той же транзакции создаются запрос,
+// задачи в outbox, запись о запуске и следующее время запуска, поэтому запуск не выполняется дважды и не теряется.
+type MonitorService struct {
+	log         *zap.Logger
+	monitorRepo MonitorRepository
+	requests    MonitorRequests
+	events      MonitorEvents
+	settings    MonitorSettings
+}
+
+func NewMonitorService(log *zap.Logger, monitorRepo MonitorRepository, requests MonitorRequests, events MonitorEvents, settings MonitorSettings) *MonitorService {
+	return &MonitorService{
+		log:         log,
+		monitorRepo: monitorRepo,
+		requests:    requests,
+		events:      events,
+		settings:    settings,
+	}
+}
+
+// CreateMonitor заводит монитор пользователя. Задача проверяется так же, как /check/task, с правами пользователя.
+func (s *MonitorService) CreateMonitor(ctx context.Context, userID uuid.UUID, req model.MonitorCreateRequest) (*model.Monitor, error) {
+	monitor := &model.Monitor{
+		ID:              uuid.New(),
+		UserID:          userID,
+		Name:            strings.TrimSpace(req.Name),
+		Task:            req.Task,
+		IntervalSeconds: req.IntervalSeconds,
+		Cron:            strings.TrimSpace(req.Cron),
+		Enabled:         req.Enabled == nil || *req.Enabled,
+	}
+
+	fieldErrs := s.validateSchedule(monitor.IntervalSeconds, monitor.Cron)
+	fieldErrs = append(fieldErrs, validateMonitorName(monitor.Name)...)
+
+	if len(fieldErrs) > 0 {
+		return nil, &contract.ValidationError{Fields: fieldErrs}
+	}
+
+	if err := s.validateTask(ctx, userID, monitor.Task); err != nil {
+		return nil, err
+	}
+
+	count, err := s.monitorRepo.CountMonitorsByUser(ctx, nil, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count monitors: %w", err)
+	}
+
+	if s.settings.MaxPerUser > 0 && count >= s.settings.MaxPerUser {
+		return nil, fmt.Errorf("%w: at most %d monitors per user", apperrors.ErrMonitorLimitExceeded, s.settings.MaxPerUser)
+	}
+
+	monitor.NextRunAt, err = firstMonitorRun(monitor, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.monitorRepo.InsertMonitor(ctx, nil, monitor); err != nil {
+		return nil, fmt.Errorf("failed to insert monitor: %w", err)
+	}
+
+	s.log.Info("Monitor created",
+		zap.String("monitor_id", monitor.ID.String()),
+		zap.String("user_id", userID.String()),
+		zap.Time("next_run_at", monitor.NextRunAt),
+	)
+
+	return monitor, nil
+}
+
+func (s *MonitorService) GetMonitors(ctx context.Context, userID uuid.UUID) ([]*model.Monitor, error) {
+	monitors, err := s.monitorRepo.SelectMonitorsByUser(ctx, nil, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select monitors: %w", err)
+	}
+
+	if monitors == nil {
+		monitors = []*model.Monitor{}
+	}
+
+	return monitors, nil
+}
+
+func (s *MonitorService) GetMonitor(ctx context.Context, userID, id uuid.UUID) (*model.Monitor, error) {
+	return s.selectMonitor(ctx, userID, id)
+}
+
+// UpdateMonitor меняет монитор. Новое расписание или включение монитора заново отсчитывает следующий запуск от текущего времени.
+func (s *MonitorService) UpdateMonitor(ctx context.Context, userID, id uuid.UUID, req model.MonitorUpdateRequest) (*model.Monitor, error) {
+	monitor, err := s.selectMonitor(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var fieldErrs []contract.FieldError
+
+	if req.Name != nil {
+		monitor.Name = strings.TrimSpace(*req.Name)
+		fieldErrs = append(fieldErrs, validateMonitorName(monitor.Name)...)
+	}
+
+	reschedule := false
+
+	switch {
+	case req.IntervalSeconds != nil && req.Cron != nil:
+		fieldErrs = append(fieldErrs, contract.FieldError{Field: "cron", Message: "intervalSeconds and cron are mutually exclusive"})
+	case req.IntervalSeconds != nil:
+		monitor.IntervalSeconds, monitor.Cron = *req.IntervalSeconds, ""
+		fieldErrs = append(fieldErrs, s.validateSchedule(monitor.IntervalSeconds, monitor.Cron)...)
+		reschedule = true
+	case req.Cron != nil:
+		monitor.IntervalSeconds, monitor.Cron = 0, strings.TrimSpace(*req.Cron)
+		fieldErrs = append(fieldErrs, s.validateSchedule(monitor.IntervalSeconds, monitor.Cron)...)
+		reschedule = true
+	}
+
+	if len(fieldErrs) > 0 {
+		return nil, &contract.ValidationError{Fields: fieldErrs}
+	}
+
+	if req.Task != nil {
+		if err := s.validateTask(ctx, userID, *req.Task); err != nil {
+			return nil, err
+		}
+
+		monitor.Task = *req.Task
+	}
+
+	if req.Enabled != nil {
+		reschedule = reschedule || *req.Enabled && !monitor.Enabled
+		monitor.Enabled = *req.Enabled
+	}
+
+	if reschedule {
+		monitor.NextRunAt, err = firstMonitorRun(monitor, time.Now())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.monitorRepo.UpdateMonitor(ctx, nil, monitor, reschedule); err != nil {
+		return nil, fmt.Errorf("failed to update monitor: %w", err)
+	}
+
+	return monitor, nil
+}
+
+func (s *MonitorService) DeleteMonitor(ctx context.Context, userID, id uuid.UUID) error {
+	if _, err := s.selectMonitor(ctx, userID, id); err != nil {
+		return err
+	}
+
+	if err := s.monitorRepo.DeleteMonitor(ctx, nil, id); err != nil {
+		return fmt.Errorf("failed to delete monitor: %w", err)
+	}
+
+	s.log.Info("Monitor deleted",
+		zap.String("monitor_id", id.String()),
+		zap.String("user_id", userID.String()),
+	)
+
+	return nil
+}
+
+// GetRuns последние limit запусков монитора, 0 — defaultMonitorRunLimit.
+func (s *MonitorService) GetRuns(ctx context.Context, userID, id uuid.UUID, limit int) ([]*model.MonitorRun, error) {
+	if _, err := s.selectMonitor(ctx, userID, id); err != nil {
+		return nil, err
+	}
+
+	if limit <= 0 {
+		limit = defaultMonitorRunLimit
+	}
+
+	runs, err := s.monitorRepo.SelectMonitorRuns(ctx, nil, id, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select monitor runs: %w", err)
+	}
+
+	if runs == nil {
+		runs = []*model.MonitorRun{}
+	}
+
+	return runs, nil
+}
+
+// RunScheduler раз в PollInterval запускает наступившие мониторы, пока они есть, но не больше BatchSize за раз.
+// Ошибка одного запуска не останавливает пачку: остальные наступившие мониторы запускаются в этот же тик.
+func (s *MonitorService) RunScheduler(ctx context.Context) {
+	ticker := time.NewTicker(s.settings.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.log.Info("Context canceled, stopping monitor scheduler")
+
+			return
+		case <-ticker.C:
+			for range s.settings.BatchSize {
+				err := s.runDue(ctx)
+				if errors.Is(err, apperrors.ErrMonitorDoesNotExist) {
+					break
+				}
+
+				if err != nil {
+					s.log.Error("Failed to run monitor", zap.Error(err))
+				}
+			}
+		}
+	}
+}
+
+// runDue запускает один наступивший монитор. Нет наступивших — apperrors.ErrMonitorDoesNotExist.
+// Если запрос не создан — из-за самой задачи (нет агентов, невалидные targets, нет доступа к пулу) или из-за сбоя, —
+// запуск записывается как FAILED и монитор ждёт следующего, поэтому сломанный монитор не занимает очередь.
+// Транзакция откатывается, только если не удалось записать сам запуск, тогда он повторится.
+func (s *MonitorService) runDue(ctx context.Context) (err error) {
+	tx, err := s.monitorRepo.Pool().Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		if err != nil {
+			if rErr := tx.Rollback(ctx); rErr != nil {
+				err = fmt.Errorf("%w, failed to roll back transaction: %w", err, rErr)
+			}
+		}
+	}()
+
+	monitor, err := s.monitorRepo.ClaimDueMonitor(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+
+	run := &model.MonitorRun{
+		ID:          uuid.New(),
+		MonitorID:   monitor.ID,
+		Status:      model.MonitorRunDispatched,
+		ScheduledAt: monitor.NextRunAt,
+	}
+
+	request, reqErr := s.createRequest(ctx, tx, monitor)
+
+	switch {
+	case reqErr == nil:
+		run.RequestID = &request.ID
+	case isMonitorRunError(reqErr):
+		run.Status = model.MonitorRunFailed
+		run.Error = reqErr.Error()
+	default:
+		s.log.Error("Failed to create monitor request",
+			zap.String("monitor_id", monitor.ID.String()),
+			zap.Error(reqErr),
+		)
+
+		run.Status = model.MonitorRunFailed
+		run.Error = monitorInternalError
+	}
+
+	next, nextErr := nextMonitorRun(monitor, now)
+	if nextErr != nil {
+		s.log.Error("Failed to schedule monitor, retrying later",
+			zap.String("monitor_id", monitor.ID.String()),
+			zap.Error(nextErr),
+		)
+
+		next = now.Add(monitorRetryDelay)
+	}
+
+	if err := s.monitorRepo.InsertMonitorRun(ctx, tx, run); err != nil {
+		return fmt.Errorf("failed to insert monitor run: %w", err)
+	}
+
+	if err := s.monitorRepo.UpdateMonitorSchedule(ctx, tx, monitor.ID, now, next); err != nil {
+		return fmt.Errorf("failed to update monitor schedule: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	s.publishRun(ctx, run)
+
+	if run.Status == model.MonitorRunFailed {
+		s.log.Warn("Monitor run failed",
+			zap.String("monitor_id", monitor.ID.String()),
+			zap.String("error", run.Error),
+			zap.Time("next_run_at", next),
+		)
+
+		return nil
+	}
+
+	s.log.Debug("Monitor run dispatched",
+		zap.String("monitor_id", monitor.ID.String()),
+		zap.String("request_id", request.ID.String()),
+		zap.Time("next_run_at", next),
+	)
+
+	return nil
+}
+
+// publishRun рассылка не критична: подписчик монитора, пропустивший запуск, получит его в снимке.
+func (s *MonitorService) publishRun(ctx context.Context, run *model.MonitorRun) {
+	if err := s.events.Publish(ctx, model.StreamEvent{
+		Topic:     model.MonitorTopic(run.MonitorID),
+		Type:      model.StreamEventMonitorRun,
+		MonitorID: run.MonitorID,
+		Run:       run,
+	}); err != nil {
+		s.log.Warn("Failed to publish monitor run",
+			zap.String("monitor_id", run.MonitorID.String()),
+			zap.String("run_id", run.ID.String()),
+			zap.Error(err),
+		)
+	}
+}
+
+// createRequest создаёт запрос монитора в точке сохранения: сбой откатывает только её, а запуск записывается в той же транзакции.
+func (s *MonitorService) createRequest(ctx context.Context, tx pgx.Tx, monitor *model.Monitor) (request *model.Request, err error) {
+	sp, err := tx.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin savepoint: %w", err)
+	}
+
+	defer func() {
+		if err != nil {
+			if rErr := sp.Rollback(ctx); rErr != nil {
+				err = fmt.Errorf("%w, failed to roll back savepoint: %w", err, rErr)
+			}
+		}
+	}()
+
+	request, err = s.requests.CreateMonitorRequest(ctx, sp, monitor)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := sp.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to release savepoint: %w", err)
+	}
+
+	return request, nil
+}
+
+func (s *MonitorService) selectMonitor(ctx context.Context, userID, id uuid.UUID) (*model.Monitor, error) {
+	monitor, err := s.monitorRepo.SelectMonitorByID(ctx, nil, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select monitor: %w", err)
+	}
+
+	// чужой монитор для пользователя не существует
+	if monitor.UserID != userID {
+		return nil, apperrors.ErrMonitorDoesNotExist
+	}
+
+	return monitor, nil
+}
+
+func (s *MonitorService) validateTask(ctx context.Context, userID uuid.UUID, task model.TaskMessageRequest) error {
+	err := s.requests.ValidateRequest(ctx, task, userID)
+	if err == nil {
+		return nil
+	}
+
+	var vErr *contract.ValidationError
+	if errors.As(err, &vErr) {
+		return vErr.WithPrefix("task")
+	}
+
+	if errors.Is(err, apperrors.ErrAgentPoolForbidden) {
+		return err
+	}
+
+	return fmt.Errorf("failed to validate monitor task: %w", err)
+}
+
+func (s *MonitorService) validateSchedule(intervalSeconds int, cronExpr string) []contract.FieldError {
+	switch {
+	case intervalSeconds == 0 && cronExpr == "":
+		return []contract.FieldError{{Field: "intervalSeconds", Message: "intervalSeconds or cron is required"}}
+	case intervalSeconds != 0 && cronExpr != "":
+		return []contract.FieldError{{Field: "cron", Message: "intervalSeconds and cron are mutually exclusive"}}
+	case cronExpr != "":
+		schedule, err := cron.Parse(cronExpr)
+		if err != nil {
+			return []contract.FieldError{{Field: "cron", Message: err.Error()}}
+		}
+
+		// расписание не должно срабатывать чаще, чем разрешено интервалу
+		if schedule.MinInterval() < s.settings.MinInterval {
+			return []contract.FieldError{{Field: "cron", Message: fmt.Sprintf("must not fire more often than every %d seconds", int(s.settings.MinInterval.Seconds()))}}
+		}
+	case time.Duration(intervalSeconds)*time.Second < s.settings.MinInterval:
+		return []contract.FieldError{{Field: "intervalSeconds", Message: fmt.Sprintf("must be at least %d", int(s.settings.MinInterval.Seconds()))}}
+	}
+
+	return nil
+}
+
+func validateMonitorName(name string) []contract.FieldError {
+	if name == "" || len(name) > maxMonitorNameLength {
+		return []contract.FieldError{{Field: "name", Message: fmt.Sprintf("must be 1 to %d characters", maxMonitorNameLength)}}
+	}
+
+	return nil
+}
+
+// isMonitorRunError запрос монитора не создан из-за задачи или состояния агентов, а не из-за сбоя.
+func isMonitorRunError(err error) bool {
+	var vErr *contract.ValidationError
+
+	return errors.As(err, &vErr) ||
+		errors.Is(err, apperrors.ErrNoOnlineAgents) ||
+		errors.Is(err, apperrors.ErrNoCapableAgents) ||
+		errors.Is(err, apperrors.ErrAgentPoolForbidden)
+}
+
+// firstMonitorRun первый запуск нового или перенастроенного монитора: с интервалом — сразу, по cron — ближайшее время расписания.
+func firstMonitorRun(monitor *model.Monitor, now time.Time) (time.Time, error) {
+	if monitor.Cron == "" {
+		return now, nil
+	}
+
+	schedule, err := cron.Parse(monitor.Cron)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to parse monitor schedule: %w", err)
+	}
+
+	return schedule.Next(now), nil
+}
+
+// nextMonitorRun следующий запуск после текущего. Интервал отсчитывается от запланированного времени,
+// чтобы запуски не дрейфовали, а пропущенные за время простоя запуски не выполняются пачкой.
+func nextMonitorRun(monitor *model.Monitor, now time.Time) (time.Time, error) {
+	if monitor.Cron != "" {
+		schedule, err := cron.Parse(monitor.Cron)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("failed to parse monitor schedule: %w", err)
+		}
+
+		return schedule.Next(now), nil
+	}
+
+	interval := time.Duration(monitor.IntervalSeconds) * time.Second
+
+	next := monitor.NextRunAt.Add(interval)
+	if !next.After(now) {
+		next = now.Add(interval)
+	}
+
+	return next, nil
+}
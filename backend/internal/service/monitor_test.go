@@ -0,0 +1,39 @@
+package service
+
+import (
+	"testing"
+	"time"
+)
+
+func TestValidateSchedule(t *testing.T) {
+	svc := &MonitorService{settings: MonitorSettings{MinInterval: 5 * time.Minute}}
+
+	tests := []struct {
+		name            string
+		intervalSeconds int
+		cron            string
+		field           string
+	}{
+		{name: "interval", intervalSeconds: 300},
+		{name: "cron", cron: "*/5 * * * *"},
+		{name: "no schedule", field: "intervalSeconds"},
+		{name: "both", intervalSeconds: 300, cron: "*/5 * * * *", field: "cron"},
+		{name: "short interval", intervalSeconds: 60, field: "intervalSeconds"},
+		{name: "invalid cron", cron: "* * *", field: "cron"},
+		{name: "frequent cron", cron: "* * * * *", field: "cron"},
+		{name: "cron with a short gap", cron: "0,1 * * * *", field: "cron"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fieldErrs := svc.validateSchedule(tt.intervalSeconds, tt.cron)
+
+			switch {
+			case tt.field == "" && len(fieldErrs) != 0:
+				t.Fatalf("unexpected field errors %v", fieldErrs)
+			case tt.field != "" && (len(fieldErrs) != 1 || fieldErrs[0].Field != tt.field):
+				t.Fatalf("got field errors %v, want %s", fieldErrs, tt.field)
+			}
+		})
+	}
+}
@@ -0,0 +1,174 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+	"hackathon-contract"
+
+	"hackathon-back/internal/apperrors"
+	"hackathon-back/internal/model"
+	"hackathon-back/internal/repository"
+)
+
+const maxOrganizationNameLen = 128
+
+type OrganizationRepository interface {
+	Pool() *pgxpool.Pool
+	InsertOrganization(ctx context.Context, ext repository.RepoExtension, org *model.Organization) error
+	InsertMember(ctx context.Context, ext repository.RepoExtension, orgID, userID uuid.UUID) error
+	DeleteMember(ctx context.Context, ext repository.RepoExtension, orgID, userID uuid.UUID) error
+	SelectOrganizationsByUser(ctx context.Context, ext repository.RepoExtension, userID uuid.UUID) ([]*model.Organization, error)
+	SelectMembers(ctx context.Context, ext repository.RepoExtension, orgID uuid.UUID) ([]*model.OrganizationMember, error)
+}
+
+// OrganizationService организации пользователей. Все участники равны: видят и выбирают частных агентов организации,
+// заводят новых и управляют составом. Чужая организация для пользователя не существует.
+type OrganizationService struct {
+	log     *zap.Logger
+	orgRepo OrganizationRepository
+}
+
+func NewOrganizationService(log *zap.Logger, orgRepo OrganizationRepository) *OrganizationService {
+	return &OrganizationService{
+		log:     log,
+		orgRepo: orgRepo,
+	}
+}
+
+// CreateOrganization создаёт организацию, создатель становится её участником.
+func (s *OrganizationService) CreateOrganization(ctx context.Context, userID uuid.UUID, req model.OrganizationCreateRequest) (org *model.Organization, err error) {
+	org = &model.Organization{
+		ID:        uuid.New(),
+		Name:      strings.TrimSpace(req.Name),
+		CreatedBy: userID,
+	}
+
+	if org.Name == "" || len(org.Name) > maxOrganizationNameLen {
+		return nil, &contract.ValidationError{Fields: []contract.FieldError{{
+			Field:   "name",
+			Message: fmt.Sprintf("must be 1 to %d characters", maxOrganizationNameLen),
+		}}}
+	}
+
+	tx, err := s.orgRepo.Pool().Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		if err != nil {
+			if rErr := tx.Rollback(ctx); rErr != nil {
+				err = fmt.Errorf("%w, failed to roll back transaction: %w", err, rErr)
+			}
+		}
+	}()
+
+	if err := s.orgRepo.InsertOrganization(ctx, tx, org); err != nil {
+		return nil, fmt.Errorf("failed to insert organization: %w", err)
+	}
+
+	if err := s.orgRepo.InsertMember(ctx, tx, org.ID, userID); err != nil {
+		return nil, fmt.Errorf("failed to insert organization member: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	s.log.Info("Organization created", zap.String("organization_id", org.ID.String()), zap.String("user_id", userID.String()))
+
+	return org, nil
+}
+
+func (s *OrganizationService) GetOrganizations(ctx context.Context, userID uuid.UUID) ([]*model.Organization, error) {
+	orgs, err := s.orgRepo.SelectOrganizationsByUser(ctx, nil, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select organizations: %w", err)
+	}
+
+	return orgs, nil
+}
+
+func (s *OrganizationService) GetMembers(ctx context.Context, userID, orgID uuid.UUID) ([]*model.OrganizationMember, error) {
+	if err := s.checkMember(ctx, userID, orgID); err != nil {
+		return nil, err
+	}
+
+	members, err := s.orgRepo.SelectMembers(ctx, nil, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select organization members: %w", err)
+	}
+
+	return members, nil
+}
+
+func (s *OrganizationService) AddMember(ctx context.Context, userID, orgID, memberID uuid.UUID) error {
+	if err := s.checkMember(ctx, userID, orgID); err != nil {
+		return err
+	}
+
+	if err := s.orgRepo.InsertMember(ctx, nil, orgID, memberID); err != nil {
+		return fmt.Errorf("failed to insert organization member: %w", err)
+	}
+
+	s.log.Info("Organization member added",
+		zap.String("organization_id", orgID.String()),
+		zap.String("user_id", memberID.String()),
+		zap.String("added_by", userID.String()),
+	)
+
+	return nil
+}
+
+// RemoveMember убирает участника, в том числе самого себя. Агенты организации остаются ей.
+func (s *OrganizationService) RemoveMember(ctx context.Context, userID, orgID, memberID uuid.UUID) error {
+	if err := s.checkMember(ctx, userID, orgID); err != nil {
+		return err
+	}
+
+	if err := s.orgRepo.DeleteMember(ctx, nil, orgID, memberID); err != nil {
+		return fmt.Errorf("failed to delete organization member: %w", err)
+	}
+
+	s.log.Info("Organization member removed",
+		zap.String("organization_id", orgID.String()),
+		zap.String("user_id", memberID.String()),
+		zap.String("removed_by", userID.String()),
+	)
+
+	return nil
+}
+
+// PoolAccess чьи частные агенты доступны пользователю: его собственные и организаций, где он состоит.
+func (s *OrganizationService) PoolAccess(ctx context.Context, userID uuid.UUID) (*model.PoolAccess, error) {
+	orgs, err := s.orgRepo.SelectOrganizationsByUser(ctx, nil, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select organizations: %w", err)
+	}
+
+	access := &model.PoolAccess{UserID: userID, OrganizationIDs: make([]uuid.UUID, 0, len(orgs))}
+	for _, org := range orgs {
+		access.OrganizationIDs = append(access.OrganizationIDs, org.ID)
+	}
+
+	return access, nil
+}
+
+func (s *OrganizationService) checkMember(ctx context.Context, userID, orgID uuid.UUID) error {
+	access, err := s.PoolAccess(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if !slices.Contains(access.OrganizationIDs, orgID) {
+		return apperrors.ErrOrganizationDoesNotExist
+	}
+
+	return nil
+}
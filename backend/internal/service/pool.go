@@ -0,0 +1,118 @@
+package service
+
+import (
+	"fmt"
+	"slices"
+
+	"hackathon-back/internal/apperrors"
+	"hackathon-back/internal/model"
+)
+
+// checkPoolTargets частные регионы в селекторе может выбирать только владелец пула.
+func checkPoolTargets(sel *model.TargetSelector, access *model.PoolAccess) error {
+	if sel == nil {
+		return nil
+	}
+
+	for i, region := range sel.Regions {
+		if model.IsPrivateRegion(region) && !access.OwnsRegion(region) {
+			return fmt.Errorf("%w: targets.regions[%d]", apperrors.ErrAgentPoolForbidden, i)
+		}
+	}
+
+	return nil
+}
+
+// filterPoolAgents оставляет публичных агентов и тех частных агентов пула пользователя, которых запрос выбрал явно:
+// по региону пула или по id. Без явного выбора частные агенты задач не получают, даже от владельца.
+// Чужой частный агент в targets.agentIds — apperrors.ErrAgentPoolForbidden.
+func filterPoolAgents(agents []*model.Agent, sel *model.TargetSelector, access *model.PoolAccess) ([]*model.Agent, error) {
+	filtered := make([]*model.Agent, 0, len(agents))
+
+	for _, agent := range agents {
+		if agent.IsPublic() {
+			filtered = append(filtered, agent)
+
+			continue
+		}
+
+		if sel == nil || !agent.IsPrivate() {
+			continue
+		}
+
+		named := slices.Contains(sel.AgentIDs, agent.ID)
+
+		if !access.Owns(agent) {
+			if i := slices.Index(sel.AgentIDs, agent.ID); named {
+				return nil, fmt.Errorf("%w: targets.agentIds[%d]", apperrors.ErrAgentPoolForbidden, i)
+			}
+
+			continue
+		}
+
+		if named || slices.Contains(sel.Regions, agent.Region) {
+			filtered = append(filtered, agent)
+		}
+	}
+
+	return filtered, nil
+}
+
+// locations регионы, которые пользователь может выбрать: публичные и пулов, к которым у него есть доступ.
+// Выключенные агенты не учитываются.
+func locations(agents []*model.Agent, access *model.PoolAccess) []*model.Location {
+	var (
+		result   []*model.Location
+		byRegion = make(map[string]*model.Location)
+	)
+
+	for _, agent := range agents {
+		if agent.Disabled || !agent.IsPublic() && !access.Owns(agent) {
+			continue
+		}
+
+		loc, ok := byRegion[agent.Region]
+		if !ok {
+			loc = &model.Location{
+				Region:         agent.Region,
+				Private:        agent.IsPrivate(),
+				OrganizationID: agent.OwnerOrgID,
+			}
+
+			byRegion[agent.Region] = loc
+			result = append(result, loc)
+		}
+
+		loc.Agents++
+
+		if agent.Online {
+			loc.Online++
+		}
+
+		if agent.Country != "" && !slices.Contains(loc.Countries, agent.Country) {
+			loc.Countries = append(loc.Countries, agent.Country)
+		}
+	}
+
+	// сначала публичные регионы, затем частные, внутри — по имени
+	slices.SortFunc(result, func(a, b *model.Location) int {
+		switch {
+		case a.Private != b.Private && !a.Private:
+			return -1
+		case a.Private != b.Private:
+			return 1
+		case a.Region < b.Region:
+			return -1
+		case a.Region > b.Region:
+			return 1
+		default:
+			return 0
+		}
+	})
+
+	for _, loc := range result {
+		slices.Sort(loc.Countries)
+	}
+
+	return result
+}
@@ -0,0 +1,569 @@
+package service
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+	"hackathon-contract"
+
+	"hackathon-back/internal/apperrors"
+	"hackathon-back/internal/model"
+	"hackathon-back/internal/repository"
+)
+
+const (
+	agentTokenBytes     = 32
+	maxAgentNameLength  = 100
+	maxPoolRegionLength = 32
+)
+
+type AgentRegistryRepository interface {
+	Pool() *pgxpool.Pool
+
+	SelectAgentByID(ctx context.Context, ext repository.RepoExtension, id uuid.UUID) (*model.Agent, error)
+	SelectAgentsByOwner(ctx context.Context, ext repository.RepoExtension, userID uuid.UUID, orgIDs []uuid.UUID) ([]*model.Agent, error)
+	SelectPoolUsage(ctx context.Context, ext repository.RepoExtension, ownerUserID, ownerOrgID *uuid.UUID) (agents int, regions []string, err error)
+	InsertAgent(ctx context.Context, ext repository.RepoExtension, agent *model.Agent) error
+	UpdateAgent(ctx context.Context, ext repository.RepoExtension, id uuid.UUID, upd *model.AgentUpdateRequest) (*model.Agent, bool, error)
+	UpdateAgentCredentials(ctx context.Context, ext repository.RepoExtension, id uuid.UUID, publicKey, gatewayTokenHash []byte) error
+	DeleteAgent(ctx context.Context, ext repository.RepoExtension, id uuid.UUID) error
+}
+
+type AgentEnrollmentRepository interface {
+	InsertEnrollment(ctx context.Context, ext repository.RepoExtension, enrollment *model.AgentEnrollment, tokenHash []byte) error
+	ExpireUnusedEnrollments(ctx context.Context, ext repository.RepoExtension, agentID uuid.UUID) error
+	ClaimEnrollment(ctx context.Context, ext repository.RepoExtension, tokenHash []byte, agentVersion, ip string) (*model.AgentEnrollment, error)
+	SelectEnrollments(ctx context.Context, ext repository.RepoExtension, agentID uuid.UUID) ([]*model.AgentEnrollment, error)
+}
+
+// RegionTopics создаёт топик задач региона, в который будет читать новый агент.
+type RegionTopics interface {
+	EnsureRegionTopic(ctx context.Context, region string) error
+}
+
+// EnrollmentSettings то, что агент получает при регистрации помимо своих полей из реестра.
+// TokenTTL — сколько действует выданный токен, GatewayEnabled — выдавать ли агенту собственный токен gateway.
+type EnrollmentSettings struct {
+	TokenTTL         time.Duration
+	ResultsTopic     string
+	HeartbeatTopic   string
+	Brokers          []string
+	GatewayEnabled   bool
+	BackendPublicKey ed25519.PublicKey
+}
+
+// PoolLimits сколько частных агентов и регионов пулов может быть у одного владельца, пользователя или организации.
+// Каждый новый регион пула — отдельный топик задач в Kafka.
+type PoolLimits struct {
+	MaxAgents  int
+	MaxRegions int
+}
+
+// AgentRegistryService реестр агентов: администратор заводит агента и получает одноразовый токен,
+// агент при первом запуске обменивает токен на свой id, регион, топики и ключи (Enroll).
+type AgentRegistryService struct {
+	log            *zap.Logger
+	agentRepo      AgentRegistryRepository
+	enrollmentRepo AgentEnrollmentRepository
+	events         FleetEvents
+	topics         RegionTopics
+	settings       EnrollmentSettings
+	limits         PoolLimits
+}
+
+func NewAgentRegistryService(
+	log *zap.Logger,
+	agentRepo AgentRegistryRepository,
+	enrollmentRepo AgentEnrollmentRepository,
+	events FleetEvents,
+	topics RegionTopics,
+	settings EnrollmentSettings,
+	limits PoolLimits,
+) *AgentRegistryService {
+	return &AgentRegistryService{
+		log:            log,
+		agentRepo:      agentRepo,
+		enrollmentRepo: enrollmentRepo,
+		events:         events,
+		topics:         topics,
+		settings:       settings,
+		limits:         limits,
+	}
+}
+
+// CreateAgent заводит агента и выдаёт ему токен регистрации. Агент offline до первого heartbeat.
+// access nil — агента заводит администратор: публичного или, с organizationId, частного агента организации.
+// Иначе агент частный: пользователя или его организации, регион — метка внутри пула владельца (см. model.PrivateRegion).
+func (s *AgentRegistryService) CreateAgent(ctx context.Context, req model.AgentCreateRequest, access *model.PoolAccess) (resp *model.AgentCreateResponse, err error) {
+	agent := &model.Agent{
+		ID:         uuid.New(),
+		Name:       strings.TrimSpace(req.Name),
+		Region:     strings.ToUpper(strings.TrimSpace(req.Region)),
+		Country:    strings.ToUpper(strings.TrimSpace(req.Country)),
+		ASN:        req.ASN,
+		Lat:        req.Lat,
+		Lon:        req.Lon,
+		Transport:  req.Transport,
+		Registered: true,
+	}
+
+	if agent.Transport == "" {
+		agent.Transport = contract.TransportKafka
+	}
+
+	fieldErrs := validateAgentFields(&agent.Name, &agent.Country, &agent.ASN, agent.Lat, agent.Lon)
+	fieldErrs = append(fieldErrs, validateAgentRegion(agent.Region)...)
+
+	if req.OrganizationID != nil || access != nil {
+		if access != nil && req.OrganizationID != nil && !slices.Contains(access.OrganizationIDs, *req.OrganizationID) {
+			return nil, apperrors.ErrOrganizationDoesNotExist
+		}
+
+		if req.OrganizationID != nil {
+			agent.OwnerOrgID = req.OrganizationID
+		} else {
+			agent.OwnerUserID = &access.UserID
+		}
+
+		agent.Region = model.PrivateRegion(agent.OwnerUserID, agent.OwnerOrgID, agent.Region)
+	}
+
+	if agent.Transport != contract.TransportKafka && agent.Transport != contract.TransportHTTP {
+		fieldErrs = append(fieldErrs, contract.FieldError{
+			Field:   "transport",
+			Message: fmt.Sprintf("must be %q or %q", contract.TransportKafka, contract.TransportHTTP),
+		})
+	}
+
+	if len(fieldErrs) > 0 {
+		return nil, &contract.ValidationError{Fields: fieldErrs}
+	}
+
+	if agent.IsPrivate() {
+		if err := s.checkPoolLimits(ctx, agent); err != nil {
+			return nil, err
+		}
+	}
+
+	// топик создаётся до агента: если Kafka недоступна, агента без топика не остаётся
+	if err := s.topics.EnsureRegionTopic(ctx, agent.Region); err != nil {
+		return nil, fmt.Errorf("failed to ensure region topic: %w", err)
+	}
+
+	tx, err := s.agentRepo.Pool().Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		if err != nil {
+			if rErr := tx.Rollback(ctx); rErr != nil {
+				err = fmt.Errorf("%w, failed to roll back transaction: %w", err, rErr)
+			}
+		}
+	}()
+
+	if err := s.agentRepo.InsertAgent(ctx, tx, agent); err != nil {
+		return nil, fmt.Errorf("failed to insert agent: %w", err)
+	}
+
+	token, err := s.issueToken(ctx, tx, agent.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	s.log.Info("Agent created",
+		zap.String("agent_id", agent.ID.String()),
+		zap.String("region", agent.Region),
+		zap.String("name", agent.Name),
+		zap.Bool("private", agent.IsPrivate()),
+	)
+
+	return &model.AgentCreateResponse{
+		Agent:      agent,
+		Enrollment: *token,
+	}, nil
+}
+
+// GetPoolAgents частные агенты пользователя и его организаций.
+func (s *AgentRegistryService) GetPoolAgents(ctx context.Context, access *model.PoolAccess) ([]*model.Agent, error) {
+	agents, err := s.agentRepo.SelectAgentsByOwner(ctx, nil, access.UserID, access.OrganizationIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select agents: %w", err)
+	}
+
+	if agents == nil {
+		agents = []*model.Agent{}
+	}
+
+	return agents, nil
+}
+
+// UpdateAgent меняет заданные поля агента. Выключенный агент уходит в offline и больше не получает задач.
+// Здесь и дальше access nil — администратор, иначе доступны только агенты пула пользователя.
+func (s *AgentRegistryService) UpdateAgent(ctx context.Context, id uuid.UUID, req model.AgentUpdateRequest, access *model.PoolAccess) (*model.Agent, error) {
+	if req.Name != nil {
+		*req.Name = strings.TrimSpace(*req.Name)
+	}
+
+	if req.Country != nil {
+		*req.Country = strings.ToUpper(strings.TrimSpace(*req.Country))
+	}
+
+	if fieldErrs := validateAgentFields(req.Name, req.Country, req.ASN, req.Lat, req.Lon); len(fieldErrs) > 0 {
+		return nil, &contract.ValidationError{Fields: fieldErrs}
+	}
+
+	if access != nil {
+		if _, err := s.selectAgent(ctx, id, access); err != nil {
+			return nil, err
+		}
+	}
+
+	agent, wasOnline, err := s.agentRepo.UpdateAgent(ctx, nil, id, &req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update agent: %w", err)
+	}
+
+	if req.Disabled != nil {
+		s.log.Info("Agent state changed",
+			zap.String("agent_id", id.String()),
+			zap.Bool("disabled", agent.Disabled),
+		)
+	}
+
+	if wasOnline && !agent.Online {
+		s.publish(ctx, agent)
+	}
+
+	return agent, nil
+}
+
+// DeleteAgent удаляет агента из реестра. Heartbeat и результаты удалённого агента дальше отклоняются,
+// вернуть его можно только новым агентом с новым токеном регистрации.
+func (s *AgentRegistryService) DeleteAgent(ctx context.Context, id uuid.UUID, access *model.PoolAccess) error {
+	agent, err := s.selectAgent(ctx, id, access)
+	if err != nil {
+		return err
+	}
+
+	if err := s.agentRepo.DeleteAgent(ctx, nil, id); err != nil {
+		return fmt.Errorf("failed to delete agent: %w", err)
+	}
+
+	s.log.Info("Agent deleted", zap.String("agent_id", id.String()), zap.String("region", agent.Region))
+
+	if agent.Online {
+		agent.Online = false
+		s.publish(ctx, agent)
+	}
+
+	return nil
+}
+
+// IssueEnrollmentToken новый токен для агента, например после переустановки. Прежние неиспользованные токены отзываются,
+// после регистрации по новому токену прежний ключ агента больше не принимается.
+func (s *AgentRegistryService) IssueEnrollmentToken(ctx context.Context, id uuid.UUID, access *model.PoolAccess) (token *model.AgentEnrollmentToken, err error) {
+	agent, err := s.selectAgent(ctx, id, access)
+	if err != nil {
+		return nil, err
+	}
+
+	if agent.Disabled {
+		return nil, apperrors.ErrAgentDisabled
+	}
+
+	tx, err := s.agentRepo.Pool().Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		if err != nil {
+			if rErr := tx.Rollback(ctx); rErr != nil {
+				err = fmt.Errorf("%w, failed to roll back transaction: %w", err, rErr)
+			}
+		}
+	}()
+
+	if err := s.enrollmentRepo.ExpireUnusedEnrollments(ctx, tx, id); err != nil {
+		return nil, fmt.Errorf("failed to expire enrollments: %w", err)
+	}
+
+	token, err = s.issueToken(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return token, nil
+}
+
+// GetEnrollments выданные агенту токены и кто и когда ими воспользовался.
+func (s *AgentRegistryService) GetEnrollments(ctx context.Context, id uuid.UUID, access *model.PoolAccess) ([]*model.AgentEnrollment, error) {
+	if _, err := s.selectAgent(ctx, id, access); err != nil {
+		return nil, err
+	}
+
+	enrollments, err := s.enrollmentRepo.SelectEnrollments(ctx, nil, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select enrollments: %w", err)
+	}
+
+	if enrollments == nil {
+		enrollments = []*model.AgentEnrollment{}
+	}
+
+	return enrollments, nil
+}
+
+// Enroll обменивает одноразовый токен на настройки агента и привязывает к агенту его ключ и, если gateway включён,
+// новый токен gateway: он есть только у этого агента, прежний токен перестаёт действовать.
+// Регистрация записывается с версией агента и IP, с которого он пришёл.
+func (s *AgentRegistryService) Enroll(ctx context.Context, req contract.EnrollRequest, ip string) (resp *contract.EnrollResponse, err error) {
+	if err := contract.CheckVersion(req.Version); err != nil {
+		return nil, &contract.ValidationError{Fields: []contract.FieldError{{Field: "version", Message: err.Error()}}}
+	}
+
+	var fieldErrs []contract.FieldError
+
+	if req.Token == "" {
+		fieldErrs = append(fieldErrs, contract.FieldError{Field: "token", Message: "must not be empty"})
+	}
+
+	if len(req.PublicKey) != ed25519.PublicKeySize {
+		fieldErrs = append(fieldErrs, contract.FieldError{Field: "publicKey", Message: fmt.Sprintf("must be a %d byte Ed25519 public key", ed25519.PublicKeySize)})
+	}
+
+	if len(fieldErrs) > 0 {
+		return nil, &contract.ValidationError{Fields: fieldErrs}
+	}
+
+	tx, err := s.agentRepo.Pool().Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		if err != nil {
+			if rErr := tx.Rollback(ctx); rErr != nil {
+				err = fmt.Errorf("%w, failed to roll back transaction: %w", err, rErr)
+			}
+		}
+	}()
+
+	enrollment, err := s.enrollmentRepo.ClaimEnrollment(ctx, tx, hashAgentToken(req.Token), req.AgentVersion, ip)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim enrollment: %w", err)
+	}
+
+	agent, err := s.agentRepo.SelectAgentByID(ctx, tx, enrollment.AgentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select agent: %w", err)
+	}
+
+	// токен выключенного агента не тратится: откат вернёт его, когда агента включат
+	if agent.Disabled {
+		return nil, apperrors.ErrAgentDisabled
+	}
+
+	var (
+		gatewayToken     string
+		gatewayTokenHash []byte
+	)
+
+	if s.settings.GatewayEnabled {
+		gatewayToken, err = newAgentToken()
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate gateway token: %w", err)
+		}
+
+		gatewayTokenHash = hashAgentToken(gatewayToken)
+	}
+
+	if err := s.agentRepo.UpdateAgentCredentials(ctx, tx, agent.ID, req.PublicKey, gatewayTokenHash); err != nil {
+		return nil, fmt.Errorf("failed to update agent credentials: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	s.log.Info("Agent enrolled",
+		zap.String("agent_id", agent.ID.String()),
+		zap.String("region", agent.Region),
+		zap.String("agent_version", req.AgentVersion),
+		zap.String("ip", ip),
+	)
+
+	resp = &contract.EnrollResponse{
+		AgentID:   agent.ID,
+		Region:    agent.Region,
+		Country:   agent.Country,
+		ASN:       agent.ASN,
+		Transport: agent.Transport,
+		Topics: contract.EnrollTopics{
+			Tasks:     regionTopic(agent.Region),
+			Results:   s.settings.ResultsTopic,
+			Heartbeat: s.settings.HeartbeatTopic,
+		},
+		GatewayToken:     gatewayToken,
+		BackendPublicKey: s.settings.BackendPublicKey,
+	}
+
+	if agent.Transport == contract.TransportKafka {
+		resp.Brokers = s.settings.Brokers
+	}
+
+	if agent.Lat != nil && agent.Lon != nil {
+		resp.Location = &contract.Location{Lat: *agent.Lat, Lon: *agent.Lon}
+	}
+
+	return resp, nil
+}
+
+// checkPoolLimits у владельца частного агента есть место для ещё одного агента, а если регион новый — и для нового региона.
+func (s *AgentRegistryService) checkPoolLimits(ctx context.Context, agent *model.Agent) error {
+	agents, regions, err := s.agentRepo.SelectPoolUsage(ctx, nil, agent.OwnerUserID, agent.OwnerOrgID)
+	if err != nil {
+		return fmt.Errorf("failed to select pool usage: %w", err)
+	}
+
+	if agents >= s.limits.MaxAgents {
+		return fmt.Errorf("%w: at most %d agents per owner", apperrors.ErrAgentLimitExceeded, s.limits.MaxAgents)
+	}
+
+	if !slices.Contains(regions, agent.Region) && len(regions) >= s.limits.MaxRegions {
+		return fmt.Errorf("%w: at most %d pool regions per owner", apperrors.ErrAgentPoolLimitExceeded, s.limits.MaxRegions)
+	}
+
+	return nil
+}
+
+// selectAgent агент по id; агент вне пула пользователя для него не существует.
+func (s *AgentRegistryService) selectAgent(ctx context.Context, id uuid.UUID, access *model.PoolAccess) (*model.Agent, error) {
+	agent, err := s.agentRepo.SelectAgentByID(ctx, nil, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select agent: %w", err)
+	}
+
+	if access != nil && !access.Owns(agent) {
+		return nil, apperrors.ErrAgentDoesNotExist
+	}
+
+	return agent, nil
+}
+
+func (s *AgentRegistryService) issueToken(ctx context.Context, tx repository.RepoExtension, agentID uuid.UUID) (*model.AgentEnrollmentToken, error) {
+	token, err := newAgentToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate enrollment token: %w", err)
+	}
+
+	enrollment := &model.AgentEnrollment{
+		ID:        uuid.New(),
+		AgentID:   agentID,
+		ExpiresAt: time.Now().Add(s.settings.TokenTTL),
+	}
+
+	if err := s.enrollmentRepo.InsertEnrollment(ctx, tx, enrollment, hashAgentToken(token)); err != nil {
+		return nil, fmt.Errorf("failed to insert enrollment: %w", err)
+	}
+
+	return &model.AgentEnrollmentToken{
+		AgentID:   agentID,
+		Token:     token,
+		ExpiresAt: enrollment.ExpiresAt,
+	}, nil
+}
+
+// publish рассылка не критична, см. AgentService.publish.
+func (s *AgentRegistryService) publish(ctx context.Context, agent *model.Agent) {
+	if err := s.events.Publish(ctx, model.StreamEvent{
+		Topic: model.TopicFleet,
+		Type:  model.StreamEventAgentOffline,
+		Agent: agent,
+	}); err != nil {
+		s.log.Warn("Failed to publish fleet event", zap.String("agent_id", agent.ID.String()), zap.Error(err))
+	}
+}
+
+// newAgentToken случайный токен агента: регистрации или gateway.
+func newAgentToken() (string, error) {
+	raw := make([]byte, agentTokenBytes)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+// hashAgentToken токен случайный и длинный, поэтому хватает SHA-256 без соли: по хешу токен ищется в БД.
+func hashAgentToken(token string) []byte {
+	sum := sha256.Sum256([]byte(token))
+
+	return sum[:]
+}
+
+// validateAgentRegion проверяет регион агента в верхнем регистре. Регион становится частью имени топика задач,
+// поэтому и публичный регион, и метка частного пула — только латинские буквы, цифры и _.
+func validateAgentRegion(region string) []contract.FieldError {
+	switch {
+	case region == "":
+		return []contract.FieldError{{Field: "region", Message: "must not be empty"}}
+	case model.IsPrivateRegion(region):
+		return []contract.FieldError{{
+			Field:   "region",
+			Message: fmt.Sprintf("prefixes %q and %q are reserved for private agent pools", model.PrivateRegionUserPrefix, model.PrivateRegionOrgPrefix),
+		}}
+	case len(region) > maxPoolRegionLength || strings.Trim(region, "ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789_") != "":
+		return []contract.FieldError{{
+			Field:   "region",
+			Message: fmt.Sprintf("must be up to %d latin letters, digits or underscores", maxPoolRegionLength),
+		}}
+	}
+
+	return nil
+}
+
+// validateAgentFields проверяет поля агента реестра, nil — поле не задано.
+func validateAgentFields(name, country *string, asn *int, lat, lon *float64) []contract.FieldError {
+	var fieldErrs []contract.FieldError
+
+	if name != nil && len(*name) > maxAgentNameLength {
+		fieldErrs = append(fieldErrs, contract.FieldError{Field: "name", Message: fmt.Sprintf("must be at most %d characters", maxAgentNameLength)})
+	}
+
+	if country != nil && *country != "" && (len(*country) != 2 || strings.Trim(*country, "ABCDEFGHIJKLMNOPQRSTUVWXYZ") != "") {
+		fieldErrs = append(fieldErrs, contract.FieldError{Field: "country", Message: "must be an ISO 3166-1 alpha-2 code"})
+	}
+
+	if asn != nil && *asn < 0 {
+		fieldErrs = append(fieldErrs, contract.FieldError{Field: "asn", Message: "must not be negative"})
+	}
+
+	if (lat == nil) != (lon == nil) {
+		fieldErrs = append(fieldErrs, contract.FieldError{Field: "lat", Message: "lat and lon must be set together"})
+	} else if lat != nil && agentLocation(&contract.Location{Lat: *lat, Lon: *lon}) == nil {
+		fieldErrs = append(fieldErrs, contract.FieldError{Field: "lat", Message: "lat must be within [-90, 90] and lon within [-180, 180]"})
+	}
+
+	return fieldErrs
+}
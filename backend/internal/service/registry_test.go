@@ -0,0 +1,47 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"hackathon-back/internal/model"
+)
+
+func TestValidateAgentRegion(t *testing.T) {
+	userID := uuid.New()
+
+	tests := []struct {
+		name    string
+		region  string
+		wantErr string
+	}{
+		{name: "public region", region: "EU_WEST"},
+		{name: "pool label", region: "OFFICE_2"},
+		{name: "empty", region: "", wantErr: "must not be empty"},
+		{name: "reserved prefix", region: model.PrivateRegion(&userID, nil, "OFFICE"), wantErr: "reserved"},
+		{name: "hyphen", region: "EU-WEST", wantErr: "underscores"},
+		{name: "topic separator", region: "EU.WEST", wantErr: "underscores"},
+		{name: "space", region: "EU WEST", wantErr: "underscores"},
+		{name: "too long", region: strings.Repeat("A", maxPoolRegionLength+1), wantErr: "underscores"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fieldErrs := validateAgentRegion(tt.region)
+
+			if tt.wantErr == "" {
+				if len(fieldErrs) != 0 {
+					t.Fatalf("validateAgentRegion(%q) = %v, want no errors", tt.region, fieldErrs)
+				}
+
+				return
+			}
+
+			if len(fieldErrs) != 1 || fieldErrs[0].Field != "region" || !strings.Contains(fieldErrs[0].Message, tt.wantErr) {
+				t.Fatalf("validateAgentRegion(%q) = %v, want region error containing %q", tt.region, fieldErrs, tt.wantErr)
+			}
+		})
+	}
+}
@@ -3,13 +3,18 @@ package service
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"go.uber.org/zap"
+	"hackathon-contract"
 
+	"hackathon-back/internal/apperrors"
 	"hackathon-back/internal/model"
 	"hackathon-back/internal/repository"
 	"hackathon-back/pkg/geoip"
@@ -23,18 +28,34 @@ type RequestRepository interface {
 	SelectResultsByRequestID(ctx context.Context, ext repository.RepoExtension, requestID uuid.UUID) ([]model.CheckResultResponse, error)
 	InsertRequest(ctx context.Context, ext repository.RepoExtension, request *model.Request) error
 	InsertAssignment(ctx context.Context, ext repository.RepoExtension, assignment *model.Assignment) error
-	InsertCheckResult(ctx context.Context, ext repository.RepoExtension, checkResult *model.CheckResult) error
+	InsertCheckResult(ctx context.Context, ext repository.RepoExtension, checkResult *model.CheckResult) (inserted bool, err error)
+	SelectProgressByRequestID(ctx context.Context, ext repository.RepoExtension, requestID uuid.UUID) ([]model.CheckProgress, error)
+	SelectRequestStatus(ctx context.Context, ext repository.RepoExtension, requestID uuid.UUID) (string, error)
 }
 
 type OutboxRepository interface {
 	InsertMessage(ctx context.Context, ext repository.RepoExtension, message model.OutboxMessage) error
 	UpdateAsSent(ctx context.Context, ext repository.RepoExtension, messageID uuid.UUID) error
 	SelectUnsentBatch(ctx context.Context, ext repository.RepoExtension, batchSize int) ([]model.OutboxMessage, error)
+	ClaimUnsentBatch(ctx context.Context, ext repository.RepoExtension, topic string, agentID uuid.UUID, batchSize int) ([]model.OutboxMessage, error)
 }
 
 type AgentRepository interface {
 	SelectAgents(ctx context.Context, ext repository.RepoExtension) ([]*model.Agent, error)
-	SelectAgentByRegion(ctx context.Context, ext repository.RepoExtension, region string) (*model.Agent, error)
+	SelectOnlineAgents(ctx context.Context, ext repository.RepoExtension) ([]*model.Agent, error)
+	SelectAgentByID(ctx context.Context, ext repository.RepoExtension, id uuid.UUID) (*model.Agent, error)
+	UpdateHeartbeat(ctx context.Context, ext repository.RepoExtension, hb *model.AgentHeartbeat) (wasOnline bool, err error)
+	UpdateStaleAsOffline(ctx context.Context, ext repository.RepoExtension, offlineAfter time.Duration) ([]*model.Agent, error)
+}
+
+// TaskSealer подписывает задачу для агентов, см. EnvelopeService.
+type TaskSealer interface {
+	SealTask(payload []byte, timeout time.Duration) ([]byte, error)
+}
+
+// PoolAccessResolver частные пулы, доступные пользователю, см. OrganizationService.
+type PoolAccessResolver interface {
+	PoolAccess(ctx context.Context, userID uuid.UUID) (*model.PoolAccess, error)
 }
 
 type GeoIPDB interface {
@@ -46,177 +67,369 @@ type RequestService struct {
 	requestRepo RequestRepository
 	outboxRepo  OutboxRepository
 	agentRepo   AgentRepository
+	sealer      TaskSealer
 	geo         GeoIPDB
+	routers     *Routers
+	pools       PoolAccessResolver
 }
 
-func NewRequestService(log *zap.Logger, requestRepo RequestRepository, outboxRepo OutboxRepository, agentRepo AgentRepository, geo GeoIPDB) *RequestService {
+func NewRequestService(
+	log *zap.Logger,
+	requestRepo RequestRepository,
+	outboxRepo OutboxRepository,
+	agentRepo AgentRepository,
+	sealer TaskSealer,
+	geo GeoIPDB,
+	routers *Routers,
+	pools PoolAccessResolver,
+) *RequestService {
 	return &RequestService{
 		log:         log,
 		requestRepo: requestRepo,
 		outboxRepo:  outboxRepo,
 		agentRepo:   agentRepo,
+		sealer:      sealer,
 		geo:         geo,
+		routers:     routers,
+		pools:       pools,
 	}
 }
 
-func (s *RequestService) CreateRequest(ctx context.Context, req model.TaskMessageRequest, ip net.IP, ua string) (request *model.Request, error error) {
+// preparedRequest проверенный запрос, готовый к постановке в очередь, см. prepareRequest и enqueueRequest.
+type preparedRequest struct {
+	request     *model.Request
+	taskMessage *contract.TaskMessage
+	targets     *model.TargetSelector
+	access      *model.PoolAccess
+	router      Router
+	geo         geoip.GeoInfo
+}
+
+// CreateRequest userID — автор запроса, uuid.Nil для анонимного: частных агентов можно выбирать только из своих пулов.
+func (s *RequestService) CreateRequest(ctx context.Context, req model.TaskMessageRequest, ip net.IP, ua string, userID uuid.UUID) (request *model.Request, error error) {
+	prepared, err := s.prepareRequest(ctx, req, ip, ua, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	prepared.taskMessage.Metadata["origin"] = "api"
+
+	tx, err := s.requestRepo.Pool().Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		if rErr := tx.Rollback(ctx); rErr != nil {
+			err = fmt.Errorf("%w, failed to roll back transaction: %w", err, rErr)
+		}
+	}()
+
+	if err := s.enqueueRequest(ctx, tx, prepared); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("error committing transaction: %w", err)
+	}
+
+	s.logUnassigned(prepared.request)
+
+	return prepared.request, nil
+}
+
+// ValidateRequest проверяет запрос так же, как CreateRequest, но ничего не создаёт: монитор с невалидной задачей не заводится.
+func (s *RequestService) ValidateRequest(ctx context.Context, req model.TaskMessageRequest, userID uuid.UUID) error {
+	_, err := s.prepareRequest(ctx, req, nil, "", userID)
+
+	return err
+}
+
+// CreateMonitorRequest ставит в очередь очередной запуск монитора в транзакции планировщика:
+// запрос, задачи в outbox и запись о запуске фиксируются вместе. Запрос выполняется с правами владельца монитора.
+func (s *RequestService) CreateMonitorRequest(ctx context.Context, tx repository.RepoExtension, monitor *model.Monitor) (*model.Request, error) {
+	prepared, err := s.prepareRequest(ctx, monitor.Task, nil, "", monitor.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	prepared.taskMessage.Metadata["origin"] = "monitor"
+	prepared.taskMessage.Metadata["monitor_id"] = monitor.ID.String()
+
+	if err := s.enqueueRequest(ctx, tx, prepared); err != nil {
+		return nil, err
+	}
+
+	s.logUnassigned(prepared.request)
+
+	return prepared.request, nil
+}
+
+// prepareRequest проверяет параметры проверок, targets, стратегию и доступ к частным пулам и собирает задачу,
+// ip nil — запрос не от клиента (монитор), клиентский контекст остаётся пустым.
+func (s *RequestService) prepareRequest(ctx context.Context, req model.TaskMessageRequest, ip net.IP, ua string, userID uuid.UUID) (*preparedRequest, error) {
 	gi := s.geo.Lookup(ip)
 
+	var clientIP string
+	if ip != nil {
+		clientIP = ip.String()
+	}
+
 	id := uuid.New()
 
-	taskMessage := &model.TaskMessage{
+	taskMessage := &contract.TaskMessage{
+		Version:        contract.SchemaVersion,
 		ID:             id,
 		Target:         req.Target,
 		TimeoutSeconds: req.TimeoutSeconds,
-		ClientContext: model.ClientContext{
-			IP:  ip.String(),
+		ClientContext: contract.ClientContext{
+			IP:  clientIP,
 			ASN: gi.ASN,
-			Geo: model.Geo{
+			Geo: contract.Geo{
 				Region:    gi.Region,
 				Continent: gi.Continent,
 			},
 			UserAgent: ua,
 		},
-		Checks:   make([]model.CheckRequest, 0, len(req.Checks)),
-		Metadata: map[string]string{"origin": "api", "region": gi.Region},
+		Checks:   make([]contract.CheckRequest, 0, len(req.Checks)),
+		Metadata: map[string]string{"region": gi.Region},
 	}
 
 	checkTypes := make([]string, 0, len(req.Checks))
 
-	for _, check := range req.Checks {
-		taskMessage.Checks = append(taskMessage.Checks, model.CheckRequest{
-			Type:   check.Type,
-			Params: check.Params,
+	// параметры проверяются до записи в БД: с невалидными агент всё равно ничего не выполнит
+	var fieldErrs []contract.FieldError
+
+	for i, check := range req.Checks {
+		params, err := contract.NormalizeParams(check.Type, check.Params)
+		if err != nil {
+			var vErr *contract.ValidationError
+
+			switch {
+			case errors.As(err, &vErr):
+				fieldErrs = append(fieldErrs, vErr.WithPrefix(fmt.Sprintf("checks[%d].params", i)).Fields...)
+			case errors.Is(err, contract.ErrUnknownCheckType):
+				fieldErrs = append(fieldErrs, contract.FieldError{Field: fmt.Sprintf("checks[%d].type", i), Message: err.Error()})
+			default:
+				return nil, fmt.Errorf("failed to normalize check params: %w", err)
+			}
+
+			continue
+		}
+
+		checkType := strings.ToLower(check.Type)
+
+		taskMessage.Checks = append(taskMessage.Checks, contract.CheckRequest{
+			Type:   checkType,
+			Params: params,
+			Index:  &i,
 		})
 
-		checkTypes = append(checkTypes, check.Type)
+		checkTypes = append(checkTypes, checkType)
 	}
 
-	payload, err := json.Marshal(taskMessage)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal task message: %w", err)
+	targets := req.Targets
+	if targets.IsEmpty() {
+		targets = nil
+	} else {
+		fieldErrs = append(fieldErrs, normalizeTargets(targets, req.Broadcast)...)
+	}
+
+	router, ok := s.routers.Get(req.Strategy)
+	if !ok {
+		fieldErrs = append(fieldErrs, contract.FieldError{Field: "strategy", Message: "must be one of " + strings.Join(s.routers.Names(), ", ")})
+	}
+
+	if len(fieldErrs) > 0 {
+		return nil, &contract.ValidationError{Fields: fieldErrs}
+	}
+
+	var access *model.PoolAccess
+
+	if userID != uuid.Nil {
+		pool, err := s.pools.PoolAccess(ctx, userID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve pool access: %w", err)
+		}
+
+		access = pool
+	}
+
+	if err := checkPoolTargets(targets, access); err != nil {
+		return nil, err
 	}
 
-	request = &model.Request{
+	request := &model.Request{
 		ID:             id,
 		Target:         req.Target,
 		TimeoutSeconds: req.TimeoutSeconds,
 		Broadcast:      req.Broadcast,
-		ClientIP:       ip.String(),
+		Targets:        targets,
+		Strategy:       router.Name(),
+		ClientIP:       clientIP,
 		UserAgent:      ua,
 		ClientASN:      gi.ASN,
 		ClientCC:       gi.CC,
 		ClientRegion:   gi.Region,
 		// Status
 		ChecksTypes: checkTypes,
-		RequestJSON: payload,
+		// RequestJSON
 		// CreatedAt
 		// UpdatedAt
 	}
 
-	if req.Broadcast {
-		tx, err := s.requestRepo.Pool().Begin(ctx)
-		if err != nil {
-			return nil, fmt.Errorf("failed to begin transaction: %w", err)
-		}
+	return &preparedRequest{
+		request:     request,
+		taskMessage: taskMessage,
+		targets:     targets,
+		access:      access,
+		router:      router,
+		geo:         gi,
+	}, nil
+}
 
-		defer func() {
-			if rErr := tx.Rollback(ctx); rErr != nil {
-				err = fmt.Errorf("%w, failed to roll back transaction: %w", err, rErr)
-			}
-		}()
+// enqueueRequest выбирает агентов и в транзакции tx пишет запрос, задачи в outbox и назначения.
+func (s *RequestService) enqueueRequest(ctx context.Context, tx repository.RepoExtension, p *preparedRequest) error {
+	request, taskMessage, targets := p.request, p.taskMessage, p.targets
 
-		if err := s.requestRepo.InsertRequest(ctx, tx, request); err != nil {
-			return nil, fmt.Errorf("failed to insert request: %w", err)
-		}
+	payload, err := json.Marshal(taskMessage)
+	if err != nil {
+		return fmt.Errorf("failed to marshal task message: %w", err)
+	}
 
-		allAgents, err := s.agentRepo.SelectAgents(ctx, tx)
-		if err != nil {
-			return nil, fmt.Errorf("failed to select agents: %w", err)
-		}
+	request.RequestJSON = payload
 
-		for _, agent := range allAgents {
-			topic := fmt.Sprintf("%s-%s", baseProduceTopic, agent.Region)
-			outboxID := uuid.New()
+	agents, err := s.agentRepo.SelectOnlineAgents(ctx, tx)
+	if err != nil {
+		return fmt.Errorf("failed to select agents: %w", err)
+	}
 
-			outboxMessage := model.OutboxMessage{
-				ID:      outboxID,
-				Topic:   topic,
-				Payload: payload,
-			}
+	agents, err = filterPoolAgents(agents, targets, p.access)
+	if err != nil {
+		return err
+	}
 
-			assignment := &model.Assignment{
-				ID:          uuid.New(),
-				RequestID:   id,
-				AgentID:     agent.ID,
-				AgentRegion: agent.Region,
-				OutboxID:    outboxID,
-			}
+	if len(agents) == 0 {
+		return apperrors.ErrNoOnlineAgents
+	}
 
-			if err := s.outboxRepo.InsertMessage(ctx, tx, outboxMessage); err != nil {
-				return nil, fmt.Errorf("failed to insert outbox message: %w", err)
-			}
+	agents, err = p.router.Rank(ctx, agents, p.geo)
+	if err != nil {
+		return fmt.Errorf("failed to rank agents: %w", err)
+	}
 
-			if err := s.requestRepo.InsertAssignment(ctx, tx, assignment); err != nil {
-				return nil, fmt.Errorf("failed to insert assignment: %w", err)
-			}
-		}
+	var (
+		dispatches []dispatch
+		unassigned []model.UnassignedCheck
+	)
 
-		if err = tx.Commit(ctx); err != nil {
-			return nil, fmt.Errorf("error committing transaction: %w", err)
+	if targets != nil {
+		var targetErrs []contract.FieldError
+
+		dispatches, unassigned, targetErrs = planTargets(request.Target, taskMessage.Checks, agents, targets, request.Broadcast)
+		if len(targetErrs) > 0 {
+			return &contract.ValidationError{Fields: targetErrs}
 		}
 	} else {
-		tx, err := s.requestRepo.Pool().Begin(ctx)
-		if err != nil {
-			return nil, fmt.Errorf("failed to begin transaction: %w", err)
-		}
+		dispatches, unassigned = planDispatch(request.Target, taskMessage.Checks, agents, request.Broadcast)
+	}
 
-		defer func() {
-			if rErr := tx.Rollback(ctx); rErr != nil {
-				err = fmt.Errorf("%w, failed to roll back transaction: %w", err, rErr)
-			}
-		}()
+	if len(dispatches) == 0 {
+		return fmt.Errorf("%w: %s", apperrors.ErrNoCapableAgents, describeUnassigned(unassigned))
+	}
+
+	request.Unassigned = unassigned
+
+	if err := s.requestRepo.InsertRequest(ctx, tx, request); err != nil {
+		return fmt.Errorf("failed to insert request: %w", err)
+	}
+
+	for _, d := range dispatches {
+		// каждый агент получает только те проверки, которые может выполнить
+		agentTask := *taskMessage
+		agentTask.Checks = d.checks
+		agentTask.AssignmentID = uuid.New()
+		agentTask.AgentID = d.agent.ID
 
-		if err := s.requestRepo.InsertRequest(ctx, tx, request); err != nil {
-			return nil, fmt.Errorf("failed to insert request: %w", err)
+		agentPayload, err := json.Marshal(agentTask)
+		if err != nil {
+			return fmt.Errorf("failed to marshal task message: %w", err)
 		}
 
-		agent, err := s.agentRepo.SelectAgentByRegion(ctx, tx, gi.Region)
+		agentPayload, err = s.sealer.SealTask(agentPayload, time.Duration(agentTask.TimeoutSeconds)*time.Second)
 		if err != nil {
-			return nil, fmt.Errorf("failed to select agent: %w", err)
+			return err
 		}
 
-		topic := fmt.Sprintf("%s-%s", baseProduceTopic, gi.Region)
 		outboxID := uuid.New()
 
 		outboxMessage := model.OutboxMessage{
-			ID:      outboxID,
-			Topic:   topic,
-			Payload: payload,
+			ID:        outboxID,
+			Topic:     regionTopic(d.agent.Region),
+			Payload:   agentPayload,
+			Transport: d.agent.Transport,
 		}
 
 		assignment := &model.Assignment{
-			ID:          uuid.New(),
-			RequestID:   id,
-			AgentID:     agent.ID,
-			AgentRegion: agent.Region,
+			ID:          agentTask.AssignmentID,
+			RequestID:   request.ID,
+			AgentID:     d.agent.ID,
+			AgentRegion: d.agent.Region,
+			ChecksTotal: len(d.checks),
 			OutboxID:    outboxID,
 		}
 
 		if err := s.outboxRepo.InsertMessage(ctx, tx, outboxMessage); err != nil {
-			return nil, fmt.Errorf("failed to insert outbox message: %w", err)
+			return fmt.Errorf("failed to insert outbox message: %w", err)
 		}
 
 		if err := s.requestRepo.InsertAssignment(ctx, tx, assignment); err != nil {
-			return nil, fmt.Errorf("failed to insert assignment: %w", err)
+			return fmt.Errorf("failed to insert assignment: %w", err)
 		}
+	}
+
+	return nil
+}
 
-		if err = tx.Commit(ctx); err != nil {
-			return nil, fmt.Errorf("error committing transaction: %w", err)
+func (s *RequestService) logUnassigned(request *model.Request) {
+	if len(request.Unassigned) > 0 {
+		s.log.Info("Some checks have no capable agent",
+			zap.String("request_id", request.ID.String()),
+			zap.Any("unassigned", request.Unassigned),
+		)
+	}
+}
+
+// regionTopic топик задач региона: его читает каждый агент региона своей consumer group и выполняет только
+// назначенные ему задачи (TaskMessage.AgentID), а http агентам их задачи отдаёт gateway.
+func regionTopic(region string) string {
+	return fmt.Sprintf("%s-%s", baseProduceTopic, region)
+}
+
+// GetLocations регионы для targets.regions: публичные и частных пулов пользователя, uuid.Nil — только публичные.
+func (s *RequestService) GetLocations(ctx context.Context, userID uuid.UUID) ([]*model.Location, error) {
+	var access *model.PoolAccess
+
+	if userID != uuid.Nil {
+		var err error
+
+		access, err = s.pools.PoolAccess(ctx, userID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve pool access: %w", err)
 		}
 	}
 
-	return request, nil
+	agents, err := s.agentRepo.SelectAgents(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select agents: %w", err)
+	}
+
+	result := locations(agents, access)
+	if result == nil {
+		result = []*model.Location{}
+	}
+
+	return result, nil
 }
 
 func (s *RequestService) GetResultsByRequestID(ctx context.Context, requestID uuid.UUID) ([]model.CheckResultResponse, error) {
@@ -227,3 +440,54 @@ func (s *RequestService) GetResultsByRequestID(ctx context.Context, requestID uu
 
 	return results, nil
 }
+
+// GetAgentResultsByRequestID результаты запроса по агентам, для broadcast — отдельно по каждому агенту и региону.
+func (s *RequestService) GetAgentResultsByRequestID(ctx context.Context, requestID uuid.UUID) ([]model.AgentResults, error) {
+	results, err := s.requestRepo.SelectResultsByRequestID(ctx, nil, requestID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select results: %w", err)
+	}
+
+	return groupResultsByAgent(results), nil
+}
+
+// groupResultsByAgent сохраняет порядок результатов (регион, агент, номер проверки).
+func groupResultsByAgent(results []model.CheckResultResponse) []model.AgentResults {
+	groups := make([]model.AgentResults, 0)
+	byAgent := make(map[uuid.UUID]int)
+
+	for _, res := range results {
+		idx, ok := byAgent[res.AgentID]
+		if !ok {
+			idx = len(groups)
+			byAgent[res.AgentID] = idx
+			groups = append(groups, model.AgentResults{
+				AgentID:     res.AgentID,
+				AgentRegion: res.AgentRegion,
+			})
+		}
+
+		groups[idx].Results = append(groups[idx].Results, res)
+	}
+
+	return groups
+}
+
+func (s *RequestService) GetProgressByRequestID(ctx context.Context, requestID uuid.UUID) ([]model.CheckProgress, error) {
+	progress, err := s.requestRepo.SelectProgressByRequestID(ctx, nil, requestID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select progress: %w", err)
+	}
+
+	return progress, nil
+}
+
+// GetRequestStatus статус запроса, см. LifecycleService.
+func (s *RequestService) GetRequestStatus(ctx context.Context, requestID uuid.UUID) (string, error) {
+	status, err := s.requestRepo.SelectRequestStatus(ctx, nil, requestID)
+	if err != nil {
+		return "", fmt.Errorf("failed to select request status: %w", err)
+	}
+
+	return status, nil
+}
@@ -0,0 +1,248 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+
+	"hackathon-back/internal/model"
+	"hackathon-back/internal/repository"
+	"hackathon-back/pkg/geoip"
+)
+
+const (
+	StrategyGeo         = "geo"          // StrategyGeo ближайший к клиенту агент
+	StrategyRoundRobin  = "round_robin"  // StrategyRoundRobin агенты по очереди
+	StrategyLeastLoaded = "least_loaded" // StrategyLeastLoaded агент с самой короткой очередью по heartbeat
+	StrategyLatency     = "latency"      // StrategyLatency агент с минимальным RTT до сети клиента
+)
+
+// Router стратегия выбора агентов. Rank возвращает online агентов в порядке, в котором они пробуются
+// для запроса клиента: planDispatch и planTargets берут первых подходящих. Срез agents не меняется.
+type Router interface {
+	Name() string
+	Rank(ctx context.Context, agents []*model.Agent, client geoip.GeoInfo) ([]*model.Agent, error)
+}
+
+// Routers стратегии по имени: запрос выбирает свою полем strategy, без него берётся стратегия по умолчанию.
+type Routers struct {
+	byName      map[string]Router
+	defaultName string
+}
+
+func NewRouters(defaultName string, routers ...Router) (*Routers, error) {
+	r := &Routers{
+		byName:      make(map[string]Router, len(routers)),
+		defaultName: defaultName,
+	}
+
+	for _, router := range routers {
+		r.byName[router.Name()] = router
+	}
+
+	if _, ok := r.byName[defaultName]; !ok {
+		return nil, fmt.Errorf("unknown default routing strategy %q, expected one of %s", defaultName, strings.Join(r.Names(), ", "))
+	}
+
+	return r, nil
+}
+
+// Get стратегия по имени, пустое имя — стратегия по умолчанию.
+func (r *Routers) Get(name string) (Router, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		name = r.defaultName
+	}
+
+	router, ok := r.byName[name]
+
+	return router, ok
+}
+
+func (r *Routers) Names() []string {
+	names := make([]string, 0, len(r.byName))
+	for name := range r.byName {
+		names = append(names, name)
+	}
+
+	slices.Sort(names)
+
+	return names
+}
+
+// GeoRouter регионы идут по расстоянию по большому кругу от клиента (GeoIP City) до ближайшего агента региона
+// (координаты из heartbeat), внутри региона агенты идут по загрузке, равные — в случайном порядке, чтобы запросы
+// делились между ними. Агент без координат в регионе клиента считается ближайшим, в других регионах — дальше всех;
+// без координат клиента первым идёт его регион. Когда ближайший агент уходит в offline, запрос получает следующий по расстоянию.
+type GeoRouter struct{}
+
+func NewGeoRouter() *GeoRouter {
+	return &GeoRouter{}
+}
+
+func (*GeoRouter) Name() string {
+	return StrategyGeo
+}
+
+func (*GeoRouter) Rank(_ context.Context, agents []*model.Agent, client geoip.GeoInfo) ([]*model.Agent, error) {
+	ranked := shuffled(agents)
+
+	regionDistance := make(map[string]float64)
+
+	for _, agent := range ranked {
+		distance := math.Inf(1)
+
+		switch {
+		case client.HasLocation && agent.Lat != nil && agent.Lon != nil:
+			distance = geoip.Distance(client.Lat, client.Lon, *agent.Lat, *agent.Lon)
+		case agent.Region == client.Region:
+			distance = 0
+		}
+
+		if current, ok := regionDistance[agent.Region]; !ok || distance < current {
+			regionDistance[agent.Region] = distance
+		}
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		di, dj := regionDistance[ranked[i].Region], regionDistance[ranked[j].Region]
+		if di != dj {
+			return di < dj
+		}
+
+		if ranked[i].Region != ranked[j].Region {
+			return ranked[i].Region < ranked[j].Region
+		}
+
+		return agentLoad(ranked[i]) < agentLoad(ranked[j])
+	})
+
+	return ranked, nil
+}
+
+// RoundRobinRouter каждый следующий запрос начинает с агента, следующего за первым агентом прошлого запроса.
+// Счётчик свой у каждой реплики бэкенда, поэтому очередь общая только в пределах реплики.
+type RoundRobinRouter struct {
+	next atomic.Uint64
+}
+
+func NewRoundRobinRouter() *RoundRobinRouter {
+	return &RoundRobinRouter{}
+}
+
+func (*RoundRobinRouter) Name() string {
+	return StrategyRoundRobin
+}
+
+func (r *RoundRobinRouter) Rank(_ context.Context, agents []*model.Agent, _ geoip.GeoInfo) ([]*model.Agent, error) {
+	if len(agents) == 0 {
+		return nil, nil
+	}
+
+	sorted := slices.Clone(agents)
+	slices.SortFunc(sorted, func(a, b *model.Agent) int {
+		return strings.Compare(a.ID.String(), b.ID.String())
+	})
+
+	start := int((r.next.Add(1) - 1) % uint64(len(sorted)))
+
+	return append(sorted[start:], sorted[:start]...), nil
+}
+
+// LeastLoadedRouter агенты по длине очереди из последнего heartbeat, затем по числу выполняемых задач,
+// равные — в случайном порядке.
+type LeastLoadedRouter struct{}
+
+func NewLeastLoadedRouter() *LeastLoadedRouter {
+	return &LeastLoadedRouter{}
+}
+
+func (*LeastLoadedRouter) Name() string {
+	return StrategyLeastLoaded
+}
+
+func (*LeastLoadedRouter) Rank(_ context.Context, agents []*model.Agent, _ geoip.GeoInfo) ([]*model.Agent, error) {
+	ranked := shuffled(agents)
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if ranked[i].QueueDepth != ranked[j].QueueDepth {
+			return ranked[i].QueueDepth < ranked[j].QueueDepth
+		}
+
+		return ranked[i].InFlight < ranked[j].InFlight
+	})
+
+	return ranked, nil
+}
+
+// LatencyStats измерения RTT от агентов до сетей, см. repository.AgentRepository.SelectAgentLatencies.
+type LatencyStats interface {
+	SelectAgentLatencies(ctx context.Context, ext repository.RepoExtension, asn int, window time.Duration) (map[uuid.UUID]float64, error)
+}
+
+// LatencyRouter первыми идут агенты с минимальным RTT ping до адресов из ASN клиента за последние window,
+// остальные — в порядке fallback. Без ASN клиента или без измерений порядок целиком берётся у fallback.
+type LatencyRouter struct {
+	stats    LatencyStats
+	window   time.Duration
+	fallback Router
+}
+
+func NewLatencyRouter(stats LatencyStats, window time.Duration, fallback Router) *LatencyRouter {
+	return &LatencyRouter{
+		stats:    stats,
+		window:   window,
+		fallback: fallback,
+	}
+}
+
+func (*LatencyRouter) Name() string {
+	return StrategyLatency
+}
+
+func (r *LatencyRouter) Rank(ctx context.Context, agents []*model.Agent, client geoip.GeoInfo) ([]*model.Agent, error) {
+	ranked, err := r.fallback.Rank(ctx, agents, client)
+	if err != nil {
+		return nil, err
+	}
+
+	if client.ASN == 0 {
+		return ranked, nil
+	}
+
+	latencies, err := r.stats.SelectAgentLatencies(ctx, nil, client.ASN, r.window)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select agent latencies: %w", err)
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		li, okI := latencies[ranked[i].ID]
+		lj, okJ := latencies[ranked[j].ID]
+
+		if okI != okJ {
+			return okI
+		}
+
+		return okI && li < lj
+	})
+
+	return ranked, nil
+}
+
+func shuffled(agents []*model.Agent) []*model.Agent {
+	out := slices.Clone(agents)
+
+	rand.Shuffle(len(out), func(i, j int) {
+		out[i], out[j] = out[j], out[i]
+	})
+
+	return out
+}
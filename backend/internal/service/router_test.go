@@ -0,0 +1,268 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"hackathon-back/internal/model"
+	"hackathon-back/internal/repository"
+	"hackathon-back/pkg/geoip"
+)
+
+func testAgent(name, region string, queueDepth, inFlight int) *model.Agent {
+	return &model.Agent{
+		ID:         uuid.NewSHA1(uuid.NameSpaceOID, []byte(name)),
+		Name:       name,
+		Region:     region,
+		QueueDepth: queueDepth,
+		InFlight:   inFlight,
+	}
+}
+
+func withLocation(agent *model.Agent, lat, lon float64) *model.Agent {
+	agent.Lat = &lat
+	agent.Lon = &lon
+
+	return agent
+}
+
+func agentNames(agents []*model.Agent) []string {
+	names := make([]string, 0, len(agents))
+	for _, agent := range agents {
+		names = append(names, agent.Name)
+	}
+
+	return names
+}
+
+func assertOrder(t *testing.T, got []*model.Agent, want ...string) {
+	t.Helper()
+
+	names := agentNames(got)
+	if len(names) != len(want) {
+		t.Fatalf("got %v, want %v", names, want)
+	}
+
+	for i := range want {
+		if names[i] != want[i] {
+			t.Fatalf("got %v, want %v", names, want)
+		}
+	}
+}
+
+func TestGeoRouterRanksRegionsByDistance(t *testing.T) {
+	agents := []*model.Agent{
+		withLocation(testAgent("tokyo", "ASIA", 0, 0), 35.68, 139.69),
+		withLocation(testAgent("new-york", "NA", 0, 0), 40.71, -74.01),
+		withLocation(testAgent("berlin-busy", "EU", 5, 0), 52.52, 13.40),
+		testAgent("eu-no-location", "EU", 1, 0),
+		testAgent("sa-no-location", "SA", 0, 0),
+	}
+
+	// Франкфурт: ближе всего EU, затем NA, затем ASIA; регион без координат — последним
+	client := geoip.GeoInfo{Region: "EU", Lat: 50.11, Lon: 8.68, HasLocation: true}
+
+	for range 20 {
+		ranked, err := NewGeoRouter().Rank(context.Background(), agents, client)
+		if err != nil {
+			t.Fatalf("Rank: %v", err)
+		}
+
+		assertOrder(t, ranked, "eu-no-location", "berlin-busy", "new-york", "tokyo", "sa-no-location")
+	}
+}
+
+func TestGeoRouterWithoutClientLocationPrefersClientRegion(t *testing.T) {
+	agents := []*model.Agent{
+		withLocation(testAgent("na", "NA", 0, 0), 40.71, -74.01),
+		withLocation(testAgent("asia", "ASIA", 0, 0), 35.68, 139.69),
+		withLocation(testAgent("eu", "EU", 0, 0), 52.52, 13.40),
+	}
+
+	ranked, err := NewGeoRouter().Rank(context.Background(), agents, geoip.GeoInfo{Region: "ASIA"})
+	if err != nil {
+		t.Fatalf("Rank: %v", err)
+	}
+
+	if ranked[0].Name != "asia" {
+		t.Fatalf("got %v, want asia first", agentNames(ranked))
+	}
+}
+
+func TestGeoRouterDoesNotModifyInput(t *testing.T) {
+	agents := []*model.Agent{
+		testAgent("a", "NA", 0, 0),
+		testAgent("b", "EU", 0, 0),
+	}
+
+	if _, err := NewGeoRouter().Rank(context.Background(), agents, geoip.GeoInfo{Region: "EU"}); err != nil {
+		t.Fatalf("Rank: %v", err)
+	}
+
+	assertOrder(t, agents, "a", "b")
+}
+
+func TestRoundRobinRouterRotatesStart(t *testing.T) {
+	agents := []*model.Agent{
+		testAgent("a", "EU", 0, 0),
+		testAgent("b", "EU", 0, 0),
+		testAgent("c", "EU", 0, 0),
+	}
+
+	router := NewRoundRobinRouter()
+
+	first, err := router.Rank(context.Background(), agents, geoip.GeoInfo{})
+	if err != nil {
+		t.Fatalf("Rank: %v", err)
+	}
+
+	order := agentNames(first)
+
+	for call := 1; call <= len(agents); call++ {
+		ranked, err := router.Rank(context.Background(), agents, geoip.GeoInfo{})
+		if err != nil {
+			t.Fatalf("Rank: %v", err)
+		}
+
+		want := make([]string, 0, len(order))
+		for i := range order {
+			want = append(want, order[(i+call)%len(order)])
+		}
+
+		assertOrder(t, ranked, want...)
+	}
+}
+
+func TestRoundRobinRouterEmpty(t *testing.T) {
+	ranked, err := NewRoundRobinRouter().Rank(context.Background(), nil, geoip.GeoInfo{})
+	if err != nil {
+		t.Fatalf("Rank: %v", err)
+	}
+
+	if len(ranked) != 0 {
+		t.Fatalf("got %v, want no agents", agentNames(ranked))
+	}
+}
+
+func TestLeastLoadedRouterOrdersByQueueThenInFlight(t *testing.T) {
+	agents := []*model.Agent{
+		testAgent("long-queue", "EU", 9, 0),
+		testAgent("busy", "EU", 1, 7),
+		testAgent("idle", "EU", 0, 0),
+		testAgent("short-queue", "EU", 1, 2),
+	}
+
+	ranked, err := NewLeastLoadedRouter().Rank(context.Background(), agents, geoip.GeoInfo{})
+	if err != nil {
+		t.Fatalf("Rank: %v", err)
+	}
+
+	assertOrder(t, ranked, "idle", "short-queue", "busy", "long-queue")
+}
+
+type fakeLatencyStats struct {
+	latencies map[uuid.UUID]float64
+	err       error
+	calls     int
+	asn       int
+	window    time.Duration
+}
+
+func (f *fakeLatencyStats) SelectAgentLatencies(_ context.Context, _ repository.RepoExtension, asn int, window time.Duration) (map[uuid.UUID]float64, error) {
+	f.calls++
+	f.asn = asn
+	f.window = window
+
+	return f.latencies, f.err
+}
+
+func TestLatencyRouterPrefersMeasuredAgents(t *testing.T) {
+	slow := testAgent("slow", "EU", 3, 0)
+	fast := testAgent("fast", "EU", 4, 0)
+	idle := testAgent("idle", "EU", 0, 0)
+	busy := testAgent("busy", "EU", 9, 0)
+
+	stats := &fakeLatencyStats{latencies: map[uuid.UUID]float64{
+		slow.ID: 80,
+		fast.ID: 12.5,
+	}}
+
+	router := NewLatencyRouter(stats, time.Hour, NewLeastLoadedRouter())
+
+	ranked, err := router.Rank(context.Background(), []*model.Agent{busy, slow, idle, fast}, geoip.GeoInfo{ASN: 3320})
+	if err != nil {
+		t.Fatalf("Rank: %v", err)
+	}
+
+	// без измерений агенты идут в порядке fallback
+	assertOrder(t, ranked, "fast", "slow", "idle", "busy")
+
+	if stats.asn != 3320 || stats.window != time.Hour {
+		t.Fatalf("stats queried with asn %d window %s", stats.asn, stats.window)
+	}
+}
+
+func TestLatencyRouterWithoutClientASNUsesFallback(t *testing.T) {
+	stats := &fakeLatencyStats{}
+	router := NewLatencyRouter(stats, time.Hour, NewLeastLoadedRouter())
+
+	ranked, err := router.Rank(context.Background(), []*model.Agent{
+		testAgent("busy", "EU", 2, 0),
+		testAgent("idle", "EU", 0, 0),
+	}, geoip.GeoInfo{})
+	if err != nil {
+		t.Fatalf("Rank: %v", err)
+	}
+
+	assertOrder(t, ranked, "idle", "busy")
+
+	if stats.calls != 0 {
+		t.Fatalf("stats queried %d times without client ASN", stats.calls)
+	}
+}
+
+func TestLatencyRouterStatsError(t *testing.T) {
+	errStats := errors.New("stats unavailable")
+	router := NewLatencyRouter(&fakeLatencyStats{err: errStats}, time.Hour, NewLeastLoadedRouter())
+
+	_, err := router.Rank(context.Background(), []*model.Agent{testAgent("a", "EU", 0, 0)}, geoip.GeoInfo{ASN: 1})
+	if !errors.Is(err, errStats) {
+		t.Fatalf("got %v, want %v", err, errStats)
+	}
+}
+
+func TestRouters(t *testing.T) {
+	if _, err := NewRouters("unknown", NewGeoRouter()); err == nil {
+		t.Fatal("expected error for unknown default strategy")
+	}
+
+	routers, err := NewRouters(StrategyGeo, NewGeoRouter(), NewLeastLoadedRouter())
+	if err != nil {
+		t.Fatalf("NewRouters: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		want string
+		ok   bool
+	}{
+		{name: "", want: StrategyGeo, ok: true},
+		{name: " Least_Loaded ", want: StrategyLeastLoaded, ok: true},
+		{name: StrategyRoundRobin, ok: false},
+	}
+
+	for _, tt := range tests {
+		router, ok := routers.Get(tt.name)
+		if ok != tt.ok {
+			t.Fatalf("Get(%q) ok = %v, want %v", tt.name, ok, tt.ok)
+		}
+
+		if ok && router.Name() != tt.want {
+			t.Fatalf("Get(%q) = %s, want %s", tt.name, router.Name(), tt.want)
+		}
+	}
+}
@@ -0,0 +1,307 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"hackathon-contract"
+
+	"hackathon-back/internal/model"
+)
+
+// targetSlot значение селектора, которому нужен свой агент.
+type targetSlot struct {
+	field string // field поле запроса для ошибки, например targets.countries[1]
+	match func(agent *model.Agent) bool
+}
+
+// normalizeTargets приводит селектор к каноническому виду (регионы и страны в верхнем регистре, без повторов)
+// и проверяет его до записи в БД.
+func normalizeTargets(t *model.TargetSelector, broadcast bool) []contract.FieldError {
+	var fieldErrs []contract.FieldError
+
+	fail := func(field, msg string) {
+		fieldErrs = append(fieldErrs, contract.FieldError{Field: field, Message: msg})
+	}
+
+	t.Regions = normalizeCodes(t.Regions)
+	for i, region := range t.Regions {
+		if region == "" {
+			fail(fmt.Sprintf("targets.regions[%d]", i), "must not be empty")
+		}
+	}
+
+	t.Countries = normalizeCodes(t.Countries)
+	for i, country := range t.Countries {
+		if len(country) != 2 || strings.Trim(country, "ABCDEFGHIJKLMNOPQRSTUVWXYZ") != "" {
+			fail(fmt.Sprintf("targets.countries[%d]", i), "must be an ISO 3166-1 alpha-2 code")
+		}
+	}
+
+	t.ASNs = dedupe(t.ASNs)
+	for i, asn := range t.ASNs {
+		if asn <= 0 {
+			fail(fmt.Sprintf("targets.asns[%d]", i), "must be positive")
+		}
+	}
+
+	t.AgentIDs = dedupe(t.AgentIDs)
+	for i, id := range t.AgentIDs {
+		if id == uuid.Nil {
+			fail(fmt.Sprintf("targets.agentIds[%d]", i), "must not be nil uuid")
+		}
+	}
+
+	switch {
+	case t.Spread < 0:
+		fail("targets.spread", "must not be negative")
+	case t.Spread > 0 && broadcast:
+		fail("targets.spread", "cannot be combined with broadcast")
+	case t.Spread > 0 && len(t.AgentIDs) > 0:
+		fail("targets.spread", "cannot be combined with agentIds")
+	}
+
+	return fieldErrs
+}
+
+// planTargets распределяет проверки по агентам, подходящим под селектор, агенты — в порядке Router.Rank.
+// broadcast: каждый подходящий агент получает все проверки, которые может выполнить.
+// Иначе каждое значение самого точного заданного поля (или каждый из spread первых по стратегии регионов) получает одного агента:
+// первого в порядке стратегии, кто может выполнить все проверки (см. pickAgent).
+// Если какому-то значению агента не нашлось, третьим значением возвращается причина по каждому такому значению.
+func planTargets(
+	target string,
+	checks []contract.CheckRequest,
+	agents []*model.Agent,
+	sel *model.TargetSelector,
+	broadcast bool,
+) ([]dispatch, []model.UnassignedCheck, []contract.FieldError) {
+	var matched []*model.Agent
+
+	for _, agent := range agents {
+		if sel.Matches(agent) {
+			matched = append(matched, agent)
+		}
+	}
+
+	if broadcast {
+		if len(matched) == 0 {
+			return nil, nil, []contract.FieldError{{Field: "targets", Message: "no online agent matches the selector"}}
+		}
+
+		dispatches, unassigned := planBroadcast(target, checks, matched)
+		if len(dispatches) == 0 {
+			return nil, nil, []contract.FieldError{{Field: "targets", Message: "no matching agent can run any of the checks: " + describeUnassigned(unassigned)}}
+		}
+
+		return dispatches, unassigned, nil
+	}
+
+	var (
+		dispatches []dispatch
+		fieldErrs  []contract.FieldError
+		picked     = make(map[*model.Agent]struct{})
+	)
+
+	if sel.Spread > 0 {
+		dispatches, fieldErrs = planSpread(target, checks, matched, sel.Spread)
+	} else {
+		for _, slot := range targetSlots(sel) {
+			var candidates []*model.Agent
+
+			for _, agent := range matched {
+				if _, ok := picked[agent]; !ok && slot.match(agent) {
+					candidates = append(candidates, agent)
+				}
+			}
+
+			d, err := pickAgent(target, checks, candidates)
+			if err != nil {
+				fieldErrs = append(fieldErrs, contract.FieldError{Field: slot.field, Message: err.Error()})
+
+				continue
+			}
+
+			picked[d.agent] = struct{}{}
+			dispatches = append(dispatches, d)
+		}
+	}
+
+	if len(fieldErrs) > 0 {
+		return nil, nil, fieldErrs
+	}
+
+	return dispatches, unassignedChecks(target, checks, dispatches, matched), nil
+}
+
+// targetSlots значения самого точного заданного поля селектора, остальные поля — фильтр в Matches.
+func targetSlots(sel *model.TargetSelector) []targetSlot {
+	var slots []targetSlot
+
+	switch {
+	case len(sel.AgentIDs) > 0:
+		for i, id := range sel.AgentIDs {
+			slots = append(slots, targetSlot{
+				field: fmt.Sprintf("targets.agentIds[%d]", i),
+				match: func(agent *model.Agent) bool { return agent.ID == id },
+			})
+		}
+	case len(sel.Countries) > 0:
+		for i, country := range sel.Countries {
+			slots = append(slots, targetSlot{
+				field: fmt.Sprintf("targets.countries[%d]", i),
+				match: func(agent *model.Agent) bool { return agent.Country == country },
+			})
+		}
+	case len(sel.ASNs) > 0:
+		for i, asn := range sel.ASNs {
+			slots = append(slots, targetSlot{
+				field: fmt.Sprintf("targets.asns[%d]", i),
+				match: func(agent *model.Agent) bool { return agent.ASN == asn },
+			})
+		}
+	default:
+		for i, region := range sel.Regions {
+			slots = append(slots, targetSlot{
+				field: fmt.Sprintf("targets.regions[%d]", i),
+				match: func(agent *model.Agent) bool { return agent.Region == region },
+			})
+		}
+	}
+
+	return slots
+}
+
+// planSpread по одному агенту из n разных регионов, регионы в порядке стратегии (для geo — ближайшие к клиенту первыми).
+func planSpread(target string, checks []contract.CheckRequest, agents []*model.Agent, n int) ([]dispatch, []contract.FieldError) {
+	var (
+		regions  []string
+		byRegion = make(map[string][]*model.Agent)
+	)
+
+	for _, agent := range agents {
+		if _, ok := byRegion[agent.Region]; !ok {
+			regions = append(regions, agent.Region)
+		}
+
+		byRegion[agent.Region] = append(byRegion[agent.Region], agent)
+	}
+
+	var dispatches []dispatch
+
+	for _, region := range regions {
+		if len(dispatches) == n {
+			break
+		}
+
+		if d, err := pickAgent(target, checks, byRegion[region]); err == nil {
+			dispatches = append(dispatches, d)
+		}
+	}
+
+	if len(dispatches) < n {
+		return nil, []contract.FieldError{{
+			Field:   "targets.spread",
+			Message: fmt.Sprintf("%d distinct regions requested, only %d have a matching online agent able to run the checks", n, len(dispatches)),
+		}}
+	}
+
+	return dispatches, nil
+}
+
+// pickAgent первый в порядке роутера агент, который выполнит все проверки, иначе — больше всего проверок.
+// Кандидаты уже отсортированы Router.Rank, поэтому из равных побеждает более ранний: порядок определяет стратегия.
+func pickAgent(target string, checks []contract.CheckRequest, candidates []*model.Agent) (dispatch, error) {
+	if len(candidates) == 0 {
+		return dispatch{}, fmt.Errorf("no matching online agent")
+	}
+
+	if len(checks) == 0 {
+		return dispatch{agent: candidates[0]}, nil
+	}
+
+	var best dispatch
+
+	for _, agent := range candidates {
+		d := dispatch{agent: agent}
+
+		for _, check := range checks {
+			if agent.CanRun(target, check) == nil {
+				d.checks = append(d.checks, check)
+			}
+		}
+
+		if len(d.checks) == len(checks) {
+			return d, nil
+		}
+
+		if len(d.checks) > len(best.checks) {
+			best = d
+		}
+	}
+
+	if len(best.checks) == 0 {
+		_, reason := firstCapable(candidates, target, checks[0])
+
+		return dispatch{}, fmt.Errorf("no matching agent can run any of the checks: %w", reason)
+	}
+
+	return best, nil
+}
+
+// unassignedChecks проверки, которые не достались ни одному выбранному агенту, с причиной по подходящим агентам.
+func unassignedChecks(target string, checks []contract.CheckRequest, dispatches []dispatch, agents []*model.Agent) []model.UnassignedCheck {
+	assigned := make(map[int]struct{})
+
+	for _, d := range dispatches {
+		for i, check := range d.checks {
+			assigned[check.CheckIndex(i)] = struct{}{}
+		}
+	}
+
+	var unassigned []model.UnassignedCheck
+
+	for i, check := range checks {
+		if _, ok := assigned[check.CheckIndex(i)]; ok {
+			continue
+		}
+
+		_, reason := firstCapable(agents, target, check)
+		if reason == nil {
+			reason = fmt.Errorf("selected agents cannot run the check")
+		}
+
+		unassigned = append(unassigned, newUnassigned(i, check, reason))
+	}
+
+	return unassigned
+}
+
+func agentLoad(agent *model.Agent) int {
+	return agent.InFlight + agent.QueueDepth
+}
+
+func normalizeCodes(codes []string) []string {
+	for i, code := range codes {
+		codes[i] = strings.ToUpper(strings.TrimSpace(code))
+	}
+
+	return dedupe(codes)
+}
+
+func dedupe[T comparable](values []T) []T {
+	seen := make(map[T]struct{}, len(values))
+	out := values[:0]
+
+	for _, v := range values {
+		if _, ok := seen[v]; ok {
+			continue
+		}
+
+		seen[v] = struct{}{}
+		out = append(out, v)
+	}
+
+	return out
+}
@@ -0,0 +1,285 @@
+package service
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+
+	"hackathon-contract"
+
+	"hackathon-back/internal/model"
+)
+
+const testTarget = "93.184.216.34"
+
+func testChecks(types ...string) []contract.CheckRequest {
+	checks := make([]contract.CheckRequest, 0, len(types))
+	for i, checkType := range types {
+		checks = append(checks, contract.CheckRequest{Type: checkType, Params: map[string]interface{}{}, Index: &i})
+	}
+
+	return checks
+}
+
+func withChecks(agent *model.Agent, checks ...string) *model.Agent {
+	agent.Capabilities = &contract.Capabilities{Checks: checks, IPv4: true, IPv6: true, ICMP: true}
+
+	return agent
+}
+
+func withCountry(agent *model.Agent, country string) *model.Agent {
+	agent.Country = country
+
+	return agent
+}
+
+// dispatchPlan агент и индексы его проверок в виде "name:0,2" для сравнения планов.
+func dispatchPlan(dispatches []dispatch) []string {
+	plan := make([]string, 0, len(dispatches))
+
+	for _, d := range dispatches {
+		indexes := make([]string, 0, len(d.checks))
+		for i, check := range d.checks {
+			indexes = append(indexes, strconv.Itoa(check.CheckIndex(i)))
+		}
+
+		plan = append(plan, d.agent.Name+":"+strings.Join(indexes, ","))
+	}
+
+	return plan
+}
+
+func assertPlan(t *testing.T, dispatches []dispatch, want ...string) {
+	t.Helper()
+
+	got := dispatchPlan(dispatches)
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Fatalf("got plan %v, want %v", got, want)
+	}
+}
+
+func TestPlanDispatchPrefersFirstAgentAbleToRunAll(t *testing.T) {
+	agents := []*model.Agent{
+		withChecks(testAgent("http-only", "EU", 0, 0), "http"),
+		withChecks(testAgent("full", "EU", 5, 0), "http", "ping"),
+		withChecks(testAgent("full-idle", "EU", 0, 0), "http", "ping"),
+	}
+
+	dispatches, unassigned := planDispatch(testTarget, testChecks("http", "ping"), agents, false)
+
+	assertPlan(t, dispatches, "full:0,1")
+
+	if len(unassigned) != 0 {
+		t.Fatalf("unexpected unassigned checks %v", unassigned)
+	}
+}
+
+func TestPlanDispatchSplitsChecks(t *testing.T) {
+	agents := []*model.Agent{
+		withChecks(testAgent("http-only", "EU", 0, 0), "http"),
+		withChecks(testAgent("ping-only", "EU", 0, 0), "ping"),
+	}
+
+	dispatches, unassigned := planDispatch(testTarget, testChecks("http", "ping", "dns"), agents, false)
+
+	assertPlan(t, dispatches, "http-only:0", "ping-only:1")
+
+	if len(unassigned) != 1 || unassigned[0].Index != 2 || unassigned[0].Type != "dns" {
+		t.Fatalf("got unassigned %v, want dns check 2", unassigned)
+	}
+
+	if !strings.Contains(unassigned[0].Reason, contract.ErrCheckNotSupported.Error()) {
+		t.Fatalf("got reason %q", unassigned[0].Reason)
+	}
+}
+
+func TestPlanDispatchBroadcast(t *testing.T) {
+	agents := []*model.Agent{
+		withChecks(testAgent("http-only", "EU", 0, 0), "http"),
+		withChecks(testAgent("dns-only", "NA", 0, 0), "dns"),
+		withChecks(testAgent("full", "ASIA", 0, 0), "http", "ping"),
+		testAgent("legacy", "EU", 0, 0),
+	}
+
+	dispatches, unassigned := planDispatch(testTarget, testChecks("http", "ping"), agents, true)
+
+	// агент без capabilities считается способным на всё, агент без подходящих проверок пропускается
+	assertPlan(t, dispatches, "http-only:0", "full:0,1", "legacy:0,1")
+
+	if len(unassigned) != 0 {
+		t.Fatalf("unexpected unassigned checks %v", unassigned)
+	}
+}
+
+func TestPlanTargetsKeepsRouterOrder(t *testing.T) {
+	agents := []*model.Agent{
+		withCountry(withChecks(testAgent("de-busy", "EU", 9, 9), "http"), "DE"),
+		withCountry(withChecks(testAgent("de-idle", "EU", 0, 0), "http"), "DE"),
+		withCountry(withChecks(testAgent("fi-busy", "EU", 9, 9), "http"), "FI"),
+		withCountry(withChecks(testAgent("fi-idle", "EU", 0, 0), "http"), "FI"),
+	}
+
+	sel := &model.TargetSelector{Countries: []string{"FI", "DE"}}
+
+	dispatches, unassigned, fieldErrs := planTargets(testTarget, testChecks("http"), agents, sel, false)
+	if len(fieldErrs) != 0 {
+		t.Fatalf("unexpected field errors %v", fieldErrs)
+	}
+
+	// порядок внутри страны задаёт роутер, загрузка его не переставляет
+	assertPlan(t, dispatches, "fi-busy:0", "de-busy:0")
+
+	if len(unassigned) != 0 {
+		t.Fatalf("unexpected unassigned checks %v", unassigned)
+	}
+}
+
+func TestPlanTargetsPrefersAgentAbleToRunAll(t *testing.T) {
+	agents := []*model.Agent{
+		withChecks(testAgent("partial", "EU", 0, 0), "http"),
+		withChecks(testAgent("full", "EU", 0, 0), "http", "ping"),
+	}
+
+	sel := &model.TargetSelector{Regions: []string{"EU"}}
+
+	dispatches, _, fieldErrs := planTargets(testTarget, testChecks("http", "ping"), agents, sel, false)
+	if len(fieldErrs) != 0 {
+		t.Fatalf("unexpected field errors %v", fieldErrs)
+	}
+
+	assertPlan(t, dispatches, "full:0,1")
+}
+
+func TestPlanTargetsReportsUnassignedChecks(t *testing.T) {
+	agents := []*model.Agent{
+		withChecks(testAgent("http-only", "EU", 0, 0), "http"),
+	}
+
+	sel := &model.TargetSelector{Regions: []string{"EU"}}
+
+	dispatches, unassigned, fieldErrs := planTargets(testTarget, testChecks("http", "ping"), agents, sel, false)
+	if len(fieldErrs) != 0 {
+		t.Fatalf("unexpected field errors %v", fieldErrs)
+	}
+
+	assertPlan(t, dispatches, "http-only:0")
+
+	if len(unassigned) != 1 || unassigned[0].Index != 1 {
+		t.Fatalf("got unassigned %v, want check 1", unassigned)
+	}
+}
+
+func TestPlanTargetsSlotWithoutAgent(t *testing.T) {
+	agents := []*model.Agent{
+		withCountry(testAgent("de", "EU", 0, 0), "DE"),
+		withCountry(withChecks(testAgent("fi-dns", "EU", 0, 0), "dns"), "FI"),
+	}
+
+	sel := &model.TargetSelector{Countries: []string{"DE", "FI", "SE"}}
+
+	dispatches, _, fieldErrs := planTargets(testTarget, testChecks("http"), agents, sel, false)
+	if dispatches != nil {
+		t.Fatalf("got plan %v, want none", dispatchPlan(dispatches))
+	}
+
+	if len(fieldErrs) != 2 {
+		t.Fatalf("got field errors %v, want 2", fieldErrs)
+	}
+
+	if fieldErrs[0].Field != "targets.countries[1]" || !strings.Contains(fieldErrs[0].Message, contract.ErrCheckNotSupported.Error()) {
+		t.Fatalf("got %v", fieldErrs[0])
+	}
+
+	if fieldErrs[1].Field != "targets.countries[2]" || fieldErrs[1].Message != "no matching online agent" {
+		t.Fatalf("got %v", fieldErrs[1])
+	}
+}
+
+func TestPlanTargetsSpread(t *testing.T) {
+	agents := []*model.Agent{
+		withChecks(testAgent("eu-1", "EU", 0, 0), "http"),
+		withChecks(testAgent("eu-2", "EU", 0, 0), "http"),
+		withChecks(testAgent("na-dns", "NA", 0, 0), "dns"),
+		withChecks(testAgent("asia", "ASIA", 0, 0), "http"),
+		withChecks(testAgent("sa", "SA", 0, 0), "http"),
+	}
+
+	dispatches, _, fieldErrs := planTargets(testTarget, testChecks("http"), agents, &model.TargetSelector{Spread: 2}, false)
+	if len(fieldErrs) != 0 {
+		t.Fatalf("unexpected field errors %v", fieldErrs)
+	}
+
+	// регион, где никто не может выполнить проверки, пропускается
+	assertPlan(t, dispatches, "eu-1:0", "asia:0")
+
+	_, _, fieldErrs = planTargets(testTarget, testChecks("http"), agents, &model.TargetSelector{Spread: 4}, false)
+	if len(fieldErrs) != 1 || fieldErrs[0].Field != "targets.spread" {
+		t.Fatalf("got field errors %v, want targets.spread", fieldErrs)
+	}
+}
+
+func TestPlanTargetsBroadcast(t *testing.T) {
+	agents := []*model.Agent{
+		withChecks(testAgent("eu", "EU", 0, 0), "http"),
+		withChecks(testAgent("na", "NA", 0, 0), "http"),
+		withChecks(testAgent("asia", "ASIA", 0, 0), "http"),
+	}
+
+	sel := &model.TargetSelector{Regions: []string{"EU", "ASIA"}}
+
+	dispatches, _, fieldErrs := planTargets(testTarget, testChecks("http"), agents, sel, true)
+	if len(fieldErrs) != 0 {
+		t.Fatalf("unexpected field errors %v", fieldErrs)
+	}
+
+	assertPlan(t, dispatches, "eu:0", "asia:0")
+
+	_, _, fieldErrs = planTargets(testTarget, testChecks("ping"), agents, sel, true)
+	if len(fieldErrs) != 1 || fieldErrs[0].Field != "targets" {
+		t.Fatalf("got field errors %v, want targets", fieldErrs)
+	}
+
+	_, _, fieldErrs = planTargets(testTarget, testChecks("http"), agents, &model.TargetSelector{Regions: []string{"SA"}}, true)
+	if len(fieldErrs) != 1 || fieldErrs[0].Message != "no online agent matches the selector" {
+		t.Fatalf("got field errors %v", fieldErrs)
+	}
+}
+
+func TestNormalizeTargets(t *testing.T) {
+	sel := &model.TargetSelector{
+		Regions:   []string{" eu", "EU", "na "},
+		Countries: []string{"de", "DE", "fin"},
+		ASNs:      []int{24940, 24940, 0},
+		Spread:    2,
+	}
+
+	fieldErrs := normalizeTargets(sel, true)
+
+	if strings.Join(sel.Regions, ",") != "EU,NA" || strings.Join(sel.Countries, ",") != "DE,FIN" || len(sel.ASNs) != 2 {
+		t.Fatalf("got normalized selector %+v", sel)
+	}
+
+	want := []string{"targets.countries[1]", "targets.asns[1]", "targets.spread"}
+	if len(fieldErrs) != len(want) {
+		t.Fatalf("got field errors %v, want fields %v", fieldErrs, want)
+	}
+
+	for i, field := range want {
+		if fieldErrs[i].Field != field {
+			t.Fatalf("got field errors %v, want fields %v", fieldErrs, want)
+		}
+	}
+}
+
+func TestPickAgentWithoutCandidates(t *testing.T) {
+	_, err := pickAgent(testTarget, testChecks("http"), nil)
+	if err == nil {
+		t.Fatal("expected error without candidates")
+	}
+
+	_, err = pickAgent(testTarget, testChecks("http"), []*model.Agent{withChecks(testAgent("dns", "EU", 0, 0), "dns")})
+	if !errors.Is(err, contract.ErrCheckNotSupported) {
+		t.Fatalf("got %v, want %v", err, contract.ErrCheckNotSupported)
+	}
+}
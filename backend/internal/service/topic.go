@@ -0,0 +1,209 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"go.uber.org/zap"
+
+	"hackathon-back/internal/apperrors"
+	"hackathon-back/internal/model"
+	"hackathon-back/internal/repository"
+	"hackathon-back/pkg/kafka"
+)
+
+type TopicAdmin interface {
+	EnsureTopic(name string, spec kafka.TopicSpec) (created bool, err error)
+	DescribeTopics(names []string) ([]kafka.TopicState, error)
+}
+
+type TopicAgentRepository interface {
+	SelectAgents(ctx context.Context, ext repository.RepoExtension) ([]*model.Agent, error)
+}
+
+// TopicSettings AutoCreate — создавать недостающие топики с параметрами Spec,
+// без него бэкенд только проверяет, что они есть.
+type TopicSettings struct {
+	AutoCreate     bool
+	Spec           kafka.TopicSpec
+	ResultsTopic   string
+	HeartbeatTopic string
+}
+
+// TopicService топики Kafka: топик задач создаётся при заведении агента в новом регионе,
+// топики результатов и heartbeat проверяются при старте.
+type TopicService struct {
+	log       *zap.Logger
+	admin     TopicAdmin
+	agentRepo TopicAgentRepository
+	settings  TopicSettings
+}
+
+func NewTopicService(log *zap.Logger, admin TopicAdmin, agentRepo TopicAgentRepository, settings TopicSettings) *TopicService {
+	return &TopicService{
+		log:       log,
+		admin:     admin,
+		agentRepo: agentRepo,
+		settings:  settings,
+	}
+}
+
+// EnsureRegionTopic создаёт топик задач региона, если его ещё нет. Без auto_create ничего не делает:
+// топики заводит оператор, а задачи в отсутствующий топик outbox будет ретраить.
+func (s *TopicService) EnsureRegionTopic(_ context.Context, region string) error {
+	if !s.settings.AutoCreate {
+		return nil
+	}
+
+	return s.ensure(regionTopic(region))
+}
+
+// VerifyTopics проверяет при старте топики результатов и heartbeat, без которых бэкенд не получит ничего от агентов,
+// и при auto_create создаёт их и топики задач регионов уже известных агентов.
+func (s *TopicService) VerifyTopics(ctx context.Context) error {
+	required := []string{s.settings.ResultsTopic, s.settings.HeartbeatTopic}
+
+	if !s.settings.AutoCreate {
+		states, err := s.admin.DescribeTopics(required)
+		if err != nil {
+			return fmt.Errorf("failed to describe topics: %w", err)
+		}
+
+		for _, state := range states {
+			if errors.Is(state.Err, kafka.ErrTopicNotFound) {
+				return fmt.Errorf("%w: %s", apperrors.ErrTopicDoesNotExist, state.Name)
+			}
+		}
+
+		return nil
+	}
+
+	regions, err := s.regions(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, region := range regions {
+		required = append(required, regionTopic(region))
+	}
+
+	for _, name := range required {
+		if err := s.ensure(name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetTopics состояние топиков результатов, heartbeat и задач всех регионов, где есть агенты.
+func (s *TopicService) GetTopics(ctx context.Context) ([]*model.TopicHealth, error) {
+	regions, err := s.regions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	topics := []*model.TopicHealth{
+		{Name: s.settings.ResultsTopic, Role: model.TopicRoleResults},
+		{Name: s.settings.HeartbeatTopic, Role: model.TopicRoleHeartbeat},
+	}
+
+	for _, region := range regions {
+		topics = append(topics, &model.TopicHealth{Name: regionTopic(region), Role: model.TopicRoleTasks, Region: region})
+	}
+
+	names := make([]string, len(topics))
+	for i, t := range topics {
+		names[i] = t.Name
+	}
+
+	states, err := s.admin.DescribeTopics(names)
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe topics: %w", err)
+	}
+
+	for i, state := range states {
+		fillTopicHealth(topics[i], state)
+	}
+
+	return topics, nil
+}
+
+func (s *TopicService) ensure(name string) error {
+	created, err := s.admin.EnsureTopic(name, s.settings.Spec)
+	if err != nil {
+		return fmt.Errorf("failed to ensure topic %s: %w", name, err)
+	}
+
+	if created {
+		s.log.Info("Kafka topic created",
+			zap.String("topic", name),
+			zap.Int32("partitions", s.settings.Spec.Partitions),
+			zap.Int16("replication_factor", s.settings.Spec.ReplicationFactor),
+			zap.Duration("retention", s.settings.Spec.Retention),
+		)
+	}
+
+	return nil
+}
+
+// regions регионы агентов реестра и тех, что пришли heartbeat'ом, по алфавиту.
+func (s *TopicService) regions(ctx context.Context) ([]string, error) {
+	agents, err := s.agentRepo.SelectAgents(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select agents: %w", err)
+	}
+
+	var regions []string
+
+	for _, agent := range agents {
+		if !slices.Contains(regions, agent.Region) {
+			regions = append(regions, agent.Region)
+		}
+	}
+
+	slices.Sort(regions)
+
+	return regions, nil
+}
+
+func fillTopicHealth(topic *model.TopicHealth, state kafka.TopicState) {
+	topic.Exists = !errors.Is(state.Err, kafka.ErrTopicNotFound)
+
+	if state.Err != nil {
+		topic.Error = state.Err.Error()
+	}
+
+	topic.Partitions = make([]*model.PartitionHealth, 0, len(state.Partitions))
+
+	for _, p := range state.Partitions {
+		partition := &model.PartitionHealth{
+			ID:       p.ID,
+			Leader:   p.Leader,
+			Replicas: p.Replicas,
+			ISR:      p.ISR,
+		}
+
+		if p.Err != nil {
+			partition.Error = p.Err.Error()
+		}
+
+		if p.Leader < 0 {
+			topic.Offline++
+		}
+
+		if len(p.ISR) < len(p.Replicas) {
+			topic.UnderReplicated++
+		}
+
+		topic.Partitions = append(topic.Partitions, partition)
+	}
+
+	if len(state.Partitions) > 0 {
+		topic.ReplicationFactor = len(state.Partitions[0].Replicas)
+	}
+
+	topic.Healthy = topic.Exists && state.Err == nil && len(state.Partitions) > 0 && topic.Offline == 0 && topic.UnderReplicated == 0
+}
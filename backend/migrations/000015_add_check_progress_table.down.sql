@@ -0,0 +1,3 @@
+-- 000015_add_check_progress_table.down.sql
+
+DROP TABLE IF EXISTS domain.check_progress;
@@ -0,0 +1,14 @@
+-- 000015_add_check_progress_table.up.sql
+
+-- последнее промежуточное состояние долгих проверок (хопы traceroute, ответы ping),
+-- строка удаляется, когда приходит итоговый результат
+CREATE TABLE IF NOT EXISTS domain.check_progress (
+    assignment_id    UUID NOT NULL,
+    check_index      INTEGER NOT NULL,
+    type             TEXT NOT NULL,
+    seq              INTEGER NOT NULL,
+    started_at       TIMESTAMPTZ,
+    updated_at       TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    payload          JSONB NOT NULL,
+    PRIMARY KEY (assignment_id, check_index)
+);
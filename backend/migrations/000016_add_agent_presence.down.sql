@@ -0,0 +1,11 @@
+-- 000016_add_agent_presence.down.sql
+
+DROP INDEX IF EXISTS domain.idx_agents_region_online;
+
+ALTER TABLE domain.agents
+    DROP COLUMN IF EXISTS last_seen,
+    DROP COLUMN IF EXISTS version,
+    DROP COLUMN IF EXISTS uptime_seconds,
+    DROP COLUMN IF EXISTS in_flight,
+    DROP COLUMN IF EXISTS queue_depth,
+    ALTER COLUMN online SET DEFAULT TRUE;
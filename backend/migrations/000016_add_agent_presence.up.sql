@@ -0,0 +1,14 @@
+-- 000016_add_agent_presence.up.sql
+
+-- online выставляет только heartbeat агента, пока его не было — агент считается offline
+ALTER TABLE domain.agents
+    ADD COLUMN IF NOT EXISTS last_seen      TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS version        TEXT,
+    ADD COLUMN IF NOT EXISTS uptime_seconds BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS in_flight      INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS queue_depth    INTEGER NOT NULL DEFAULT 0,
+    ALTER COLUMN online SET DEFAULT FALSE;
+
+UPDATE domain.agents SET online = FALSE;
+
+CREATE INDEX IF NOT EXISTS idx_agents_region_online ON domain.agents (region) WHERE online;
@@ -0,0 +1,3 @@
+-- 000017_add_agent_capabilities.down.sql
+
+ALTER TABLE domain.agents DROP COLUMN IF EXISTS capabilities;
@@ -0,0 +1,4 @@
+-- 000017_add_agent_capabilities.up.sql
+
+-- возможности агента из heartbeat (contract.Capabilities), NULL — агент их не сообщал и получает любые проверки
+ALTER TABLE domain.agents ADD COLUMN IF NOT EXISTS capabilities JSONB;
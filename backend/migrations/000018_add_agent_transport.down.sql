@@ -0,0 +1,7 @@
+-- 000018_add_agent_transport.down.sql
+
+DROP INDEX IF EXISTS messages.idx_outbox_messages_unsent_http;
+
+ALTER TABLE messages.outbox_messages DROP COLUMN IF EXISTS transport;
+
+ALTER TABLE domain.agents DROP COLUMN IF EXISTS transport;
@@ -0,0 +1,10 @@
+-- 000018_add_agent_transport.up.sql
+
+-- транспорт агента из heartbeat: kafka или http (long-poll через бэкенд)
+ALTER TABLE domain.agents ADD COLUMN IF NOT EXISTS transport TEXT NOT NULL DEFAULT 'kafka';
+
+-- задачи для http агентов забирает long-poll, а не outbox publisher
+ALTER TABLE messages.outbox_messages ADD COLUMN IF NOT EXISTS transport TEXT NOT NULL DEFAULT 'kafka';
+
+CREATE INDEX IF NOT EXISTS idx_outbox_messages_unsent_http
+    ON messages.outbox_messages (topic, created_at) WHERE NOT sent AND transport = 'http';
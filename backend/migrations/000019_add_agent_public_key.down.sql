@@ -0,0 +1,3 @@
+-- 000019_add_agent_public_key.down.sql
+
+ALTER TABLE domain.agents DROP COLUMN IF EXISTS public_key;
@@ -0,0 +1,4 @@
+-- 000019_add_agent_public_key.up.sql
+
+-- ключ Ed25519 агента: запоминается по первому heartbeat, дальше им проверяются подписи результатов и heartbeat
+ALTER TABLE domain.agents ADD COLUMN IF NOT EXISTS public_key BYTEA;
@@ -0,0 +1,11 @@
+-- 000020_link_check_results_to_assignments.down.sql
+
+UPDATE domain.check_results c
+SET assignment_id = a.request_id
+FROM domain.assignments a
+WHERE a.id = c.assignment_id;
+
+ALTER TABLE domain.check_progress DROP COLUMN IF EXISTS agent_id;
+
+ALTER TABLE domain.check_results DROP COLUMN IF EXISTS agent_id;
+ALTER TABLE domain.check_results DROP COLUMN IF EXISTS check_index;
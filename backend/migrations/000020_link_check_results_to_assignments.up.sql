@@ -0,0 +1,25 @@
+-- 000020_link_check_results_to_assignments.up.sql
+
+-- результат ссылается на настоящую строку domain.assignments, а не на id запроса;
+-- agent_id — агент, который выполнил проверку (задачу региона мог взять любой его агент)
+ALTER TABLE domain.check_results ADD COLUMN IF NOT EXISTS check_index INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE domain.check_results ADD COLUMN IF NOT EXISTS agent_id TEXT;
+
+ALTER TABLE domain.check_progress ADD COLUMN IF NOT EXISTS agent_id TEXT;
+
+UPDATE domain.check_results
+SET check_index = (payload->>'checkIndex')::INTEGER
+WHERE payload ? 'checkIndex';
+
+-- раньше в assignment_id писался id запроса: однозначно переносятся результаты запросов с одним назначением,
+-- результаты broadcast запросов без агента в payload привязать не к чему
+UPDATE domain.check_results c
+SET assignment_id = a.id,
+    agent_id      = a.agent_id
+FROM domain.assignments a
+WHERE a.request_id = c.assignment_id
+  AND (SELECT COUNT(*) FROM domain.assignments x WHERE x.request_id = c.assignment_id) = 1;
+
+-- промежуточные состояния недолговечны, старые с id запроса просто удаляются
+DELETE FROM domain.check_progress p
+WHERE NOT EXISTS (SELECT 1 FROM domain.assignments a WHERE a.id = p.assignment_id);
@@ -0,0 +1,26 @@
+-- 000021_add_request_lifecycle.down.sql
+
+DROP INDEX IF EXISTS domain.assignments_outbox_idx;
+
+ALTER TABLE domain.assignments DROP COLUMN IF EXISTS dispatched_at;
+ALTER TABLE domain.assignments DROP COLUMN IF EXISTS checks_total;
+
+-- значение из enum не удалить: тип пересоздаётся без DISPATCHED
+UPDATE domain.requests SET status = 'PENDING' WHERE status = 'DISPATCHED';
+UPDATE domain.assignments SET status = 'PENDING' WHERE status = 'DISPATCHED';
+
+ALTER TYPE domain.check_status RENAME TO check_status_old;
+
+CREATE TYPE domain.check_status AS ENUM ('PENDING','RUNNING','DONE','FAILED','TIMEOUT');
+
+ALTER TABLE domain.requests ALTER COLUMN status DROP DEFAULT;
+ALTER TABLE domain.requests ALTER COLUMN status TYPE domain.check_status USING status::TEXT::domain.check_status;
+ALTER TABLE domain.requests ALTER COLUMN status SET DEFAULT 'PENDING';
+
+ALTER TABLE domain.assignments ALTER COLUMN status DROP DEFAULT;
+ALTER TABLE domain.assignments ALTER COLUMN status TYPE domain.check_status USING status::TEXT::domain.check_status;
+ALTER TABLE domain.assignments ALTER COLUMN status SET DEFAULT 'PENDING';
+
+ALTER TABLE domain.check_results ALTER COLUMN status TYPE domain.check_status USING status::TEXT::domain.check_status;
+
+DROP TYPE domain.check_status_old;
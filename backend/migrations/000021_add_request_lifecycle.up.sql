@@ -0,0 +1,47 @@
+-- 000021_add_request_lifecycle.up.sql
+
+-- жизненный цикл запроса и назначения: PENDING → DISPATCHED → RUNNING → DONE/FAILED/TIMEOUT,
+-- переходы описаны в model.AssignmentTransitions
+ALTER TYPE domain.check_status ADD VALUE IF NOT EXISTS 'DISPATCHED' AFTER 'PENDING';
+
+-- checks_total — сколько итоговых результатов ждать от назначения, dispatched_at — когда задача ушла агенту
+ALTER TABLE domain.assignments ADD COLUMN IF NOT EXISTS checks_total INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE domain.assignments ADD COLUMN IF NOT EXISTS dispatched_at TIMESTAMPTZ;
+
+UPDATE domain.assignments a
+SET checks_total = cardinality(r.checks_types)
+FROM domain.requests r
+WHERE r.id = a.request_id;
+
+-- назначения, по которым уже пришли все результаты, завершаются сразу;
+-- остальные зависшие в PENDING переведёт в TIMEOUT sweeper
+UPDATE domain.assignments a
+SET status      = CASE WHEN c.failed > 0 THEN 'FAILED' ELSE 'DONE' END::domain.check_status,
+    finished_at = c.finished_at
+FROM (
+    SELECT assignment_id,
+           COUNT(DISTINCT check_index)                 AS total,
+           COUNT(*) FILTER (WHERE status = 'FAILED')   AS failed,
+           MAX(finished_at)                            AS finished_at
+    FROM domain.check_results
+    GROUP BY assignment_id
+) c
+WHERE c.assignment_id = a.id
+  AND a.status = 'PENDING'
+  AND c.total >= a.checks_total;
+
+UPDATE domain.requests r
+SET status     = CASE WHEN s.all_done THEN 'DONE' ELSE 'FAILED' END::domain.check_status,
+    updated_at = NOW()
+FROM (
+    SELECT request_id,
+           bool_and(status = 'DONE')               AS all_done,
+           bool_and(status IN ('DONE', 'FAILED'))  AS all_finished
+    FROM domain.assignments
+    GROUP BY request_id
+) s
+WHERE s.request_id = r.id
+  AND s.all_finished
+  AND r.status = 'PENDING';
+
+CREATE INDEX IF NOT EXISTS assignments_outbox_idx ON domain.assignments(outbox_id);
@@ -0,0 +1,5 @@
+-- 000022_add_request_targets.down.sql
+
+ALTER TABLE domain.requests DROP COLUMN IF EXISTS targets;
+
+ALTER TABLE domain.agents DROP COLUMN IF EXISTS country;
@@ -0,0 +1,7 @@
+-- 000022_add_request_targets.up.sql
+
+-- страна размещения агента из heartbeat: по ней и по asn запросы выбирают агентов
+ALTER TABLE domain.agents ADD COLUMN IF NOT EXISTS country TEXT;
+
+-- селектор агентов из запроса, NULL — агент выбирался по региону клиента
+ALTER TABLE domain.requests ADD COLUMN IF NOT EXISTS targets JSONB;
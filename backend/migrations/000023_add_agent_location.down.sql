@@ -0,0 +1,4 @@
+-- 000023_add_agent_location.down.sql
+
+ALTER TABLE domain.agents DROP COLUMN IF EXISTS lon;
+ALTER TABLE domain.agents DROP COLUMN IF EXISTS lat;
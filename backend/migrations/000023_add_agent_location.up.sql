@@ -0,0 +1,5 @@
+-- 000023_add_agent_location.up.sql
+
+-- координаты агента из heartbeat: запрос без targets уходит ближайшему к клиенту агенту
+ALTER TABLE domain.agents ADD COLUMN IF NOT EXISTS lat DOUBLE PRECISION;
+ALTER TABLE domain.agents ADD COLUMN IF NOT EXISTS lon DOUBLE PRECISION;
@@ -0,0 +1,5 @@
+-- 000024_add_routing_strategy.down.sql
+
+DROP INDEX IF EXISTS domain.results_ping_asn_idx;
+
+ALTER TABLE domain.requests DROP COLUMN IF EXISTS strategy;
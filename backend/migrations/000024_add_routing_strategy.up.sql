@@ -0,0 +1,9 @@
+-- 000024_add_routing_strategy.up.sql
+
+-- стратегия, которой выбраны агенты запроса (geo, round_robin, least_loaded, latency)
+ALTER TABLE domain.requests ADD COLUMN IF NOT EXISTS strategy TEXT;
+
+-- стратегия latency ищет минимальный RTT от агентов до сети клиента по результатам ping
+CREATE INDEX IF NOT EXISTS results_ping_asn_idx
+    ON domain.check_results (((payload->'payload'->>'asn')::INTEGER), finished_at)
+    WHERE type = 'ping' AND status = 'DONE';
@@ -0,0 +1,9 @@
+-- 000025_add_agent_registry.down.sql
+
+DROP TABLE IF EXISTS domain.agent_enrollments;
+
+ALTER TABLE domain.agents
+    DROP COLUMN IF EXISTS created_at,
+    DROP COLUMN IF EXISTS disabled,
+    DROP COLUMN IF EXISTS registered,
+    DROP COLUMN IF EXISTS name;
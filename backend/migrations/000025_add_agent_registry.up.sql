@@ -0,0 +1,24 @@
+-- 000025_add_agent_registry.up.sql
+
+-- registered: агент заведён через /admin/agents, его регион, страну, ASN и координаты задаёт администратор,
+-- heartbeat их не меняет; disabled: агент не получает задач и не выходит online
+ALTER TABLE domain.agents
+    ADD COLUMN IF NOT EXISTS name       TEXT,
+    ADD COLUMN IF NOT EXISTS registered BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN IF NOT EXISTS disabled   BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN IF NOT EXISTS created_at TIMESTAMPTZ NOT NULL DEFAULT NOW();
+
+-- одноразовые токены регистрации агентов, хранится только SHA-256 токена;
+-- used_at, agent_version и ip заполняются, когда агент обменял токен на свои настройки
+CREATE TABLE IF NOT EXISTS domain.agent_enrollments (
+    id            UUID PRIMARY KEY,
+    agent_id      UUID NOT NULL REFERENCES domain.agents(id) ON DELETE CASCADE,
+    token_hash    BYTEA NOT NULL UNIQUE,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at    TIMESTAMPTZ NOT NULL,
+    used_at       TIMESTAMPTZ,
+    agent_version TEXT,
+    ip            TEXT
+);
+
+CREATE INDEX IF NOT EXISTS idx_agent_enrollments_agent ON domain.agent_enrollments (agent_id, created_at DESC);
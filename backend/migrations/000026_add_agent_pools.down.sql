@@ -0,0 +1,9 @@
+-- 000026_add_agent_pools.down.sql
+
+ALTER TABLE domain.agents
+    DROP CONSTRAINT IF EXISTS agents_single_owner,
+    DROP COLUMN IF EXISTS owner_org_id,
+    DROP COLUMN IF EXISTS owner_user_id;
+
+DROP TABLE IF EXISTS domain.organization_members;
+DROP TABLE IF EXISTS domain.organizations;
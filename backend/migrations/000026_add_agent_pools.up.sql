@@ -0,0 +1,28 @@
+-- 000026_add_agent_pools.up.sql
+
+-- организации пользователей: частных агентов организации видят и выбирают все её участники
+CREATE TABLE IF NOT EXISTS domain.organizations (
+    id         UUID PRIMARY KEY,
+    name       TEXT NOT NULL,
+    created_by UUID NOT NULL REFERENCES sso.users(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS domain.organization_members (
+    organization_id UUID NOT NULL REFERENCES domain.organizations(id) ON DELETE CASCADE,
+    user_id         UUID NOT NULL REFERENCES sso.users(id) ON DELETE CASCADE,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (organization_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_organization_members_user ON domain.organization_members (user_id);
+
+-- частный агент принадлежит пользователю или организации: задачи ему направляет только владелец,
+-- публичный трафик он не получает; у публичных агентов оба поля NULL
+ALTER TABLE domain.agents
+    ADD COLUMN IF NOT EXISTS owner_user_id UUID REFERENCES sso.users(id) ON DELETE CASCADE,
+    ADD COLUMN IF NOT EXISTS owner_org_id  UUID REFERENCES domain.organizations(id) ON DELETE CASCADE,
+    ADD CONSTRAINT agents_single_owner CHECK (owner_user_id IS NULL OR owner_org_id IS NULL);
+
+CREATE INDEX IF NOT EXISTS idx_agents_owner_user ON domain.agents (owner_user_id) WHERE owner_user_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_agents_owner_org ON domain.agents (owner_org_id) WHERE owner_org_id IS NOT NULL;
@@ -0,0 +1,4 @@
+-- 000027_add_monitors.down.sql
+
+DROP TABLE IF EXISTS domain.monitor_runs;
+DROP TABLE IF EXISTS domain.monitors;
@@ -0,0 +1,34 @@
+-- 000027_add_monitors.up.sql
+
+-- мониторы: задача проверок, которую планировщик ставит в очередь по расписанию (интервал или cron)
+CREATE TABLE IF NOT EXISTS domain.monitors (
+    id               UUID PRIMARY KEY,
+    user_id          UUID NOT NULL REFERENCES sso.users(id) ON DELETE CASCADE,
+    name             TEXT NOT NULL,
+    task             JSONB NOT NULL,
+    interval_seconds INTEGER,
+    cron             TEXT,
+    enabled          BOOLEAN NOT NULL DEFAULT TRUE,
+    next_run_at      TIMESTAMPTZ NOT NULL,
+    last_run_at      TIMESTAMPTZ,
+    created_at       TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at       TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT monitors_single_schedule CHECK ((interval_seconds IS NULL) <> (cron IS NULL))
+);
+
+CREATE INDEX IF NOT EXISTS idx_monitors_user ON domain.monitors (user_id);
+-- реплики забирают наступившие запуски через FOR UPDATE SKIP LOCKED
+CREATE INDEX IF NOT EXISTS idx_monitors_due ON domain.monitors (next_run_at) WHERE enabled;
+
+-- запуски мониторов: созданный запрос или причина, по которой запуск не состоялся
+CREATE TABLE IF NOT EXISTS domain.monitor_runs (
+    id           UUID PRIMARY KEY,
+    monitor_id   UUID NOT NULL REFERENCES domain.monitors(id) ON DELETE CASCADE,
+    request_id   UUID REFERENCES domain.requests(id) ON DELETE SET NULL,
+    status       TEXT NOT NULL,
+    error_text   TEXT,
+    scheduled_at TIMESTAMPTZ NOT NULL,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_monitor_runs_monitor ON domain.monitor_runs (monitor_id, created_at DESC);
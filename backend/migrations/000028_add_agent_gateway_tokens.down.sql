@@ -0,0 +1,5 @@
+-- 000028_add_agent_gateway_tokens.down.sql
+
+DROP INDEX IF EXISTS domain.idx_agents_gateway_token;
+
+ALTER TABLE domain.agents DROP COLUMN IF EXISTS gateway_token_hash;
@@ -0,0 +1,7 @@
+-- 000028_add_agent_gateway_tokens.up.sql
+
+-- токен HTTP gateway у каждого агента свой: выдаётся при регистрации, хранится только SHA-256;
+-- агенты, зарегистрированные до этой миграции, получают токен при следующей регистрации
+ALTER TABLE domain.agents ADD COLUMN IF NOT EXISTS gateway_token_hash BYTEA;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_agents_gateway_token ON domain.agents (gateway_token_hash) WHERE gateway_token_hash IS NOT NULL;
@@ -0,0 +1,5 @@
+-- 000029_add_check_progress_final.down.sql
+
+DELETE FROM domain.check_progress WHERE final;
+
+ALTER TABLE domain.check_progress DROP COLUMN IF EXISTS final;
@@ -0,0 +1,5 @@
+-- 000029_add_check_progress_final.up.sql
+
+-- итоговый результат больше не удаляет строку промежуточного состояния, а помечает её final:
+-- запоздавшее промежуточное сообщение, обработанное параллельно с итоговым, упирается в эту строку и не воскрешает прогресс
+ALTER TABLE domain.check_progress ADD COLUMN IF NOT EXISTS final BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,3 @@
+-- 000030_add_check_results_unique.down.sql
+
+ALTER TABLE domain.check_results DROP CONSTRAINT IF EXISTS uk_check_results_assignment_check;
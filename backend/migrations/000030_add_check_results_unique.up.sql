@@ -0,0 +1,16 @@
+-- 000030_add_check_results_unique.up.sql
+
+-- итоговый результат проверки назначения единственный: повторная доставка с другим message id
+-- (агент переотправил после таймаута) не должна добавлять вторую строку и второй раз завершать назначение.
+-- из уже сохранённых дублей остаётся самый ранний; строка без finished_at остаётся, только если других нет
+DELETE FROM domain.check_results c
+USING (
+    SELECT id,
+           ROW_NUMBER() OVER (PARTITION BY assignment_id, check_index ORDER BY finished_at NULLS LAST, id) AS n
+    FROM domain.check_results
+) d
+WHERE c.id = d.id
+  AND d.n > 1;
+
+ALTER TABLE domain.check_results
+    ADD CONSTRAINT uk_check_results_assignment_check UNIQUE (assignment_id, check_index);
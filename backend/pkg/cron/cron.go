@@ -0,0 +1,243 @@
+package cron
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidExpression = errors.New("invalid cron expression")
+
+// searchHorizon дальше этого Next не ищет: расписание, которое не срабатывает за 5 лет (например 31 февраля), считается ошибкой.
+const searchHorizon = 5
+
+// calendarCycle через столько лет григорианский календарь повторяет дни недели по датам (в пределах одного века).
+const calendarCycle = 28
+
+// Schedule расписание cron из 5 полей: минута, час, день месяца, месяц, день недели (0 и 7 — воскресенье).
+// Поле — список через запятую из *, N, N-M с необязательным шагом /S. Время считается в UTC.
+// Если заданы и день месяца, и день недели, достаточно совпадения любого из них, как в классическом cron.
+type Schedule struct {
+	minute uint64
+	hour   uint64
+	dom    uint64
+	month  uint64
+	dow    uint64
+
+	domAny bool
+	dowAny bool
+}
+
+type field struct {
+	name     string
+	min, max int
+}
+
+var fields = [5]field{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12},
+	{name: "day of week", min: 0, max: 7},
+}
+
+func Parse(expr string) (*Schedule, error) {
+	parts := strings.Fields(expr)
+	if len(parts) != len(fields) {
+		return nil, fmt.Errorf("%w: expected %d fields, got %d", ErrInvalidExpression, len(fields), len(parts))
+	}
+
+	var bits [5]uint64
+
+	for i, part := range parts {
+		b, err := parseField(part, fields[i])
+		if err != nil {
+			return nil, err
+		}
+
+		bits[i] = b
+	}
+
+	// 7 — то же воскресенье, что и 0
+	if bits[4]&(1<<7) != 0 {
+		bits[4] = bits[4]&^(1<<7) | 1
+	}
+
+	s := &Schedule{
+		minute: bits[0],
+		hour:   bits[1],
+		dom:    bits[2],
+		month:  bits[3],
+		dow:    bits[4],
+		domAny: parts[2] == "*",
+		dowAny: parts[4] == "*",
+	}
+
+	if s.Next(time.Now()).IsZero() {
+		return nil, fmt.Errorf("%w: schedule never fires", ErrInvalidExpression)
+	}
+
+	return s, nil
+}
+
+// Next ближайшее время срабатывания строго после t, нулевое время — если его нет в пределах searchHorizon лет.
+func (s *Schedule) Next(t time.Time) time.Time {
+	t = t.UTC().Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(searchHorizon, 0, 0)
+
+	for t.Before(limit) {
+		switch {
+		case s.month&(1<<uint(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+		case !s.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, time.UTC)
+		case s.hour&(1<<uint(t.Hour())) == 0:
+			t = t.Truncate(time.Hour).Add(time.Hour)
+		case s.minute&(1<<uint(t.Minute())) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+
+	return time.Time{}
+}
+
+// MinInterval наименьший промежуток между соседними срабатываниями. Время срабатывания внутри дня у всех дней одно,
+// поэтому промежуток внутри дня считается по минутам и часам, а между днями — от последнего срабатывания дня
+// до первого в ближайший следующий день срабатывания за полный календарный цикл.
+func (s *Schedule) MinInterval() time.Duration {
+	var times []int // times минуты от начала суток, по возрастанию
+
+	for h := range 24 {
+		if s.hour&(1<<uint(h)) == 0 {
+			continue
+		}
+
+		for m := range 60 {
+			if s.minute&(1<<uint(m)) != 0 {
+				times = append(times, h*60+m)
+			}
+		}
+	}
+
+	gap := math.MaxInt
+
+	for i := 1; i < len(times); i++ {
+		gap = min(gap, times[i]-times[i-1])
+	}
+
+	if days := s.minDayGap(); days > 0 {
+		gap = min(gap, days*24*60-times[len(times)-1]+times[0])
+	}
+
+	return time.Duration(gap) * time.Minute
+}
+
+// minDayGap наименьшее число дней между соседними днями срабатывания, 0 — за цикл срабатывает не больше одного дня.
+func (s *Schedule) minDayGap() int {
+	start := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(calendarCycle, 0, 0)
+
+	gap, prev := 0, -1
+
+	for day, t := 0, start; t.Before(end); day, t = day+1, t.AddDate(0, 0, 1) {
+		if s.month&(1<<uint(t.Month())) == 0 || !s.dayMatches(t) {
+			continue
+		}
+
+		if prev >= 0 && (gap == 0 || day-prev < gap) {
+			gap = day - prev
+		}
+
+		prev = day
+	}
+
+	return gap
+}
+
+func (s *Schedule) dayMatches(t time.Time) bool {
+	dom := s.dom&(1<<uint(t.Day())) != 0
+	dow := s.dow&(1<<uint(t.Weekday())) != 0
+
+	switch {
+	case s.domAny && s.dowAny:
+		return true
+	case s.domAny:
+		return dow
+	case s.dowAny:
+		return dom
+	default:
+		return dom || dow
+	}
+}
+
+func parseField(expr string, f field) (uint64, error) {
+	var bits uint64
+
+	for _, item := range strings.Split(expr, ",") {
+		rangeExpr, stepExpr, hasStep := strings.Cut(item, "/")
+
+		step := 1
+
+		if hasStep {
+			n, err := strconv.Atoi(stepExpr)
+			if err != nil || n < 1 {
+				return 0, fmt.Errorf("%w: %s: invalid step %q", ErrInvalidExpression, f.name, stepExpr)
+			}
+
+			step = n
+		}
+
+		lo, hi := f.min, f.max
+
+		switch {
+		case rangeExpr == "*":
+		case strings.Contains(rangeExpr, "-"):
+			loExpr, hiExpr, _ := strings.Cut(rangeExpr, "-")
+
+			var err error
+
+			if lo, err = parseValue(loExpr, f); err != nil {
+				return 0, err
+			}
+
+			if hi, err = parseValue(hiExpr, f); err != nil {
+				return 0, err
+			}
+
+			if lo > hi {
+				return 0, fmt.Errorf("%w: %s: range %q is reversed", ErrInvalidExpression, f.name, rangeExpr)
+			}
+		default:
+			v, err := parseValue(rangeExpr, f)
+			if err != nil {
+				return 0, err
+			}
+
+			// N/S — от N до конца диапазона с шагом S
+			lo = v
+			if !hasStep {
+				hi = v
+			}
+		}
+
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+
+	return bits, nil
+}
+
+func parseValue(expr string, f field) (int, error) {
+	v, err := strconv.Atoi(expr)
+	if err != nil || v < f.min || v > f.max {
+		return 0, fmt.Errorf("%w: %s: %q must be within [%d, %d]", ErrInvalidExpression, f.name, expr, f.min, f.max)
+	}
+
+	return v, nil
+}
@@ -0,0 +1,132 @@
+package cron
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func mustParse(t *testing.T, expr string) *Schedule {
+	t.Helper()
+
+	s, err := Parse(expr)
+	if err != nil {
+		t.Fatalf("Parse(%q): %v", expr, err)
+	}
+
+	return s
+}
+
+func date(s string) time.Time {
+	t, err := time.Parse("2006-01-02 15:04", s)
+	if err != nil {
+		panic(err)
+	}
+
+	return t
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"a * * * *",
+		"0 0 31 2 *",
+	}
+
+	for _, expr := range tests {
+		if _, err := Parse(expr); !errors.Is(err, ErrInvalidExpression) {
+			t.Errorf("Parse(%q) = %v, want %v", expr, err, ErrInvalidExpression)
+		}
+	}
+}
+
+func TestNext(t *testing.T) {
+	tests := []struct {
+		expr string
+		from string
+		want string
+	}{
+		{expr: "* * * * *", from: "2026-03-10 12:00", want: "2026-03-10 12:01"},
+		{expr: "*/15 * * * *", from: "2026-03-10 12:07", want: "2026-03-10 12:15"},
+		{expr: "0 9 * * *", from: "2026-03-10 09:00", want: "2026-03-11 09:00"},
+		{expr: "30 8-10/2 * * *", from: "2026-03-10 08:30", want: "2026-03-10 10:30"},
+		{expr: "0 0 1 * *", from: "2026-01-31 12:00", want: "2026-02-01 00:00"},
+		{expr: "0 0 * * 0", from: "2026-03-10 00:00", want: "2026-03-15 00:00"},
+		{expr: "0 0 * * 7", from: "2026-03-10 00:00", want: "2026-03-15 00:00"},
+		{expr: "0 0 29 2 *", from: "2026-03-01 00:00", want: "2028-02-29 00:00"},
+		{expr: "0 12 * 12 *", from: "2026-03-10 00:00", want: "2026-12-01 12:00"},
+		// заданы день месяца и день недели: достаточно любого
+		{expr: "0 0 13 * 5", from: "2026-03-10 00:00", want: "2026-03-13 00:00"},
+		{expr: "0 0 20 * 1", from: "2026-03-10 00:00", want: "2026-03-16 00:00"},
+	}
+
+	for _, tt := range tests {
+		got := mustParse(t, tt.expr).Next(date(tt.from))
+		if !got.Equal(date(tt.want)) {
+			t.Errorf("Next(%q, %s) = %s, want %s", tt.expr, tt.from, got.Format("2006-01-02 15:04"), tt.want)
+		}
+	}
+}
+
+func TestNextUsesUTC(t *testing.T) {
+	msk := time.FixedZone("MSK", 3*60*60)
+
+	got := mustParse(t, "0 9 * * *").Next(time.Date(2026, time.March, 10, 11, 0, 0, 0, msk))
+	if want := date("2026-03-10 09:00"); !got.Equal(want) || got.Location() != time.UTC {
+		t.Fatalf("got %s, want %s", got, want)
+	}
+}
+
+func TestMinInterval(t *testing.T) {
+	tests := []struct {
+		expr string
+		want time.Duration
+	}{
+		{expr: "* * * * *", want: time.Minute},
+		{expr: "*/5 * * * *", want: 5 * time.Minute},
+		{expr: "0,50 * * * *", want: 10 * time.Minute},
+		{expr: "0 * * * *", want: time.Hour},
+		{expr: "30 23 * * *", want: 24 * time.Hour},
+		// 23:59 и 00:00 следующего дня
+		{expr: "0,59 0,23 * * *", want: time.Minute},
+		{expr: "0 9,17 * * 1-5", want: 8 * time.Hour},
+		{expr: "0 0 * * 1", want: 7 * 24 * time.Hour},
+		{expr: "0 0 1,2 * *", want: 24 * time.Hour},
+		// 31-е число или понедельник: понедельник 1-го после воскресенья 31-го
+		{expr: "0 0 31 * 1", want: 24 * time.Hour},
+	}
+
+	for _, tt := range tests {
+		if got := mustParse(t, tt.expr).MinInterval(); got != tt.want {
+			t.Errorf("MinInterval(%q) = %s, want %s", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestMinIntervalMatchesNext(t *testing.T) {
+	for _, expr := range []string{"*/7 */5 * * *", "15 3 1,15 * *", "0 6 * 2 0"} {
+		s := mustParse(t, expr)
+
+		want := time.Duration(1<<63 - 1)
+
+		prev := s.Next(date("2026-01-01 00:00"))
+		for range 500 {
+			next := s.Next(prev)
+			want = min(want, next.Sub(prev))
+			prev = next
+		}
+
+		if got := s.MinInterval(); got != want {
+			t.Errorf("MinInterval(%q) = %s, want %s from consecutive Next", expr, got, want)
+		}
+	}
+}
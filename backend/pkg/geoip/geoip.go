@@ -2,6 +2,7 @@ package geoip
 
 import (
 	"fmt"
+	"math"
 	"net"
 	"strings"
 
@@ -28,18 +29,21 @@ type GeoIP interface {
 type Geo struct {
 	countryDB *geoip2.Reader // GeoLite2-Country.mmdb
 	asnDB     *geoip2.Reader // GeoLite2-ASN.mmdb
+	cityDB    *geoip2.Reader // GeoLite2-City.mmdb
 }
 
-func NewGeo(countryPath, asnPath string) (g *Geo, err error) {
+// NewGeo открывает базы GeoLite2. Country обязательна, ASN и City — если задан путь.
+func NewGeo(countryPath, asnPath, cityPath string) (g *Geo, err error) {
 	cdb, err := geoip2.Open(countryPath)
 	if err != nil {
 		return nil, err
 	}
 
-	var adb *geoip2.Reader
+	g = &Geo{countryDB: cdb}
+
 	if asnPath != "" {
-		if adb, err = geoip2.Open(asnPath); err != nil {
-			if cErr := cdb.Close(); cErr != nil {
+		if g.asnDB, err = geoip2.Open(asnPath); err != nil {
+			if cErr := g.Close(); cErr != nil {
 				err = fmt.Errorf("%w, failed to close geoip db: %v", err, cErr)
 			}
 
@@ -47,13 +51,26 @@ func NewGeo(countryPath, asnPath string) (g *Geo, err error) {
 		}
 	}
 
-	return &Geo{
-		countryDB: cdb,
-		asnDB:     adb,
-	}, nil
+	if cityPath != "" {
+		if g.cityDB, err = geoip2.Open(cityPath); err != nil {
+			if cErr := g.Close(); cErr != nil {
+				err = fmt.Errorf("%w, failed to close geoip db: %v", err, cErr)
+			}
+
+			return nil, err
+		}
+	}
+
+	return g, nil
 }
 
 func (g *Geo) Close() (err error) {
+	if g.cityDB != nil {
+		if cErr := g.cityDB.Close(); cErr != nil {
+			err = fmt.Errorf("%w, failed to close geoip db: %v", err, cErr)
+		}
+	}
+
 	if g.asnDB != nil {
 		if cErr := g.asnDB.Close(); cErr != nil {
 			err = fmt.Errorf("%w, failed to close geoip db: %v", err, cErr)
@@ -70,10 +87,15 @@ func (g *Geo) Close() (err error) {
 }
 
 type GeoInfo struct {
-	ASN       int
-	CC        string // ISO-2
-	Continent string // EU, AS, NA, OC, AF, SA, AN
-	Region    string
+	ASN         int
+	ASOrg       string
+	CC          string // ISO-2
+	Continent   string // EU, AS, NA, OC, AF, SA, AN
+	Region      string
+	City        string
+	Lat         float64
+	Lon         float64
+	HasLocation bool // координаты есть только при подключённой City базе
 }
 
 func (g *Geo) Lookup(ip net.IP) GeoInfo {
@@ -86,10 +108,25 @@ func (g *Geo) Lookup(ip net.IP) GeoInfo {
 	if g.asnDB != nil {
 		if rec, err := g.asnDB.ASN(ip); err == nil && rec != nil {
 			out.ASN = int(rec.AutonomousSystemNumber)
+			out.ASOrg = rec.AutonomousSystemOrganization
 		}
 	}
 
-	if g.countryDB != nil {
+	if g.cityDB != nil {
+		if rec, err := g.cityDB.City(ip); err == nil && rec != nil {
+			out.CC = rec.Country.IsoCode
+			out.Continent = rec.Continent.Code
+			out.City = rec.City.Names["en"]
+
+			if rec.Location.Latitude != 0 || rec.Location.Longitude != 0 {
+				out.Lat = rec.Location.Latitude
+				out.Lon = rec.Location.Longitude
+				out.HasLocation = true
+			}
+		}
+	}
+
+	if g.countryDB != nil && out.CC == "" {
 		if rec, err := g.countryDB.Country(ip); err == nil && rec != nil {
 			out.CC = rec.Country.IsoCode
 			if rec.Continent.Code != "" {
@@ -99,7 +136,7 @@ func (g *Geo) Lookup(ip net.IP) GeoInfo {
 	}
 
 	switch strings.ToUpper(out.Continent) {
-	case "US":
+	case "NA", "SA":
 		out.Region = UnitedStatesRegion.String()
 	case "AS", "OC":
 		out.Region = APACRegion.String()
@@ -109,3 +146,18 @@ func (g *Geo) Lookup(ip net.IP) GeoInfo {
 
 	return out
 }
+
+const earthRadiusKm = 6371.0
+
+// Distance расстояние по большому кругу между двумя точками в километрах (формула гаверсинусов).
+func Distance(lat1, lon1, lat2, lon2 float64) float64 {
+	toRad := func(deg float64) float64 { return deg * math.Pi / 180 }
+
+	dLat := toRad(lat2 - lat1)
+	dLon := toRad(lon2 - lon1)
+
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRad(lat1))*math.Cos(toRad(lat2))*math.Sin(dLon/2)*math.Sin(dLon/2)
+
+	return 2 * earthRadiusKm * math.Asin(math.Min(1, math.Sqrt(a)))
+}
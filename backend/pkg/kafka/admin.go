@@ -0,0 +1,141 @@
+package kafka
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/IBM/sarama"
+)
+
+var ErrTopicNotFound = fmt.Errorf("kafka topic not found")
+
+// TopicSpec describes how a topic is created. Zero Retention keeps the broker default.
+type TopicSpec struct {
+	Partitions        int32
+	ReplicationFactor int16
+	Retention         time.Duration
+}
+
+// PartitionState is a partition as reported by the cluster metadata.
+type PartitionState struct {
+	ID       int32
+	Leader   int32
+	Replicas []int32
+	ISR      []int32
+	Err      error
+}
+
+// TopicState is a topic as reported by the cluster metadata. Err is ErrTopicNotFound for a missing topic.
+type TopicState struct {
+	Name       string
+	Partitions []PartitionState
+	Err        error
+}
+
+// Admin manages topics of the cluster.
+type Admin interface {
+	EnsureTopic(name string, spec TopicSpec) (created bool, err error)
+	DescribeTopics(names []string) ([]TopicState, error)
+	Close() error
+}
+
+type admin struct {
+	client sarama.ClusterAdmin
+}
+
+// NewAdmin connects a cluster admin to the brokers.
+func NewAdmin(brokers []string, opts ...Option) (Admin, error) {
+	config := sarama.NewConfig()
+	config.Version = sarama.V2_8_0_0
+
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	client, err := sarama.NewClusterAdmin(brokers, config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &admin{client: client}, nil
+}
+
+// EnsureTopic creates the topic unless it already exists. An existing topic is left as is,
+// its partitions and retention are not changed.
+func (a *admin) EnsureTopic(name string, spec TopicSpec) (bool, error) {
+	detail := &sarama.TopicDetail{
+		NumPartitions:     spec.Partitions,
+		ReplicationFactor: spec.ReplicationFactor,
+	}
+
+	if spec.Retention > 0 {
+		retention := strconv.FormatInt(spec.Retention.Milliseconds(), 10)
+		detail.ConfigEntries = map[string]*string{"retention.ms": &retention}
+	}
+
+	err := a.client.CreateTopic(name, detail, false)
+
+	switch {
+	case err == nil:
+		return true, nil
+	case errors.Is(err, sarama.ErrTopicAlreadyExists):
+		return false, nil
+	default:
+		return false, err
+	}
+}
+
+// DescribeTopics returns the state of each topic in names order.
+func (a *admin) DescribeTopics(names []string) ([]TopicState, error) {
+	metadata, err := a.client.DescribeTopics(names)
+	if err != nil {
+		return nil, err
+	}
+
+	byName := make(map[string]*sarama.TopicMetadata, len(metadata))
+	for _, m := range metadata {
+		byName[m.Name] = m
+	}
+
+	states := make([]TopicState, 0, len(names))
+
+	for _, name := range names {
+		state := TopicState{Name: name}
+
+		m, ok := byName[name]
+
+		switch {
+		case !ok || errors.Is(m.Err, sarama.ErrUnknownTopicOrPartition):
+			state.Err = ErrTopicNotFound
+		case !errors.Is(m.Err, sarama.ErrNoError):
+			state.Err = m.Err
+		}
+
+		if ok {
+			for _, p := range m.Partitions {
+				partition := PartitionState{
+					ID:       p.ID,
+					Leader:   p.Leader,
+					Replicas: p.Replicas,
+					ISR:      p.Isr,
+				}
+
+				if !errors.Is(p.Err, sarama.ErrNoError) {
+					partition.Err = p.Err
+				}
+
+				state.Partitions = append(state.Partitions, partition)
+			}
+		}
+
+		states = append(states, state)
+	}
+
+	return states, nil
+}
+
+func (a *admin) Close() error {
+	return a.client.Close()
+}
@@ -0,0 +1,78 @@
+package rdns
+
+import (
+	"context"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Resolver обратный DNS (PTR) с кешем в памяти: ответ, в том числе пустой, живёт ttl.
+type Resolver struct {
+	resolver *net.Resolver
+	timeout  time.Duration
+	ttl      time.Duration
+	maxSize  int
+
+	mu    sync.Mutex
+	cache map[string]entry
+}
+
+type entry struct {
+	host string
+	exp  time.Time
+}
+
+func NewResolver(timeout, ttl time.Duration, maxSize int) *Resolver {
+	return &Resolver{
+		resolver: net.DefaultResolver,
+		timeout:  timeout,
+		ttl:      ttl,
+		maxSize:  maxSize,
+		cache:    make(map[string]entry, maxSize),
+	}
+}
+
+// Lookup возвращает первое PTR имя ip без точки в конце или пустую строку, если записи нет.
+func (r *Resolver) Lookup(ctx context.Context, ip string) string {
+	now := time.Now()
+
+	r.mu.Lock()
+	e, ok := r.cache[ip]
+	r.mu.Unlock()
+
+	if ok && now.Before(e.exp) {
+		return e.host
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	var host string
+	if names, err := r.resolver.LookupAddr(ctx, ip); err == nil && len(names) > 0 {
+		host = strings.TrimSuffix(names[0], ".")
+	} else if ctx.Err() != nil {
+		// таймаут не кешируем, в следующий раз резолвер может успеть
+		return ""
+	}
+
+	r.mu.Lock()
+	if len(r.cache) >= r.maxSize {
+		r.evictExpired(now)
+	}
+	if len(r.cache) < r.maxSize {
+		r.cache[ip] = entry{host: host, exp: now.Add(r.ttl)}
+	}
+	r.mu.Unlock()
+
+	return host
+}
+
+func (r *Resolver) evictExpired(now time.Time) {
+	for ip, e := range r.cache {
+		if now.After(e.exp) {
+			delete(r.cache, ip)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package contract
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"slices"
+	"strings"
+)
+
+var (
+	ErrCheckNotSupported = errors.New("check type is not supported by agent")
+	ErrNoIPv4            = errors.New("agent has no IPv4 connectivity")
+	ErrNoIPv6            = errors.New("agent has no IPv6 connectivity")
+	ErrNoICMP            = errors.New("agent has no raw ICMP privilege")
+)
+
+// Capabilities что умеет агент на своём хосте. Агент определяет их при старте и присылает в heartbeat.
+type Capabilities struct {
+	Checks []string `json:"checks"` // Checks включённые проверки, для которых на хосте есть нужные утилиты
+	IPv4   bool     `json:"ipv4"`
+	IPv6   bool     `json:"ipv6"`
+	ICMP   bool     `json:"icmp"` // ICMP агент может открыть raw сокет, без него traceroute работает только в режиме udp
+}
+
+// CanRun проверяет, что агент может выполнить проверку check по target. Возвращает причину, если нет.
+func (c Capabilities) CanRun(target string, check CheckRequest) error {
+	checkType := strings.ToLower(check.Type)
+
+	if !slices.Contains(c.Checks, checkType) {
+		return fmt.Errorf("%w: %q", ErrCheckNotSupported, checkType)
+	}
+
+	// для доменов семейство адресов заранее неизвестно, требование есть только у IP литералов
+	if addr, err := netip.ParseAddr(strings.Trim(target, "[]")); err == nil {
+		switch {
+		case addr.Unmap().Is4() && !c.IPv4:
+			return ErrNoIPv4
+		case !addr.Unmap().Is4() && !c.IPv6:
+			return ErrNoIPv6
+		}
+	}
+
+	if checkType == CheckTraceroute && !c.ICMP {
+		mode, _ := check.Params["mode"].(string)
+		if mode = strings.ToLower(mode); mode == "icmp" || mode == "tcp" {
+			return fmt.Errorf("%w: traceroute mode %q", ErrNoICMP, mode)
+		}
+	}
+
+	return nil
+}
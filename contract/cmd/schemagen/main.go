@@ -0,0 +1,66 @@
+// Command schemagen пишет JSON Schema контракта в каталог schema/.
+//
+//	go run ./cmd/schemagen -out schema
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+	"path/filepath"
+
+	"hackathon-contract"
+)
+
+func main() {
+	out := flag.String("out", "schema", "output directory")
+	flag.Parse()
+
+	if err := os.MkdirAll(*out, 0o755); err != nil {
+		log.Fatalf("failed to create output dir: %v", err)
+	}
+
+	files := map[string]*contract.Schema{
+		"task.json":      contract.TaskSchema(),
+		"result.json":    contract.ResultSchema(),
+		"heartbeat.json": contract.HeartbeatSchema(),
+		"gateway.json":   contract.GatewaySchema(),
+		"envelope.json":  contract.EnvelopeSchema(),
+		"enroll.json":    contract.EnrollSchema(),
+	}
+
+	for _, t := range contract.CheckTypes() {
+		params, err := contract.ParamsSchema(t)
+		if err != nil {
+			log.Fatalf("failed to build params schema: %v", err)
+		}
+
+		payload, err := contract.PayloadSchema(t)
+		if err != nil {
+			log.Fatalf("failed to build payload schema: %v", err)
+		}
+
+		files[filepath.Join("params", t+".json")] = params
+		files[filepath.Join("payload", t+".json")] = payload
+	}
+
+	for name, s := range files {
+		if err := write(filepath.Join(*out, name), s); err != nil {
+			log.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+}
+
+func write(path string, s *contract.Schema) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	b, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, append(b, '\n'), 0o644)
+}
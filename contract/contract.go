@@ -0,0 +1,64 @@
+// Package contract описывает проводной контракт между бэкендом и агентами:
+// задачу (TaskMessage), параметры проверок, результаты (CheckResult) и их payload, heartbeat агентов
+// и подписанный конверт (Envelope), в котором они передаются.
+// Агент и бэкенд компилируются против одних и тех же определений,
+// а версия схемы в каждом сообщении позволяет отбрасывать несовместимые.
+package contract
+
+//go:generate go run ./cmd/schemagen -out schema
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// SchemaVersion текущая версия контракта в формате MAJOR.MINOR.
+// MINOR растёт при обратно совместимых изменениях (новые необязательные поля, новые типы проверок),
+// MAJOR — при несовместимых.
+const SchemaVersion = "2.7"
+
+// LegacyVersion версия, которую подразумевают сообщения без поля version (до появления контракта).
+const LegacyVersion = "1.0"
+
+var (
+	ErrIncompatibleVersion = errors.New("incompatible schema version")
+	ErrInvalidVersion      = errors.New("invalid schema version")
+	ErrUnknownCheckType    = errors.New("unknown check type")
+)
+
+// CheckVersion проверяет, что сообщение версии v можно обработать текущей версией контракта:
+// совпадать должна мажорная версия.
+func CheckVersion(v string) error {
+	if v == "" {
+		v = LegacyVersion
+	}
+
+	major, err := majorVersion(v)
+	if err != nil {
+		return err
+	}
+
+	current, err := majorVersion(SchemaVersion)
+	if err != nil {
+		return err
+	}
+
+	if major != current {
+		return fmt.Errorf("%w: got %s, supported %d.x", ErrIncompatibleVersion, v, current)
+	}
+
+	return nil
+}
+
+func majorVersion(v string) (int, error) {
+	majorStr, _, _ := strings.Cut(v, ".")
+
+	major, err := strconv.Atoi(majorStr)
+	if err != nil || major < 0 {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidVersion, v)
+	}
+
+	return major, nil
+}
@@ -0,0 +1,35 @@
+package contract
+
+import (
+	"github.com/google/uuid"
+)
+
+// EnrollRequest агент при первом запуске обменивает одноразовый токен, выданный администратором,
+// на свой agent_id и настройки. Бэкенд привязывает к агенту PublicKey: дальше им проверяются heartbeat и результаты.
+type EnrollRequest struct {
+	Version      string `json:"version"` // Version версия контракта, см. SchemaVersion
+	Token        string `json:"token"`
+	PublicKey    []byte `json:"publicKey"`              // PublicKey публичный ключ Ed25519 агента, base64
+	AgentVersion string `json:"agentVersion,omitempty"` // AgentVersion версия сборки агента, сохраняется в истории регистраций
+}
+
+// EnrollResponse всё, что агенту нужно для работы, кроме адреса бэкенда и самого ключа агента.
+type EnrollResponse struct {
+	AgentID          uuid.UUID    `json:"agentId"`
+	Region           string       `json:"region"`
+	Country          string       `json:"country,omitempty"`
+	ASN              int          `json:"asn,omitempty"`
+	Location         *Location    `json:"location,omitempty"`
+	Transport        string       `json:"transport"` // Transport TransportKafka или TransportHTTP
+	Topics           EnrollTopics `json:"topics"`
+	Brokers          []string     `json:"brokers,omitempty"`      // Brokers адреса Kafka для TransportKafka
+	GatewayToken     string       `json:"gatewayToken,omitempty"` // GatewayToken токен HTTP транспорта, выданный только этому агенту; пусто — gateway выключен
+	BackendPublicKey []byte       `json:"backendPublicKey"`       // BackendPublicKey ключ Ed25519, которым бэкенд подписывает задачи, base64
+}
+
+// EnrollTopics топики агента: задачи его региона, результаты и heartbeat.
+type EnrollTopics struct {
+	Tasks     string `json:"tasks"`
+	Results   string `json:"results"`
+	Heartbeat string `json:"heartbeat"`
+}
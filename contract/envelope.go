@@ -0,0 +1,116 @@
+package contract
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// BackendSigner подписант задач. Агенты подписывают результаты и heartbeat своим id.
+const BackendSigner = "backend"
+
+// envelopeDomain отделяет подпись конверта от любых других подписей тем же ключом.
+const envelopeDomain = "hackathon-contract/envelope/v1"
+
+var (
+	ErrInvalidSignature = errors.New("invalid envelope signature")
+	ErrEnvelopeExpired  = errors.New("envelope expired")
+	ErrUnexpectedSigner = errors.New("unexpected envelope signer")
+	ErrInvalidKey       = errors.New("invalid ed25519 key")
+)
+
+// Envelope подписанная обёртка сообщения: задачи от бэкенда, результата или heartbeat от агента.
+// Payload — исходное сообщение как есть, подпись Ed25519 покрывает его вместе с заголовками конверта.
+type Envelope struct {
+	Version   string          `json:"version"` // Version версия контракта, см. SchemaVersion
+	Signer    string          `json:"signer"`  // Signer BackendSigner или id агента
+	IssuedAt  time.Time       `json:"issuedAt"`
+	ExpiresAt *time.Time      `json:"expiresAt,omitempty"` // ExpiresAt после этого момента сообщение отбрасывается, нет — бессрочно
+	Payload   json.RawMessage `json:"payload"`             // Payload TaskMessage, CheckResult или Heartbeat
+	Signature []byte          `json:"signature"`           // Signature подпись Ed25519, base64
+}
+
+// Seal подписывает payload ключом key от имени signer. ttl 0 — конверт без срока действия.
+func Seal(key ed25519.PrivateKey, signer string, payload []byte, issuedAt time.Time, ttl time.Duration) (*Envelope, error) {
+	if len(key) != ed25519.PrivateKeySize {
+		return nil, ErrInvalidKey
+	}
+
+	// компактный JSON: payload не должен меняться при пересериализации конверта
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, payload); err != nil {
+		return nil, fmt.Errorf("bad payload json: %w", err)
+	}
+
+	env := &Envelope{
+		Version:  SchemaVersion,
+		Signer:   signer,
+		IssuedAt: issuedAt.UTC(),
+		Payload:  compact.Bytes(),
+	}
+
+	if ttl > 0 {
+		expiresAt := env.IssuedAt.Add(ttl)
+		env.ExpiresAt = &expiresAt
+	}
+
+	env.Signature = ed25519.Sign(key, env.signingBytes())
+
+	return env, nil
+}
+
+// OpenEnvelope разбирает конверт и проверяет версию контракта. Подпись проверяет Verify.
+func OpenEnvelope(data []byte) (*Envelope, error) {
+	var env Envelope
+	if err := json.Unmarshal(data, &env); err != nil {
+		return nil, fmt.Errorf("bad envelope json: %w", err)
+	}
+
+	if err := CheckVersion(env.Version); err != nil {
+		return nil, err
+	}
+
+	return &env, nil
+}
+
+// Verify проверяет, что конверт подписан signer ключом pub и не истёк к моменту now.
+func (e *Envelope) Verify(pub ed25519.PublicKey, signer string, now time.Time) error {
+	if e.Signer != signer {
+		return fmt.Errorf("%w: got %q, want %q", ErrUnexpectedSigner, e.Signer, signer)
+	}
+
+	if len(pub) != ed25519.PublicKeySize {
+		return ErrInvalidKey
+	}
+
+	if !ed25519.Verify(pub, e.signingBytes(), e.Signature) {
+		return ErrInvalidSignature
+	}
+
+	if e.ExpiresAt != nil && now.After(*e.ExpiresAt) {
+		return fmt.Errorf("%w at %s", ErrEnvelopeExpired, e.ExpiresAt.Format(time.RFC3339))
+	}
+
+	return nil
+}
+
+func (e *Envelope) signingBytes() []byte {
+	var expiresAt string
+	if e.ExpiresAt != nil {
+		expiresAt = e.ExpiresAt.UTC().Format(time.RFC3339Nano)
+	}
+
+	var b bytes.Buffer
+
+	for _, part := range []string{envelopeDomain, e.Version, e.Signer, e.IssuedAt.UTC().Format(time.RFC3339Nano), expiresAt} {
+		b.WriteString(part)
+		b.WriteByte('\n')
+	}
+
+	b.Write(e.Payload)
+
+	return b.Bytes()
+}
@@ -0,0 +1,199 @@
+package contract
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func testKey(t *testing.T) (ed25519.PublicKey, ed25519.PrivateKey) {
+	t.Helper()
+
+	pub, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+
+	return pub, key
+}
+
+// sealed конверт после передачи по сети: сериализован и разобран заново.
+func sealed(t *testing.T, key ed25519.PrivateKey, signer string, issuedAt time.Time, ttl time.Duration) *Envelope {
+	t.Helper()
+
+	env, err := Seal(key, signer, []byte(`{ "target": "example.com" }`), issuedAt, ttl)
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+
+	b, err := json.Marshal(env)
+	if err != nil {
+		t.Fatalf("marshal envelope: %v", err)
+	}
+
+	opened, err := OpenEnvelope(b)
+	if err != nil {
+		t.Fatalf("OpenEnvelope: %v", err)
+	}
+
+	return opened
+}
+
+func TestEnvelopeVerify(t *testing.T) {
+	pub, key := testKey(t)
+	otherPub, _ := testKey(t)
+
+	issuedAt := time.Date(2026, time.March, 10, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		env     func() *Envelope
+		pub     ed25519.PublicKey
+		signer  string
+		now     time.Time
+		wantErr error
+	}{
+		{
+			name:   "valid",
+			env:    func() *Envelope { return sealed(t, key, BackendSigner, issuedAt, time.Minute) },
+			pub:    pub,
+			signer: BackendSigner,
+			now:    issuedAt.Add(30 * time.Second),
+		},
+		{
+			name:   "without expiry",
+			env:    func() *Envelope { return sealed(t, key, "agent-1", issuedAt, 0) },
+			pub:    pub,
+			signer: "agent-1",
+			now:    issuedAt.Add(24 * time.Hour),
+		},
+		{
+			name:    "expired",
+			env:     func() *Envelope { return sealed(t, key, BackendSigner, issuedAt, time.Minute) },
+			pub:     pub,
+			signer:  BackendSigner,
+			now:     issuedAt.Add(2 * time.Minute),
+			wantErr: ErrEnvelopeExpired,
+		},
+		{
+			name:    "wrong key",
+			env:     func() *Envelope { return sealed(t, key, BackendSigner, issuedAt, time.Minute) },
+			pub:     otherPub,
+			signer:  BackendSigner,
+			now:     issuedAt,
+			wantErr: ErrInvalidSignature,
+		},
+		{
+			name:    "unexpected signer",
+			env:     func() *Envelope { return sealed(t, key, "agent-1", issuedAt, 0) },
+			pub:     pub,
+			signer:  BackendSigner,
+			now:     issuedAt,
+			wantErr: ErrUnexpectedSigner,
+		},
+		{
+			name: "tampered payload",
+			env: func() *Envelope {
+				env := sealed(t, key, BackendSigner, issuedAt, time.Minute)
+				env.Payload = json.RawMessage(`{"target":"evil.example"}`)
+
+				return env
+			},
+			pub:     pub,
+			signer:  BackendSigner,
+			now:     issuedAt,
+			wantErr: ErrInvalidSignature,
+		},
+		{
+			name: "tampered signer",
+			env: func() *Envelope {
+				env := sealed(t, key, "agent-1", issuedAt, 0)
+				env.Signer = "agent-2"
+
+				return env
+			},
+			pub:     pub,
+			signer:  "agent-2",
+			now:     issuedAt,
+			wantErr: ErrInvalidSignature,
+		},
+		{
+			name: "extended expiry",
+			env: func() *Envelope {
+				env := sealed(t, key, BackendSigner, issuedAt, time.Minute)
+				expiresAt := env.ExpiresAt.Add(time.Hour)
+				env.ExpiresAt = &expiresAt
+
+				return env
+			},
+			pub:     pub,
+			signer:  BackendSigner,
+			now:     issuedAt.Add(2 * time.Minute),
+			wantErr: ErrInvalidSignature,
+		},
+		{
+			name: "expiry removed",
+			env: func() *Envelope {
+				env := sealed(t, key, BackendSigner, issuedAt, time.Minute)
+				env.ExpiresAt = nil
+
+				return env
+			},
+			pub:     pub,
+			signer:  BackendSigner,
+			now:     issuedAt.Add(2 * time.Minute),
+			wantErr: ErrInvalidSignature,
+		},
+		{
+			name:    "invalid public key",
+			env:     func() *Envelope { return sealed(t, key, BackendSigner, issuedAt, time.Minute) },
+			pub:     pub[:16],
+			signer:  BackendSigner,
+			now:     issuedAt,
+			wantErr: ErrInvalidKey,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.env().Verify(tt.pub, tt.signer, tt.now)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Verify = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSealCompactsPayload(t *testing.T) {
+	_, key := testKey(t)
+
+	env := sealed(t, key, BackendSigner, time.Now(), 0)
+	if string(env.Payload) != `{"target":"example.com"}` {
+		t.Fatalf("got payload %s", env.Payload)
+	}
+}
+
+func TestSealErrors(t *testing.T) {
+	_, key := testKey(t)
+
+	if _, err := Seal(key[:10], BackendSigner, []byte(`{}`), time.Now(), 0); !errors.Is(err, ErrInvalidKey) {
+		t.Fatalf("got %v, want %v", err, ErrInvalidKey)
+	}
+
+	if _, err := Seal(key, BackendSigner, []byte(`not json`), time.Now(), 0); err == nil {
+		t.Fatal("expected error for invalid payload")
+	}
+}
+
+func TestOpenEnvelopeRejectsUnsupportedVersion(t *testing.T) {
+	if _, err := OpenEnvelope([]byte(`{"version":"99.0","signer":"backend","payload":{}}`)); err == nil {
+		t.Fatal("expected error for unsupported major version")
+	}
+
+	if _, err := OpenEnvelope([]byte(`not json`)); err == nil {
+		t.Fatal("expected error for invalid json")
+	}
+}
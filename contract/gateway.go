@@ -0,0 +1,26 @@
+package contract
+
+import (
+	"encoding/json"
+
+	"github.com/google/uuid"
+)
+
+// Транспорт, по которому агент получает задачи и отправляет результаты.
+const (
+	TransportKafka = "kafka" // TransportKafka агент напрямую ходит в брокеры
+	TransportHTTP  = "http"  // TransportHTTP агент за NAT: long-poll задач и отправка сообщений через HTTPS бэкенда
+)
+
+// GatewayMessage сообщение HTTP транспорта, заменяющее сообщение Kafka: задача из топика региона
+// или результат/heartbeat, адресованный топику бэкенда. Key и Value те же, что ушли бы в Kafka.
+type GatewayMessage struct {
+	Topic string          `json:"topic"`
+	Key   uuid.UUID       `json:"key"`
+	Value json.RawMessage `json:"value"`
+}
+
+// GatewayPoll ответ бэкенда на long-poll агента. Пустой список — за время ожидания задач не было.
+type GatewayPoll struct {
+	Messages []GatewayMessage `json:"messages"`
+}
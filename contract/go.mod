@@ -0,0 +1,5 @@
+module hackathon-contract
+
+go 1.25.2
+
+require github.com/google/uuid v1.6.0
@@ -0,0 +1,32 @@
+package contract
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Heartbeat периодическое сообщение агента о том, что он жив, и о его загрузке.
+// Агент публикует его в отдельный топик, бэкенд по нему ведёт online/last_seen агентов реестра.
+// Регион, страну, ASN и координаты бэкенд берёт из реестра, значения из heartbeat не сохраняются.
+type Heartbeat struct {
+	Version       string        `json:"version"` // Version версия контракта, см. SchemaVersion
+	AgentID       uuid.UUID     `json:"agentId"`
+	Region        string        `json:"region"`
+	Country       string        `json:"country,omitempty"`      // Country страна размещения агента, ISO 3166-1 alpha-2; нет у агентов до версии 2.3
+	ASN           int           `json:"asn,omitempty"`          // ASN автономная система сети агента; нет у агентов до версии 2.3
+	Location      *Location     `json:"location,omitempty"`     // Location координаты агента, по ним выбирается ближайший к клиенту; нет у агентов до версии 2.4
+	AgentVersion  string        `json:"agentVersion,omitempty"` // AgentVersion версия сборки агента
+	SentAt        time.Time     `json:"sentAt"`
+	UptimeSeconds int64         `json:"uptimeSeconds"`
+	InFlight      int           `json:"inFlight"`               // InFlight задачи, которые агент выполняет прямо сейчас
+	QueueDepth    int           `json:"queueDepth"`             // QueueDepth задачи, прочитанные из топика и ждущие свободного воркера
+	Capabilities  *Capabilities `json:"capabilities,omitempty"` // Capabilities возможности агента, нет у агентов до версии 1.4
+	Transport     string        `json:"transport,omitempty"`    // Transport TransportKafka или TransportHTTP, пусто у агентов до версии 1.5 — kafka
+}
+
+// Location координаты размещения агента в градусах WGS 84.
+type Location struct {
+	Lat float64 `json:"lat"`
+	Lon float64 `json:"lon"`
+}
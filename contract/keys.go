@@ -0,0 +1,136 @@
+package contract
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ParsePrivateKeyPEM разбирает приватный ключ Ed25519 в PEM (PKCS#8).
+func ParsePrivateKeyPEM(data []byte) (ed25519.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%w: no PEM block", ErrInvalidKey)
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidKey, err)
+	}
+
+	priv, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("%w: not an ed25519 key", ErrInvalidKey)
+	}
+
+	return priv, nil
+}
+
+// ParsePublicKeyPEM разбирает публичный ключ Ed25519 в PEM (PKIX).
+func ParsePublicKeyPEM(data []byte) (ed25519.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%w: no PEM block", ErrInvalidKey)
+	}
+
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidKey, err)
+	}
+
+	pub, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("%w: not an ed25519 key", ErrInvalidKey)
+	}
+
+	return pub, nil
+}
+
+// MarshalPrivateKeyPEM кодирует приватный ключ Ed25519 в PEM (PKCS#8).
+func MarshalPrivateKeyPEM(key ed25519.PrivateKey) ([]byte, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+}
+
+// MarshalPublicKeyPEM кодирует публичный ключ Ed25519 в PEM (PKIX).
+func MarshalPublicKeyPEM(key ed25519.PublicKey) ([]byte, error) {
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), nil
+}
+
+// LoadPublicKey читает публичный ключ Ed25519 из PEM файла.
+func LoadPublicKey(path string) (ed25519.PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read public key: %w", err)
+	}
+
+	return ParsePublicKeyPEM(data)
+}
+
+// LoadOrGenerateKey читает приватный ключ Ed25519 из privPath, а если файла нет — создаёт новую пару:
+// приватный ключ с правами 0600 и, если pubPath не пустой, публичный рядом. generated сообщает о создании.
+func LoadOrGenerateKey(privPath, pubPath string) (key ed25519.PrivateKey, generated bool, err error) {
+	data, err := os.ReadFile(privPath)
+	if err == nil {
+		key, err = ParsePrivateKeyPEM(data)
+
+		return key, false, err
+	}
+
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, false, fmt.Errorf("failed to read private key: %w", err)
+	}
+
+	pub, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to generate key: %w", err)
+	}
+
+	privPEM, err := MarshalPrivateKeyPEM(key)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to encode private key: %w", err)
+	}
+
+	if err := writeKeyFile(privPath, privPEM, 0o600); err != nil {
+		return nil, false, err
+	}
+
+	if pubPath != "" {
+		pubPEM, err := MarshalPublicKeyPEM(pub)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to encode public key: %w", err)
+		}
+
+		if err := writeKeyFile(pubPath, pubPEM, 0o644); err != nil {
+			return nil, false, err
+		}
+	}
+
+	return key, true, nil
+}
+
+func writeKeyFile(path string, data []byte, perm os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create key dir: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, perm); err != nil {
+		return fmt.Errorf("failed to write key %s: %w", path, err)
+	}
+
+	return nil
+}
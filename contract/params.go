@@ -0,0 +1,119 @@
+package contract
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Типы проверок.
+const (
+	CheckHTTP       = "http"
+	CheckPing       = "ping"
+	CheckTCP        = "tcp"
+	CheckTraceroute = "traceroute"
+	CheckDNS        = "dns"
+	CheckWebSocket  = "websocket"
+	CheckGRPC       = "grpc"
+	CheckThroughput = "throughput"
+)
+
+// Параметры проверок. Тег jsonschema описывает ограничения и значения по умолчанию,
+// из него генерируется JSON Schema (см. schema.go): required, default=..., minimum=..., maximum=...,
+// minLength=..., enum=a|b|c, description=... (описание — последним, может содержать запятые).
+
+type HTTPParams struct {
+	Scheme              string            `json:"scheme" jsonschema:"default=https,enum=http|https"`
+	Path                string            `json:"path" jsonschema:"default=/"`
+	Headers             map[string]string `json:"headers,omitempty"`
+	ExpectedStatusRange [2]int            `json:"expectedStatusRange" jsonschema:"default=[200,299],minimum=100,maximum=599"`
+	FollowRedirects     bool              `json:"followRedirects"`
+	MaxBodyBytes        int               `json:"maxBodyBytes" jsonschema:"minimum=0,maximum=10485760"`
+}
+
+type PingParams struct {
+	Count      int `json:"count" jsonschema:"default=4,minimum=1,maximum=100"`
+	IntervalMs int `json:"intervalMs" jsonschema:"default=1000,minimum=50,maximum=10000"`
+}
+
+type TCPParams struct {
+	Port             int `json:"port" jsonschema:"required,minimum=1,maximum=65535"`
+	ConnectTimeoutMs int `json:"connectTimeoutMs" jsonschema:"default=3000,minimum=1,maximum=30000"`
+}
+
+type TracerouteParams struct {
+	Mode    string `json:"mode" jsonschema:"default=udp,enum=udp|tcp|icmp"`
+	Port    int    `json:"port" jsonschema:"minimum=0,maximum=65535"`
+	MaxHops int    `json:"maxHops" jsonschema:"default=30,minimum=1,maximum=64"`
+	Paris   bool   `json:"paris"`
+}
+
+type DNSParams struct {
+	Records  []string `json:"records" jsonschema:"default=[\"A\"],enum=A|AAAA|MX"`
+	Resolver string   `json:"resolver,omitempty" jsonschema:"description=IP адрес резолвера, по умолчанию системный"`
+}
+
+type WebSocketParams struct {
+	Scheme        string            `json:"scheme" jsonschema:"default=wss,enum=ws|wss"`
+	Path          string            `json:"path" jsonschema:"default=/"`
+	Port          int               `json:"port,omitempty" jsonschema:"minimum=0,maximum=65535"`
+	Headers       map[string]string `json:"headers,omitempty"`
+	Subprotocols  []string          `json:"subprotocols,omitempty"`
+	Message       string            `json:"message,omitempty" jsonschema:"description=сообщение, которое отправляется после upgrade"`
+	Expect        string            `json:"expect,omitempty" jsonschema:"description=подстрока, которую должен содержать ответ"`
+	ReadTimeoutMs int               `json:"readTimeoutMs" jsonschema:"default=3000,minimum=1,maximum=30000"`
+}
+
+type GRPCParams struct {
+	Port               int    `json:"port" jsonschema:"minimum=0,maximum=65535,description=по умолчанию 443 с TLS и 50051 без"`
+	Service            string `json:"service,omitempty"`
+	TLS                bool   `json:"tls"`
+	InsecureSkipVerify bool   `json:"insecureSkipVerify,omitempty"`
+	Authority          string `json:"authority,omitempty"`
+}
+
+type ThroughputParams struct {
+	Scheme           string            `json:"scheme" jsonschema:"default=https,enum=http|https"`
+	Path             string            `json:"path" jsonschema:"default=/"`
+	Headers          map[string]string `json:"headers,omitempty"`
+	RangeStart       int64             `json:"rangeStart,omitempty" jsonschema:"minimum=0"`
+	RangeEnd         int64             `json:"rangeEnd,omitempty" jsonschema:"minimum=0"`
+	MaxBytes         int64             `json:"maxBytes" jsonschema:"default=10485760,minimum=1,maximum=1073741824"`
+	MaxDurationMs    int               `json:"maxDurationMs" jsonschema:"default=10000,minimum=1,maximum=12000"`
+	SampleIntervalMs int               `json:"sampleIntervalMs" jsonschema:"default=250,minimum=10,maximum=5000"`
+	StallThresholdMs int               `json:"stallThresholdMs" jsonschema:"default=500,minimum=10,maximum=10000"`
+}
+
+var paramTypes = map[string]reflect.Type{
+	CheckHTTP:       reflect.TypeFor[HTTPParams](),
+	CheckPing:       reflect.TypeFor[PingParams](),
+	CheckTCP:        reflect.TypeFor[TCPParams](),
+	CheckTraceroute: reflect.TypeFor[TracerouteParams](),
+	CheckDNS:        reflect.TypeFor[DNSParams](),
+	CheckWebSocket:  reflect.TypeFor[WebSocketParams](),
+	CheckGRPC:       reflect.TypeFor[GRPCParams](),
+	CheckThroughput: reflect.TypeFor[ThroughputParams](),
+}
+
+// CheckTypes возвращает отсортированный список известных контракту типов проверок.
+func CheckTypes() []string {
+	types := make([]string, 0, len(paramTypes))
+	for t := range paramTypes {
+		types = append(types, t)
+	}
+
+	sort.Strings(types)
+
+	return types
+}
+
+// NewParams возвращает указатель на пустую структуру параметров для типа проверки.
+func NewParams(checkType string) (any, error) {
+	t, ok := paramTypes[strings.ToLower(checkType)]
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownCheckType, checkType)
+	}
+
+	return reflect.New(t).Interface(), nil
+}
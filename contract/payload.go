@@ -0,0 +1,188 @@
+package contract
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// Payload проверок. Поля с omitempty могут отсутствовать, если проверка упала раньше, чем их измерила.
+
+type HTTPPayload struct {
+	URL        string `json:"url"`
+	Status     int    `json:"status,omitempty"`
+	LatencyMs  int64  `json:"latencyMs,omitempty"`
+	FinalURL   string `json:"finalURL,omitempty"`
+	LimitBytes int    `json:"limitBytes,omitempty"`
+}
+
+type TCPPayload struct {
+	Addr      string `json:"addr"`
+	Handshake int64  `json:"handshake,omitempty"` // Handshake время установки соединения, мс
+}
+
+// CommandPayload общий payload для проверок, которые запускают системную утилиту.
+type CommandPayload struct {
+	Command  string `json:"command"`
+	Output   string `json:"output"`
+	ExitCode int    `json:"exitCode"`
+}
+
+// PingReply один ответ на эхо-запрос, разобранный из вывода ping.
+type PingReply struct {
+	Seq    int     `json:"seq"`
+	TTL    int     `json:"ttl"`
+	TimeMs float64 `json:"timeMs"`
+}
+
+type PingPayload struct {
+	CommandPayload
+	IP      string      `json:"ip,omitempty"`  // IP адрес, который пинговал агент, из вывода ping
+	ASN     int         `json:"asn,omitempty"` // ASN сети адреса, заполняет бэкенд по GeoIP
+	Replies []PingReply `json:"replies,omitempty"`
+}
+
+// Hop узел маршрута. Агент присылает только IP, остальное заполняет бэкенд по GeoIP и PTR.
+type Hop struct {
+	IP       string   `json:"ip"`
+	Lat      *float64 `json:"lat,omitempty"`
+	Lon      *float64 `json:"lon,omitempty"`
+	City     string   `json:"city,omitempty"`
+	Country  string   `json:"country,omitempty"` // Country ISO-2
+	ASN      int      `json:"asn,omitempty"`
+	ASOrg    string   `json:"asOrg,omitempty"`
+	Hostname string   `json:"hostname,omitempty"` // Hostname PTR запись
+}
+
+type TraceroutePayload struct {
+	CommandPayload
+	Hops []Hop `json:"hops"`
+}
+
+type MXRecord struct {
+	Host string `json:"host"`
+	Pref uint16 `json:"pref"`
+}
+
+// DNSPayload на проводе плоский объект: {"A": [...], "MX": [...], "AAAA_error": "..."}.
+type DNSPayload struct {
+	A      []string          `json:"A,omitempty"`
+	AAAA   []string          `json:"AAAA,omitempty"`
+	MX     []MXRecord        `json:"MX,omitempty"`
+	Errors map[string]string `json:"-"` // Errors ошибки по типу записи, на проводе ключи вида "<TYPE>_error"
+}
+
+const dnsErrorSuffix = "_error"
+
+func (p DNSPayload) MarshalJSON() ([]byte, error) {
+	type plain DNSPayload
+
+	b, err := json.Marshal(plain(p))
+	if err != nil || len(p.Errors) == 0 {
+		return b, err
+	}
+
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, err
+	}
+
+	for rr, e := range p.Errors {
+		raw, err := json.Marshal(e)
+		if err != nil {
+			return nil, err
+		}
+
+		m[rr+dnsErrorSuffix] = raw
+	}
+
+	return json.Marshal(m)
+}
+
+func (p *DNSPayload) UnmarshalJSON(data []byte) error {
+	type plain DNSPayload
+
+	var out plain
+	if err := json.Unmarshal(data, &out); err != nil {
+		return err
+	}
+
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(data, &m); err != nil {
+		return err
+	}
+
+	for k, v := range m {
+		rr, ok := strings.CutSuffix(k, dnsErrorSuffix)
+		if !ok {
+			continue
+		}
+
+		var e string
+		if err := json.Unmarshal(v, &e); err != nil {
+			return err
+		}
+
+		if out.Errors == nil {
+			out.Errors = make(map[string]string)
+		}
+
+		out.Errors[rr] = e
+	}
+
+	*p = DNSPayload(out)
+
+	return nil
+}
+
+type WebSocketPayload struct {
+	URL         string `json:"url"`
+	Status      int    `json:"status,omitempty"`
+	ConnectMs   int64  `json:"connectMs"`
+	HandshakeMs int64  `json:"handshakeMs,omitempty"`
+	Subprotocol string `json:"subprotocol,omitempty"`
+	RTTMs       int64  `json:"rttMs,omitempty"`
+	Reply       string `json:"reply,omitempty"`
+}
+
+type GRPCPayload struct {
+	Addr      string `json:"addr"`
+	Service   string `json:"service"`
+	TLS       bool   `json:"tls"`
+	ConnectMs int64  `json:"connectMs"`
+	RPCMs     int64  `json:"rpcMs,omitempty"`
+	Code      string `json:"code,omitempty"`   // Code gRPC код ошибки вызова
+	Status    string `json:"status,omitempty"` // Status ответ Health/Check: SERVING, NOT_SERVING, ...
+}
+
+type Stall struct {
+	AtMs       int64 `json:"atMs"`
+	DurationMs int64 `json:"durationMs"`
+}
+
+type ThroughputPayload struct {
+	URL           string  `json:"url"`
+	Status        int     `json:"status,omitempty"`
+	Bytes         int64   `json:"bytes"`
+	ContentLength int64   `json:"contentLength"`
+	TTFBMs        int64   `json:"ttfbMs"`
+	DurationMs    int64   `json:"durationMs"`
+	AvgBps        int64   `json:"avgBps"`
+	PeakBps       int64   `json:"peakBps"`
+	AvgMbps       float64 `json:"avgMbps"`
+	PeakMbps      float64 `json:"peakMbps"`
+	Stalls        []Stall `json:"stalls"`
+	StalledMs     int64   `json:"stalledMs"`
+	StoppedBy     string  `json:"stoppedBy"` // StoppedBy eof|size|time
+}
+
+var payloadTypes = map[string]reflect.Type{
+	CheckHTTP:       reflect.TypeFor[HTTPPayload](),
+	CheckPing:       reflect.TypeFor[PingPayload](),
+	CheckTCP:        reflect.TypeFor[TCPPayload](),
+	CheckTraceroute: reflect.TypeFor[TraceroutePayload](),
+	CheckDNS:        reflect.TypeFor[DNSPayload](),
+	CheckWebSocket:  reflect.TypeFor[WebSocketPayload](),
+	CheckGRPC:       reflect.TypeFor[GRPCPayload](),
+	CheckThroughput: reflect.TypeFor[ThroughputPayload](),
+}
@@ -0,0 +1,42 @@
+package contract
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CheckResult результат одной проверки, который агент публикует в топик результатов.
+// С версии 2.2 перед запуском проверки агент публикует промежуточное сообщение без payload (seq 1):
+// по нему бэкенд переводит назначение в RUNNING.
+type CheckResult struct {
+	Version      string          `json:"version"` // Version версия контракта, см. SchemaVersion
+	TaskID       uuid.UUID       `json:"taskId"`
+	AgentID      uuid.UUID       `json:"agentId"`      // AgentID агент, выполнивший проверку; совпадает с подписантом конверта
+	AssignmentID uuid.UUID       `json:"assignmentId"` // AssignmentID из задачи, нулевой у агентов до версии 2.1
+	CheckIndex   int             `json:"checkIndex"`
+	Type         string          `json:"type"`
+	Target       string          `json:"target"`
+	StartedAt    time.Time       `json:"startedAt"`
+	DurationMs   int64           `json:"durationMs"`
+	OK           bool            `json:"ok"`
+	Error        string          `json:"error,omitempty"`
+	Payload      json.RawMessage `json:"payload,omitempty"` // разный по проверкам, см. payload.go
+	Seq          int             `json:"seq,omitempty"`     // Seq номер сообщения в рамках проверки, начиная с 1
+	Final        bool            `json:"final"`             // Final последнее сообщение проверки, до него идут промежуточные с частичным payload
+}
+
+// IsFinal сообщает, что результат окончательный. Сообщения без seq (до версии 1.1) всегда окончательные.
+func (r CheckResult) IsFinal() bool {
+	return r.Final || r.Seq == 0
+}
+
+// DecodePayload раскладывает Payload в типизированную структуру из payload.go.
+func (r CheckResult) DecodePayload(out any) error {
+	if len(r.Payload) == 0 {
+		return nil
+	}
+
+	return json.Unmarshal(r.Payload, out)
+}
@@ -0,0 +1,297 @@
+package contract
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const jsonSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// Schema подмножество JSON Schema, которого достаточно для описания контракта.
+type Schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	ID                   string             `json:"$id,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+	Default              any                `json:"default,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+}
+
+var (
+	timeType  = reflect.TypeFor[time.Time]()
+	uuidType  = reflect.TypeFor[uuid.UUID]()
+	rawType   = reflect.TypeFor[json.RawMessage]()
+	bytesType = reflect.TypeFor[[]byte]()
+)
+
+// TaskSchema JSON Schema сообщения задачи.
+func TaskSchema() *Schema {
+	return rootSchema("task", "Задача на проверку, которую бэкенд отправляет агентам", reflect.TypeFor[TaskMessage]())
+}
+
+// ResultSchema JSON Schema сообщения с результатом проверки.
+func ResultSchema() *Schema {
+	return rootSchema("result", "Результат одной проверки от агента", reflect.TypeFor[CheckResult]())
+}
+
+// HeartbeatSchema JSON Schema heartbeat сообщения агента.
+func HeartbeatSchema() *Schema {
+	return rootSchema("heartbeat", "Heartbeat агента с его состоянием", reflect.TypeFor[Heartbeat]())
+}
+
+// GatewaySchema JSON Schema сообщения HTTP транспорта агента.
+func GatewaySchema() *Schema {
+	return rootSchema("gateway", "Сообщение HTTP транспорта агента вместо сообщения Kafka", reflect.TypeFor[GatewayMessage]())
+}
+
+// EnrollSchema JSON Schema запроса регистрации агента по одноразовому токену.
+func EnrollSchema() *Schema {
+	return rootSchema("enroll", "Регистрация агента по одноразовому токену", reflect.TypeFor[EnrollRequest]())
+}
+
+// EnvelopeSchema JSON Schema подписанного конверта, в котором передаются задачи, результаты и heartbeat.
+func EnvelopeSchema() *Schema {
+	return rootSchema("envelope", "Подписанный Ed25519 конверт сообщения", reflect.TypeFor[Envelope]())
+}
+
+// ParamsSchema JSON Schema параметров проверки checkType.
+func ParamsSchema(checkType string) (*Schema, error) {
+	t, ok := paramTypes[strings.ToLower(checkType)]
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownCheckType, checkType)
+	}
+
+	return rootSchema("params/"+checkType, "Параметры проверки "+checkType, t), nil
+}
+
+// PayloadSchema JSON Schema payload результата проверки checkType.
+func PayloadSchema(checkType string) (*Schema, error) {
+	t, ok := payloadTypes[strings.ToLower(checkType)]
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownCheckType, checkType)
+	}
+
+	s := rootSchema("payload/"+checkType, "Payload результата проверки "+checkType, t)
+	if t == reflect.TypeFor[DNSPayload]() {
+		// ошибки по типам записей лежат в плоских ключах "<TYPE>_error"
+		s.AdditionalProperties = &Schema{Type: "string"}
+	}
+
+	return s, nil
+}
+
+func rootSchema(id, title string, t reflect.Type) *Schema {
+	s := schemaFor(t)
+	s.Schema = jsonSchemaDraft
+	s.ID = "hackathon-contract/v" + SchemaVersion + "/" + id
+	s.Title = title
+
+	return s
+}
+
+func schemaFor(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case uuidType:
+		return &Schema{Type: "string", Format: "uuid"}
+	case rawType:
+		return &Schema{}
+	case bytesType:
+		// encoding/json пишет []byte строкой base64
+		return &Schema{Type: "string", Format: "byte"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice:
+		return &Schema{Type: "array", Items: schemaFor(t.Elem())}
+	case reflect.Array:
+		n := t.Len()
+		return &Schema{Type: "array", Items: schemaFor(t.Elem()), MinItems: &n, MaxItems: &n}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: schemaFor(t.Elem())}
+	case reflect.Struct:
+		s := &Schema{Type: "object", Properties: map[string]*Schema{}}
+		addStructFields(s, t)
+
+		return s
+	default:
+		// interface{} и прочее — любой JSON
+		return &Schema{}
+	}
+}
+
+func addStructFields(s *Schema, t reflect.Type) {
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+
+		if f.Anonymous && name == "" && f.Type.Kind() == reflect.Struct {
+			addStructFields(s, f.Type)
+			continue
+		}
+
+		if name == "" {
+			name = f.Name
+		}
+
+		prop := schemaFor(f.Type)
+
+		rules, err := ParseRules(f.Tag.Get("jsonschema"))
+		if err != nil {
+			panic(fmt.Sprintf("contract: %s.%s: %v", t.Name(), f.Name, err))
+		}
+
+		rules.apply(prop)
+
+		if rules.Required {
+			s.Required = append(s.Required, name)
+		}
+
+		s.Properties[name] = prop
+	}
+}
+
+// Rules ограничения поля из тега jsonschema.
+type Rules struct {
+	Required    bool
+	Default     any
+	Minimum     *float64
+	Maximum     *float64
+	MinLength   *int
+	Enum        []string
+	Description string
+}
+
+// ParseRules разбирает тег jsonschema вида "required,default=4,minimum=1,enum=a|b,description=...".
+func ParseRules(tag string) (Rules, error) {
+	var r Rules
+
+	for tag != "" {
+		var part string
+
+		// description — последним, в нём допускаются запятые
+		if strings.HasPrefix(tag, "description=") {
+			part, tag = tag, ""
+		} else {
+			part, tag = cutRule(tag)
+		}
+
+		key, val, _ := strings.Cut(part, "=")
+
+		switch key {
+		case "required":
+			r.Required = true
+		case "default":
+			var v any
+			if err := json.Unmarshal([]byte(val), &v); err != nil {
+				// строки в теге пишутся без кавычек
+				v = val
+			}
+
+			r.Default = v
+		case "minimum", "maximum":
+			f, err := strconv.ParseFloat(val, 64)
+			if err != nil {
+				return r, fmt.Errorf("bad %s %q: %w", key, val, err)
+			}
+
+			if key == "minimum" {
+				r.Minimum = &f
+			} else {
+				r.Maximum = &f
+			}
+		case "minLength":
+			n, err := strconv.Atoi(val)
+			if err != nil {
+				return r, fmt.Errorf("bad minLength %q: %w", val, err)
+			}
+
+			r.MinLength = &n
+		case "enum":
+			r.Enum = strings.Split(val, "|")
+		case "description":
+			r.Description = val
+		default:
+			return r, fmt.Errorf("unknown jsonschema rule %q", key)
+		}
+	}
+
+	return r, nil
+}
+
+// cutRule отрезает первое правило по запятой вне квадратных скобок (default=[200,299]).
+func cutRule(tag string) (string, string) {
+	depth := 0
+
+	for i, c := range tag {
+		switch c {
+		case '[':
+			depth++
+		case ']':
+			depth--
+		case ',':
+			if depth == 0 {
+				return tag[:i], tag[i+1:]
+			}
+		}
+	}
+
+	return tag, ""
+}
+
+func (r Rules) apply(s *Schema) {
+	s.Default = r.Default
+	s.Description = r.Description
+
+	// для массивов ограничения относятся к элементам
+	target := s
+	if s.Type == "array" && s.Items != nil {
+		target = s.Items
+	}
+
+	target.Minimum = r.Minimum
+	target.Maximum = r.Maximum
+	target.MinLength = r.MinLength
+
+	for _, e := range r.Enum {
+		target.Enum = append(target.Enum, e)
+	}
+}
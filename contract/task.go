@@ -0,0 +1,73 @@
+package contract
+
+import (
+	"encoding/json"
+
+	"github.com/google/uuid"
+)
+
+type TaskMessage struct {
+	Version        string            `json:"version"`            // Version версия контракта, см. SchemaVersion
+	ID             uuid.UUID         `json:"id"`                 // ID уникальный идентификатор задачи
+	AssignmentID   uuid.UUID         `json:"assignmentId"`       // AssignmentID назначение задачи агенту, агент возвращает его в каждом CheckResult
+	AgentID        uuid.UUID         `json:"agentId,omitempty"`  // AgentID агент, которому назначена задача: остальные агенты региона её пропускают; нет у задач до версии 2.7
+	Target         string            `json:"target"`             // Target домен или IP, который нужно проверить
+	TimeoutSeconds int               `json:"timeoutSeconds"`     // TimeoutSeconds время выполнения всех задачи в секундах
+	ClientContext  ClientContext     `json:"clientContext"`      // ClientContext информация о клиенте, от которого инициирована проверка
+	Checks         []CheckRequest    `json:"checks"`             // Checks список проверок
+	Metadata       map[string]string `json:"metadata,omitempty"` // Metadata дополнительная информация
+}
+
+type ClientContext struct {
+	IP        string `json:"ip,omitempty"`
+	ASN       int    `json:"asn,omitempty"`
+	Geo       Geo    `json:"geo,omitempty"`
+	UserAgent string `json:"userAgent,omitempty"`
+}
+
+type Geo struct {
+	Region    string `json:"region,omitempty"`
+	Continent string `json:"continent,omitempty"`
+}
+
+// CheckRequest одна проверка в задаче. Params на проводе — произвольный объект,
+// типизированный вид получается через DecodeParams в структуру из params.go.
+type CheckRequest struct {
+	Type   string                 `json:"type"`
+	Params map[string]interface{} `json:"params"`
+	Index  *int                   `json:"index,omitempty"` // Index номер проверки в исходном запросе, если бэкенд разделил проверки между агентами
+}
+
+// CheckIndex номер проверки для CheckResult.CheckIndex: Index, а без него позиция pos в задаче.
+func (c CheckRequest) CheckIndex(pos int) int {
+	if c.Index != nil {
+		return *c.Index
+	}
+
+	return pos
+}
+
+// DecodeParams раскладывает Params в типизированную структуру параметров.
+func (c CheckRequest) DecodeParams(out any) error {
+	return DecodeLoose(c.Params, out)
+}
+
+// DecodeLoose перекладывает map в структуру через JSON.
+func DecodeLoose(m map[string]interface{}, out any) error {
+	b, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(b, out)
+}
+
+// ToMap обратная операция к DecodeLoose.
+func ToMap(v any) map[string]interface{} {
+	b, _ := json.Marshal(v)
+
+	var m map[string]interface{}
+	_ = json.Unmarshal(b, &m)
+
+	return m
+}
@@ -0,0 +1,330 @@
+package contract
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+var ErrInvalidParams = errors.New("invalid check params")
+
+// FieldError ошибка валидации одного поля.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationError набор ошибок по полям, errors.Is(err, ErrInvalidParams) == true.
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	parts := make([]string, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		parts = append(parts, f.Field+": "+f.Message)
+	}
+
+	return fmt.Sprintf("%s: %s", ErrInvalidParams, strings.Join(parts, "; "))
+}
+
+func (e *ValidationError) Unwrap() error {
+	return ErrInvalidParams
+}
+
+// WithPrefix возвращает копию ошибок с путём prefix перед именем каждого поля.
+func (e *ValidationError) WithPrefix(prefix string) *ValidationError {
+	out := &ValidationError{Fields: make([]FieldError, 0, len(e.Fields))}
+	for _, f := range e.Fields {
+		out.Fields = append(out.Fields, FieldError{Field: prefix + "." + f.Field, Message: f.Message})
+	}
+
+	return out
+}
+
+// crossValidator реализуют параметры, у которых есть ограничения между полями.
+type crossValidator interface {
+	validate() []FieldError
+}
+
+func (p HTTPParams) validate() []FieldError {
+	if p.ExpectedStatusRange[0] > p.ExpectedStatusRange[1] {
+		return []FieldError{{Field: "expectedStatusRange", Message: "lower bound is greater than upper bound"}}
+	}
+
+	return nil
+}
+
+func (p ThroughputParams) validate() []FieldError {
+	if p.RangeEnd > 0 && p.RangeEnd < p.RangeStart {
+		return []FieldError{{Field: "rangeEnd", Message: "must be >= rangeStart"}}
+	}
+
+	return nil
+}
+
+// NormalizeParams строго проверяет параметры проверки checkType по схеме из тегов jsonschema
+// и подставляет значения по умолчанию. Неизвестные поля, неверные типы и значения вне диапазона
+// возвращаются одной *ValidationError со всеми найденными ошибками, неизвестный тип — ErrUnknownCheckType.
+func NormalizeParams(checkType string, params map[string]interface{}) (map[string]interface{}, error) {
+	t, ok := paramTypes[strings.ToLower(checkType)]
+	if !ok {
+		return nil, fmt.Errorf("%w %q, expected one of: %s", ErrUnknownCheckType, checkType, strings.Join(CheckTypes(), ", "))
+	}
+
+	v := reflect.New(t).Elem()
+	errs := decodeStrict(v, params)
+
+	// поле с ошибкой типа дальше не проверяем, иначе к ней добавится ещё и "is required"
+	failed := make(map[string]struct{}, len(errs))
+	for _, e := range errs {
+		failed[e.Field] = struct{}{}
+	}
+
+	for i := range t.NumField() {
+		f := t.Field(i)
+		name := jsonName(f)
+
+		if _, ok := failed[name]; ok {
+			continue
+		}
+
+		rules, err := ParseRules(f.Tag.Get("jsonschema"))
+		if err != nil {
+			return nil, fmt.Errorf("%s.%s: %w", t.Name(), f.Name, err)
+		}
+
+		fv := v.Field(i)
+		if fv.IsZero() && rules.Default != nil {
+			if err := setDefault(fv, rules.Default); err != nil {
+				return nil, fmt.Errorf("%s.%s default: %w", t.Name(), f.Name, err)
+			}
+		}
+
+		errs = append(errs, rules.check(name, fv)...)
+	}
+
+	if cv, ok := v.Interface().(crossValidator); ok && len(errs) == 0 {
+		errs = append(errs, cv.validate()...)
+	}
+
+	if len(errs) > 0 {
+		sort.SliceStable(errs, func(i, j int) bool { return errs[i].Field < errs[j].Field })
+		return nil, &ValidationError{Fields: errs}
+	}
+
+	return ToMap(v.Interface()), nil
+}
+
+// decodeStrict раскладывает params по полям структуры v, поле за полем,
+// чтобы собрать ошибки типов всех полей, а не только первого.
+func decodeStrict(v reflect.Value, params map[string]interface{}) []FieldError {
+	t := v.Type()
+	fields := make(map[string]int, t.NumField())
+
+	for i := range t.NumField() {
+		fields[jsonName(t.Field(i))] = i
+	}
+
+	var errs []FieldError
+
+	for key, raw := range params {
+		i, ok := fields[key]
+		if !ok {
+			errs = append(errs, FieldError{Field: key, Message: "unknown field"})
+			continue
+		}
+
+		if raw == nil {
+			continue
+		}
+
+		fv := v.Field(i)
+		raw = coerceString(fv.Type(), raw)
+
+		b, err := json.Marshal(raw)
+		if err != nil {
+			errs = append(errs, FieldError{Field: key, Message: err.Error()})
+			continue
+		}
+
+		ptr := reflect.New(fv.Type())
+		if err := json.Unmarshal(b, ptr.Interface()); err != nil {
+			errs = append(errs, FieldError{Field: key, Message: "must be " + typeName(fv.Type())})
+			continue
+		}
+
+		fv.Set(ptr.Elem())
+	}
+
+	return errs
+}
+
+// coerceString разбирает составные значения, пришедшие строкой: "[200,299]", "200,299", `{"a":"b"}`, `["A","MX"]`, "A,MX", "A".
+// Пустая строка считается отсутствующим значением.
+func coerceString(t reflect.Type, raw interface{}) interface{} {
+	s, ok := raw.(string)
+	if !ok {
+		return raw
+	}
+
+	kind := t.Kind()
+
+	switch kind {
+	case reflect.Map, reflect.Slice, reflect.Array:
+	default:
+		return raw
+	}
+
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil
+	}
+
+	if kind != reflect.Map && !strings.HasPrefix(s, "[") {
+		parts := strings.Split(s, ",")
+
+		// элементы-строки приходят без кавычек
+		if t.Elem().Kind() == reflect.String {
+			for i, part := range parts {
+				quoted, err := json.Marshal(strings.TrimSpace(part))
+				if err != nil {
+					return raw
+				}
+
+				parts[i] = string(quoted)
+			}
+		}
+
+		s = "[" + strings.Join(parts, ",") + "]"
+	}
+
+	var out interface{}
+	if err := json.Unmarshal([]byte(s), &out); err != nil {
+		return raw
+	}
+
+	return out
+}
+
+func setDefault(fv reflect.Value, def any) error {
+	b, err := json.Marshal(def)
+	if err != nil {
+		return err
+	}
+
+	ptr := reflect.New(fv.Type())
+	if err := json.Unmarshal(b, ptr.Interface()); err != nil {
+		return err
+	}
+
+	fv.Set(ptr.Elem())
+
+	return nil
+}
+
+func (r Rules) check(name string, fv reflect.Value) []FieldError {
+	if r.Required && fv.IsZero() {
+		return []FieldError{{Field: name, Message: "is required"}}
+	}
+
+	switch fv.Kind() {
+	case reflect.Slice, reflect.Array:
+		var errs []FieldError
+		for i := range fv.Len() {
+			errs = append(errs, r.checkScalar(fmt.Sprintf("%s[%d]", name, i), fv.Index(i))...)
+		}
+
+		return errs
+	default:
+		return r.checkScalar(name, fv)
+	}
+}
+
+func (r Rules) checkScalar(name string, fv reflect.Value) []FieldError {
+	var num float64
+
+	switch fv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		num = float64(fv.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		num = float64(fv.Uint())
+	case reflect.Float32, reflect.Float64:
+		num = fv.Float()
+	case reflect.String:
+		s := fv.String()
+		if r.MinLength != nil && len(s) < *r.MinLength {
+			return []FieldError{{Field: name, Message: fmt.Sprintf("must be at least %d characters", *r.MinLength)}}
+		}
+
+		if len(r.Enum) == 0 {
+			return nil
+		}
+
+		canonical, ok := lookupFold(r.Enum, s)
+		if !ok {
+			return []FieldError{{Field: name, Message: "must be one of: " + strings.Join(r.Enum, ", ")}}
+		}
+
+		// "UDP" -> "udp", "mx" -> "MX": дальше значения сравниваются точно
+		fv.SetString(canonical)
+
+		return nil
+	default:
+		return nil
+	}
+
+	if r.Minimum != nil && num < *r.Minimum {
+		return []FieldError{{Field: name, Message: fmt.Sprintf("must be >= %v", *r.Minimum)}}
+	}
+
+	if r.Maximum != nil && num > *r.Maximum {
+		return []FieldError{{Field: name, Message: fmt.Sprintf("must be <= %v", *r.Maximum)}}
+	}
+
+	return nil
+}
+
+func lookupFold(list []string, s string) (string, bool) {
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return v, true
+		}
+	}
+
+	return "", false
+}
+
+func jsonName(f reflect.StructField) string {
+	name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+	if name == "" {
+		return f.Name
+	}
+
+	return name
+}
+
+func typeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "an integer"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.String:
+		return "a string"
+	case reflect.Array:
+		return fmt.Sprintf("an array of %d elements", t.Len())
+	case reflect.Slice:
+		return "an array"
+	case reflect.Map:
+		return "an object"
+	default:
+		return t.String()
+	}
+}
@@ -0,0 +1,188 @@
+package contract
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestNormalizeParamsDefaults(t *testing.T) {
+	params, err := NormalizeParams("PING", map[string]interface{}{})
+	if err != nil {
+		t.Fatalf("NormalizeParams: %v", err)
+	}
+
+	want := map[string]interface{}{"count": float64(4), "intervalMs": float64(1000)}
+	if !reflect.DeepEqual(params, want) {
+		t.Fatalf("got %v, want %v", params, want)
+	}
+}
+
+func TestNormalizeParamsCoercesValues(t *testing.T) {
+	tests := []struct {
+		name      string
+		checkType string
+		params    map[string]interface{}
+		field     string
+		want      interface{}
+	}{
+		{
+			name:      "enum is case insensitive",
+			checkType: CheckTraceroute,
+			params:    map[string]interface{}{"mode": "UDP"},
+			field:     "mode",
+			want:      "udp",
+		},
+		{
+			name:      "list from JSON string",
+			checkType: CheckDNS,
+			params:    map[string]interface{}{"records": `["a","mx"]`},
+			field:     "records",
+			want:      []interface{}{"A", "MX"},
+		},
+		{
+			name:      "list from comma separated string",
+			checkType: CheckDNS,
+			params:    map[string]interface{}{"records": "A, MX"},
+			field:     "records",
+			want:      []interface{}{"A", "MX"},
+		},
+		{
+			name:      "list from single value",
+			checkType: CheckDNS,
+			params:    map[string]interface{}{"records": "A"},
+			field:     "records",
+			want:      []interface{}{"A"},
+		},
+		{
+			name:      "list from single lower case value",
+			checkType: CheckDNS,
+			params:    map[string]interface{}{"records": "mx"},
+			field:     "records",
+			want:      []interface{}{"MX"},
+		},
+		{
+			name:      "array from string",
+			checkType: CheckHTTP,
+			params:    map[string]interface{}{"expectedStatusRange": "200,204"},
+			field:     "expectedStatusRange",
+			want:      []interface{}{float64(200), float64(204)},
+		},
+		{
+			name:      "empty string is a missing value",
+			checkType: CheckDNS,
+			params:    map[string]interface{}{"records": ""},
+			field:     "records",
+			want:      []interface{}{"A"},
+		},
+		{
+			name:      "ping interval from the UI",
+			checkType: CheckPing,
+			params:    map[string]interface{}{"intervalMs": 50},
+			field:     "intervalMs",
+			want:      float64(50),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params, err := NormalizeParams(tt.checkType, tt.params)
+			if err != nil {
+				t.Fatalf("NormalizeParams: %v", err)
+			}
+
+			if !reflect.DeepEqual(params[tt.field], tt.want) {
+				t.Fatalf("got %s = %#v, want %#v", tt.field, params[tt.field], tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeParamsFieldErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		checkType string
+		params    map[string]interface{}
+		want      []FieldError
+	}{
+		{
+			name:      "required",
+			checkType: CheckTCP,
+			params:    map[string]interface{}{},
+			want:      []FieldError{{Field: "port", Message: "is required"}},
+		},
+		{
+			name:      "range",
+			checkType: CheckPing,
+			params:    map[string]interface{}{"count": 101, "intervalMs": 49},
+			want: []FieldError{
+				{Field: "count", Message: "must be <= 100"},
+				{Field: "intervalMs", Message: "must be >= 50"},
+			},
+		},
+		{
+			name:      "type error is reported once",
+			checkType: CheckTCP,
+			params:    map[string]interface{}{"port": "https"},
+			want:      []FieldError{{Field: "port", Message: "must be an integer"}},
+		},
+		{
+			name:      "unknown field",
+			checkType: CheckTCP,
+			params:    map[string]interface{}{"port": 443, "timeout": 1},
+			want:      []FieldError{{Field: "timeout", Message: "unknown field"}},
+		},
+		{
+			name:      "enum",
+			checkType: CheckDNS,
+			params:    map[string]interface{}{"records": []interface{}{"A", "TXT"}},
+			want:      []FieldError{{Field: "records[1]", Message: "must be one of: A, AAAA, MX"}},
+		},
+		{
+			name:      "cross field",
+			checkType: CheckHTTP,
+			params:    map[string]interface{}{"expectedStatusRange": []interface{}{299, 200}},
+			want:      []FieldError{{Field: "expectedStatusRange", Message: "lower bound is greater than upper bound"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NormalizeParams(tt.checkType, tt.params)
+			if !errors.Is(err, ErrInvalidParams) {
+				t.Fatalf("got %v, want %v", err, ErrInvalidParams)
+			}
+
+			var vErr *ValidationError
+			if !errors.As(err, &vErr) {
+				t.Fatalf("got %T, want *ValidationError", err)
+			}
+
+			if !reflect.DeepEqual(vErr.Fields, tt.want) {
+				t.Fatalf("got %v, want %v", vErr.Fields, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeParamsUnknownCheckType(t *testing.T) {
+	_, err := NormalizeParams("smtp", map[string]interface{}{})
+	if !errors.Is(err, ErrUnknownCheckType) {
+		t.Fatalf("got %v, want %v", err, ErrUnknownCheckType)
+	}
+}
+
+func TestValidationErrorWithPrefix(t *testing.T) {
+	err := &ValidationError{Fields: []FieldError{{Field: "port", Message: "is required"}}}
+
+	prefixed := err.WithPrefix("checks[1].params")
+
+	want := []FieldError{{Field: "checks[1].params.port", Message: "is required"}}
+	if !reflect.DeepEqual(prefixed.Fields, want) {
+		t.Fatalf("got %v, want %v", prefixed.Fields, want)
+	}
+
+	if err.Fields[0].Field != "port" {
+		t.Fatalf("WithPrefix modified the original error: %v", err.Fields)
+	}
+}